@@ -1,15 +1,21 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
+	"time"
 
 	"github.com/Orololuwa/go-backend-boilerplate/src/config"
 	"github.com/Orololuwa/go-backend-boilerplate/src/driver"
 	"github.com/Orololuwa/go-backend-boilerplate/src/handlers"
+	"github.com/Orololuwa/go-backend-boilerplate/src/jobs"
+	"github.com/Orololuwa/go-backend-boilerplate/src/payments"
+	dbrepo "github.com/Orololuwa/go-backend-boilerplate/src/repository/db-repo"
 	"github.com/go-playground/validator/v10"
 	"github.com/joho/godotenv"
 )
@@ -27,6 +33,11 @@ func main (){
 	}
 	defer db.SQL.Close()
 
+	go jobs.SweepExpiredHolds(context.Background(), &app, handlers.Repo.DB, time.Minute)
+	go jobs.SweepExpiredIdempotencyKeys(context.Background(), &app, dbrepo.NewIdempotencyDBRepo(db.SQL), time.Hour)
+	go jobs.ExpireWaitlistOffers(context.Background(), &app, handlers.Repo.Waitlist, time.Minute)
+	go jobs.SyncCalendarFeeds(context.Background(), &app, handlers.Repo.CalendarSync, 15*time.Minute)
+
 	fmt.Println(fmt.Sprintf("Staring application on port %s", portNumber))
 
 	srv := &http.Server{
@@ -54,6 +65,10 @@ func run() (*driver.DB, error) {
 	dbUser := os.Getenv("DB_USER")
 	dbPassword := os.Getenv("DB_PASSWORD")
 	dbSSL := os.Getenv("DB_SSL")
+	holdDuration := os.Getenv("HOLD_DURATION_MINUTES")
+	idempotencyTTL := os.Getenv("IDEMPOTENCY_TTL_HOURS")
+	idempotencyLease := os.Getenv("IDEMPOTENCY_LEASE_SECONDS")
+	waitlistOfferDuration := os.Getenv("WAITLIST_OFFER_HOURS")
 	
 	// read flags
 	// goEnv := flag.String("goenv", "development", "the application environment")
@@ -67,6 +82,44 @@ func run() (*driver.DB, error) {
 	// flag.Parse()
 
 	app.GoEnv = goEnv
+	app.PaymentGateway = os.Getenv("PAYMENT_GATEWAY")
+	app.PaymentWebhookSecret = os.Getenv("PAYMENT_WEBHOOK_SECRET")
+	app.PropertyName = os.Getenv("PROPERTY_NAME")
+	app.InvoiceSeries = os.Getenv("INVOICE_SERIES")
+	app.Currency = os.Getenv("CURRENCY")
+	app.PhoneCountryCode = os.Getenv("PHONE_COUNTRY_CODE")
+
+	if holdDuration != "" {
+		minutes, err := strconv.Atoi(holdDuration)
+		if err != nil {
+			log.Fatal("Invalid HOLD_DURATION_MINUTES: ", err)
+		}
+		app.HoldDuration = time.Duration(minutes) * time.Minute
+	}
+
+	if idempotencyTTL != "" {
+		hours, err := strconv.Atoi(idempotencyTTL)
+		if err != nil {
+			log.Fatal("Invalid IDEMPOTENCY_TTL_HOURS: ", err)
+		}
+		app.IdempotencyTTL = time.Duration(hours) * time.Hour
+	}
+
+	if idempotencyLease != "" {
+		seconds, err := strconv.Atoi(idempotencyLease)
+		if err != nil {
+			log.Fatal("Invalid IDEMPOTENCY_LEASE_SECONDS: ", err)
+		}
+		app.IdempotencyLease = time.Duration(seconds) * time.Second
+	}
+
+	if waitlistOfferDuration != "" {
+		hours, err := strconv.Atoi(waitlistOfferDuration)
+		if err != nil {
+			log.Fatal("Invalid WAITLIST_OFFER_HOURS: ", err)
+		}
+		app.WaitlistOfferDuration = time.Duration(hours) * time.Hour
+	}
 
 	infoLog = log.New(os.Stdout, "INFO\t", log.Ldate|log.Ltime)
 	app.InfoLog = infoLog
@@ -77,6 +130,11 @@ func run() (*driver.DB, error) {
 	validate := validator.New(validator.WithRequiredStructEnabled())
 	app.Validate = validate
 
+	gateway, err := payments.NewGateway(app.PaymentGateway, app.PaymentWebhookSecret, app.GoEnv)
+	if err != nil {
+		log.Fatal("Invalid PAYMENT_GATEWAY: ", err)
+	}
+
 	// Connecto to DB
 	log.Println("Connecting to dabase")
 	connectionString := fmt.Sprintf("host=%s port=%s dbname=%s user=%s password=%s sslmode=%s", dbHost, dbPort, dbName, dbUser, dbPassword, dbSSL)
@@ -87,7 +145,7 @@ func run() (*driver.DB, error) {
 	log.Println("Connected to database")
 	// 
 
-	repo := handlers.NewRepo(&app, db)
+	repo := handlers.NewRepo(&app, db, gateway)
 	handlers.NewHandlers(repo)
 
 	return db, nil
@@ -2,6 +2,7 @@ package main
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/Orololuwa/go-backend-boilerplate/src/config"
 	"github.com/Orololuwa/go-backend-boilerplate/src/driver"
@@ -25,13 +26,113 @@ func routes(a *config.AppConfig, conn *driver.DB) http.Handler {
 	mux.Get("/health", handlers.Repo.Health)
 
 	// reservations
-	mux.Post("/reservation", handlers.Repo.PostReservation)
+	mux.Post("/reservation", md.Idempotency(http.HandlerFunc(handlers.Repo.PostReservation)).ServeHTTP)
+	mux.Get("/reservation", md.Authorization(http.HandlerFunc(handlers.Repo.GetReservations)).ServeHTTP)
+	mux.Get("/reservation/lookup", md.RateLimit(http.HandlerFunc(handlers.Repo.LookupReservation), 10, time.Minute).ServeHTTP)
+	mux.Post("/reservation/{id}/payment", md.ValidateReqBody(http.HandlerFunc(handlers.Repo.PostReservationPayment), &dtos.PaymentBody{}).ServeHTTP)
+	mux.Get("/reservation/{id}/payment", md.Authorization(http.HandlerFunc(handlers.Repo.GetReservationPayments)).ServeHTTP)
+	mux.Post("/reservation/{id}/payment/capture", md.Authorization(http.HandlerFunc(handlers.Repo.CaptureReservationPayment)).ServeHTTP)
+	mux.Post("/reservation/{id}/payment/refund", md.Authorization(md.ValidateReqBody(http.HandlerFunc(handlers.Repo.RefundReservationPayment), &dtos.RefundBody{})).ServeHTTP)
+	mux.Post("/reservation/{id}/cancel", md.ValidateReqBody(http.HandlerFunc(handlers.Repo.CancelReservation), &dtos.CancelBody{}).ServeHTTP)
+	mux.Post("/reservation/{id}/no-show", md.Authorization(http.HandlerFunc(handlers.Repo.PostNoShow)).ServeHTTP)
+	mux.Get("/reservation/{id}/history", md.Authorization(http.HandlerFunc(handlers.Repo.GetReservationHistory)).ServeHTTP)
+	mux.Post("/reservation/{id}/special-requests/resolve", md.Authorization(http.HandlerFunc(handlers.Repo.ResolveReservationRequests)).ServeHTTP)
+	mux.Get("/reservation/{id}/notes", md.Authorization(http.HandlerFunc(handlers.Repo.GetReservationNotes)).ServeHTTP)
+	mux.Post("/reservation/{id}/notes", md.Authorization(md.ValidateReqBody(http.HandlerFunc(handlers.Repo.PostReservationNote), &dtos.ReservationNoteBody{})).ServeHTTP)
+	mux.Post("/reservation/hold", md.ValidateReqBody(http.HandlerFunc(handlers.Repo.PostRoomHold), &dtos.PostHoldBody{}).ServeHTTP)
+	mux.Get("/reservation/{id}/calendar.ics", handlers.Repo.GetReservationCalendar)
+	mux.Get("/reservation/{id}/invoice", handlers.Repo.GetReservationInvoice)
+	mux.Post("/reservation/group", md.ValidateReqBody(http.HandlerFunc(handlers.Repo.PostReservationGroup), &dtos.GroupReservationBody{}).ServeHTTP)
+	mux.Get("/reservation/group/{id}", handlers.Repo.GetReservationGroup)
+	mux.Put("/reservation/group/{id}", md.ValidateReqBody(http.HandlerFunc(handlers.Repo.UpdateReservationGroup), &dtos.GroupUpdateBody{}).ServeHTTP)
+	mux.Post("/reservation/group/{id}/cancel", md.ValidateReqBody(http.HandlerFunc(handlers.Repo.CancelReservationGroup), &dtos.CancelBody{}).ServeHTTP)
+
+	// waitlist
+	mux.Post("/waitlist", md.ValidateReqBody(http.HandlerFunc(handlers.Repo.PostWaitlist), &dtos.WaitlistBody{}).ServeHTTP)
+	mux.Get("/waitlist", md.Authorization(http.HandlerFunc(handlers.Repo.GetWaitlist)).ServeHTTP)
+
+	// payments
+	mux.Post("/payments/webhook", handlers.Repo.PaymentWebhook)
 
 	// rooms
 	mux.Post("/search-availability", md.ValidateReqBody(http.HandlerFunc(handlers.Repo.SearchAvailability), &dtos.PostAvailabilityBody{} ).ServeHTTP)
 	mux.Post("/search-availability/{id}", md.ValidateReqBody(http.HandlerFunc(handlers.Repo.SearchAvailabilityByRoomId), &dtos.PostAvailabilityBody{}).ServeHTTP)
 	mux.Get("/room", handlers.Repo.GetAllRooms)
 	mux.Get("/room/{id}", handlers.Repo.GetRoomById)
+	mux.Get("/room/{id}/seasonal-rate", handlers.Repo.GetRoomRates)
+	mux.Get("/room/{id}/calendar.ics", handlers.Repo.GetRoomCalendar)
+	mux.Post("/room/{id}/calendar-token", md.Authorization(http.HandlerFunc(handlers.Repo.RotateRoomCalendarToken)).ServeHTTP)
+	mux.Get("/room/{id}/calendar-feed", md.Authorization(http.HandlerFunc(handlers.Repo.GetCalendarFeeds)).ServeHTTP)
+	mux.Post("/room/{id}/calendar-feed", md.Authorization(md.ValidateReqBody(http.HandlerFunc(handlers.Repo.PostCalendarFeed), &dtos.CalendarFeedBody{})).ServeHTTP)
+	mux.Delete("/room/{id}/calendar-feed/{feedId}", md.Authorization(http.HandlerFunc(handlers.Repo.DeleteCalendarFeed)).ServeHTTP)
+	mux.Post("/room/{id}/calendar-feed/{feedId}/sync", md.Authorization(http.HandlerFunc(handlers.Repo.SyncCalendarFeed)).ServeHTTP)
+	mux.Post("/room/{id}/calendar-feed/{feedId}/upload", md.Authorization(http.HandlerFunc(handlers.Repo.UploadCalendarFeed)).ServeHTTP)
+	mux.Put("/room/{id}/rate", md.Authorization(md.ValidateReqBody(http.HandlerFunc(handlers.Repo.UpdateRoomRates), &dtos.RoomRatesBody{})).ServeHTTP)
+	mux.Put("/room/{id}/occupancy", md.Authorization(md.ValidateReqBody(http.HandlerFunc(handlers.Repo.UpdateRoomOccupancy), &dtos.RoomOccupancyBody{})).ServeHTTP)
+	mux.Put("/room/{id}/cancellation-policy", md.Authorization(md.ValidateReqBody(http.HandlerFunc(handlers.Repo.UpdateRoomCancellationPolicy), &dtos.RoomCancellationPolicyBody{})).ServeHTTP)
+	mux.Post("/room/{id}/seasonal-rate", md.Authorization(md.ValidateReqBody(http.HandlerFunc(handlers.Repo.PostRoomRate), &dtos.RoomRateBody{})).ServeHTTP)
+	mux.Delete("/room/{id}/seasonal-rate/{rateId}", md.Authorization(http.HandlerFunc(handlers.Repo.DeleteRoomRate)).ServeHTTP)
+	mux.Get("/room/{id}/block", md.Authorization(http.HandlerFunc(handlers.Repo.GetRoomBlocks)).ServeHTTP)
+	mux.Post("/room/{id}/block", md.Authorization(md.ValidateReqBody(http.HandlerFunc(handlers.Repo.PostRoomBlock), &dtos.RoomBlockBody{})).ServeHTTP)
+	mux.Delete("/room/{id}/block/{blockId}", md.Authorization(http.HandlerFunc(handlers.Repo.DeleteRoomBlock)).ServeHTTP)
+	mux.Get("/room/{id}/recurring-block", md.Authorization(http.HandlerFunc(handlers.Repo.GetRecurringBlocks)).ServeHTTP)
+	mux.Post("/room/{id}/recurring-block", md.Authorization(md.ValidateReqBody(http.HandlerFunc(handlers.Repo.PostRecurringBlock), &dtos.RecurringBlockBody{})).ServeHTTP)
+	mux.Delete("/room/{id}/recurring-block/{blockId}", md.Authorization(http.HandlerFunc(handlers.Repo.DeleteRecurringBlock)).ServeHTTP)
+	mux.Post("/room/{id}/recurring-block/{blockId}/exception", md.Authorization(md.ValidateReqBody(http.HandlerFunc(handlers.Repo.PostRecurringBlockException), &dtos.RecurringBlockExceptionBody{})).ServeHTTP)
+	mux.Get("/room/{id}/stay-rule", md.Authorization(http.HandlerFunc(handlers.Repo.GetStayRules)).ServeHTTP)
+	mux.Post("/room/{id}/stay-rule", md.Authorization(md.ValidateReqBody(http.HandlerFunc(handlers.Repo.PostStayRule), &dtos.StayRuleBody{})).ServeHTTP)
+	mux.Delete("/room/{id}/stay-rule/{ruleId}", md.Authorization(http.HandlerFunc(handlers.Repo.DeleteStayRule)).ServeHTTP)
+
+	// restriction types
+	mux.Get("/restriction", md.Authorization(http.HandlerFunc(handlers.Repo.GetRestrictions)).ServeHTTP)
+	mux.Post("/restriction", md.Authorization(md.ValidateReqBody(http.HandlerFunc(handlers.Repo.PostRestriction), &dtos.RestrictionBody{})).ServeHTTP)
+	mux.Put("/restriction/{id}", md.Authorization(md.ValidateReqBody(http.HandlerFunc(handlers.Repo.UpdateRestriction), &dtos.RestrictionBody{})).ServeHTTP)
+	mux.Delete("/restriction/{id}", md.Authorization(http.HandlerFunc(handlers.Repo.DeleteRestriction)).ServeHTTP)
+
+	// cancellation policies
+	mux.Get("/cancellation-policy", md.Authorization(http.HandlerFunc(handlers.Repo.GetCancellationPolicies)).ServeHTTP)
+	mux.Post("/cancellation-policy", md.Authorization(md.ValidateReqBody(http.HandlerFunc(handlers.Repo.PostCancellationPolicy), &dtos.CancellationPolicyBody{})).ServeHTTP)
+	mux.Delete("/cancellation-policy/{id}", md.Authorization(http.HandlerFunc(handlers.Repo.DeleteCancellationPolicy)).ServeHTTP)
+
+	// promo codes
+	mux.Get("/promo-code", md.Authorization(http.HandlerFunc(handlers.Repo.GetPromoCodes)).ServeHTTP)
+	mux.Post("/promo-code", md.Authorization(md.ValidateReqBody(http.HandlerFunc(handlers.Repo.PostPromoCode), &dtos.PromoCodeBody{})).ServeHTTP)
+	mux.Put("/promo-code/{id}", md.Authorization(md.ValidateReqBody(http.HandlerFunc(handlers.Repo.UpdatePromoCode), &dtos.PromoCodeBody{})).ServeHTTP)
+	mux.Delete("/promo-code/{id}", md.Authorization(http.HandlerFunc(handlers.Repo.DeletePromoCode)).ServeHTTP)
+
+	// taxes and fees
+	mux.Get("/charge-rule", md.Authorization(http.HandlerFunc(handlers.Repo.GetChargeRules)).ServeHTTP)
+	mux.Post("/charge-rule", md.Authorization(md.ValidateReqBody(http.HandlerFunc(handlers.Repo.PostChargeRule), &dtos.ChargeRuleBody{})).ServeHTTP)
+	mux.Delete("/charge-rule/{id}", md.Authorization(http.HandlerFunc(handlers.Repo.DeleteChargeRule)).ServeHTTP)
+
+	// exchange rates
+	mux.Get("/exchange-rate", md.Authorization(http.HandlerFunc(handlers.Repo.GetExchangeRates)).ServeHTTP)
+	mux.Put("/exchange-rate", md.Authorization(md.ValidateReqBody(http.HandlerFunc(handlers.Repo.PutExchangeRate), &dtos.ExchangeRateBody{})).ServeHTTP)
+	mux.Delete("/exchange-rate/{id}", md.Authorization(http.HandlerFunc(handlers.Repo.DeleteExchangeRate)).ServeHTTP)
+
+	// front desk
+	mux.Get("/admin/calendar", md.Authorization(http.HandlerFunc(handlers.Repo.GetCalendarGrid)).ServeHTTP)
+
+	// guests
+	mux.Get("/admin/guest", md.Authorization(http.HandlerFunc(handlers.Repo.GetGuests)).ServeHTTP)
+	mux.Post("/admin/guest/backfill", md.Authorization(http.HandlerFunc(handlers.Repo.BackfillGuests)).ServeHTTP)
+	mux.Get("/admin/guest/{id}", md.Authorization(http.HandlerFunc(handlers.Repo.GetGuest)).ServeHTTP)
+	mux.Post("/admin/guest/{id}/merge", md.Authorization(md.ValidateReqBody(http.HandlerFunc(handlers.Repo.MergeGuests), &dtos.GuestMergeBody{})).ServeHTTP)
+	mux.Post("/admin/guest/{id}/split", md.Authorization(md.ValidateReqBody(http.HandlerFunc(handlers.Repo.SplitGuest), &dtos.GuestSplitBody{})).ServeHTTP)
+
+	// reports
+	mux.Get("/admin/reports/occupancy", md.Authorization(http.HandlerFunc(handlers.Repo.GetOccupancyReport)).ServeHTTP)
+	mux.Get("/admin/reports/revenue", md.Authorization(http.HandlerFunc(handlers.Repo.GetRevenueReport)).ServeHTTP)
+	mux.Get("/admin/reports/stays", md.Authorization(http.HandlerFunc(handlers.Repo.GetStaysReport)).ServeHTTP)
+
+	// spreadsheets
+	mux.Get("/admin/export/rooms", md.Authorization(http.HandlerFunc(handlers.Repo.ExportRooms)).ServeHTTP)
+	mux.Get("/admin/export/reservations", md.Authorization(http.HandlerFunc(handlers.Repo.ExportReservations)).ServeHTTP)
+	mux.Post("/admin/import/rooms", md.Authorization(http.HandlerFunc(handlers.Repo.ImportRooms)).ServeHTTP)
+	mux.Post("/admin/import/reservations", md.Authorization(http.HandlerFunc(handlers.Repo.ImportReservations)).ServeHTTP)
+
+	// pricing
+	mux.Post("/quote", md.ValidateReqBody(http.HandlerFunc(handlers.Repo.PostQuote), &dtos.QuoteBody{}).ServeHTTP)
 
 	// auth
 	mux.Post("/login", md.ValidateReqBody(http.HandlerFunc(handlers.Repo.LoginUser), &dtos.UserLoginBody{} ).ServeHTTP)
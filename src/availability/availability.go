@@ -0,0 +1,151 @@
+package availability
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+)
+
+// Day statuses
+const (
+	StatusFree = "free"
+	StatusBooked = "booked"
+	StatusBlocked = "blocked"
+)
+
+// Day is what a room is doing on a day. Booked days carry their reservation, blocked days the restriction name
+type Day struct {
+	Status string
+	ReservationID int
+	Restriction string
+}
+
+func (d Day) Free() bool {
+	return d.Status == StatusFree
+}
+
+func daysBetween(from, to time.Time) int {
+	return int(to.Sub(from).Hours() / 24)
+}
+
+// Days lays the room's restrictions and recurring blocks out over the days from start.
+// A booking wins over a block on the same day, so double bookings stay visible
+func Days(cal models.RoomCalendar, start time.Time, days int) ([]Day, error) {
+	out := make([]Day, days)
+	for i := range out {
+		out[i].Status = StatusFree
+	}
+
+	fill := func(from, to time.Time, day Day) {
+		first := daysBetween(start, from)
+		last := daysBetween(start, to)
+		if first < 0 {
+			first = 0
+		}
+		if last > days {
+			last = days
+		}
+
+		for d := first; d < last; d++ {
+			if out[d].Status != StatusBooked {
+				out[d] = day
+			}
+		}
+	}
+
+	for _, rr := range cal.Restrictions {
+		if rr.ReservationID != 0 {
+			fill(rr.StartDate, rr.EndDate, Day{Status: StatusBooked, ReservationID: rr.ReservationID})
+			continue
+		}
+		fill(rr.StartDate, rr.EndDate, Day{Status: StatusBlocked, Restriction: rr.Restriction.RestrictionName})
+	}
+
+	end := start.AddDate(0, 0, days)
+	for _, b := range cal.RecurringBlocks {
+		occurrences, err := b.Occurrences(start, end)
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range occurrences {
+			fill(o, o.AddDate(0, 0, b.Nights), Day{Status: StatusBlocked, Restriction: b.Restriction.RestrictionName})
+		}
+	}
+
+	return out, nil
+}
+
+// Option is a stay the rooms are all free for
+type Option struct {
+	StartDate time.Time
+	EndDate time.Time
+	Rooms []models.Room
+}
+
+// Options finds the stays of nights nights inside the window of days from start that at least one room is free for.
+// allow can refuse a room for a stay, for rules the calendar does not know about.
+// Stays arriving closest to around come first, earlier ones first on a tie, and at most limit are returned
+func Options(cals []models.RoomCalendar, start time.Time, days, nights int, around time.Time, limit int, allow func(room models.Room, start, end time.Time) bool) ([]Option, error) {
+	options := make([]Option, 0)
+	if nights <= 0 || nights > days || limit <= 0 {
+		return options, nil
+	}
+
+	byArrival := make([]Option, days - nights + 1)
+	for i := range byArrival {
+		byArrival[i].StartDate = start.AddDate(0, 0, i)
+		byArrival[i].EndDate = start.AddDate(0, 0, i + nights)
+	}
+
+	for _, cal := range cals {
+		roomDays, err := Days(cal, start, days)
+		if err != nil {
+			return options, err
+		}
+
+		// free counts the free days in a row ending on each day
+		free := 0
+		for d, day := range roomDays {
+			if !day.Free() {
+				free = 0
+				continue
+			}
+			free++
+
+			if free < nights {
+				continue
+			}
+
+			option := &byArrival[d - nights + 1]
+			if allow != nil && !allow(cal.Room, option.StartDate, option.EndDate) {
+				continue
+			}
+			option.Rooms = append(option.Rooms, cal.Room)
+		}
+	}
+
+	for _, option := range byArrival {
+		if len(option.Rooms) > 0 {
+			options = append(options, option)
+		}
+	}
+
+	distance := func(t time.Time) int {
+		d := daysBetween(around, t)
+		if d < 0 {
+			return -d
+		}
+		return d
+	}
+
+	sort.SliceStable(options, func(i, j int) bool {
+		return distance(options[i].StartDate) < distance(options[j].StartDate)
+	})
+
+	if len(options) > limit {
+		options = options[:limit]
+	}
+
+	return options, nil
+}
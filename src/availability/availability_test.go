@@ -0,0 +1,93 @@
+package availability
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+)
+
+func day(d int) time.Time {
+	return time.Date(2050, time.January, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestDays(t *testing.T){
+	cal := models.RoomCalendar{
+		Room: models.Room{ID: 1},
+		Restrictions: []models.RoomRestriction{
+			{StartDate: day(2), EndDate: day(4), ReservationID: 7},
+			// a block over a booking does not hide it
+			{StartDate: day(3), EndDate: day(6), Restriction: models.Restriction{RestrictionName: "Owner Block"}},
+		},
+		RecurringBlocks: []models.RecurringBlock{
+			{StartDate: day(3), Nights: 1, RRule: "FREQ=WEEKLY;BYDAY=MO", Restriction: models.Restriction{RestrictionName: "Maintenance"}},
+		},
+	}
+
+	days, err := Days(cal, day(1), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		StatusFree, StatusBooked, StatusBooked, StatusBlocked, StatusBlocked,
+		StatusFree, StatusFree, StatusFree, StatusFree, StatusBlocked,
+	}
+	for i, d := range days {
+		if d.Status != expected[i] {
+			t.Errorf("day %d is %s, wanted %s", i, d.Status, expected[i])
+		}
+	}
+
+	if days[1].ReservationID != 7 || days[3].Restriction != "Owner Block" || days[9].Restriction != "Maintenance" {
+		t.Errorf("Days returned %+v, wanted the reservation and restriction names", days)
+	}
+}
+
+func TestOptions(t *testing.T){
+	cals := []models.RoomCalendar{
+		{Room: models.Room{ID: 1}, Restrictions: []models.RoomRestriction{{StartDate: day(1), EndDate: day(5), ReservationID: 1}}},
+		{Room: models.Room{ID: 2}, Restrictions: []models.RoomRestriction{{StartDate: day(7), EndDate: day(10), ReservationID: 2}}},
+	}
+
+	// room 2 does not take arrivals on the 4th
+	allow := func(room models.Room, start, end time.Time) bool {
+		return !(room.ID == 2 && start.Equal(day(4)))
+	}
+
+	options, err := Options(cals, day(1), 10, 3, day(5), 3, allow)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// room 1 is free from the 5th and room 2 until the 6th, ties go to the earlier arrival
+	expected := []struct {
+		start time.Time
+		rooms []int
+	}{
+		{day(5), []int{1}},
+		{day(6), []int{1}},
+		{day(3), []int{2}},
+	}
+
+	if len(options) != len(expected) {
+		t.Fatalf("Options returned %d options, wanted %d", len(options), len(expected))
+	}
+
+	for i, e := range expected {
+		if !options[i].StartDate.Equal(e.start) || len(options[i].Rooms) != len(e.rooms) {
+			t.Errorf("option %d is %+v, wanted arrival %s in rooms %v", i, options[i], e.start.Format("2006-01-02"), e.rooms)
+			continue
+		}
+		for n, room := range options[i].Rooms {
+			if room.ID != e.rooms[n] {
+				t.Errorf("option %d is %+v, wanted arrival %s in rooms %v", i, options[i], e.start.Format("2006-01-02"), e.rooms)
+			}
+		}
+	}
+
+	options, _ = Options(cals, day(1), 10, 11, day(1), 3, nil)
+	if len(options) != 0 {
+		t.Errorf("Options returned %+v for a stay longer than the window, wanted none", options)
+	}
+}
@@ -0,0 +1,210 @@
+package calendarsync
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/config"
+	"github.com/Orololuwa/go-backend-boilerplate/src/ical"
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+	"github.com/Orololuwa/go-backend-boilerplate/src/repository"
+)
+
+// MaxCalendarSize caps how much of a remote calendar or uploaded file is read
+const MaxCalendarSize = 5 << 20
+
+var (
+	ErrNoURL = errors.New("calendar feed has no URL, upload a file instead")
+	ErrInvalidCalendar = errors.New("invalid calendar")
+)
+
+// Result counts the changes a sync made to the room's blocks
+type Result struct {
+	Events int `json:"events"`
+	Inserted int `json:"inserted"`
+	Updated int `json:"updated"`
+	Deleted int `json:"deleted"`
+}
+
+// Service turns the events of external calendars into external block restrictions on the room
+type Service struct {
+	App *config.AppConfig
+	DB repository.DatabaseRepo
+	Client *http.Client
+}
+
+func New(a *config.AppConfig, db repository.DatabaseRepo) *Service {
+	return &Service{
+		App: a,
+		DB: db,
+		Client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// SyncFeed downloads the feed's calendar and imports it
+func (s *Service) SyncFeed(ctx context.Context, feed models.CalendarFeed) (Result, error) {
+	if feed.URL == "" {
+		return Result{}, ErrNoURL
+	}
+
+	body, err := s.fetch(ctx, feed.URL)
+	if err != nil {
+		s.recordStatus(ctx, &feed, Result{}, err)
+		return Result{}, err
+	}
+	defer body.Close()
+
+	return s.Import(ctx, feed, io.LimitReader(body, MaxCalendarSize))
+}
+
+func (s *Service) fetch(ctx context.Context, url string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/calendar")
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("calendar feed returned status %d", resp.StatusCode)
+	}
+
+	return resp.Body, nil
+}
+
+// Import replaces the feed's blocks with the events in the calendar. Events are matched on their UID,
+// so a moved event updates its block and an event that disappeared from the source releases the room.
+// When the calendar cannot be read the existing blocks are kept, a broken feed must not free up the room
+func (s *Service) Import(ctx context.Context, feed models.CalendarFeed, r io.Reader) (Result, error) {
+	var result Result
+
+	events, err := ical.Parse(r)
+	if err != nil {
+		err = fmt.Errorf("%w: %v", ErrInvalidCalendar, err)
+		s.recordStatus(ctx, &feed, result, err)
+		return result, err
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	err = s.DB.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		err := s.DB.LockRoom(ctx, tx, feed.RoomID)
+		if err != nil {
+			return err
+		}
+
+		blocks, err := s.DB.GetExternalBlocksByFeedId(ctx, tx, feed.ID)
+		if err != nil {
+			return err
+		}
+
+		existing := make(map[string]models.RoomRestriction, len(blocks))
+		for _, b := range blocks {
+			existing[b.ExternalUID] = b
+		}
+
+		seen := make(map[string]bool, len(events))
+
+		for _, e := range events {
+			// past stays no longer block anything, and a UID repeated in the same file is only applied once
+			if !e.End.After(today) || seen[e.UID] {
+				continue
+			}
+			seen[e.UID] = true
+			result.Events++
+
+			block, ok := existing[e.UID]
+			if !ok {
+				_, err = s.DB.InsertExternalBlock(ctx, tx, models.RoomRestriction{
+					StartDate: e.Start,
+					EndDate: e.End,
+					RoomID: feed.RoomID,
+					RestrictionID: models.RestrictionExternalBlock,
+					FeedID: feed.ID,
+					ExternalUID: e.UID,
+				})
+				if err != nil {
+					return err
+				}
+				result.Inserted++
+				continue
+			}
+
+			if block.StartDate.Equal(e.Start) && block.EndDate.Equal(e.End) {
+				continue
+			}
+
+			err = s.DB.UpdateRoomRestrictionDates(ctx, tx, block.ID, e.Start, e.End)
+			if err != nil {
+				return err
+			}
+			result.Updated++
+		}
+
+		for uid, block := range existing {
+			if seen[uid] {
+				continue
+			}
+
+			err = s.DB.DeleteRoomRestriction(ctx, tx, block.ID)
+			if err != nil {
+				return err
+			}
+			result.Deleted++
+		}
+
+		return nil
+	})
+
+	s.recordStatus(ctx, &feed, result, err)
+
+	return result, err
+}
+
+// recordStatus saves the outcome of a sync on the feed. A failure to save is only logged so it does not hide the sync error
+func (s *Service) recordStatus(ctx context.Context, feed *models.CalendarFeed, result Result, syncErr error) {
+	feed.LastSyncedAt = time.Now()
+
+	if syncErr != nil {
+		feed.LastStatus = models.CalendarFeedStatusError
+		feed.LastError = syncErr.Error()
+	}else{
+		feed.LastStatus = models.CalendarFeedStatusOK
+		feed.LastError = ""
+		feed.EventCount = result.Events
+	}
+
+	err := s.DB.UpdateCalendarFeedStatus(ctx, nil, *feed)
+	if err != nil {
+		s.App.ErrorLog.Println(err)
+	}
+}
+
+// SyncAll syncs every feed with a URL and returns how many failed. Each failure is recorded on its feed
+func (s *Service) SyncAll(ctx context.Context) (int, error) {
+	feeds, err := s.DB.GetRemoteCalendarFeeds(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	failed := 0
+	for _, feed := range feeds {
+		_, err := s.SyncFeed(ctx, feed)
+		if err != nil {
+			s.App.ErrorLog.Printf("calendar feed %d: %v", feed.ID, err)
+			failed++
+		}
+	}
+
+	return failed, nil
+}
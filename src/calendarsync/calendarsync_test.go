@@ -0,0 +1,96 @@
+package calendarsync
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/config"
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+	dbrepo "github.com/Orololuwa/go-backend-boilerplate/src/repository/db-repo"
+)
+
+// the test repository already holds blocks for keep@external (2050-03-01 to 03) and gone@external
+const testCalendar = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:keep@external\r\n" +
+	"DTSTART;VALUE=DATE:20500301\r\n" +
+	"DTEND;VALUE=DATE:20500304\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:new@external\r\n" +
+	"DTSTART;VALUE=DATE:20500501\r\n" +
+	"DTEND;VALUE=DATE:20500503\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:past@external\r\n" +
+	"DTSTART;VALUE=DATE:20000101\r\n" +
+	"DTEND;VALUE=DATE:20000103\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func newTestService() *Service {
+	app := &config.AppConfig{
+		ErrorLog: log.New(os.Stdout, "ERROR\t", log.Ldate|log.Ltime|log.Lshortfile),
+	}
+
+	return New(app, dbrepo.NewTestingDBRepo())
+}
+
+func TestImport(t *testing.T){
+	s := newTestService()
+	feed := models.CalendarFeed{ID: 1, RoomID: 15}
+
+	result, err := s.Import(context.Background(), feed, strings.NewReader(testCalendar))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := Result{Events: 2, Inserted: 1, Updated: 1, Deleted: 1}
+	if result != expected {
+		t.Errorf("Import expected %+v, got %+v", expected, result)
+	}
+
+	_, err = s.Import(context.Background(), feed, strings.NewReader("garbage"))
+	if !errors.Is(err, ErrInvalidCalendar) {
+		t.Errorf("Import expected ErrInvalidCalendar, got %v", err)
+	}
+}
+
+func TestSyncFeed(t *testing.T){
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/calendar.ics" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/calendar")
+		w.Write([]byte(testCalendar))
+	}))
+	defer srv.Close()
+
+	s := newTestService()
+
+	result, err := s.SyncFeed(context.Background(), models.CalendarFeed{ID: 1, RoomID: 15, URL: srv.URL + "/calendar.ics"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Events != 2 {
+		t.Errorf("SyncFeed expected 2 events, got %d", result.Events)
+	}
+
+	_, err = s.SyncFeed(context.Background(), models.CalendarFeed{ID: 1, RoomID: 15, URL: srv.URL + "/missing.ics"})
+	if err == nil {
+		t.Error("SyncFeed expected an error for a feed that returns 404")
+	}
+
+	_, err = s.SyncFeed(context.Background(), models.CalendarFeed{ID: 1, RoomID: 15})
+	if err != ErrNoURL {
+		t.Errorf("SyncFeed expected ErrNoURL, got %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+package cancellation
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+	"github.com/Orololuwa/go-backend-boilerplate/src/money"
+)
+
+var ErrDuplicateWindow = errors.New("two windows start the same number of hours before arrival")
+
+// Validate checks every window of the policy starts at a different time
+func Validate(p models.CancellationPolicy) error {
+	seen := make(map[int]bool)
+	for _, w := range p.Windows {
+		if seen[w.HoursBefore] {
+			return ErrDuplicateWindow
+		}
+		seen[w.HoursBefore] = true
+	}
+	return nil
+}
+
+// Sort orders the windows from the earliest before arrival to the latest
+func Sort(p *models.CancellationPolicy) {
+	sort.Slice(p.Windows, func(i, j int) bool {
+		return p.Windows[i].HoursBefore > p.Windows[j].HoursBefore
+	})
+}
+
+// Arrival is when a stay starting on the date is taken to begin, the start of the day
+func Arrival(startDate time.Time) time.Time {
+	return time.Date(startDate.Year(), startDate.Month(), startDate.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func percentOf(total money.Amount, percent int) money.Amount {
+	return total.Share(int64(percent), 100)
+}
+
+// FeePercent is the share of the stay total charged for cancelling at the time. The window closest to arrival that
+// has started applies, and cancelling before every window is free. Without a policy cancelling is always free
+func FeePercent(p *models.CancellationPolicy, arrival, at time.Time) int {
+	if p == nil {
+		return 0
+	}
+
+	hoursLeft := arrival.Sub(at).Hours()
+
+	percent := 0
+	closest := -1
+	for _, w := range p.Windows {
+		if hoursLeft >= float64(w.HoursBefore) {
+			continue
+		}
+		if closest == -1 || w.HoursBefore < closest {
+			closest = w.HoursBefore
+			percent = w.FeePercent
+		}
+	}
+
+	return percent
+}
+
+// Fee is what cancelling the stay at the time costs, in minor units
+func Fee(p *models.CancellationPolicy, total money.Amount, arrival, at time.Time) money.Amount {
+	return percentOf(total, FeePercent(p, arrival, at))
+}
+
+// NoShowFee is what not turning up for the stay costs. Without a policy it is free
+func NoShowFee(p *models.CancellationPolicy, total money.Amount) money.Amount {
+	if p == nil {
+		return 0
+	}
+	return percentOf(total, p.NoShowPercent)
+}
+
+// Refund is the part of what the guest paid they get back once the fee is kept
+func Refund(paid, fee money.Amount) money.Amount {
+	if paid <= fee {
+		return 0
+	}
+	return paid - fee
+}
+
+func hours(n int) string {
+	if n % 24 == 0 && n > 0 {
+		if n == 24 {
+			return "1 day"
+		}
+		return fmt.Sprintf("%d days", n / 24)
+	}
+	if n == 1 {
+		return "1 hour"
+	}
+	return fmt.Sprintf("%d hours", n)
+}
+
+// Describe puts the policy in words for guests, such as
+// "Free cancellation until 2 days before arrival, 50% of the total after that. No-shows are charged 100% of the total."
+func Describe(p *models.CancellationPolicy) string {
+	if p == nil {
+		return "Free cancellation."
+	}
+
+	policy := *p
+	policy.Windows = append([]models.CancellationWindow(nil), p.Windows...)
+	Sort(&policy)
+
+	parts := make([]string, 0, len(policy.Windows) + 1)
+	for _, w := range policy.Windows {
+		if w.FeePercent == 0 {
+			continue
+		}
+		if len(parts) == 0 {
+			parts = append(parts, fmt.Sprintf("Free cancellation until %s before arrival, %d%% of the total after that", hours(w.HoursBefore), w.FeePercent))
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%d%% from %s before arrival", w.FeePercent, hours(w.HoursBefore)))
+	}
+	if len(parts) == 0 {
+		parts = append(parts, "Free cancellation")
+	}
+
+	return fmt.Sprintf("%s. No-shows are charged %d%% of the total.", strings.Join(parts, ", "), policy.NoShowPercent)
+}
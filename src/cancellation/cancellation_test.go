@@ -0,0 +1,97 @@
+package cancellation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+	"github.com/Orololuwa/go-backend-boilerplate/src/money"
+)
+
+// free until 48 hours before arrival, half after that and everything in the last day
+var policy = &models.CancellationPolicy{
+	PolicyName: "Moderate",
+	Windows: []models.CancellationWindow{
+		{HoursBefore: 24, FeePercent: 100},
+		{HoursBefore: 48, FeePercent: 50},
+	},
+	NoShowPercent: 100,
+}
+
+func TestFee(t *testing.T){
+	arrival := Arrival(time.Date(2050, time.January, 10, 0, 0, 0, 0, time.UTC))
+
+	var feeTests = []struct {
+		name string
+		policy *models.CancellationPolicy
+		at time.Time
+		expected money.Amount
+	}{
+		{"a week before", policy, arrival.AddDate(0, 0, -7), 0},
+		{"exactly 48 hours before", policy, arrival.Add(-48 * time.Hour), 0},
+		{"a minute into the 48 hours", policy, arrival.Add(-48 * time.Hour + time.Minute), 15000},
+		{"30 hours before", policy, arrival.Add(-30 * time.Hour), 15000},
+		{"the night before", policy, arrival.Add(-6 * time.Hour), 30000},
+		{"after arrival", policy, arrival.Add(6 * time.Hour), 30000},
+		{"without a policy", nil, arrival.Add(-6 * time.Hour), 0},
+	}
+
+	for _, e := range feeTests {
+		fee := Fee(e.policy, 30000, arrival, e.at)
+		if fee != e.expected {
+			t.Errorf("Fee returned %d for cancelling %s, wanted %d", fee, e.name, e.expected)
+		}
+	}
+}
+
+func TestNoShowFeeAndRefund(t *testing.T){
+	if fee := NoShowFee(policy, 30000); fee != 30000 {
+		t.Errorf("NoShowFee returned %d, wanted the whole total", fee)
+	}
+	if fee := NoShowFee(&models.CancellationPolicy{NoShowPercent: 33}, 10001); fee != 3300 {
+		t.Errorf("NoShowFee returned %d, wanted 3300", fee)
+	}
+	if fee := NoShowFee(nil, 30000); fee != 0 {
+		t.Errorf("NoShowFee returned %d without a policy, wanted 0", fee)
+	}
+
+	if refund := Refund(30000, 15000); refund != 15000 {
+		t.Errorf("Refund returned %d, wanted 15000", refund)
+	}
+	if refund := Refund(10000, 15000); refund != 0 {
+		t.Errorf("Refund returned %d when the fee is more than was paid, wanted 0", refund)
+	}
+}
+
+func TestValidate(t *testing.T){
+	if err := Validate(*policy); err != nil {
+		t.Errorf("Validate returned %v for a valid policy", err)
+	}
+
+	duplicate := models.CancellationPolicy{Windows: []models.CancellationWindow{{HoursBefore: 24, FeePercent: 50}, {HoursBefore: 24, FeePercent: 100}}}
+	if err := Validate(duplicate); err != ErrDuplicateWindow {
+		t.Errorf("Validate returned %v for windows starting at the same time, wanted %v", err, ErrDuplicateWindow)
+	}
+}
+
+func TestDescribe(t *testing.T){
+	var describeTests = []struct {
+		policy *models.CancellationPolicy
+		expected string
+	}{
+		{policy, "Free cancellation until 2 days before arrival, 50% of the total after that, 100% from 1 day before arrival. No-shows are charged 100% of the total."},
+		{&models.CancellationPolicy{Windows: []models.CancellationWindow{{HoursBefore: 6, FeePercent: 20}}, NoShowPercent: 50}, "Free cancellation until 6 hours before arrival, 20% of the total after that. No-shows are charged 50% of the total."},
+		{&models.CancellationPolicy{NoShowPercent: 100}, "Free cancellation. No-shows are charged 100% of the total."},
+		{nil, "Free cancellation."},
+	}
+
+	for _, e := range describeTests {
+		if got := Describe(e.policy); got != e.expected {
+			t.Errorf("Describe returned %q, wanted %q", got, e.expected)
+		}
+	}
+
+	if policy.Windows[0].HoursBefore != 24 {
+		t.Errorf("Describe reordered the windows of the policy it was given")
+	}
+}
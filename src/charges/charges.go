@@ -0,0 +1,92 @@
+package charges
+
+import (
+	"errors"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+	"github.com/Orololuwa/go-backend-boilerplate/src/money"
+)
+
+// ErrInvalidRule is returned for a rule that would not add a positive charge, or a percentage above 100%
+var ErrInvalidRule = errors.New("charge rules need a positive amount, percentages of at most 10000 hundredths")
+
+// Validate returns an error when the rule cannot be applied to a stay
+func Validate(rule models.ChargeRule) error {
+	if rule.Amount <= 0 {
+		return ErrInvalidRule
+	}
+
+	switch rule.Kind {
+	case models.ChargePerPersonNight, models.ChargeFlat:
+		return nil
+	case models.ChargePercent:
+		if rule.Amount > 10000 {
+			return ErrInvalidRule
+		}
+		return nil
+	}
+	return ErrInvalidRule
+}
+
+// ForRoom keeps the rules that apply to stays in the room, in their order
+func ForRoom(rules []models.ChargeRule, roomId int) []models.ChargeRule {
+	var kept []models.ChargeRule
+	for _, rule := range rules {
+		if rule.RoomID == 0 || rule.RoomID == roomId {
+			kept = append(kept, rule)
+		}
+	}
+	return kept
+}
+
+// Apply returns a line for each rule charged on a stay of nights nights for guests guests costing subtotal, in the
+// order of the rules. Percent charges are taken on the subtotal and the flat fees, not on the per person taxes, and
+// rounded half up to the minor unit. A stay is charged for one guest at least
+func Apply(rules []models.ChargeRule, subtotal money.Amount, guests, nights int) []models.ReservationCharge {
+	if guests < 1 {
+		guests = 1
+	}
+
+	base := subtotal
+	for _, rule := range rules {
+		if rule.Kind == models.ChargeFlat {
+			base += money.Amount(rule.Amount)
+		}
+	}
+
+	lines := make([]models.ReservationCharge, 0, len(rules))
+	for _, rule := range rules {
+		line := models.ReservationCharge{
+			ChargeRuleID: rule.ID,
+			ChargeName: rule.RuleName,
+			Kind: rule.Kind,
+			Rate: rule.Amount,
+			Quantity: 1,
+		}
+
+		switch rule.Kind {
+		case models.ChargePerPersonNight:
+			line.Quantity = guests * nights
+			line.Amount = money.Amount(rule.Amount * int64(line.Quantity))
+		case models.ChargePercent:
+			line.Amount = base.Share(rule.Amount, 10000)
+		case models.ChargeFlat:
+			line.Amount = money.Amount(rule.Amount)
+		default:
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+// Total adds up the lines
+func Total(lines []models.ReservationCharge) money.Amount {
+	var total money.Amount
+	for _, line := range lines {
+		total += line.Amount
+	}
+	return total
+}
@@ -0,0 +1,82 @@
+package charges
+
+import (
+	"testing"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+)
+
+var rules = []models.ChargeRule{
+	{ID: 1, RuleName: "City tax", Kind: models.ChargePerPersonNight, Amount: 150},
+	{ID: 2, RuleName: "Cleaning fee", Kind: models.ChargeFlat, Amount: 2500, RoomID: 20},
+	{ID: 3, RuleName: "VAT", Kind: models.ChargePercent, Amount: 750},
+}
+
+func TestValidate(t *testing.T){
+	var validateTests = []struct {
+		name string
+		rule models.ChargeRule
+		valid bool
+	}{
+		{"city tax", rules[0], true},
+		{"cleaning fee", rules[1], true},
+		{"vat", rules[2], true},
+		{"whole price", models.ChargeRule{Kind: models.ChargePercent, Amount: 10000}, true},
+		{"above the price", models.ChargeRule{Kind: models.ChargePercent, Amount: 10001}, false},
+		{"no amount", models.ChargeRule{Kind: models.ChargeFlat}, false},
+		{"negative amount", models.ChargeRule{Kind: models.ChargeFlat, Amount: -100}, false},
+		{"unknown kind", models.ChargeRule{Kind: "per_room_night", Amount: 100}, false},
+	}
+
+	for _, e := range validateTests {
+		err := Validate(e.rule)
+		if (err == nil) != e.valid {
+			t.Errorf("Validate returned %v for the %s rule, wanted valid %v", err, e.name, e.valid)
+		}
+	}
+}
+
+func TestForRoom(t *testing.T){
+	if got := ForRoom(rules, 20); len(got) != 3 {
+		t.Errorf("ForRoom returned %d rules for room 20, wanted 3", len(got))
+	}
+
+	got := ForRoom(rules, 15)
+	if len(got) != 2 || got[0].ID != 1 || got[1].ID != 3 {
+		t.Errorf("ForRoom returned %+v for room 15, wanted the rules for every room", got)
+	}
+}
+
+func TestApply(t *testing.T){
+	// 2 guests for 3 nights costing 34000
+	lines := Apply(rules, 34000, 2, 3)
+
+	expected := []models.ReservationCharge{
+		{ChargeRuleID: 1, ChargeName: "City tax", Kind: models.ChargePerPersonNight, Rate: 150, Quantity: 6, Amount: 900},
+		{ChargeRuleID: 2, ChargeName: "Cleaning fee", Kind: models.ChargeFlat, Rate: 2500, Quantity: 1, Amount: 2500},
+		// 7.5% of 36500, 2737.5 rounded up
+		{ChargeRuleID: 3, ChargeName: "VAT", Kind: models.ChargePercent, Rate: 750, Quantity: 1, Amount: 2738},
+	}
+
+	if len(lines) != len(expected) {
+		t.Fatalf("Apply returned %d lines, wanted %d", len(lines), len(expected))
+	}
+	for i, e := range expected {
+		if lines[i] != e {
+			t.Errorf("Apply returned %+v, wanted %+v", lines[i], e)
+		}
+	}
+
+	if total := Total(lines); total != 6138 {
+		t.Errorf("Total returned %d, wanted 6138", total)
+	}
+
+	// a stay booked without a guest count is charged for one guest
+	if lines := Apply(rules[:1], 34000, 0, 3); lines[0].Amount != 450 {
+		t.Errorf("Apply charged %d city tax for a stay without guests, wanted 450", lines[0].Amount)
+	}
+
+	if lines := Apply(nil, 34000, 2, 3); len(lines) != 0 || Total(lines) != 0 {
+		t.Errorf("Apply returned %+v without rules, wanted no lines", lines)
+	}
+}
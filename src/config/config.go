@@ -2,6 +2,7 @@ package config
 
 import (
 	"log"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -11,4 +12,14 @@ type AppConfig struct {
 	InfoLog *log.Logger
 	ErrorLog *log.Logger
 	Validate *validator.Validate
+	HoldDuration time.Duration
+	IdempotencyTTL time.Duration
+	IdempotencyLease time.Duration
+	PaymentGateway string
+	PaymentWebhookSecret string
+	WaitlistOfferDuration time.Duration
+	PropertyName string
+	InvoiceSeries string
+	Currency string
+	PhoneCountryCode string
 }
\ No newline at end of file
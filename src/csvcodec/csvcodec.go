@@ -0,0 +1,255 @@
+package csvcodec
+
+import (
+	"encoding"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+type field struct {
+	name string
+	index []int
+}
+
+// fields lists the columns of a struct type. Columns are named after the json tags, so a spreadsheet uses the same
+// names as the API. Embedded structs add their fields, and fields that are not strings, numbers, booleans, times or
+// encoding.TextMarshalers are left out
+func fields(t reflect.Type) []field {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	list := make([]field, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			for _, inner := range fields(f.Type) {
+				inner.index = append([]int{i}, inner.index...)
+				list = append(list, inner)
+			}
+			continue
+		}
+
+		if !scalar(f.Type) {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+		list = append(list, field{name: name, index: []int{i}})
+	}
+
+	return list
+}
+
+func scalar(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Float32, reflect.Float64:
+		return true
+	}
+	return t == timeType || t.Implements(textMarshalerType)
+}
+
+// Header returns the column names of the struct v
+func Header(v interface{}) []string {
+	list := fields(reflect.TypeOf(v))
+
+	header := make([]string, len(list))
+	for i, f := range list {
+		header[i] = f.name
+	}
+	return header
+}
+
+// Record returns the values of the struct v in the order of its Header. Times are written as RFC 3339, and the values
+// of other types that implement encoding.TextMarshaler as they marshal themselves
+func Record(v interface{}) []string {
+	value := reflect.Indirect(reflect.ValueOf(v))
+	list := fields(value.Type())
+
+	record := make([]string, len(list))
+	for i, f := range list {
+		fv := value.FieldByIndex(f.index)
+
+		switch {
+		case fv.Type() == timeType:
+			t := fv.Interface().(time.Time)
+			if !t.IsZero() {
+				record[i] = t.Format(time.RFC3339)
+			}
+		case fv.Type().Implements(textMarshalerType):
+			text, _ := fv.Interface().(encoding.TextMarshaler).MarshalText()
+			record[i] = string(text)
+		case fv.Kind() == reflect.String:
+			record[i] = fv.String()
+		case fv.Kind() == reflect.Bool:
+			record[i] = strconv.FormatBool(fv.Bool())
+		case fv.CanInt():
+			record[i] = strconv.FormatInt(fv.Int(), 10)
+		case fv.CanFloat():
+			record[i] = strconv.FormatFloat(fv.Float(), 'f', -1, 64)
+		}
+	}
+
+	return record
+}
+
+// RowError is a row that was read but does not fit its columns, the decoder can go on with the next row
+type RowError struct {
+	Err error
+}
+
+func (e *RowError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// Decoder reads the rows of a CSV file with a header line into structs
+type Decoder struct {
+	reader *csv.Reader
+	columns []field
+	line int
+}
+
+// NewDecoder reads the header line and checks every column is a field of the struct v or one of the ignored columns,
+// which are skipped. Ignoring the read-only columns of an export lets the exported file be imported again
+func NewDecoder(r io.Reader, v interface{}, ignore ...string) (*Decoder, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("the file is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]field)
+	for _, name := range ignore {
+		known[name] = field{name: name}
+	}
+	for _, f := range fields(reflect.TypeOf(v)) {
+		known[f.name] = f
+	}
+
+	columns := make([]field, len(header))
+	for i, name := range header {
+		// spreadsheet apps often start the file with a byte order mark
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		f, ok := known[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+		columns[i] = f
+	}
+
+	// rows are checked against the header by the decoder, not by the csv reader
+	reader.FieldsPerRecord = -1
+
+	return &Decoder{reader: reader, columns: columns, line: 1}, nil
+}
+
+// Line is the line of the file the last row was read from, the header is line 1
+func (d *Decoder) Line() int {
+	return d.line
+}
+
+// Decode reads the next row into the struct dst points to and returns io.EOF after the last row.
+// A *RowError about the content of the row leaves the decoder ready to read the next one, other errors end the file
+func (d *Decoder) Decode(dst interface{}) error {
+	record, err := d.reader.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			d.line = parseErr.Line
+		}
+		return err
+	}
+	d.line, _ = d.reader.FieldPos(0)
+
+	if len(record) != len(d.columns) {
+		return &RowError{fmt.Errorf("expected %d columns, got %d", len(d.columns), len(record))}
+	}
+
+	value := reflect.ValueOf(dst).Elem()
+	for i, f := range d.columns {
+		if f.index == nil {
+			continue
+		}
+		err := set(value.FieldByIndex(f.index), strings.TrimSpace(record[i]))
+		if err != nil {
+			return &RowError{fmt.Errorf("%s: %w", f.name, err)}
+		}
+	}
+
+	return nil
+}
+
+func set(fv reflect.Value, s string) error {
+	if s == "" {
+		fv.Set(reflect.Zero(fv.Type()))
+		return nil
+	}
+
+	switch {
+	case fv.Type() == timeType:
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return fmt.Errorf("%q is not an RFC 3339 time", s)
+		}
+		fv.Set(reflect.ValueOf(t))
+	case fv.Addr().Type().Implements(textUnmarshalerType):
+		err := fv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+		if err != nil {
+			return fmt.Errorf("%q: %w", s, err)
+		}
+	case fv.Kind() == reflect.String:
+		fv.SetString(s)
+	case fv.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("%q is not true or false", s)
+		}
+		fv.SetBool(b)
+	case fv.CanInt():
+		n, err := strconv.ParseInt(s, 10, fv.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%q is not a whole number", s)
+		}
+		fv.SetInt(n)
+	case fv.CanFloat():
+		n, err := strconv.ParseFloat(s, fv.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%q is not a number", s)
+		}
+		fv.SetFloat(n)
+	}
+
+	return nil
+}
@@ -0,0 +1,132 @@
+package csvcodec
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type Contact struct {
+	Email string `json:"email"`
+	Phone string `json:"phone"`
+}
+
+// percent is written to CSV as 15%
+type percent int
+
+func (p percent) MarshalText() ([]byte, error) {
+	return []byte(strconv.Itoa(int(p)) + "%"), nil
+}
+
+func (p *percent) UnmarshalText(b []byte) error {
+	n, err := strconv.Atoi(strings.TrimSuffix(string(b), "%"))
+	*p = percent(n)
+	return err
+}
+
+type booking struct {
+	Name string `json:"name"`
+	Nights int `json:"nights"`
+	Total int64 `json:"total,omitempty"`
+	Paid bool `json:"paid"`
+	Deposit percent `json:"deposit"`
+	Secret string `json:"-"`
+	Tags []string `json:"tags"`
+	CreatedAt time.Time `json:"createdAt"`
+	Contact
+}
+
+func TestHeaderAndRecord(t *testing.T){
+	header := Header(booking{})
+	expected := []string{"name", "nights", "total", "paid", "deposit", "createdAt", "email", "phone"}
+	if !reflect.DeepEqual(header, expected) {
+		t.Errorf("Header returned %v, wanted %v", header, expected)
+	}
+
+	b := booking{
+		Name: "Doe, John",
+		Nights: 3,
+		Total: 30000,
+		Paid: true,
+		Deposit: 15,
+		Secret: "hidden",
+		CreatedAt: time.Date(2050, 1, 1, 12, 0, 0, 0, time.UTC),
+		Contact: Contact{Email: "john@doe.com"},
+	}
+
+	record := Record(&b)
+	expected = []string{"Doe, John", "3", "30000", "true", "15%", "2050-01-01T12:00:00Z", "john@doe.com", ""}
+	if !reflect.DeepEqual(record, expected) {
+		t.Errorf("Record returned %v, wanted %v", record, expected)
+	}
+}
+
+func TestDecoder(t *testing.T){
+	file := "\ufeffname, nights,email,id\n" +
+		"John,3,john@doe.com,1\n" +
+		"Jane,three,jane@doe.com,2\n" +
+		"Jim,2\n" +
+		"\"Doe, Jo\",,jo@doe.com,\n"
+
+	d, err := NewDecoder(strings.NewReader(file), booking{}, "id")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var rows []booking
+	var lines []int
+	var failed []int
+	for {
+		var b booking
+		err := d.Decode(&b)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var rowErr *RowError
+		if errors.As(err, &rowErr) {
+			failed = append(failed, d.Line())
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows = append(rows, b)
+		lines = append(lines, d.Line())
+	}
+
+	expected := []booking{
+		{Name: "John", Nights: 3, Contact: Contact{Email: "john@doe.com"}},
+		{Name: "Doe, Jo", Contact: Contact{Email: "jo@doe.com"}},
+	}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("Decode returned %+v, wanted %+v", rows, expected)
+	}
+	if !reflect.DeepEqual(lines, []int{2, 5}) {
+		t.Errorf("Decode read rows from lines %v, wanted 2 and 5", lines)
+	}
+	if !reflect.DeepEqual(failed, []int{3, 4}) {
+		t.Errorf("Decode failed on lines %v, wanted 3 and 4", failed)
+	}
+}
+
+func TestNewDecoder(t *testing.T){
+	var decoderTests = []struct {
+		name string
+		file string
+	}{
+		{"empty file", ""},
+		{"unknown column", "name,room\n"},
+		{"column that is not exported", "Secret\n"},
+	}
+
+	for _, e := range decoderTests {
+		_, err := NewDecoder(strings.NewReader(e.file), booking{})
+		if err == nil {
+			t.Errorf("NewDecoder accepted the header of the %s", e.name)
+		}
+	}
+}
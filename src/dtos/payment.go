@@ -0,0 +1,12 @@
+package dtos
+
+import "github.com/Orololuwa/go-backend-boilerplate/src/money"
+
+type PaymentBody struct {
+	ConfirmationCode string `json:"confirmationCode" validate:"required"`
+	PaymentMethod string `json:"paymentMethod" validate:"required"`
+}
+
+type RefundBody struct {
+	Amount money.Amount `json:"amount" validate:"gt=0"`
+}
\ No newline at end of file
@@ -1,5 +1,7 @@
 package dtos
 
+import "github.com/Orololuwa/go-backend-boilerplate/src/money"
+
 type ReservationBody struct {
 	FirstName string `json:"firstName" validate:"required" faker:"first_name"`
 	LastName string `json:"lastName" validate:"required" faker:"last_name"`
@@ -8,4 +10,73 @@ type ReservationBody struct {
 	StartDate string `json:"startDate" validate:"required" faker:"date"`
 	EndDate string `json:"endDate" validate:"required" faker:"date"`
 	RoomId int `json:"roomId" validate:"required" faker:"oneof: 15, 27, 61"`
-}
\ No newline at end of file
+	Adults int `json:"adults" validate:"gte=0" faker:"oneof: 1, 2"`
+	Children int `json:"children" validate:"gte=0" faker:"oneof: 0, 1"`
+	HoldToken string `json:"holdToken" faker:"-"`
+	QuotedTotal money.Amount `json:"quotedTotal" faker:"-"`
+	PromoCode string `json:"promoCode" validate:"max=50" faker:"-"`
+	Currency string `json:"currency" validate:"omitempty,iso4217" faker:"-"`
+	SpecialRequests string `json:"specialRequests" validate:"max=1000" faker:"-"`
+}
+
+type PostHoldBody struct {
+	StartDate string `json:"startDate" validate:"required" faker:"date"`
+	EndDate string `json:"endDate" validate:"required" faker:"date"`
+	RoomId int `json:"roomId" validate:"required" faker:"oneof: 15, 27, 61"`
+}
+
+type GuestDetails struct {
+	FirstName string `json:"firstName" validate:"required" faker:"first_name"`
+	LastName string `json:"lastName" validate:"required" faker:"last_name"`
+	Email string `json:"email" validate:"required,email" faker:"email"`
+	Phone string `json:"phone" validate:"required" faker:"phone_number"`
+}
+
+// GroupRoomBody is one room of a group booking. Rooms without their own guest are booked for the organiser
+type GroupRoomBody struct {
+	RoomId int `json:"roomId" validate:"required"`
+	Adults int `json:"adults" validate:"gte=0"`
+	Children int `json:"children" validate:"gte=0"`
+	Guest *GuestDetails `json:"guest" validate:"omitempty"`
+}
+
+type GroupReservationBody struct {
+	GroupName string `json:"groupName" validate:"required"`
+	StartDate string `json:"startDate" validate:"required"`
+	EndDate string `json:"endDate" validate:"required"`
+	Guest GuestDetails `json:"guest" validate:"required"`
+	Rooms []GroupRoomBody `json:"rooms" validate:"required,min=1,max=20,dive"`
+	Currency string `json:"currency" validate:"omitempty,iso4217"`
+}
+
+type CancelBody struct {
+	ConfirmationCode string `json:"confirmationCode" validate:"required"`
+}
+
+type GroupUpdateBody struct {
+	ConfirmationCode string `json:"confirmationCode" validate:"required"`
+	StartDate string `json:"startDate" validate:"required"`
+	EndDate string `json:"endDate" validate:"required"`
+}
+
+// WaitlistBody asks to be told when the dates free up. Leaving out the roomId accepts any room
+type WaitlistBody struct {
+	RoomId int `json:"roomId"`
+	StartDate string `json:"startDate" validate:"required"`
+	EndDate string `json:"endDate" validate:"required"`
+	GuestDetails
+}
+// GuestMergeBody names the duplicate profile whose reservations move to the guest in the path
+type GuestMergeBody struct {
+	GuestId int `json:"guestId" validate:"required,gt=0"`
+}
+
+// GuestSplitBody lists the reservations that move from the guest in the path to a new profile
+type GuestSplitBody struct {
+	ReservationIds []int `json:"reservationIds" validate:"required,min=1,dive,gt=0"`
+}
+
+// ReservationNoteBody is an internal note staff add to a reservation
+type ReservationNoteBody struct {
+	Body string `json:"body" validate:"required,max=2000"`
+}
@@ -1,6 +1,142 @@
 package dtos
 
+import "github.com/Orololuwa/go-backend-boilerplate/src/money"
+
+// PostAvailabilityBody searches the dates for a party of adults and children. Leaving both out skips the capacity check.
+// Setting Nights searches for a stay that long anywhere between StartDate and EndDate instead, and FlexDays
+// also suggests stays arriving up to that many days before or after StartDate
 type PostAvailabilityBody struct {
 	StartDate string `json:"startDate" validate:"required" faker:"date"`
 	EndDate string `json:"endDate" validate:"required" faker:"date"`
+	Adults int `json:"adults" validate:"gte=0" faker:"-"`
+	Children int `json:"children" validate:"gte=0" faker:"-"`
+	Nights int `json:"nights" validate:"gte=0" faker:"-"`
+	FlexDays int `json:"flexDays" validate:"gte=0,lte=30" faker:"-"`
+}
+
+type QuoteBody struct {
+	PostAvailabilityBody
+	RoomId int `json:"roomId" faker:"-"`
+	PromoCode string `json:"promoCode" validate:"max=50" faker:"-"`
+	Currency string `json:"currency" validate:"omitempty,iso4217" faker:"-"`
+}
+
+// RoomBody creates a room. A MaxOccupancy of 0 is not enforced
+type RoomBody struct {
+	RoomName string `json:"roomName" validate:"required,max=255"`
+	BaseRate money.Amount `json:"baseRate" validate:"gte=0"`
+	WeekendRate money.Amount `json:"weekendRate" validate:"gte=0"`
+	MaxOccupancy int `json:"maxOccupancy" validate:"gte=0"`
+	Currency string `json:"currency" validate:"omitempty,iso4217"`
+}
+
+type RoomOccupancyBody struct {
+	MaxOccupancy int `json:"maxOccupancy" validate:"gte=1"`
+}
+
+// RoomRatesBody sets the default prices of a room. Leaving out the currency keeps the room's base currency
+type RoomRatesBody struct {
+	BaseRate money.Amount `json:"baseRate" validate:"gte=0"`
+	WeekendRate money.Amount `json:"weekendRate" validate:"gte=0"`
+	Currency string `json:"currency" validate:"omitempty,iso4217"`
+}
+
+type RoomRateBody struct {
+	RateName string `json:"rateName"`
+	StartDate string `json:"startDate" validate:"required" faker:"date"`
+	EndDate string `json:"endDate" validate:"required" faker:"date"`
+	NightlyRate money.Amount `json:"nightlyRate" validate:"gt=0"`
+	WeekendRate money.Amount `json:"weekendRate" validate:"gte=0"`
+	CancellationPolicyId int `json:"cancellationPolicyId" validate:"gte=0"`
+}
+
+// CalendarFeedBody registers an external calendar. Leave the url empty to import uploaded files only
+type CalendarFeedBody struct {
+	Name string `json:"name" validate:"required"`
+	URL string `json:"url" validate:"omitempty,url"`
+}
+
+type RestrictionBody struct {
+	RestrictionName string `json:"restrictionName" validate:"required,max=255"`
+}
+
+// RoomBlockBody blocks a room for the dates. RestrictionId defaults to an owner block
+type RoomBlockBody struct {
+	StartDate string `json:"startDate" validate:"required" faker:"date"`
+	EndDate string `json:"endDate" validate:"required" faker:"date"`
+	RestrictionId int `json:"restrictionId" validate:"gte=0"`
+	Note string `json:"note" validate:"max=1000"`
+}
+
+// RecurringBlockBody blocks the room for Nights nights, one by default, from every occurrence of the RFC 5545 RRULE
+type RecurringBlockBody struct {
+	StartDate string `json:"startDate" validate:"required" faker:"date"`
+	Nights int `json:"nights" validate:"gte=0,lte=366"`
+	RRule string `json:"rrule" validate:"required"`
+	RestrictionId int `json:"restrictionId" validate:"gte=0"`
+	Note string `json:"note" validate:"max=1000"`
+}
+
+type RecurringBlockExceptionBody struct {
+	Date string `json:"date" validate:"required" faker:"date"`
+}
+
+// StayRuleBody limits the stays arriving between StartDate and EndDate. Zero limits and empty day lists are not enforced
+type StayRuleBody struct {
+	RuleName string `json:"ruleName"`
+	StartDate string `json:"startDate" validate:"required" faker:"date"`
+	EndDate string `json:"endDate" validate:"required" faker:"date"`
+	MinNights int `json:"minNights" validate:"gte=0"`
+	MaxNights int `json:"maxNights" validate:"gte=0"`
+	ClosedToArrival []string `json:"closedToArrival"`
+	ClosedToDeparture []string `json:"closedToDeparture"`
+	MinLeadDays int `json:"minLeadDays" validate:"gte=0"`
+	MaxLeadDays int `json:"maxLeadDays" validate:"gte=0"`
+}
+
+type CancellationWindowBody struct {
+	HoursBefore int `json:"hoursBefore" validate:"gte=0"`
+	FeePercent int `json:"feePercent" validate:"gte=0,lte=100"`
+}
+
+// CancellationPolicyBody creates a cancellation policy. Without windows cancelling is free
+type CancellationPolicyBody struct {
+	PolicyName string `json:"policyName" validate:"required,max=255"`
+	Windows []CancellationWindowBody `json:"windows" validate:"max=20,dive"`
+	NoShowPercent int `json:"noShowPercent" validate:"gte=0,lte=100"`
+}
+
+// RoomCancellationPolicyBody attaches a policy to a room, a CancellationPolicyId of 0 detaches it
+type RoomCancellationPolicyBody struct {
+	CancellationPolicyId int `json:"cancellationPolicyId" validate:"gte=0"`
+}
+
+// PromoCodeBody creates or replaces a promo code. The value of a percent discount is a percentage, the value of a fixed
+// one an amount in minor units of the room's currency. Dates, caps and roomIds left out do not restrict the code
+type PromoCodeBody struct {
+	Code string `json:"code" validate:"required,alphanum,max=50"`
+	DiscountType string `json:"discountType" validate:"required,oneof=percent fixed"`
+	DiscountValue int64 `json:"discountValue" validate:"gt=0"`
+	ValidFrom string `json:"validFrom"`
+	ValidUntil string `json:"validUntil"`
+	MinNights int `json:"minNights" validate:"gte=0"`
+	MaxUses int `json:"maxUses" validate:"gte=0"`
+	MaxUsesPerEmail int `json:"maxUsesPerEmail" validate:"gte=0"`
+	RoomIds []int `json:"roomIds" validate:"max=100,dive,gt=0"`
+}
+
+// ChargeRuleBody creates a tax or fee. The amount of a percent charge is in hundredths of a percent, of the other
+// kinds in minor units of the room's currency. Leave out the roomId to charge stays in every room
+type ChargeRuleBody struct {
+	RuleName string `json:"ruleName" validate:"required,max=255"`
+	Kind string `json:"kind" validate:"required,oneof=per_person_night percent flat"`
+	Amount int64 `json:"amount" validate:"gt=0"`
+	RoomId int `json:"roomId" validate:"gte=0"`
+}
+
+// ExchangeRateBody sets how many units of the quote currency one unit of the base currency buys, as a decimal string
+type ExchangeRateBody struct {
+	BaseCurrency string `json:"baseCurrency" validate:"required,iso4217"`
+	QuoteCurrency string `json:"quoteCurrency" validate:"required,iso4217,nefield=BaseCurrency"`
+	Rate money.Rate `json:"rate" validate:"required"`
 }
\ No newline at end of file
@@ -0,0 +1,120 @@
+package guests
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+	"github.com/Orololuwa/go-backend-boilerplate/src/money"
+)
+
+var ErrInvalidPhone = errors.New("phone number cannot be read as an international number")
+
+// gmailDomains ignore dots in the local part of an address
+var gmailDomains = map[string]bool{
+	"gmail.com": true,
+	"googlemail.com": true,
+}
+
+// NormalizeEmail returns the key guests are matched on by email. It is trimmed and lower case without the +tag of
+// the local part, and Gmail addresses lose the dots Gmail ignores, so J.Doe+spa@GoogleMail.com is jdoe@gmail.com
+func NormalizeEmail(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok {
+		return email
+	}
+
+	local, _, _ = strings.Cut(local, "+")
+	if gmailDomains[domain] {
+		local = strings.ReplaceAll(local, ".", "")
+		domain = "gmail.com"
+	}
+
+	return local + "@" + domain
+}
+
+// NormalizePhone returns the number in E.164, +2348012345678. Numbers written without an international prefix, + or 00,
+// are national numbers of the country calling code, whose trunk 0 is dropped. ErrInvalidPhone is returned for a number
+// that is not a plausible international number, or for a national number when countryCode is empty
+func NormalizePhone(phone, countryCode string) (string, error) {
+	phone = strings.TrimSpace(phone)
+
+	international := false
+	switch {
+	case strings.HasPrefix(phone, "+"):
+		international = true
+		phone = phone[1:]
+	case strings.HasPrefix(phone, "00"):
+		international = true
+		phone = phone[2:]
+	}
+
+	var digits strings.Builder
+	for _, c := range phone {
+		switch {
+		case c >= '0' && c <= '9':
+			digits.WriteRune(c)
+		case strings.ContainsRune(" -.()/", c):
+		default:
+			return "", ErrInvalidPhone
+		}
+	}
+
+	number := digits.String()
+	if !international {
+		if countryCode == "" {
+			return "", ErrInvalidPhone
+		}
+		number = strings.TrimPrefix(countryCode, "+") + strings.TrimPrefix(number, "0")
+	}
+
+	// E.164 numbers have at most 15 digits and country codes never start with 0
+	if len(number) < 8 || len(number) > 15 || number[0] == '0' {
+		return "", ErrInvalidPhone
+	}
+
+	return "+" + number, nil
+}
+
+// Keys returns the email and phone keys of a guest. A phone number that cannot be normalised has an empty key and
+// is not matched on
+func Keys(email, phone, countryCode string) (string, string) {
+	phoneKey, err := NormalizePhone(phone, countryCode)
+	if err != nil {
+		phoneKey = ""
+	}
+	return NormalizeEmail(email), phoneKey
+}
+
+// Stats sums up the reservations of a guest. Stays and nights count the confirmed reservations, spend is what the
+// guest was charged for them and the fees of the ones they cancelled or did not turn up for, per currency
+func Stats(reservations []models.Reservation) models.GuestStats {
+	stats := models.GuestStats{
+		Reservations: len(reservations),
+		Spend: make(map[string]money.Amount),
+	}
+
+	for _, res := range reservations {
+		switch res.Status {
+		case models.ReservationStatusConfirmed:
+			stats.Stays++
+			stats.Nights += int(res.EndDate.Sub(res.StartDate).Hours() / 24)
+			stats.Spend[res.Currency] += res.TotalPrice
+
+			if stats.LastStay == nil || res.StartDate.After(*stats.LastStay) {
+				start := res.StartDate
+				stats.LastStay = &start
+			}
+		case models.ReservationStatusCancelled, models.ReservationStatusNoShow:
+			stats.Cancellations++
+			if res.CancellationFee > 0 {
+				stats.Spend[res.Currency] += res.CancellationFee
+			}
+		}
+	}
+
+	return stats
+}
+
@@ -0,0 +1,85 @@
+package guests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+)
+
+func TestNormalizeEmail(t *testing.T){
+	var emailTests = []struct {
+		in string
+		expected string
+	}{
+		{"johndoe@example.com", "johndoe@example.com"},
+		{"  JohnDoe@Example.COM ", "johndoe@example.com"},
+		{"john.doe+spa@example.com", "john.doe@example.com"},
+		{"J.Doe+spa@GoogleMail.com", "jdoe@gmail.com"},
+		{"john.doe@gmail.com", "johndoe@gmail.com"},
+		{"not-an-email", "not-an-email"},
+	}
+
+	for _, e := range emailTests {
+		if got := NormalizeEmail(e.in); got != e.expected {
+			t.Errorf("NormalizeEmail(%q) returned %q, wanted %q", e.in, got, e.expected)
+		}
+	}
+}
+
+func TestNormalizePhone(t *testing.T){
+	var phoneTests = []struct {
+		in string
+		countryCode string
+		expected string
+		valid bool
+	}{
+		{"+234 801 234 5678", "", "+2348012345678", true},
+		{"002348012345678", "", "+2348012345678", true},
+		{"08012345678", "234", "+2348012345678", true},
+		{"(080) 1234-5678", "+234", "+2348012345678", true},
+		{"08012345678", "", "", false},
+		{"+44 20 7946 0958", "234", "+442079460958", true},
+		{"+0 801 234 5678", "", "", false},
+		{"+1234567", "", "", false},
+		{"+1234567890123456", "", "", false},
+		{"+234 801 CALL NOW", "", "", false},
+		{"", "234", "", false},
+	}
+
+	for _, e := range phoneTests {
+		got, err := NormalizePhone(e.in, e.countryCode)
+		if e.valid && (err != nil || got != e.expected) {
+			t.Errorf("NormalizePhone(%q, %q) returned %q and %v, wanted %q", e.in, e.countryCode, got, err, e.expected)
+		}
+		if !e.valid && err == nil {
+			t.Errorf("NormalizePhone(%q, %q) returned %q, wanted an error", e.in, e.countryCode, got)
+		}
+	}
+}
+
+func TestStats(t *testing.T){
+	day := func(d int) time.Time {
+		return time.Date(2050, time.January, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	reservations := []models.Reservation{
+		{StartDate: day(2), EndDate: day(4), TotalPrice: 20000, Currency: "USD", Status: models.ReservationStatusConfirmed},
+		{StartDate: day(10), EndDate: day(13), TotalPrice: 30000, Currency: "EUR", Status: models.ReservationStatusConfirmed},
+		{StartDate: day(6), EndDate: day(7), TotalPrice: 10000, Currency: "USD", Status: models.ReservationStatusConfirmed},
+		{StartDate: day(20), EndDate: day(22), TotalPrice: 20000, CancellationFee: 5000, Currency: "USD", Status: models.ReservationStatusCancelled},
+		{StartDate: day(25), EndDate: day(26), TotalPrice: 10000, Currency: "USD", Status: models.ReservationStatusPending},
+	}
+
+	stats := Stats(reservations)
+
+	if stats.Reservations != 5 || stats.Stays != 3 || stats.Nights != 6 || stats.Cancellations != 1 {
+		t.Errorf("Stats returned %d reservations, %d stays, %d nights and %d cancellations, wanted 5, 3, 6 and 1", stats.Reservations, stats.Stays, stats.Nights, stats.Cancellations)
+	}
+	if stats.Spend["USD"] != 35000 || stats.Spend["EUR"] != 30000 || len(stats.Spend) != 2 {
+		t.Errorf("Stats returned a spend of %v, wanted 35000 USD and 30000 EUR", stats.Spend)
+	}
+	if stats.LastStay == nil || !stats.LastStay.Equal(day(10)) {
+		t.Errorf("Stats returned a last stay of %v, wanted %v", stats.LastStay, day(10))
+	}
+}
@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/helpers"
+	"github.com/Orololuwa/go-backend-boilerplate/src/ical"
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+)
+
+const (
+	calendarProdID = "-//Orololuwa//go-backend-boilerplate//EN"
+	calendarUIDDomain = "go-backend-boilerplate"
+
+	// how far back the room feed goes, older stays are of no use to housekeeping
+	calendarHistory = 90 * 24 * time.Hour
+)
+
+// reservationEventUID is shared by the room feed and the guest attachment, and survives the
+// restriction being recreated when the stay is moved
+func reservationEventUID(reservationId int) string {
+	return fmt.Sprintf("reservation-%d@%s", reservationId, calendarUIDDomain)
+}
+
+func reservationEventStatus(status string) string {
+	switch status {
+	case models.ReservationStatusConfirmed:
+		return ical.StatusConfirmed
+	case models.ReservationStatusCancelled:
+		return ical.StatusCancelled
+	default:
+		return ical.StatusTentative
+	}
+}
+
+// eventSequence grows with every change to the record without storing a counter
+func eventSequence(created, updated time.Time) int {
+	if updated.Before(created) {
+		return 0
+	}
+	return int(updated.Sub(created) / time.Second)
+}
+
+// restrictionEvent is an event of the room feed. The feed is handed to calendar apps and owners, and a reservation's
+// id and confirmation code are all a guest needs to cancel or pay for it, so the code is never part of it
+func restrictionEvent(rr models.RoomRestriction) ical.Event {
+	event := ical.Event{
+		UID: fmt.Sprintf("restriction-%d@%s", rr.ID, calendarUIDDomain),
+		Start: rr.StartDate,
+		End: rr.EndDate,
+		Status: ical.StatusConfirmed,
+		Sequence: eventSequence(rr.CreatedAt, rr.UpdatedAt),
+		LastModified: rr.UpdatedAt,
+	}
+
+	switch rr.RestrictionID {
+	case models.RestrictionReservation:
+		res := rr.Reservation
+		event.UID = reservationEventUID(res.ID)
+		event.Summary = fmt.Sprintf("Reserved: %s %s", res.FirstName, res.LastName)
+		event.Status = reservationEventStatus(res.Status)
+		event.Sequence = eventSequence(res.CreatedAt, res.UpdatedAt)
+		event.LastModified = res.UpdatedAt
+	case models.RestrictionHold:
+		event.Summary = "Held"
+		event.Status = ical.StatusTentative
+	default:
+		// staff blocks carry the name of their type, "Owner block", "Maintenance" and so on
+		event.Summary = rr.Restriction.RestrictionName
+		if event.Summary == "" {
+			event.Summary = "Blocked"
+		}
+		event.Description = rr.Note
+	}
+
+	return event
+}
+
+func writeCalendar(w http.ResponseWriter, cal ical.Calendar, filename string) {
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	if filename != "" {
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(cal.Marshal(time.Now()))
+}
+
+// GetRoomCalendar serves the room's occupancy as an iCalendar feed. The URL carries the room's secret token
+// because calendar apps cannot send an Authorization header
+func (m *Repository) GetRoomCalendar(w http.ResponseWriter, r *http.Request) {
+	roomId, err := m.urlParamInt(r, "id", 2)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	ctx := context.Background()
+
+	secret, err := m.DB.GetRoomCalendarToken(ctx, nil, roomId)
+	if errors.Is(err, sql.ErrNoRows) {
+		helpers.ClientError(w, err, http.StatusNotFound, "room not found")
+		return
+	}
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	token := r.URL.Query().Get("token")
+	if secret == "" || subtle.ConstantTimeCompare([]byte(secret), []byte(token)) != 1 {
+		helpers.ClientError(w, errors.New("invalid calendar token"), http.StatusUnauthorized, "")
+		return
+	}
+
+	room, err := m.DB.GetRoomById(ctx, nil, roomId)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	restrictions, err := m.DB.GetRoomRestrictionsForCalendar(ctx, nil, roomId, time.Now().Add(-calendarHistory))
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	cal := ical.Calendar{
+		ProdID: calendarProdID,
+		Name: room.RoomName,
+		Events: make([]ical.Event, 0, len(restrictions)),
+	}
+
+	for _, rr := range restrictions {
+		// blocks imported from other platforms are left out, or each platform would read its own bookings back
+		if rr.RestrictionID == models.RestrictionExternalBlock {
+			continue
+		}
+		cal.Events = append(cal.Events, restrictionEvent(rr))
+	}
+
+	writeCalendar(w, cal, "")
+}
+
+// RotateRoomCalendarToken issues a new secret for the room's calendar feed, the previous feed URL stops working
+func (m *Repository) RotateRoomCalendarToken(w http.ResponseWriter, r *http.Request) {
+	roomId, err := m.urlParamInt(r, "id", 2)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	token, err := helpers.RandomToken(24)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	err = m.DB.UpdateRoomCalendarToken(context.Background(), nil, roomId, token)
+	if errors.Is(err, sql.ErrNoRows) {
+		helpers.ClientError(w, err, http.StatusNotFound, "room not found")
+		return
+	}
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	data := map[string]string{
+		"token": token,
+		"path": fmt.Sprintf("/room/%d/calendar.ics?token=%s", roomId, token),
+	}
+
+	helpers.ClientResponseWriter(w, data, http.StatusOK, "calendar token issued successfully")
+}
+
+// GetReservationCalendar returns the stay as a single event the guest can add to their own calendar
+func (m *Repository) GetReservationCalendar(w http.ResponseWriter, r *http.Request) {
+	id, err := m.urlParamInt(r, "id", 2)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	res, err := m.DB.GetReservationById(context.Background(), nil, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		helpers.ClientError(w, err, http.StatusNotFound, "reservation not found")
+		return
+	}
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	// the reservation id is guessable, so the guest proves ownership with the confirmation code
+	if !helpers.ConfirmationCodeMatches(res.ConfirmationCode, r.URL.Query().Get("code")) {
+		helpers.ClientError(w, sql.ErrNoRows, http.StatusNotFound, "reservation not found")
+		return
+	}
+
+	summary := "Stay"
+	if res.Room.RoomName != "" {
+		summary = "Stay in " + res.Room.RoomName
+	}
+
+	cal := ical.Calendar{
+		ProdID: calendarProdID,
+		Method: "PUBLISH",
+		Events: []ical.Event{
+			{
+				UID: reservationEventUID(res.ID),
+				Summary: summary,
+				Description: "Confirmation code " + res.ConfirmationCode,
+				Start: res.StartDate,
+				End: res.EndDate,
+				Status: reservationEventStatus(res.Status),
+				Sequence: eventSequence(res.CreatedAt, res.UpdatedAt),
+				LastModified: res.UpdatedAt,
+			},
+		},
+	}
+
+	writeCalendar(w, cal, fmt.Sprintf("reservation-%s.ics", res.ConfirmationCode))
+}
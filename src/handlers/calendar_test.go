@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRepository_GetRoomCalendar(t *testing.T){
+	var calendarTests = []struct {
+		name string
+		url string
+		expectedStatusCode int
+	}{
+		{"valid token", "/room/15/calendar.ics?token=room-secret", http.StatusOK},
+		{"wrong token", "/room/15/calendar.ics?token=guess", http.StatusUnauthorized},
+		{"missing token", "/room/15/calendar.ics", http.StatusUnauthorized},
+		{"feed not enabled", "/room/16/calendar.ics?token=", http.StatusUnauthorized},
+		{"room not found", "/room/404/calendar.ics?token=room-secret", http.StatusNotFound},
+		{"failed token lookup", "/room/1000/calendar.ics?token=room-secret", http.StatusInternalServerError},
+	}
+
+	for _, e := range calendarTests {
+		req, _ := http.NewRequest("GET", e.url, nil)
+		res := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(Repo.GetRoomCalendar)
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("GetRoomCalendar handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+			continue
+		}
+
+		if res.Code != http.StatusOK {
+			continue
+		}
+
+		body := res.Body.String()
+		if !strings.HasPrefix(res.Header().Get("Content-Type"), "text/calendar") {
+			t.Errorf("GetRoomCalendar handler returned content type %q", res.Header().Get("Content-Type"))
+		}
+
+		// reservation events keep the reservation's UID so moving the stay updates the same event
+		if !strings.Contains(body, "UID:reservation-7@") || !strings.Contains(body, "UID:restriction-2@") {
+			t.Errorf("GetRoomCalendar handler returned unexpected events:\n%s", body)
+		}
+
+		// the id and code of a reservation are enough to cancel it, so the shared feed never carries the code
+		if strings.Contains(body, "ABCD2345") || strings.Contains(body, "Confirmation code") {
+			t.Errorf("GetRoomCalendar handler returned a confirmation code in the room feed:\n%s", body)
+		}
+	}
+}
+
+func TestRepository_GetReservationCalendar(t *testing.T){
+	var calendarTests = []struct {
+		name string
+		url string
+		expectedStatusCode int
+	}{
+		{"valid code", "/reservation/1/calendar.ics?code=ABCD2345", http.StatusOK},
+		{"code as the guest typed it", "/reservation/1/calendar.ics?code=abcd-2345", http.StatusOK},
+		{"wrong code", "/reservation/1/calendar.ics?code=WRONG", http.StatusNotFound},
+		{"reservation not found", "/reservation/404/calendar.ics?code=ABCD2345", http.StatusNotFound},
+	}
+
+	for _, e := range calendarTests {
+		req, _ := http.NewRequest("GET", e.url, nil)
+		res := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(Repo.GetReservationCalendar)
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("GetReservationCalendar handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+			continue
+		}
+
+		if res.Code == http.StatusOK && !strings.Contains(res.Body.String(), "UID:reservation-1@") {
+			t.Errorf("GetReservationCalendar handler returned an unexpected event:\n%s", res.Body.String())
+		}
+	}
+}
+
+func TestRepository_RotateRoomCalendarToken(t *testing.T){
+	var rotateTests = []struct {
+		name string
+		url string
+		expectedStatusCode int
+	}{
+		{"rotate token", "/room/15/calendar-token", http.StatusOK},
+		{"room not found", "/room/404/calendar-token", http.StatusNotFound},
+	}
+
+	for _, e := range rotateTests {
+		req, _ := http.NewRequest("POST", e.url, nil)
+		res := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(Repo.RotateRoomCalendarToken)
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("RotateRoomCalendarToken handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/cancellation"
+	"github.com/Orololuwa/go-backend-boilerplate/src/dtos"
+	"github.com/Orololuwa/go-backend-boilerplate/src/helpers"
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+	"github.com/Orololuwa/go-backend-boilerplate/src/money"
+	"github.com/Orololuwa/go-backend-boilerplate/src/pricing"
+)
+
+var (
+	errReservationNoShow = errors.New("reservation has been marked as a no-show")
+	errNotArrived = errors.New("the guest is not due to arrive yet")
+)
+
+// stayCancellationPolicy returns the policy a quoted stay is booked under, the one of the rate the arrival night
+// is priced by or else the room's. It returns nil when there is none and cancelling is free
+func (m *Repository) stayCancellationPolicy(ctx context.Context, tx *sql.Tx, roomId int, quote pricing.Quote) (*models.CancellationPolicy, error) {
+	rateId := 0
+	if len(quote.Nights) > 0 {
+		rateId = quote.Nights[0].RateID
+	}
+
+	policy, err := m.DB.GetCancellationPolicyForStay(ctx, tx, roomId, rateId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &policy, nil
+}
+
+// paidAmount is what the guest has paid for the reservation and not been refunded yet
+func (m *Repository) paidAmount(ctx context.Context, tx *sql.Tx, reservationId int) (money.Amount, error) {
+	payment, err := m.Payment.GetLatestPaymentByReservationId(ctx, tx, reservationId, models.PaymentStatusCaptured, models.PaymentStatusPartiallyRefunded)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return payment.CapturedAmount - payment.RefundedAmount, nil
+}
+
+// closeReservation cancels the reservation or marks it as a no-show. Its room is released, and the fee its
+// cancellation policy charges and the part of what the guest paid that is refunded are recorded. The caller locks
+// the reservation with LockReservation before reading it, so a capture or refund cannot change what was paid meanwhile
+func (m *Repository) closeReservation(ctx context.Context, tx *sql.Tx, res *models.Reservation, status string) error {
+	before := *res
+
+	err := m.DB.DeleteRoomRestrictionsByReservationId(ctx, tx, res.ID)
+	if err != nil {
+		return err
+	}
+
+	fee := cancellation.NoShowFee(res.CancellationPolicy, res.TotalPrice)
+	if status == models.ReservationStatusCancelled {
+		fee = cancellation.Fee(res.CancellationPolicy, res.TotalPrice, cancellation.Arrival(res.StartDate), time.Now())
+	}
+
+	paid, err := m.paidAmount(ctx, tx, res.ID)
+	if err != nil {
+		return err
+	}
+
+	res.Status = status
+	res.CancellationFee = fee
+	res.RefundAmount = cancellation.Refund(paid, fee)
+
+	err = m.DB.UpdateReservationCancellation(ctx, tx, res.ID, res.Status, res.CancellationFee, res.RefundAmount)
+	if err != nil {
+		return err
+	}
+
+	action := models.HistoryStatusChanged
+	if status == models.ReservationStatusCancelled {
+		action = models.HistoryCancelled
+	}
+	return m.recordHistory(ctx, tx, action, &before, *res)
+}
+
+// PostNoShow marks a reservation whose guest never arrived. The room is released for the rest of the stay and the
+// no-show charge of the reservation's cancellation policy is recorded
+func (m *Repository) PostNoShow(w http.ResponseWriter, r *http.Request) {
+	id, err := m.urlParamInt(r, "id", 2)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	var res models.Reservation
+
+	err = m.DB.Transaction(withActor(r), func(ctx context.Context, tx *sql.Tx) error {
+		var err error
+
+		// the reservation stays locked from the check of its status until it is closed, so two cancellations, or a
+		// cancellation and a no-show, cannot both close it
+		err = m.DB.LockReservation(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		res, err = m.DB.GetReservationById(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		switch res.Status {
+		case models.ReservationStatusCancelled:
+			return errReservationCancelled
+		case models.ReservationStatusNoShow:
+			return errReservationNoShow
+		}
+
+		if cancellation.Arrival(res.StartDate).After(time.Now()) {
+			return errNotArrived
+		}
+
+		return m.closeReservation(ctx, tx, &res, models.ReservationStatusNoShow)
+	})
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			helpers.ClientError(w, err, http.StatusNotFound, "reservation not found")
+		case errors.Is(err, errReservationCancelled), errors.Is(err, errReservationNoShow), errors.Is(err, errNotArrived):
+			helpers.ClientError(w, err, http.StatusConflict, "")
+		default:
+			helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		}
+		return
+	}
+
+	m.offerReleasedDates(res)
+
+	helpers.ClientResponseWriter(w, reservationResponse(res), http.StatusOK, "reservation marked as a no-show")
+}
+
+func (m *Repository) GetCancellationPolicies(w http.ResponseWriter, r *http.Request) {
+	policies, err := m.DB.GetCancellationPolicies(context.Background(), nil)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, policies, http.StatusOK, "cancellation policies retrieved successfully")
+}
+
+// PostCancellationPolicy creates a policy. It applies to bookings once it is attached to a room or a seasonal rate
+func (m *Repository) PostCancellationPolicy(w http.ResponseWriter, r *http.Request) {
+	var body dtos.CancellationPolicyBody
+	requestBody, ok := r.Context().Value("validatedRequestBody").(*dtos.CancellationPolicyBody)
+	if !ok || requestBody == nil {
+		helpers.ClientError(w, errors.New("failed to retrieve request body"), http.StatusBadRequest, "")
+		return
+	}
+	body = *requestBody
+
+	policy := models.CancellationPolicy{
+		PolicyName: body.PolicyName,
+		Windows: make([]models.CancellationWindow, 0, len(body.Windows)),
+		NoShowPercent: body.NoShowPercent,
+	}
+	for _, w := range body.Windows {
+		policy.Windows = append(policy.Windows, models.CancellationWindow{HoursBefore: w.HoursBefore, FeePercent: w.FeePercent})
+	}
+
+	err := cancellation.Validate(policy)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "")
+		return
+	}
+	cancellation.Sort(&policy)
+
+	policy.ID, err = m.DB.InsertCancellationPolicy(context.Background(), nil, policy)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, policy, http.StatusCreated, "cancellation policy created successfully")
+}
+
+// DeleteCancellationPolicy removes a policy. Rooms and rates using it become free to cancel, existing reservations
+// keep the copy they were booked with
+func (m *Repository) DeleteCancellationPolicy(w http.ResponseWriter, r *http.Request) {
+	id, err := m.urlParamInt(r, "id", 2)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	err = m.DB.DeleteCancellationPolicy(context.Background(), nil, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		helpers.ClientError(w, err, http.StatusNotFound, "cancellation policy not found")
+		return
+	}
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, nil, http.StatusOK, "cancellation policy deleted successfully")
+}
+
+// UpdateRoomCancellationPolicy sets the policy of the stays in a room that are not priced by a seasonal rate with its own
+func (m *Repository) UpdateRoomCancellationPolicy(w http.ResponseWriter, r *http.Request) {
+	roomId, err := m.urlParamInt(r, "id", 2)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	var body dtos.RoomCancellationPolicyBody
+	requestBody, ok := r.Context().Value("validatedRequestBody").(*dtos.RoomCancellationPolicyBody)
+	if !ok || requestBody == nil {
+		helpers.ClientError(w, errors.New("failed to retrieve request body"), http.StatusBadRequest, "")
+		return
+	}
+	body = *requestBody
+
+	ctx := context.Background()
+
+	if body.CancellationPolicyId != 0 {
+		_, err = m.DB.GetCancellationPolicyById(ctx, nil, body.CancellationPolicyId)
+		if errors.Is(err, sql.ErrNoRows) {
+			helpers.ClientError(w, err, http.StatusNotFound, "cancellation policy not found")
+			return
+		}
+		if err != nil {
+			helpers.ClientError(w, err, http.StatusInternalServerError, "")
+			return
+		}
+	}
+
+	err = m.DB.UpdateRoomCancellationPolicy(ctx, nil, roomId, body.CancellationPolicyId)
+	if errors.Is(err, sql.ErrNoRows) {
+		helpers.ClientError(w, err, http.StatusNotFound, "room not found")
+		return
+	}
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, body, http.StatusOK, "room cancellation policy updated successfully")
+}
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/dtos"
+	"github.com/Orololuwa/go-backend-boilerplate/src/money"
+	"github.com/Orololuwa/go-backend-boilerplate/src/types"
+)
+
+func TestRepository_PostCancellationPolicy(t *testing.T){
+	windows := []dtos.CancellationWindowBody{{HoursBefore: 48, FeePercent: 50}, {HoursBefore: 24, FeePercent: 100}}
+
+	var policyTests = []struct {
+		name string
+		body dtos.CancellationPolicyBody
+		expectedStatusCode int
+	}{
+		{"success", dtos.CancellationPolicyBody{PolicyName: "Moderate", Windows: windows, NoShowPercent: 100}, http.StatusCreated},
+		{"free to cancel", dtos.CancellationPolicyBody{PolicyName: "Flexible"}, http.StatusCreated},
+		{"missing name", dtos.CancellationPolicyBody{Windows: windows}, http.StatusBadRequest},
+		{"fee above the total", dtos.CancellationPolicyBody{PolicyName: "Strict", Windows: []dtos.CancellationWindowBody{{HoursBefore: 24, FeePercent: 150}}}, http.StatusBadRequest},
+		{"no-show charge above the total", dtos.CancellationPolicyBody{PolicyName: "Strict", NoShowPercent: 101}, http.StatusBadRequest},
+		{"windows starting together", dtos.CancellationPolicyBody{PolicyName: "Strict", Windows: []dtos.CancellationWindowBody{{HoursBefore: 24, FeePercent: 50}, {HoursBefore: 24, FeePercent: 100}}}, http.StatusBadRequest},
+		{"failed insert", dtos.CancellationPolicyBody{PolicyName: "Broken Policy"}, http.StatusInternalServerError},
+	}
+
+	for _, e := range policyTests {
+		jsonData, _ := json.Marshal(e.body)
+
+		req, _ := http.NewRequest("POST", "/cancellation-policy", bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		res := httptest.NewRecorder()
+
+		handler := mdTest.ValidateReqBody(http.HandlerFunc(Repo.PostCancellationPolicy), &dtos.CancellationPolicyBody{})
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("PostCancellationPolicy handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+}
+
+func TestRepository_GetCancellationPolicies(t *testing.T){
+	req, _ := http.NewRequest("GET", "/cancellation-policy", nil)
+	res := httptest.NewRecorder()
+
+	handler := http.HandlerFunc(Repo.GetCancellationPolicies)
+	handler.ServeHTTP(res, req)
+
+	if res.Code != http.StatusOK {
+		t.Errorf("GetCancellationPolicies handler returned wrong response code: got %d, wanted %d", res.Code, http.StatusOK)
+	}
+}
+
+func TestRepository_DeleteCancellationPolicy(t *testing.T){
+	var deleteTests = []struct {
+		name string
+		url string
+		expectedStatusCode int
+	}{
+		{"success", "/cancellation-policy/1", http.StatusOK},
+		{"policy not found", "/cancellation-policy/404", http.StatusNotFound},
+		{"invalid id", "/cancellation-policy/invalid", http.StatusBadRequest},
+	}
+
+	for _, e := range deleteTests {
+		req, _ := http.NewRequest("DELETE", e.url, nil)
+		res := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(Repo.DeleteCancellationPolicy)
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("DeleteCancellationPolicy handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+}
+
+func TestRepository_UpdateRoomCancellationPolicy(t *testing.T){
+	var updateTests = []struct {
+		name string
+		url string
+		policyId int
+		expectedStatusCode int
+	}{
+		{"attach", "/room/15/cancellation-policy", 1, http.StatusOK},
+		{"detach", "/room/15/cancellation-policy", 0, http.StatusOK},
+		{"policy not found", "/room/15/cancellation-policy", 404, http.StatusNotFound},
+		{"failed policy lookup", "/room/15/cancellation-policy", 1000, http.StatusInternalServerError},
+		{"room not found", "/room/404/cancellation-policy", 1, http.StatusNotFound},
+		{"failed update", "/room/1000/cancellation-policy", 1, http.StatusInternalServerError},
+		{"negative id", "/room/15/cancellation-policy", -1, http.StatusBadRequest},
+	}
+
+	for _, e := range updateTests {
+		jsonData, _ := json.Marshal(dtos.RoomCancellationPolicyBody{CancellationPolicyId: e.policyId})
+
+		req, _ := http.NewRequest("PUT", e.url, bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		res := httptest.NewRecorder()
+
+		handler := mdTest.ValidateReqBody(http.HandlerFunc(Repo.UpdateRoomCancellationPolicy), &dtos.RoomCancellationPolicyBody{})
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("UpdateRoomCancellationPolicy handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+}
+
+func TestRepository_PostNoShow(t *testing.T){
+	var noShowTests = []struct {
+		name string
+		url string
+		expectedStatusCode int
+	}{
+		{"guest never arrived", "/reservation/10/no-show", http.StatusOK},
+		{"arrival still ahead", "/reservation/2/no-show", http.StatusConflict},
+		{"already cancelled", "/reservation/4/no-show", http.StatusConflict},
+		{"already a no-show", "/reservation/11/no-show", http.StatusConflict},
+		{"reservation not found", "/reservation/404/no-show", http.StatusNotFound},
+		{"failed lookup", "/reservation/1000/no-show", http.StatusInternalServerError},
+	}
+
+	for _, e := range noShowTests {
+		req, _ := http.NewRequest("POST", e.url, nil)
+		res := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(Repo.PostNoShow)
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("PostNoShow handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+
+	// the test policy charges no-shows the whole stay, which the captured payment covers
+	req, _ := http.NewRequest("POST", "/reservation/10/no-show", nil)
+	res := httptest.NewRecorder()
+
+	http.HandlerFunc(Repo.PostNoShow).ServeHTTP(res, req)
+
+	var resp struct {
+		Data types.ReservationResponse `json:"data"`
+	}
+	err := json.Unmarshal(res.Body.Bytes(), &resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.Data.Status != "no_show" || resp.Data.CancellationFee != 34000 || resp.Data.RefundAmount != 0 {
+		t.Errorf("PostNoShow handler recorded the wrong charges: %+v", resp.Data)
+	}
+}
+
+func TestRepository_CancelReservationFee(t *testing.T){
+	var feeTests = []struct {
+		name string
+		url string
+		expectedFee money.Amount
+		expectedRefund money.Amount
+	}{
+		// reservation 9 arrives the day after tomorrow, inside the 48 hour window of the test policy
+		{"late cancellation", "/reservation/9/cancel", 17000, 17000},
+		{"no policy", "/reservation/1/cancel", 0, 34000},
+	}
+
+	for _, e := range feeTests {
+		jsonData, _ := json.Marshal(dtos.CancelBody{ConfirmationCode: "ABCD2345"})
+
+		req, _ := http.NewRequest("POST", e.url, bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		res := httptest.NewRecorder()
+
+		handler := mdTest.ValidateReqBody(http.HandlerFunc(Repo.CancelReservation), &dtos.CancelBody{})
+		handler.ServeHTTP(res, req)
+
+		var resp struct {
+			Data types.ReservationResponse `json:"data"`
+		}
+		err := json.Unmarshal(res.Body.Bytes(), &resp)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.Data.CancellationFee != e.expectedFee || resp.Data.RefundAmount != e.expectedRefund {
+			t.Errorf("CancelReservation handler charged %d and refunded %d for the %s, wanted %d and %d", resp.Data.CancellationFee, resp.Data.RefundAmount, e.name, e.expectedFee, e.expectedRefund)
+		}
+	}
+}
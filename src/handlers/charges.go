@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/charges"
+	"github.com/Orololuwa/go-backend-boilerplate/src/dtos"
+	"github.com/Orololuwa/go-backend-boilerplate/src/helpers"
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+	"github.com/Orololuwa/go-backend-boilerplate/src/types"
+)
+
+// applyCharges adds the taxes and fees of the room to the price of the reservation, which is its discounted room
+// total until then. Fees are converted into the currency the reservation is charged in. saveCharges must store the
+// lines once the reservation is saved
+func (m *Repository) applyCharges(ctx context.Context, tx *sql.Tx, res *models.Reservation, nights int) error {
+	rules, err := m.DB.GetChargeRules(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	rules = reservationExchange(*res).chargeRules(charges.ForRoom(rules, res.RoomID))
+	res.Charges = charges.Apply(rules, res.TotalPrice, res.Guests(), nights)
+	res.TotalPrice += charges.Total(res.Charges)
+	return nil
+}
+
+// saveCharges stores the tax and fee lines of the saved reservation, replacing the lines of its previous dates
+func (m *Repository) saveCharges(ctx context.Context, tx *sql.Tx, res models.Reservation) error {
+	err := m.DB.DeleteReservationCharges(ctx, tx, res.ID)
+	if err != nil {
+		return err
+	}
+
+	return m.DB.InsertReservationCharges(ctx, tx, res.ID, res.Charges)
+}
+
+// withCharges adds the taxes and fees of the room to a quote for guests guests, after any discount
+func withCharges(q *types.RoomQuoteResponse, rules []models.ChargeRule, guests, nights int) {
+	q.Subtotal = q.Total
+	q.Charges = charges.Apply(charges.ForRoom(rules, q.RoomId), q.Subtotal, guests, nights)
+	q.Total = q.Subtotal + charges.Total(q.Charges)
+}
+
+func (m *Repository) GetChargeRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := m.DB.GetChargeRules(context.Background(), nil)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, rules, http.StatusOK, "charge rules retrieved successfully")
+}
+
+// PostChargeRule creates a tax or fee charged on the stays booked from now on
+func (m *Repository) PostChargeRule(w http.ResponseWriter, r *http.Request) {
+	var body dtos.ChargeRuleBody
+	requestBody, ok := r.Context().Value("validatedRequestBody").(*dtos.ChargeRuleBody)
+	if !ok || requestBody == nil {
+		helpers.ClientError(w, errors.New("failed to retrieve request body"), http.StatusBadRequest, "")
+		return
+	}
+	body = *requestBody
+
+	rule := models.ChargeRule{
+		RuleName: body.RuleName,
+		Kind: body.Kind,
+		Amount: body.Amount,
+		RoomID: body.RoomId,
+	}
+
+	err := charges.Validate(rule)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "")
+		return
+	}
+
+	ctx := context.Background()
+
+	if rule.RoomID != 0 {
+		_, err = m.DB.GetRoomById(ctx, nil, rule.RoomID)
+		if errors.Is(err, sql.ErrNoRows) {
+			helpers.ClientError(w, err, http.StatusNotFound, "room not found")
+			return
+		}
+		if err != nil {
+			helpers.ClientError(w, err, http.StatusInternalServerError, "")
+			return
+		}
+	}
+
+	rule.ID, err = m.DB.InsertChargeRule(ctx, nil, rule)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, rule, http.StatusCreated, "charge rule created successfully")
+}
+
+// DeleteChargeRule stops charging a tax or fee. Reservations keep the lines they were booked with
+func (m *Repository) DeleteChargeRule(w http.ResponseWriter, r *http.Request) {
+	id, err := m.urlParamInt(r, "id", 2)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	err = m.DB.DeleteChargeRule(context.Background(), nil, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		helpers.ClientError(w, err, http.StatusNotFound, "charge rule not found")
+		return
+	}
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, nil, http.StatusOK, "charge rule deleted successfully")
+}
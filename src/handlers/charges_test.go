@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/dtos"
+	"github.com/Orololuwa/go-backend-boilerplate/src/money"
+	"github.com/Orololuwa/go-backend-boilerplate/src/types"
+)
+
+func TestRepository_PostQuoteCharges(t *testing.T){
+	// three nights for two adults in room 20, which is charged city tax, a cleaning fee and VAT
+	body := dtos.QuoteBody{PostAvailabilityBody: dtos.PostAvailabilityBody{StartDate: "2050-01-06", EndDate: "2050-01-09", Adults: 2}, RoomId: 20}
+	jsonData, _ := json.Marshal(body)
+
+	req, _ := http.NewRequest("POST", "/quote", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	res := httptest.NewRecorder()
+
+	handler := mdTest.ValidateReqBody(http.HandlerFunc(Repo.PostQuote), &dtos.QuoteBody{})
+	handler.ServeHTTP(res, req)
+
+	var resp struct {
+		Data []types.RoomQuoteResponse `json:"data"`
+	}
+	err := json.Unmarshal(res.Body.Bytes(), &resp)
+	if err != nil || res.Code != http.StatusOK || len(resp.Data) != 1 {
+		t.Fatalf("PostQuote handler returned %d with %s", res.Code, res.Body.String())
+	}
+	quote := resp.Data[0]
+
+	// 900 city tax for 6 guest nights, 2500 cleaning and 7.5% VAT on 36500
+	expected := []money.Amount{900, 2500, 2738}
+	if len(quote.Charges) != len(expected) {
+		t.Fatalf("PostQuote handler returned the charges %+v, wanted %d lines", quote.Charges, len(expected))
+	}
+	for i, amount := range expected {
+		if quote.Charges[i].Amount != amount {
+			t.Errorf("PostQuote handler charged %d for %s, wanted %d", quote.Charges[i].Amount, quote.Charges[i].ChargeName, amount)
+		}
+	}
+
+	if quote.Subtotal != 34000 || quote.Total != 40138 {
+		t.Errorf("PostQuote handler returned a subtotal of %d and a total of %d, wanted 34000 and 40138", quote.Subtotal, quote.Total)
+	}
+}
+
+func TestRepository_PostReservationCharges(t *testing.T){
+	var reservationTests = []struct {
+		name string
+		roomId int
+		adults int
+		quotedTotal money.Amount
+		expectedStatusCode int
+		expectedTotal money.Amount
+	}{
+		{"taxed room", 20, 2, 0, http.StatusCreated, 40138},
+		{"matching quote", 20, 2, 40138, http.StatusCreated, 40138},
+		{"quote without the charges", 20, 2, 34000, http.StatusConflict, 0},
+		{"single guest", 20, 1, 0, http.StatusCreated, 39688},
+		{"room without charges", 15, 2, 0, http.StatusCreated, 34000},
+	}
+
+	for _, e := range reservationTests {
+		body := dtos.ReservationBody{
+			FirstName: "John",
+			LastName: "Doe",
+			Email: "johndoe@gmail.com",
+			Phone: "08012345678",
+			StartDate: "2050-01-06",
+			EndDate: "2050-01-09",
+			RoomId: e.roomId,
+			Adults: e.adults,
+			QuotedTotal: e.quotedTotal,
+		}
+		jsonBody, _ := json.Marshal(body)
+
+		req, _ := http.NewRequest("POST", "/reservation", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(Repo.PostReservation)
+		handler.ServeHTTP(rr, req)
+
+		if rr.Code != e.expectedStatusCode {
+			t.Errorf("PostReservation handler returned wrong response code for %s: got %d, wanted %d", e.name, rr.Code, e.expectedStatusCode)
+			continue
+		}
+		if rr.Code != http.StatusCreated {
+			continue
+		}
+
+		var resp struct {
+			Data types.ReservationResponse `json:"data"`
+		}
+		_ = json.Unmarshal(rr.Body.Bytes(), &resp)
+
+		if resp.Data.TotalPrice != e.expectedTotal {
+			t.Errorf("PostReservation handler returned a total of %d for %s, wanted %d", resp.Data.TotalPrice, e.name, e.expectedTotal)
+		}
+	}
+}
+
+func TestRepository_PostChargeRule(t *testing.T){
+	var ruleTests = []struct {
+		name string
+		body dtos.ChargeRuleBody
+		expectedStatusCode int
+	}{
+		{"city tax", dtos.ChargeRuleBody{RuleName: "City tax", Kind: "per_person_night", Amount: 150}, http.StatusCreated},
+		{"vat", dtos.ChargeRuleBody{RuleName: "VAT", Kind: "percent", Amount: 750}, http.StatusCreated},
+		{"cleaning fee for a room", dtos.ChargeRuleBody{RuleName: "Cleaning fee", Kind: "flat", Amount: 2500, RoomId: 15}, http.StatusCreated},
+		{"missing name", dtos.ChargeRuleBody{Kind: "flat", Amount: 2500}, http.StatusBadRequest},
+		{"unknown kind", dtos.ChargeRuleBody{RuleName: "Resort fee", Kind: "per_room_night", Amount: 2500}, http.StatusBadRequest},
+		{"no amount", dtos.ChargeRuleBody{RuleName: "Resort fee", Kind: "flat"}, http.StatusBadRequest},
+		{"percent above the price", dtos.ChargeRuleBody{RuleName: "VAT", Kind: "percent", Amount: 10001}, http.StatusBadRequest},
+		{"room not found", dtos.ChargeRuleBody{RuleName: "Cleaning fee", Kind: "flat", Amount: 2500, RoomId: 404}, http.StatusNotFound},
+		{"failed room lookup", dtos.ChargeRuleBody{RuleName: "Cleaning fee", Kind: "flat", Amount: 2500, RoomId: 1000}, http.StatusInternalServerError},
+		{"failed insert", dtos.ChargeRuleBody{RuleName: "Broken Rule", Kind: "flat", Amount: 2500}, http.StatusInternalServerError},
+	}
+
+	for _, e := range ruleTests {
+		jsonData, _ := json.Marshal(e.body)
+
+		req, _ := http.NewRequest("POST", "/charge-rule", bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		res := httptest.NewRecorder()
+
+		handler := mdTest.ValidateReqBody(http.HandlerFunc(Repo.PostChargeRule), &dtos.ChargeRuleBody{})
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("PostChargeRule handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+}
+
+func TestRepository_GetChargeRules(t *testing.T){
+	req, _ := http.NewRequest("GET", "/charge-rule", nil)
+	res := httptest.NewRecorder()
+
+	handler := http.HandlerFunc(Repo.GetChargeRules)
+	handler.ServeHTTP(res, req)
+
+	if res.Code != http.StatusOK {
+		t.Errorf("GetChargeRules handler returned wrong response code: got %d, wanted %d", res.Code, http.StatusOK)
+	}
+}
+
+func TestRepository_DeleteChargeRule(t *testing.T){
+	var deleteTests = []struct {
+		name string
+		url string
+		expectedStatusCode int
+	}{
+		{"success", "/charge-rule/1", http.StatusOK},
+		{"charge rule not found", "/charge-rule/404", http.StatusNotFound},
+		{"invalid id", "/charge-rule/invalid", http.StatusBadRequest},
+	}
+
+	for _, e := range deleteTests {
+		req, _ := http.NewRequest("DELETE", e.url, nil)
+		res := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(Repo.DeleteChargeRule)
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("DeleteChargeRule handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+}
@@ -0,0 +1,377 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/csvcodec"
+	"github.com/Orololuwa/go-backend-boilerplate/src/dtos"
+	"github.com/Orololuwa/go-backend-boilerplate/src/helpers"
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+	"github.com/Orololuwa/go-backend-boilerplate/src/pricing"
+	"github.com/Orololuwa/go-backend-boilerplate/src/promo"
+	"github.com/Orololuwa/go-backend-boilerplate/src/stayrules"
+	"github.com/Orololuwa/go-backend-boilerplate/src/types"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	// the largest file an import reads
+	maxImportSize = 10 << 20
+	// how many rows an export writes before flushing them to the client
+	exportFlushRows = 100
+)
+
+var (
+	errImportRejected = errors.New("the file has errors, nothing was imported")
+	errDryRun = errors.New("dry run, nothing was imported")
+	errHoldImport = errors.New("holds cannot be imported")
+	errImportDate = errors.New("startDate and endDate must be dates like 2050-01-31")
+)
+
+// csvExport streams a CSV download. The response starts with the first row, so an error before it
+// is still sent to the client as JSON
+type csvExport struct {
+	w http.ResponseWriter
+	filename string
+	header []string
+	out *csv.Writer
+	rows int
+}
+
+func newCSVExport(w http.ResponseWriter, filename string, header []string) *csvExport {
+	return &csvExport{w: w, filename: filename, header: header}
+}
+
+func (e *csvExport) start() error {
+	e.w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	e.w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", e.filename))
+	e.w.WriteHeader(http.StatusOK)
+
+	e.out = csv.NewWriter(e.w)
+	return e.out.Write(e.header)
+}
+
+func (e *csvExport) flush() error {
+	e.out.Flush()
+	if f, ok := e.w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return e.out.Error()
+}
+
+// Write adds a row, sending the rows written so far every exportFlushRows rows
+func (e *csvExport) Write(record []string) error {
+	if e.out == nil {
+		err := e.start()
+		if err != nil {
+			return err
+		}
+	}
+
+	err := e.out.Write(record)
+	if err != nil {
+		return err
+	}
+
+	e.rows++
+	if e.rows % exportFlushRows == 0 {
+		return e.flush()
+	}
+	return nil
+}
+
+// closeCSVExport ends the download. Once rows were sent an error can only cut the file short, so it is logged
+func (m *Repository) closeCSVExport(e *csvExport, err error) {
+	if err != nil && e.out == nil {
+		helpers.ClientError(e.w, err, http.StatusInternalServerError, "")
+		return
+	}
+	if err != nil {
+		m.App.ErrorLog.Println(err)
+		return
+	}
+
+	if e.out == nil {
+		err = e.start()
+	}
+	if err == nil {
+		err = e.flush()
+	}
+	if err != nil {
+		m.App.ErrorLog.Println(err)
+	}
+}
+
+// ExportRooms streams the rooms matching the filters of the room list as CSV
+func (m *Repository) ExportRooms(w http.ResponseWriter, r *http.Request) {
+	id, room_name, err := roomFilter(r)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "")
+		return
+	}
+
+	export := newCSVExport(w, "rooms.csv", csvcodec.Header(models.Room{}))
+
+	err = m.DB.EachRoom(r.Context(), nil, id, room_name, func(room models.Room) error {
+		return export.Write(csvcodec.Record(room))
+	})
+
+	m.closeCSVExport(export, err)
+}
+
+// ExportReservations streams the reservations matching the filters of the reservation list as CSV
+func (m *Repository) ExportReservations(w http.ResponseWriter, r *http.Request) {
+	filter, err := reservationFilter(r)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "")
+		return
+	}
+
+	export := newCSVExport(w, "reservations.csv", csvcodec.Header(types.ReservationResponse{}))
+
+	err = m.DB.EachReservation(r.Context(), nil, filter, func(res models.Reservation) error {
+		return export.Write(csvcodec.Record(reservationResponse(res)))
+	})
+
+	m.closeCSVExport(export, err)
+}
+
+// importFile returns the uploaded CSV, sent as the request body or as the file field of a form
+func importFile(w http.ResponseWriter, r *http.Request) (io.Reader, func(), bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		part, _, err := r.FormFile("file")
+		if err != nil {
+			helpers.ClientError(w, err, http.StatusBadRequest, "missing file")
+			return nil, nil, false
+		}
+		return part, func() { part.Close() }, true
+	}
+
+	return r.Body, func() {}, true
+}
+
+// validationMessages describes the failed validation rules of the row body points to, naming the columns
+func validationMessages(body interface{}, err error) []string {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return []string{err.Error()}
+	}
+
+	t := reflect.TypeOf(body).Elem()
+	messages := make([]string, 0, len(validationErrs))
+	for _, fe := range validationErrs {
+		name := fe.Field()
+		if f, ok := t.FieldByName(fe.StructField()); ok {
+			if tag, _, _ := strings.Cut(f.Tag.Get("json"), ","); tag != "" {
+				name = tag
+			}
+		}
+		messages = append(messages, fmt.Sprintf("%s: failed the %s rule", name, fe.Tag()))
+	}
+	return messages
+}
+
+// rowError describes a booking error about the row itself. It returns false when the import failed instead
+func rowError(err error) (string, bool) {
+	var violationErr *stayrules.ViolationError
+	var promoErr *promo.Error
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return "room not found", true
+	case errors.As(err, &violationErr),
+		errors.As(err, &promoErr),
+		errors.Is(err, errRoomUnavailable),
+		errors.Is(err, errOverOccupancy),
+		errors.Is(err, errPriceChanged),
+		errors.Is(err, errHoldImport),
+		errors.Is(err, errImportDate),
+		errors.Is(err, pricing.ErrInvalidStay):
+		return err.Error(), true
+	}
+	return "", false
+}
+
+// importRows reads every row of the file into a fresh copy of the struct body points to, checks it against the DTO
+// tags and saves it with save, all in one transaction. A row that does not fit its columns, fails validation or is
+// refused by save is reported with its line and the import goes on with the next row, so every error in the file is
+// found at once. Unless every row is fine and the caller asked to commit, the transaction is rolled back: a dry run
+// goes through the same checks as the real import, rows conflicting with earlier rows of the same file included
+func (m *Repository) importRows(w http.ResponseWriter, r *http.Request, body interface{}, ignore []string, save func(context.Context, *sql.Tx, interface{}) error) {
+	file, closeFile, ok := importFile(w, r)
+	if !ok {
+		return
+	}
+	defer closeFile()
+
+	decoder, err := csvcodec.NewDecoder(file, body, ignore...)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "")
+		return
+	}
+
+	result := types.ImportResult{
+		DryRun: r.URL.Query().Get("commit") != "true",
+		Errors: make([]types.ImportRowError, 0),
+	}
+
+	err = m.DB.Transaction(withActor(r), func(ctx context.Context, tx *sql.Tx) error {
+		for {
+			row := reflect.New(reflect.TypeOf(body).Elem()).Interface()
+
+			err := decoder.Decode(row)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			var rowErr *csvcodec.RowError
+			if err != nil && !errors.As(err, &rowErr) {
+				return err
+			}
+
+			result.Rows++
+			line := decoder.Line()
+
+			if err != nil {
+				result.Errors = append(result.Errors, types.ImportRowError{Line: line, Errors: []string{err.Error()}})
+				continue
+			}
+
+			err = m.App.Validate.Struct(row)
+			if err != nil {
+				result.Errors = append(result.Errors, types.ImportRowError{Line: line, Errors: validationMessages(row, err)})
+				continue
+			}
+
+			err = save(ctx, tx, row)
+			if message, ok := rowError(err); ok {
+				result.Errors = append(result.Errors, types.ImportRowError{Line: line, Errors: []string{message}})
+				continue
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(result.Errors) > 0 {
+			return errImportRejected
+		}
+		if result.DryRun {
+			return errDryRun
+		}
+
+		result.Imported = result.Rows
+		return nil
+	})
+
+	var parseErr *csv.ParseError
+	var maxBytesErr *http.MaxBytesError
+
+	switch {
+	case errors.Is(err, errImportRejected):
+		helpers.ClientResponseWriter(w, result, http.StatusUnprocessableEntity, err.Error())
+	case errors.Is(err, errDryRun):
+		helpers.ClientResponseWriter(w, result, http.StatusOK, "the file can be imported, add commit=true to save it")
+	case errors.As(err, &parseErr), errors.As(err, &maxBytesErr):
+		helpers.ClientError(w, err, http.StatusBadRequest, "")
+	case err != nil:
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+	default:
+		helpers.ClientResponseWriter(w, result, http.StatusCreated, fmt.Sprintf("%d rows imported", result.Imported))
+	}
+}
+
+// ImportRooms creates a room for every row of the CSV file. The id and timestamp columns of an export are ignored
+func (m *Repository) ImportRooms(w http.ResponseWriter, r *http.Request) {
+	m.importRows(w, r, &dtos.RoomBody{}, []string{"id", "createdAt", "updatedAt"}, func(ctx context.Context, tx *sql.Tx, row interface{}) error {
+		body := row.(*dtos.RoomBody)
+
+		currency := body.Currency
+		if currency == "" {
+			currency = m.currency()
+		}
+
+		_, err := m.DB.InsertRoom(ctx, tx, models.Room{
+			RoomName: body.RoomName,
+			BaseRate: body.BaseRate,
+			WeekendRate: body.WeekendRate,
+			MaxOccupancy: body.MaxOccupancy,
+			Currency: currency,
+		})
+		return err
+	})
+}
+
+// ImportReservations books a reservation for every row of the CSV file, with the same checks as a booking made
+// through the API. The columns of an export that are set when booking are ignored
+func (m *Repository) ImportReservations(w http.ResponseWriter, r *http.Request) {
+	ignore := []string{"id", "confirmationCode", "roomName", "totalPrice", "baseCurrency", "exchangeRate", "guestId", "requestsResolved", "status", "cancellationFee", "refundAmount", "discount"}
+
+	m.importRows(w, r, &dtos.ReservationBody{}, ignore, func(ctx context.Context, tx *sql.Tx, row interface{}) error {
+		body := row.(*dtos.ReservationBody)
+
+		if body.HoldToken != "" {
+			return errHoldImport
+		}
+
+		layout := "2006-01-02"
+
+		startDate, err := time.Parse(layout, body.StartDate)
+		if err != nil {
+			return errImportDate
+		}
+
+		endDate, err := time.Parse(layout, body.EndDate)
+		if err != nil {
+			return errImportDate
+		}
+
+		if !endDate.After(startDate) {
+			return pricing.ErrInvalidStay
+		}
+
+		// bookings made before guests were counted are for a single adult
+		adults := body.Adults
+		if adults == 0 {
+			adults = 1
+		}
+
+		res := models.Reservation{
+			FirstName: body.FirstName,
+			LastName: body.LastName,
+			Email: body.Email,
+			Phone: body.Phone,
+			StartDate: startDate,
+			EndDate: endDate,
+			RoomID: body.RoomId,
+			Adults: adults,
+			Children: body.Children,
+			Status: models.ReservationStatusPending,
+			PromoCode: body.PromoCode,
+			Currency: body.Currency,
+			SpecialRequests: strings.TrimSpace(body.SpecialRequests),
+		}
+
+		err = m.bookGroupRoom(ctx, tx, &res)
+		if err != nil {
+			return err
+		}
+
+		if body.QuotedTotal != 0 && body.QuotedTotal != res.TotalPrice {
+			return errPriceChanged
+		}
+
+		return nil
+	})
+}
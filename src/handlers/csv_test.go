@@ -0,0 +1,292 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/types"
+)
+
+func TestRepository_GetReservations(t *testing.T){
+	var listTests = []struct {
+		name string
+		url string
+		expectedStatusCode int
+		expectedIds []int
+	}{
+		{"all reservations", "/reservation", http.StatusOK, []int{8, 7}},
+		{"by status", "/reservation?status=confirmed", http.StatusOK, []int{7}},
+		{"by room", "/reservation?room_id=16&start=2050-01-01&end=2050-02-01", http.StatusOK, []int{8}},
+		{"by guest", "/reservation?guest_id=1", http.StatusOK, []int{8, 7}},
+		{"upcoming arrivals with unresolved requests", "/reservation?arriving_from=2050-01-03&unresolved_requests=true", http.StatusOK, []int{8}},
+		{"arriving from", "/reservation?arriving_from=2050-01-03", http.StatusOK, []int{8}},
+		{"invalid unresolved requests", "/reservation?unresolved_requests=maybe", http.StatusBadRequest, nil},
+		{"invalid room", "/reservation?room_id=abc", http.StatusBadRequest, nil},
+		{"invalid date", "/reservation?start=invalid", http.StatusBadRequest, nil},
+		{"query fails", "/reservation?room_id=1000", http.StatusInternalServerError, nil},
+	}
+
+	for _, e := range listTests {
+		req, _ := http.NewRequest("GET", e.url, nil)
+		res := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(Repo.GetReservations)
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("GetReservations handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+			continue
+		}
+		if e.expectedIds == nil {
+			continue
+		}
+
+		var body struct {
+			Data []types.ReservationResponse `json:"data"`
+		}
+		json.Unmarshal(res.Body.Bytes(), &body)
+
+		ids := make([]int, 0)
+		for _, r := range body.Data {
+			ids = append(ids, r.ID)
+		}
+		if !reflect.DeepEqual(ids, e.expectedIds) {
+			t.Errorf("GetReservations returned reservations %v for %s, wanted %v", ids, e.name, e.expectedIds)
+		}
+	}
+}
+
+func TestRepository_ExportRooms(t *testing.T){
+	var exportTests = []struct {
+		name string
+		url string
+		expectedStatusCode int
+		expectedBody string
+	}{
+		{"all rooms", "/admin/export/rooms", http.StatusOK, "id,roomName,baseRate,weekendRate,currency,maxOccupancy,createdAt,updatedAt\n" +
+			"15,Test Room,10000,12000,USD,4,,\n" +
+			"16,Other Room,12000,0,USD,2,,\n"},
+		{"by id", "/admin/export/rooms?id=16", http.StatusOK, "id,roomName,baseRate,weekendRate,currency,maxOccupancy,createdAt,updatedAt\n" +
+			"16,Other Room,12000,0,USD,2,,\n"},
+		{"no rooms", "/admin/export/rooms?room_name=Missing", http.StatusOK, "id,roomName,baseRate,weekendRate,currency,maxOccupancy,createdAt,updatedAt\n"},
+		{"invalid id", "/admin/export/rooms?id=abc", http.StatusBadRequest, ""},
+		{"query fails", "/admin/export/rooms?id=2", http.StatusInternalServerError, ""},
+	}
+
+	for _, e := range exportTests {
+		req, _ := http.NewRequest("GET", e.url, nil)
+		res := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(Repo.ExportRooms)
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("ExportRooms handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+			continue
+		}
+		if e.expectedBody == "" {
+			continue
+		}
+
+		if !strings.HasPrefix(res.Header().Get("Content-Type"), "text/csv") {
+			t.Errorf("ExportRooms returned content type %q for %s, wanted text/csv", res.Header().Get("Content-Type"), e.name)
+		}
+		if res.Body.String() != e.expectedBody {
+			t.Errorf("ExportRooms wrote %q for %s, wanted %q", res.Body.String(), e.name, e.expectedBody)
+		}
+	}
+}
+
+func TestRepository_ExportReservations(t *testing.T){
+	req, _ := http.NewRequest("GET", "/admin/export/reservations?status=confirmed", nil)
+	res := httptest.NewRecorder()
+
+	handler := http.HandlerFunc(Repo.ExportReservations)
+	handler.ServeHTTP(res, req)
+
+	expected := "id,confirmationCode,firstName,lastName,email,phone,startDate,endDate,roomId,roomName,adults,children,totalPrice,currency,baseCurrency,exchangeRate,guestId,specialRequests,requestsResolved,status,cancellationFee,refundAmount,promoCode,discount\n" +
+		"7,ABCD2345,John,Doe,johndoe@gmail.com,,2050-01-02,2050-01-04,15,Test Room,1,0,20000,USD,USD,1,1,,false,confirmed,0,0,,0\n"
+
+	if res.Code != http.StatusOK || res.Body.String() != expected {
+		t.Errorf("ExportReservations returned %d with %q, wanted %q", res.Code, res.Body.String(), expected)
+	}
+	if !strings.Contains(res.Header().Get("Content-Disposition"), "reservations.csv") {
+		t.Errorf("ExportReservations returned content disposition %q", res.Header().Get("Content-Disposition"))
+	}
+
+	var exportTests = []struct {
+		name string
+		url string
+		expectedStatusCode int
+	}{
+		{"invalid date", "/admin/export/reservations?end=invalid", http.StatusBadRequest},
+		{"query fails", "/admin/export/reservations?room_id=1000", http.StatusInternalServerError},
+	}
+
+	for _, e := range exportTests {
+		req, _ := http.NewRequest("GET", e.url, nil)
+		res := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(Repo.ExportReservations)
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("ExportReservations handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+}
+
+func importCSV(handler http.HandlerFunc, url, file string) (int, types.ImportResult) {
+	req, _ := http.NewRequest("POST", url, strings.NewReader(file))
+	req.Header.Set("Content-Type", "text/csv")
+	res := httptest.NewRecorder()
+
+	handler.ServeHTTP(res, req)
+
+	var body struct {
+		Data types.ImportResult `json:"data"`
+	}
+	json.Unmarshal(res.Body.Bytes(), &body)
+
+	return res.Code, body.Data
+}
+
+func TestRepository_ImportRooms(t *testing.T){
+	file := "roomName,baseRate,weekendRate,maxOccupancy\n" +
+		"Garden Suite,15000,18000,3\n" +
+		"Attic,9000,,2\n"
+
+	code, result := importCSV(Repo.ImportRooms, "/admin/import/rooms", file)
+	if code != http.StatusOK || !result.DryRun || result.Rows != 2 || result.Imported != 0 {
+		t.Errorf("ImportRooms returned %d with %+v for a dry run, wanted 2 rows checked and none imported", code, result)
+	}
+
+	code, result = importCSV(Repo.ImportRooms, "/admin/import/rooms?commit=true", file)
+	if code != http.StatusCreated || result.DryRun || result.Imported != 2 {
+		t.Errorf("ImportRooms returned %d with %+v, wanted 2 rows imported", code, result)
+	}
+
+	// an export can be imported again
+	req, _ := http.NewRequest("GET", "/admin/export/rooms", nil)
+	res := httptest.NewRecorder()
+	http.HandlerFunc(Repo.ExportRooms).ServeHTTP(res, req)
+
+	code, result = importCSV(Repo.ImportRooms, "/admin/import/rooms", res.Body.String())
+	if code != http.StatusOK || result.Rows != 2 {
+		t.Errorf("ImportRooms returned %d with %+v for an exported file, wanted 2 rows checked", code, result)
+	}
+
+	file = "roomName,baseRate,weekendRate,maxOccupancy\n" +
+		"Garden Suite,15000,18000,3\n" +
+		",-1,,2\n" +
+		"Attic,cheap,,2\n" +
+		"Cellar,9000\n"
+
+	code, result = importCSV(Repo.ImportRooms, "/admin/import/rooms?commit=true", file)
+	if code != http.StatusUnprocessableEntity || result.Imported != 0 {
+		t.Fatalf("ImportRooms returned %d with %+v for a file with errors, wanted nothing imported", code, result)
+	}
+
+	expected := []types.ImportRowError{
+		{Line: 3, Errors: []string{"roomName: failed the required rule", "baseRate: failed the gte rule"}},
+		{Line: 4, Errors: []string{`baseRate: "cheap" is not a whole number`}},
+		{Line: 5, Errors: []string{"expected 4 columns, got 2"}},
+	}
+	if !reflect.DeepEqual(result.Errors, expected) {
+		t.Errorf("ImportRooms returned errors %+v, wanted %+v", result.Errors, expected)
+	}
+
+	var importTests = []struct {
+		name string
+		url string
+		file string
+		expectedStatusCode int
+	}{
+		{"empty file", "/admin/import/rooms", "", http.StatusBadRequest},
+		{"unknown column", "/admin/import/rooms", "roomName,floor\nAttic,3\n", http.StatusBadRequest},
+		{"broken quotes", "/admin/import/rooms", "roomName\n\"Attic\n", http.StatusBadRequest},
+		{"insert fails", "/admin/import/rooms", "roomName\nBroken Room\n", http.StatusInternalServerError},
+	}
+
+	for _, e := range importTests {
+		code, _ := importCSV(Repo.ImportRooms, e.url, e.file)
+		if code != e.expectedStatusCode {
+			t.Errorf("ImportRooms handler returned wrong response code for %s: got %d, wanted %d", e.name, code, e.expectedStatusCode)
+		}
+	}
+}
+
+func TestRepository_ImportRoomsUpload(t *testing.T){
+	var form bytes.Buffer
+	writer := multipart.NewWriter(&form)
+	part, _ := writer.CreateFormFile("file", "rooms.csv")
+	part.Write([]byte("roomName,baseRate\nAttic,9000\n"))
+	writer.Close()
+
+	req, _ := http.NewRequest("POST", "/admin/import/rooms?commit=true", &form)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	res := httptest.NewRecorder()
+
+	handler := http.HandlerFunc(Repo.ImportRooms)
+	handler.ServeHTTP(res, req)
+
+	if res.Code != http.StatusCreated {
+		t.Errorf("ImportRooms handler returned wrong response code for an uploaded file: got %d, wanted %d", res.Code, http.StatusCreated)
+	}
+}
+
+func TestRepository_ImportReservations(t *testing.T){
+	header := "firstName,lastName,email,phone,startDate,endDate,roomId,adults,children\n"
+
+	file := header +
+		"John,Doe,john@doe.com,08012345678,2050-01-01,2050-01-05,15,2,0\n" +
+		"Jane,Doe,jane@doe.com,08012345678,2050-01-01,2050-01-05,16,1,1\n"
+
+	code, result := importCSV(Repo.ImportReservations, "/admin/import/reservations", file)
+	if code != http.StatusOK || result.Rows != 2 || len(result.Errors) != 0 {
+		t.Errorf("ImportReservations returned %d with %+v for a dry run, wanted 2 rows checked", code, result)
+	}
+
+	code, result = importCSV(Repo.ImportReservations, "/admin/import/reservations?commit=true", file)
+	if code != http.StatusCreated || result.Imported != 2 {
+		t.Errorf("ImportReservations returned %d with %+v, wanted 2 rows imported", code, result)
+	}
+
+	// room 3 is booked and room 404 does not exist
+	file = header +
+		"John,Doe,john@doe.com,08012345678,2050-01-01,2050-01-05,15,2,0\n" +
+		"Jane,Doe,not an email,08012345678,2050-01-01,2050-01-05,15,1,0\n" +
+		"Jim,Doe,jim@doe.com,08012345678,2050-01-01,2050-01-05,3,1,0\n" +
+		"Jo,Doe,jo@doe.com,08012345678,2050-01-01,2050-01-05,404,1,0\n" +
+		"Jo,Doe,jo@doe.com,08012345678,2050-01-05,2050-01-01,15,1,0\n" +
+		"Jo,Doe,jo@doe.com,08012345678,05/01/2050,2050-01-09,15,1,0\n" +
+		"Jo,Doe,jo@doe.com,08012345678,2050-01-01,2050-01-05,15,4,2\n"
+
+	code, result = importCSV(Repo.ImportReservations, "/admin/import/reservations?commit=true", file)
+	if code != http.StatusUnprocessableEntity || result.Rows != 7 {
+		t.Fatalf("ImportReservations returned %d with %+v for a file with errors, wanted 7 rows checked", code, result)
+	}
+
+	expected := []types.ImportRowError{
+		{Line: 3, Errors: []string{"email: failed the email rule"}},
+		{Line: 4, Errors: []string{errRoomUnavailable.Error()}},
+		{Line: 5, Errors: []string{"room not found"}},
+		{Line: 6, Errors: []string{"endDate must be after startDate"}},
+		{Line: 7, Errors: []string{errImportDate.Error()}},
+		{Line: 8, Errors: []string{"the party is too large for the room: room 15 sleeps at most 4 guests"}},
+	}
+	if !reflect.DeepEqual(result.Errors, expected) {
+		t.Errorf("ImportReservations returned errors %+v, wanted %+v", result.Errors, expected)
+	}
+
+	code, _ = importCSV(Repo.ImportReservations, "/admin/import/reservations", header + "John,Doe,john@doe.com,08012345678,2050-01-01,2050-01-05,2,1,0\n")
+	if code != http.StatusInternalServerError {
+		t.Errorf("ImportReservations handler returned wrong response code when the search fails: got %d, wanted %d", code, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/dtos"
+	"github.com/Orololuwa/go-backend-boilerplate/src/helpers"
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+	"github.com/Orololuwa/go-backend-boilerplate/src/money"
+	"github.com/Orololuwa/go-backend-boilerplate/src/pricing"
+)
+
+// defaultCurrency is the base currency of rooms created without one
+const defaultCurrency = "USD"
+
+var errNoExchangeRate = errors.New("no exchange rate is set")
+
+func (m *Repository) currency() string {
+	if m.App.Currency != "" {
+		return m.App.Currency
+	}
+
+	return defaultCurrency
+}
+
+// exchange converts the prices of a room, in its base currency From, into the currency To the guest is charged in.
+// Stays, discounts and fees are priced in the base currency and converted night by night and rule by rule, so a
+// converted quote still adds up
+type exchange struct {
+	From string
+	To string
+	Rate money.Rate
+}
+
+// reservationExchange is the exchange a reservation was booked at
+func reservationExchange(res models.Reservation) exchange {
+	if res.ExchangeRate == 0 {
+		return exchange{From: res.Currency, To: res.Currency, Rate: money.RateScale}
+	}
+	return exchange{From: res.BaseCurrency, To: res.Currency, Rate: res.ExchangeRate}
+}
+
+// exchange looks up the rate from the base currency of a room into the currency asked for, which defaults to the
+// base currency
+func (m *Repository) exchange(ctx context.Context, tx *sql.Tx, base, currency string) (exchange, error) {
+	if currency == "" || currency == base {
+		return exchange{From: base, To: base, Rate: money.RateScale}, nil
+	}
+
+	rate, err := m.DB.GetExchangeRate(ctx, tx, base, currency)
+	if errors.Is(err, sql.ErrNoRows) {
+		return exchange{}, fmt.Errorf("%w from %s to %s", errNoExchangeRate, base, currency)
+	}
+	if err != nil {
+		return exchange{}, err
+	}
+
+	return exchange{From: base, To: currency, Rate: rate.Rate}, nil
+}
+
+func (m *Repository) roomExchange(ctx context.Context, tx *sql.Tx, roomId int, currency string) (exchange, error) {
+	room, err := m.DB.GetRoomById(ctx, tx, roomId)
+	if err != nil {
+		return exchange{}, err
+	}
+
+	return m.exchange(ctx, tx, room.Currency, currency)
+}
+
+func (x exchange) amount(a money.Amount) money.Amount {
+	return money.Convert(a, x.From, x.To, x.Rate)
+}
+
+// stamp records the currency the reservation is charged in and the rate it was converted at
+func (x exchange) stamp(res *models.Reservation) {
+	res.Currency = x.To
+	res.BaseCurrency = x.From
+	res.ExchangeRate = x.Rate
+}
+
+func (x exchange) quote(q pricing.Quote) pricing.Quote {
+	nights := make([]pricing.Night, len(q.Nights))
+	q.Total = 0
+	for i, night := range q.Nights {
+		night.Amount = x.amount(night.Amount)
+		nights[i] = night
+		q.Total += night.Amount
+	}
+	q.Nights = nights
+	return q
+}
+
+// promoCode converts the amount of a fixed discount, a percent discount stays the same
+func (x exchange) promoCode(p models.PromoCode) models.PromoCode {
+	if p.DiscountType == models.DiscountFixed {
+		p.DiscountValue = int64(x.amount(money.Amount(p.DiscountValue)))
+	}
+	return p
+}
+
+// chargeRules converts the amounts of the flat and per person fees, percent charges stay the same
+func (x exchange) chargeRules(rules []models.ChargeRule) []models.ChargeRule {
+	converted := make([]models.ChargeRule, len(rules))
+	for i, rule := range rules {
+		if rule.Kind != models.ChargePercent {
+			rule.Amount = int64(x.amount(money.Amount(rule.Amount)))
+		}
+		converted[i] = rule
+	}
+	return converted
+}
+
+func (m *Repository) GetExchangeRates(w http.ResponseWriter, r *http.Request) {
+	rates, err := m.DB.GetExchangeRates(context.Background(), nil)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, rates, http.StatusOK, "exchange rates retrieved successfully")
+}
+
+// PutExchangeRate sets the rate of a currency pair for the quotes and bookings made from now on. Reservations keep
+// the rate they were booked at
+func (m *Repository) PutExchangeRate(w http.ResponseWriter, r *http.Request) {
+	var body dtos.ExchangeRateBody
+	requestBody, ok := r.Context().Value("validatedRequestBody").(*dtos.ExchangeRateBody)
+	if !ok || requestBody == nil {
+		helpers.ClientError(w, errors.New("failed to retrieve request body"), http.StatusBadRequest, "")
+		return
+	}
+	body = *requestBody
+
+	rate := models.ExchangeRate{
+		BaseCurrency: body.BaseCurrency,
+		QuoteCurrency: body.QuoteCurrency,
+		Rate: body.Rate,
+	}
+
+	var err error
+	rate.ID, err = m.DB.UpsertExchangeRate(context.Background(), nil, rate)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, rate, http.StatusOK, "exchange rate set successfully")
+}
+
+func (m *Repository) DeleteExchangeRate(w http.ResponseWriter, r *http.Request) {
+	id, err := m.urlParamInt(r, "id", 2)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	err = m.DB.DeleteExchangeRate(context.Background(), nil, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		helpers.ClientError(w, err, http.StatusNotFound, "exchange rate not found")
+		return
+	}
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, nil, http.StatusOK, "exchange rate deleted successfully")
+}
@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/dtos"
+	"github.com/Orololuwa/go-backend-boilerplate/src/money"
+	"github.com/Orololuwa/go-backend-boilerplate/src/types"
+)
+
+func TestRepository_PostQuoteCurrency(t *testing.T){
+	var quoteTests = []struct {
+		name string
+		currency string
+		expectedStatusCode int
+		expectedCurrency string
+		expectedRate money.Rate
+		expectedSubtotal money.Amount
+		expectedTotal money.Amount
+	}{
+		{"base currency", "", http.StatusOK, "USD", money.RateScale, 34000, 40138},
+		{"same as the base currency", "USD", http.StatusOK, "USD", money.RateScale, 34000, 40138},
+		// nights of 9200, 11040 and 11040, 828 city tax, 2300 cleaning and 7.5% VAT on 33580
+		{"converted", "EUR", http.StatusOK, "EUR", 920000, 31280, 36927},
+		{"no exchange rate", "GBP", http.StatusBadRequest, "", 0, 0, 0},
+		{"failed rate lookup", "XXX", http.StatusInternalServerError, "", 0, 0, 0},
+	}
+
+	for _, e := range quoteTests {
+		body := dtos.QuoteBody{
+			PostAvailabilityBody: dtos.PostAvailabilityBody{StartDate: "2050-01-06", EndDate: "2050-01-09", Adults: 2},
+			RoomId: 20,
+			Currency: e.currency,
+		}
+		jsonData, _ := json.Marshal(body)
+
+		req, _ := http.NewRequest("POST", "/quote", bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		res := httptest.NewRecorder()
+
+		handler := mdTest.ValidateReqBody(http.HandlerFunc(Repo.PostQuote), &dtos.QuoteBody{})
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("PostQuote handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+			continue
+		}
+		if res.Code != http.StatusOK {
+			continue
+		}
+
+		var resp struct {
+			Data []types.RoomQuoteResponse `json:"data"`
+		}
+		err := json.Unmarshal(res.Body.Bytes(), &resp)
+		if err != nil || len(resp.Data) != 1 {
+			t.Errorf("PostQuote handler returned %s for %s", res.Body.String(), e.name)
+			continue
+		}
+		quote := resp.Data[0]
+
+		if quote.Currency != e.expectedCurrency || quote.BaseCurrency != "USD" || quote.ExchangeRate != e.expectedRate {
+			t.Errorf("PostQuote handler quoted %s from %s at %s for %s, wanted %s from USD at %s", quote.Currency, quote.BaseCurrency, quote.ExchangeRate, e.name, e.expectedCurrency, e.expectedRate)
+		}
+		if quote.Subtotal != e.expectedSubtotal || quote.Total != e.expectedTotal {
+			t.Errorf("PostQuote handler returned a subtotal of %d and a total of %d for %s, wanted %d and %d", quote.Subtotal, quote.Total, e.name, e.expectedSubtotal, e.expectedTotal)
+		}
+	}
+}
+
+func TestRepository_PostReservationCurrency(t *testing.T){
+	var reservationTests = []struct {
+		name string
+		roomId int
+		currency string
+		quotedTotal money.Amount
+		expectedStatusCode int
+		expectedCurrency string
+		expectedRate money.Rate
+		expectedTotal money.Amount
+	}{
+		{"base currency", 15, "", 0, http.StatusCreated, "USD", money.RateScale, 34000},
+		{"taxed room in euros", 20, "EUR", 0, http.StatusCreated, "EUR", 920000, 36927},
+		{"matching quote in euros", 20, "EUR", 36927, http.StatusCreated, "EUR", 920000, 36927},
+		{"quote in the base currency", 20, "EUR", 40138, http.StatusConflict, "", 0, 0},
+		// nights of 15125, 18150 and 18150 yen, which has no minor unit
+		{"yen", 15, "JPY", 0, http.StatusCreated, "JPY", 151250000, 51425},
+		{"no exchange rate", 15, "GBP", 0, http.StatusBadRequest, "", 0, 0},
+		{"invalid currency", 15, "EURO", 0, http.StatusBadRequest, "", 0, 0},
+	}
+
+	for _, e := range reservationTests {
+		body := dtos.ReservationBody{
+			FirstName: "John",
+			LastName: "Doe",
+			Email: "johndoe@gmail.com",
+			Phone: "08012345678",
+			StartDate: "2050-01-06",
+			EndDate: "2050-01-09",
+			RoomId: e.roomId,
+			Adults: 2,
+			QuotedTotal: e.quotedTotal,
+			Currency: e.currency,
+		}
+		jsonBody, _ := json.Marshal(body)
+
+		req, _ := http.NewRequest("POST", "/reservation", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(Repo.PostReservation)
+		handler.ServeHTTP(rr, req)
+
+		if rr.Code != e.expectedStatusCode {
+			t.Errorf("PostReservation handler returned wrong response code for %s: got %d, wanted %d", e.name, rr.Code, e.expectedStatusCode)
+			continue
+		}
+		if rr.Code != http.StatusCreated {
+			continue
+		}
+
+		var resp struct {
+			Data types.ReservationResponse `json:"data"`
+		}
+		_ = json.Unmarshal(rr.Body.Bytes(), &resp)
+
+		if resp.Data.Currency != e.expectedCurrency || resp.Data.BaseCurrency != "USD" || resp.Data.ExchangeRate != e.expectedRate {
+			t.Errorf("PostReservation handler charged %s from %s at %s for %s, wanted %s from USD at %s", resp.Data.Currency, resp.Data.BaseCurrency, resp.Data.ExchangeRate, e.name, e.expectedCurrency, e.expectedRate)
+		}
+		if resp.Data.TotalPrice != e.expectedTotal {
+			t.Errorf("PostReservation handler returned a total of %d for %s, wanted %d", resp.Data.TotalPrice, e.name, e.expectedTotal)
+		}
+	}
+}
+
+func TestRepository_PutExchangeRate(t *testing.T){
+	var rateTests = []struct {
+		name string
+		body string
+		expectedStatusCode int
+	}{
+		{"decimal string", `{"baseCurrency":"USD","quoteCurrency":"EUR","rate":"0.92"}`, http.StatusOK},
+		{"number", `{"baseCurrency":"USD","quoteCurrency":"NGN","rate":1523.5}`, http.StatusOK},
+		{"missing rate", `{"baseCurrency":"USD","quoteCurrency":"EUR"}`, http.StatusBadRequest},
+		{"negative rate", `{"baseCurrency":"USD","quoteCurrency":"EUR","rate":"-0.92"}`, http.StatusBadRequest},
+		{"too many decimals", `{"baseCurrency":"USD","quoteCurrency":"EUR","rate":"0.9200001"}`, http.StatusBadRequest},
+		{"unknown currency", `{"baseCurrency":"USD","quoteCurrency":"EURO","rate":"0.92"}`, http.StatusBadRequest},
+		{"same currency", `{"baseCurrency":"USD","quoteCurrency":"USD","rate":"1"}`, http.StatusBadRequest},
+		{"failed upsert", `{"baseCurrency":"USD","quoteCurrency":"XXX","rate":"1"}`, http.StatusInternalServerError},
+	}
+
+	for _, e := range rateTests {
+		req, _ := http.NewRequest("PUT", "/exchange-rate", bytes.NewBufferString(e.body))
+		req.Header.Set("Content-Type", "application/json")
+		res := httptest.NewRecorder()
+
+		handler := mdTest.ValidateReqBody(http.HandlerFunc(Repo.PutExchangeRate), &dtos.ExchangeRateBody{})
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("PutExchangeRate handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+}
+
+func TestRepository_GetExchangeRates(t *testing.T){
+	req, _ := http.NewRequest("GET", "/exchange-rate", nil)
+	res := httptest.NewRecorder()
+
+	handler := http.HandlerFunc(Repo.GetExchangeRates)
+	handler.ServeHTTP(res, req)
+
+	if res.Code != http.StatusOK || !bytes.Contains(res.Body.Bytes(), []byte(`"rate":"0.92"`)) {
+		t.Errorf("GetExchangeRates handler returned %d with %s, wanted 200 with the rate as a decimal string", res.Code, res.Body.String())
+	}
+}
+
+func TestRepository_DeleteExchangeRate(t *testing.T){
+	var deleteTests = []struct {
+		name string
+		url string
+		expectedStatusCode int
+	}{
+		{"success", "/exchange-rate/1", http.StatusOK},
+		{"exchange rate not found", "/exchange-rate/404", http.StatusNotFound},
+		{"invalid id", "/exchange-rate/invalid", http.StatusBadRequest},
+	}
+
+	for _, e := range deleteTests {
+		req, _ := http.NewRequest("DELETE", e.url, nil)
+		res := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(Repo.DeleteExchangeRate)
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("DeleteExchangeRate handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+}
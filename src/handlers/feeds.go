@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/calendarsync"
+	"github.com/Orololuwa/go-backend-boilerplate/src/dtos"
+	"github.com/Orololuwa/go-backend-boilerplate/src/helpers"
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+)
+
+// roomCalendarFeed reads the room and feed ids from the URL and checks the feed belongs to the room
+func (m *Repository) roomCalendarFeed(w http.ResponseWriter, r *http.Request) (models.CalendarFeed, bool) {
+	roomId, err := m.urlParamInt(r, "id", 2)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return models.CalendarFeed{}, false
+	}
+
+	feedId, err := m.urlParamInt(r, "feedId", 4)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return models.CalendarFeed{}, false
+	}
+
+	feed, err := m.DB.GetCalendarFeedById(context.Background(), nil, feedId)
+	if err == nil && feed.RoomID != roomId {
+		err = sql.ErrNoRows
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		helpers.ClientError(w, err, http.StatusNotFound, "calendar feed not found")
+		return feed, false
+	}
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return feed, false
+	}
+
+	return feed, true
+}
+
+func syncError(w http.ResponseWriter, err error, fetched bool) {
+	switch {
+	case errors.Is(err, calendarsync.ErrNoURL), errors.Is(err, calendarsync.ErrInvalidCalendar):
+		helpers.ClientError(w, err, http.StatusBadRequest, "")
+	case errors.Is(err, sql.ErrNoRows):
+		helpers.ClientError(w, err, http.StatusNotFound, "room not found")
+	case fetched:
+		helpers.ClientError(w, err, http.StatusBadGateway, "failed to sync calendar feed")
+	default:
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+	}
+}
+
+// PostCalendarFeed registers an external calendar whose events block the room
+func (m *Repository) PostCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	roomId, err := m.urlParamInt(r, "id", 2)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	var body dtos.CalendarFeedBody
+	requestBody, ok := r.Context().Value("validatedRequestBody").(*dtos.CalendarFeedBody)
+	if !ok || requestBody == nil {
+		helpers.ClientError(w, errors.New("failed to retrieve request body"), http.StatusBadRequest, "")
+		return
+	}
+	body = *requestBody
+
+	ctx := context.Background()
+
+	_, err = m.DB.GetRoomById(ctx, nil, roomId)
+	if errors.Is(err, sql.ErrNoRows) {
+		helpers.ClientError(w, err, http.StatusNotFound, "room not found")
+		return
+	}
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	feed := models.CalendarFeed{
+		RoomID: roomId,
+		Name: body.Name,
+		URL: body.URL,
+		LastStatus: models.CalendarFeedStatusPending,
+	}
+
+	feed.ID, err = m.DB.InsertCalendarFeed(ctx, nil, feed)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, feed, http.StatusCreated, "calendar feed created successfully")
+}
+
+// GetCalendarFeeds lists the room's external calendars with the outcome of their last sync
+func (m *Repository) GetCalendarFeeds(w http.ResponseWriter, r *http.Request) {
+	roomId, err := m.urlParamInt(r, "id", 2)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	feeds, err := m.DB.GetCalendarFeedsByRoomId(context.Background(), nil, roomId)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, feeds, http.StatusOK, "calendar feeds retrieved successfully")
+}
+
+// DeleteCalendarFeed removes the feed and releases every date it blocked
+func (m *Repository) DeleteCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	roomId, err := m.urlParamInt(r, "id", 2)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	feedId, err := m.urlParamInt(r, "feedId", 4)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	err = m.DB.DeleteCalendarFeed(context.Background(), nil, roomId, feedId)
+	if errors.Is(err, sql.ErrNoRows) {
+		helpers.ClientError(w, err, http.StatusNotFound, "calendar feed not found")
+		return
+	}
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, nil, http.StatusOK, "calendar feed deleted successfully")
+}
+
+// SyncCalendarFeed fetches the feed's URL now instead of waiting for the periodic sync
+func (m *Repository) SyncCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	feed, ok := m.roomCalendarFeed(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := m.CalendarSync.SyncFeed(context.Background(), feed)
+	if err != nil {
+		syncError(w, err, true)
+		return
+	}
+
+	helpers.ClientResponseWriter(w, result, http.StatusOK, "calendar feed synced successfully")
+}
+
+// UploadCalendarFeed imports an .ics file into the feed, sent either as the raw body or as the "file" field of a form
+func (m *Repository) UploadCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	feed, ok := m.roomCalendarFeed(w, r)
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, calendarsync.MaxCalendarSize)
+
+	var file io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		part, _, err := r.FormFile("file")
+		if err != nil {
+			helpers.ClientError(w, err, http.StatusBadRequest, "missing file")
+			return
+		}
+		defer part.Close()
+		file = part
+	}
+
+	result, err := m.CalendarSync.Import(context.Background(), feed, file)
+	if err != nil {
+		syncError(w, err, false)
+		return
+	}
+
+	helpers.ClientResponseWriter(w, result, http.StatusOK, "calendar file imported successfully")
+}
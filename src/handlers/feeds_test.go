@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/dtos"
+)
+
+const uploadCalendar = "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VEVENT\r\nUID:keep@external\r\nDTSTART;VALUE=DATE:20500301\r\nDTEND;VALUE=DATE:20500303\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+
+func TestRepository_PostCalendarFeed(t *testing.T){
+	var feedTests = []struct {
+		name string
+		url string
+		body dtos.CalendarFeedBody
+		expectedStatusCode int
+	}{
+		{"remote feed", "/room/15/calendar-feed", dtos.CalendarFeedBody{Name: "Other platform", URL: "https://example.com/room.ics"}, http.StatusCreated},
+		{"upload only feed", "/room/15/calendar-feed", dtos.CalendarFeedBody{Name: "Offline"}, http.StatusCreated},
+		{"invalid url", "/room/15/calendar-feed", dtos.CalendarFeedBody{Name: "Other platform", URL: "not a url"}, http.StatusBadRequest},
+		{"room not found", "/room/404/calendar-feed", dtos.CalendarFeedBody{Name: "Other platform"}, http.StatusNotFound},
+	}
+
+	for _, e := range feedTests {
+		jsonData, _ := json.Marshal(e.body)
+
+		req, _ := http.NewRequest("POST", e.url, bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		res := httptest.NewRecorder()
+
+		handler := mdTest.ValidateReqBody(http.HandlerFunc(Repo.PostCalendarFeed), &dtos.CalendarFeedBody{})
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("PostCalendarFeed handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+}
+
+func TestRepository_UploadCalendarFeed(t *testing.T){
+	var form bytes.Buffer
+	writer := multipart.NewWriter(&form)
+	part, _ := writer.CreateFormFile("file", "room.ics")
+	part.Write([]byte(uploadCalendar))
+	writer.Close()
+
+	var uploadTests = []struct {
+		name string
+		url string
+		contentType string
+		body string
+		expectedStatusCode int
+	}{
+		{"raw body", "/room/15/calendar-feed/1/upload", "text/calendar", uploadCalendar, http.StatusOK},
+		{"form upload", "/room/15/calendar-feed/1/upload", writer.FormDataContentType(), form.String(), http.StatusOK},
+		{"invalid file", "/room/15/calendar-feed/1/upload", "text/calendar", "garbage", http.StatusBadRequest},
+		{"feed of another room", "/room/16/calendar-feed/1/upload", "text/calendar", uploadCalendar, http.StatusNotFound},
+		{"feed not found", "/room/15/calendar-feed/404/upload", "text/calendar", uploadCalendar, http.StatusNotFound},
+	}
+
+	for _, e := range uploadTests {
+		req, _ := http.NewRequest("POST", e.url, strings.NewReader(e.body))
+		req.Header.Set("Content-Type", e.contentType)
+		res := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(Repo.UploadCalendarFeed)
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("UploadCalendarFeed handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+}
+
+func TestRepository_SyncCalendarFeed(t *testing.T){
+	var syncTests = []struct {
+		name string
+		url string
+		expectedStatusCode int
+	}{
+		{"feed without url", "/room/15/calendar-feed/1/sync", http.StatusBadRequest},
+		{"unreachable feed", "/room/15/calendar-feed/2/sync", http.StatusBadGateway},
+	}
+
+	for _, e := range syncTests {
+		req, _ := http.NewRequest("POST", e.url, nil)
+		res := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(Repo.SyncCalendarFeed)
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("SyncCalendarFeed handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/availability"
+	"github.com/Orololuwa/go-backend-boilerplate/src/dtos"
+	"github.com/Orololuwa/go-backend-boilerplate/src/helpers"
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+	"github.com/Orololuwa/go-backend-boilerplate/src/pricing"
+	"github.com/Orololuwa/go-backend-boilerplate/src/stayrules"
+	"github.com/Orololuwa/go-backend-boilerplate/src/types"
+)
+
+const (
+	// the longest window a flexible search looks through
+	maxFlexibleDays = 90
+	// how far either side of the requested dates alternatives are looked for when a search finds nothing
+	defaultFlexDays = 7
+	// how far either side of the requested dates the nearest free windows of a room are looked for
+	nearestWindowDays = 30
+	// how many alternative stays a search suggests
+	maxAlternatives = 5
+)
+
+var errFlexibleRange = errors.New("a flexible search covers at most 90 days")
+
+// alternativeStays finds the stays of nights nights between from and to in the room, in every room when roomId is 0,
+// that sleep the guests and keep to the stay rules. Stays arriving closest to around come first and the stay
+// arriving on skip is left out, it is the one the guest already asked about
+func (m *Repository) alternativeStays(ctx context.Context, roomId int, from, to time.Time, nights, guests int, around, skip time.Time) ([]types.AlternativeDates, error) {
+	alternatives := make([]types.AlternativeDates, 0)
+
+	// stays cannot start in the past
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	if from.Before(today) {
+		from = today
+	}
+
+	days := int(to.Sub(from).Hours() / 24)
+	if nights <= 0 || nights > days {
+		return alternatives, nil
+	}
+
+	calendars, err := m.DB.GetCalendarGrid(ctx, nil, roomId, from, to)
+	if err != nil {
+		return alternatives, err
+	}
+
+	rules, err := m.DB.GetStayRulesForDates(ctx, nil, roomId, from, to)
+	if err != nil {
+		return alternatives, err
+	}
+
+	allow := func(room models.Room, start, end time.Time) bool {
+		return room.Sleeps(guests) && len(stayrules.Check(rules, room.ID, start, end, today)) == 0
+	}
+
+	options, err := availability.Options(calendars, from, days, nights, around, maxAlternatives + 1, allow)
+	if err != nil {
+		return alternatives, err
+	}
+
+	layout := "2006-01-02"
+
+	for _, o := range options {
+		if o.StartDate.Equal(skip) || len(alternatives) == maxAlternatives {
+			continue
+		}
+		alternatives = append(alternatives, types.AlternativeDates{
+			StartDate: o.StartDate.Format(layout),
+			EndDate: o.EndDate.Format(layout),
+			Rooms: o.Rooms,
+		})
+	}
+
+	return alternatives, nil
+}
+
+// flexibleSearch answers a search for body.Nights nights anywhere between the start and end dates,
+// such as 3 nights sometime in March, with the earliest stays any room is free for
+func (m *Repository) flexibleSearch(w http.ResponseWriter, body dtos.PostAvailabilityBody, startDate, endDate time.Time) {
+	if !endDate.After(startDate) {
+		helpers.ClientError(w, errors.New("endDate must be after startDate"), http.StatusBadRequest, "")
+		return
+	}
+
+	days := int(endDate.Sub(startDate).Hours() / 24)
+	if days > maxFlexibleDays {
+		helpers.ClientError(w, errFlexibleRange, http.StatusBadRequest, "")
+		return
+	}
+
+	if body.Nights > days {
+		helpers.ClientError(w, pricing.ErrInvalidStay, http.StatusBadRequest, "")
+		return
+	}
+
+	alternatives, err := m.alternativeStays(context.Background(), 0, startDate, endDate, body.Nights, body.Adults + body.Children, startDate, time.Time{})
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	data := types.AvailabilityResponse{
+		Rooms: make([]models.Room, 0),
+		Excluded: make([]types.ExcludedRoom, 0),
+		Combinations: make([][]models.Room, 0),
+		Alternatives: alternatives,
+	}
+
+	helpers.ClientResponseWriter(w, data, http.StatusFound, "rooms retrieved successfully")
+}
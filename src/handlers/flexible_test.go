@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/dtos"
+	"github.com/Orololuwa/go-backend-boilerplate/src/types"
+)
+
+func searchAvailability(body dtos.PostAvailabilityBody) (int, types.AvailabilityResponse) {
+	jsonData, _ := json.Marshal(body)
+
+	req, _ := http.NewRequest("POST", "/search-availability", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	res := httptest.NewRecorder()
+
+	handler := mdTest.ValidateReqBody(http.HandlerFunc(Repo.SearchAvailability), &dtos.PostAvailabilityBody{})
+	handler.ServeHTTP(res, req)
+
+	var resBody struct {
+		Data types.AvailabilityResponse `json:"data"`
+	}
+	json.Unmarshal(res.Body.Bytes(), &resBody)
+
+	return res.Code, resBody.Data
+}
+
+func TestRepository_SearchAvailabilityFlexible(t *testing.T){
+	// in the first week of 2050 room 15 is booked on the 2nd and 3rd, room 20 is blocked on the 3rd, 5th and 6th
+	// and takes stays of at least 3 nights, room 21 is free
+	code, data := searchAvailability(dtos.PostAvailabilityBody{StartDate: "2050-01-01", EndDate: "2050-01-08", Nights: 2})
+	if code != http.StatusFound {
+		t.Fatalf("SearchAvailability handler returned wrong response code for a flexible search: got %d, wanted %d", code, http.StatusFound)
+	}
+
+	if len(data.Alternatives) != maxAlternatives {
+		t.Fatalf("SearchAvailability returned %d alternatives, wanted %d", len(data.Alternatives), maxAlternatives)
+	}
+	first := data.Alternatives[0]
+	if first.StartDate != "2050-01-01" || first.EndDate != "2050-01-03" || len(first.Rooms) != 1 || first.Rooms[0].ID != 21 {
+		t.Errorf("SearchAvailability returned %+v first, wanted room 21 from 2050-01-01 to 2050-01-03", first)
+	}
+
+	var flexibleTests = []struct {
+		name string
+		body dtos.PostAvailabilityBody
+		expectedStatusCode int
+	}{
+		{"more than 90 days", dtos.PostAvailabilityBody{StartDate: "2050-01-01", EndDate: "2050-04-02", Nights: 3}, http.StatusBadRequest},
+		{"longer than the window", dtos.PostAvailabilityBody{StartDate: "2050-01-01", EndDate: "2050-01-03", Nights: 3}, http.StatusBadRequest},
+		{"end before start", dtos.PostAvailabilityBody{StartDate: "2050-01-03", EndDate: "2050-01-01", Nights: 1}, http.StatusBadRequest},
+		{"flex days out of range", dtos.PostAvailabilityBody{StartDate: "2050-01-01", EndDate: "2050-01-03", FlexDays: 31}, http.StatusBadRequest},
+	}
+
+	for _, e := range flexibleTests {
+		code, _ := searchAvailability(e.body)
+		if code != e.expectedStatusCode {
+			t.Errorf("SearchAvailability handler returned wrong response code for %s: got %d, wanted %d", e.name, code, e.expectedStatusCode)
+		}
+	}
+}
+
+func TestRepository_SearchAvailabilityFlexDays(t *testing.T){
+	// a day either side of the requested stay, which is not suggested again
+	_, data := searchAvailability(dtos.PostAvailabilityBody{StartDate: "2050-01-02", EndDate: "2050-01-04", Adults: 2, FlexDays: 1})
+
+	expected := []string{"2050-01-01", "2050-01-03"}
+	if len(data.Alternatives) != len(expected) {
+		t.Fatalf("SearchAvailability returned alternatives %+v, wanted arrivals %v", data.Alternatives, expected)
+	}
+	for i, a := range data.Alternatives {
+		if a.StartDate != expected[i] || len(a.Rooms) != 1 || a.Rooms[0].ID != 21 {
+			t.Errorf("SearchAvailability returned alternative %+v, wanted room 21 arriving %s", a, expected[i])
+		}
+	}
+}
+
+func TestRepository_SearchAvailabilityByRoomIdNearest(t *testing.T){
+	// room 3 is booked from 2050-01-01 to 2050-01-10
+	jsonData, _ := json.Marshal(dtos.PostAvailabilityBody{StartDate: "2050-01-04", EndDate: "2050-01-06"})
+
+	req, _ := http.NewRequest("POST", "/search-availability/3", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	req.RequestURI = "/search-availability/3"
+	res := httptest.NewRecorder()
+
+	handler := mdTest.ValidateReqBody(http.HandlerFunc(Repo.SearchAvailabilityByRoomId), &dtos.PostAvailabilityBody{})
+	handler.ServeHTTP(res, req)
+
+	var body struct {
+		Data types.RoomAvailabilityResponse `json:"data"`
+	}
+	json.Unmarshal(res.Body.Bytes(), &body)
+
+	expected := []types.DateRange{
+		{StartDate: "2049-12-30", EndDate: "2050-01-01"},
+		{StartDate: "2049-12-29", EndDate: "2049-12-31"},
+		{StartDate: "2050-01-10", EndDate: "2050-01-12"},
+	}
+
+	if body.Data.Available || len(body.Data.NearestWindows) != maxAlternatives {
+		t.Fatalf("SearchAvailabilityByRoomId returned %+v, wanted room 3 unavailable with %d nearest windows", body.Data, maxAlternatives)
+	}
+	for i, e := range expected {
+		if body.Data.NearestWindows[i] != e {
+			t.Errorf("SearchAvailabilityByRoomId returned nearest window %+v, wanted %+v", body.Data.NearestWindows[i], e)
+		}
+	}
+}
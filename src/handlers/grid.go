@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/availability"
+	"github.com/Orololuwa/go-backend-boilerplate/src/helpers"
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+	"github.com/Orololuwa/go-backend-boilerplate/src/types"
+)
+
+const (
+	// the longest range the calendar grid covers, and the range shown when no end date is given
+	maxCalendarGridDays = 90
+	calendarGridWindow = 30 * 24 * time.Hour
+)
+
+var errCalendarGridRange = errors.New("the calendar grid covers at most 90 days")
+
+// calendarGridRow merges neighbouring days of the room with the same content into spans
+func calendarGridRow(cal models.RoomCalendar, start time.Time, days int) (types.CalendarGridRow, error) {
+	cells, err := availability.Days(cal, start, days)
+	if err != nil {
+		return types.CalendarGridRow{}, err
+	}
+
+	row := types.CalendarGridRow{
+		RoomId: cal.Room.ID,
+		RoomName: cal.Room.RoomName,
+		Spans: make([]types.CalendarGridSpan, 0),
+	}
+
+	for d := 0; d < days; d++ {
+		if cells[d].Free() {
+			continue
+		}
+
+		last := len(row.Spans) - 1
+		if last >= 0 && row.Spans[last].Day + row.Spans[last].Nights == d && cells[d] == cells[d-1] {
+			row.Spans[last].Nights++
+			continue
+		}
+
+		row.Spans = append(row.Spans, types.CalendarGridSpan{
+			Day: d,
+			Nights: 1,
+			Status: cells[d].Status,
+			ReservationId: cells[d].ReservationID,
+			Restriction: cells[d].Restriction,
+		})
+	}
+
+	return row, nil
+}
+
+// GetCalendarGrid returns the front desk tape chart: every room with the days between the start and end query dates
+// it is booked or blocked, the next 30 days by default. Days are counted from start and end is exclusive
+func (m *Repository) GetCalendarGrid(w http.ResponseWriter, r *http.Request) {
+	startDate, endDate, err := dateWindow(r, calendarGridWindow)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "")
+		return
+	}
+
+	if !endDate.After(startDate) {
+		helpers.ClientError(w, errors.New("end must be after start"), http.StatusBadRequest, "")
+		return
+	}
+
+	days := int(endDate.Sub(startDate).Hours() / 24)
+	if days > maxCalendarGridDays {
+		helpers.ClientError(w, errCalendarGridRange, http.StatusBadRequest, "")
+		return
+	}
+
+	calendars, err := m.DB.GetCalendarGrid(context.Background(), nil, 0, startDate, endDate)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	layout := "2006-01-02"
+
+	data := types.CalendarGridResponse{
+		StartDate: startDate.Format(layout),
+		EndDate: endDate.Format(layout),
+		Days: days,
+		Rooms: make([]types.CalendarGridRow, 0, len(calendars)),
+	}
+
+	for _, cal := range calendars {
+		row, err := calendarGridRow(cal, startDate, days)
+		if err != nil {
+			helpers.ClientError(w, err, http.StatusInternalServerError, "")
+			return
+		}
+		data.Rooms = append(data.Rooms, row)
+	}
+
+	helpers.ClientResponseWriter(w, data, http.StatusOK, "calendar grid retrieved successfully")
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/types"
+)
+
+func TestRepository_GetCalendarGrid(t *testing.T){
+	req, _ := http.NewRequest("GET", "/admin/calendar?start=2050-01-01&end=2050-01-11", nil)
+	res := httptest.NewRecorder()
+
+	handler := http.HandlerFunc(Repo.GetCalendarGrid)
+	handler.ServeHTTP(res, req)
+
+	if res.Code != http.StatusOK {
+		t.Fatalf("GetCalendarGrid handler returned wrong response code: got %d, wanted %d", res.Code, http.StatusOK)
+	}
+
+	var body struct {
+		Data types.CalendarGridResponse `json:"data"`
+	}
+	json.Unmarshal(res.Body.Bytes(), &body)
+
+	if body.Data.Days != 10 || len(body.Data.Rooms) != 3 {
+		t.Fatalf("GetCalendarGrid returned %d days and %d rooms, wanted 10 days and 3 rooms", body.Data.Days, len(body.Data.Rooms))
+	}
+
+	// 2050-01-03 and 2050-01-10 are mondays
+	expected := map[int][]types.CalendarGridSpan{
+		15: {{Day: 1, Nights: 2, Status: "booked", ReservationId: 7}},
+		20: {
+			{Day: 2, Nights: 1, Status: "blocked", Restriction: "Maintenance"},
+			{Day: 4, Nights: 2, Status: "blocked", Restriction: "Owner Block"},
+			{Day: 9, Nights: 1, Status: "blocked", Restriction: "Maintenance"},
+		},
+		21: {},
+	}
+
+	for _, row := range body.Data.Rooms {
+		if !reflect.DeepEqual(row.Spans, expected[row.RoomId]) {
+			t.Errorf("GetCalendarGrid returned spans %+v for room %d, wanted %+v", row.Spans, row.RoomId, expected[row.RoomId])
+		}
+	}
+
+	var gridTests = []struct {
+		name string
+		url string
+		expectedStatusCode int
+	}{
+		{"default window", "/admin/calendar", http.StatusOK},
+		{"90 days", "/admin/calendar?start=2050-01-01&end=2050-04-01", http.StatusOK},
+		{"more than 90 days", "/admin/calendar?start=2050-01-01&end=2050-04-02", http.StatusBadRequest},
+		{"end before start", "/admin/calendar?start=2050-01-11&end=2050-01-01", http.StatusBadRequest},
+		{"invalid date", "/admin/calendar?start=invalid", http.StatusBadRequest},
+		{"query fails", "/admin/calendar?start=1959-01-01&end=1959-01-11", http.StatusInternalServerError},
+	}
+
+	for _, e := range gridTests {
+		req, _ := http.NewRequest("GET", e.url, nil)
+		res := httptest.NewRecorder()
+
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("GetCalendarGrid handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+}
@@ -0,0 +1,520 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/dtos"
+	"github.com/Orololuwa/go-backend-boilerplate/src/helpers"
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+	"github.com/Orololuwa/go-backend-boilerplate/src/pricing"
+	"github.com/Orololuwa/go-backend-boilerplate/src/promo"
+	"github.com/Orololuwa/go-backend-boilerplate/src/types"
+)
+
+var (
+	errGroupCancelled = errors.New("reservation group has been cancelled")
+	errConfirmedRepriced = errors.New("the new dates change the price of a room that has been paid for")
+)
+
+func reservationGroupResponse(g models.ReservationGroup) types.ReservationGroupResponse {
+	resp := types.ReservationGroupResponse{
+		ID: g.ID,
+		ConfirmationCode: g.ConfirmationCode,
+		GroupName: g.GroupName,
+		FirstName: g.FirstName,
+		LastName: g.LastName,
+		Email: g.Email,
+		Phone: g.Phone,
+		Status: g.Status,
+		Reservations: make([]types.ReservationResponse, 0, len(g.Reservations)),
+	}
+
+	for _, res := range g.Reservations {
+		resp.Currency = res.Currency
+		resp.Reservations = append(resp.Reservations, reservationResponse(res))
+		if res.Status != models.ReservationStatusCancelled {
+			resp.TotalPrice += res.TotalPrice
+		}
+	}
+
+	return resp
+}
+
+// bookGroupRoom books a single room of a group, the caller owns the transaction. A confirmed room has been paid
+// for, so it only moves to dates that cost the same
+func (m *Repository) bookGroupRoom(ctx context.Context, tx *sql.Tx, res *models.Reservation) error {
+	paid := res.TotalPrice
+
+	err := m.DB.LockRoom(ctx, tx, res.RoomID)
+	if err != nil {
+		return err
+	}
+
+	err = m.checkOccupancy(ctx, tx, res.RoomID, res.Guests())
+	if err != nil {
+		return err
+	}
+
+	err = m.checkStayRules(ctx, tx, res.RoomID, res.StartDate, res.EndDate)
+	if err != nil {
+		return err
+	}
+
+	available, err := m.DB.SearchAvailabilityForDatesByRoomId(ctx, tx, res.StartDate, res.EndDate, res.RoomID)
+	if err != nil {
+		return err
+	}
+
+	if !available {
+		return errRoomUnavailable
+	}
+
+	quote, err := m.quoteRoom(ctx, tx, res.RoomID, res.StartDate, res.EndDate)
+	if err != nil {
+		return err
+	}
+
+	// a new stay is charged at today's rate into the currency it asks for, a stay that moves at the rate it was booked at
+	x := reservationExchange(*res)
+	if res.ID == 0 {
+		x, err = m.roomExchange(ctx, tx, res.RoomID, res.Currency)
+		if err != nil {
+			return err
+		}
+		x.stamp(res)
+	}
+
+	quote = x.quote(quote)
+	res.TotalPrice = quote.Total
+
+	// a stay that moves keeps the policy and the promo rule it was booked with, its taxes and fees are charged again
+	if res.ID == 0 {
+		res.CancellationPolicy, err = m.stayCancellationPolicy(ctx, tx, res.RoomID, quote)
+		if err != nil {
+			return err
+		}
+
+		promoCodeId := 0
+		if res.PromoCode != "" {
+			promoCodeId, err = m.applyPromo(ctx, tx, res, quote)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = m.applyCharges(ctx, tx, res, len(quote.Nights))
+		if err != nil {
+			return err
+		}
+
+		res.ConfirmationCode, err = m.newConfirmationCode(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		err = m.attachGuest(ctx, tx, res)
+		if err != nil {
+			return err
+		}
+
+		res.ID, err = m.DB.InsertReservation(ctx, tx, *res)
+		if err != nil {
+			return err
+		}
+
+		err = m.saveCharges(ctx, tx, *res)
+		if err != nil {
+			return err
+		}
+
+		if promoCodeId != 0 {
+			err = m.redeemPromo(ctx, tx, promoCodeId, *res)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = m.recordHistory(ctx, tx, models.HistoryCreated, nil, *res)
+		if err != nil {
+			return err
+		}
+	}else{
+		if res.PromoCode != "" {
+			res.Discount = promo.Discount(reservationPromo(*res), res.TotalPrice)
+		}
+		res.TotalPrice -= res.Discount
+
+		err = m.applyCharges(ctx, tx, res, len(quote.Nights))
+		if err != nil {
+			return err
+		}
+
+		if res.Status == models.ReservationStatusConfirmed && res.TotalPrice != paid {
+			return errConfirmedRepriced
+		}
+
+		err = m.DB.UpdateReservationStay(ctx, tx, *res)
+		if err != nil {
+			return err
+		}
+
+		err = m.saveCharges(ctx, tx, *res)
+		if err != nil {
+			return err
+		}
+	}
+
+	return m.DB.InsertRoomRestriction(ctx, tx, models.RoomRestriction{
+		StartDate: res.StartDate,
+		EndDate: res.EndDate,
+		RoomID: res.RoomID,
+		ReservationID: res.ID,
+		RestrictionID: models.RestrictionReservation,
+	})
+}
+
+// PostReservationGroup books several rooms for the same dates. Either every room is booked or none is
+func (m *Repository) PostReservationGroup(w http.ResponseWriter, r *http.Request) {
+	var body dtos.GroupReservationBody
+	requestBody, ok := r.Context().Value("validatedRequestBody").(*dtos.GroupReservationBody)
+	if !ok || requestBody == nil {
+		helpers.ClientError(w, errors.New("failed to retrieve request body"), http.StatusBadRequest, "")
+		return
+	}
+	body = *requestBody
+
+	layout := "2006-01-02"
+
+	startDate, err := time.Parse(layout, body.StartDate)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "")
+		return
+	}
+
+	endDate, err := time.Parse(layout, body.EndDate)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "")
+		return
+	}
+
+	if !endDate.After(startDate) {
+		helpers.ClientError(w, pricing.ErrInvalidStay, http.StatusBadRequest, "")
+		return
+	}
+
+	group := models.ReservationGroup{
+		GroupName: body.GroupName,
+		FirstName: body.Guest.FirstName,
+		LastName: body.Guest.LastName,
+		Email: body.Guest.Email,
+		Phone: body.Guest.Phone,
+		Status: models.ReservationStatusPending,
+	}
+
+	seen := make(map[int]bool)
+	for _, room := range body.Rooms {
+		if seen[room.RoomId] {
+			helpers.ClientError(w, fmt.Errorf("room %d is listed more than once", room.RoomId), http.StatusBadRequest, "")
+			return
+		}
+		seen[room.RoomId] = true
+
+		guest := body.Guest
+		if room.Guest != nil {
+			guest = *room.Guest
+		}
+
+		adults := room.Adults
+		if adults == 0 {
+			adults = 1
+		}
+
+		group.Reservations = append(group.Reservations, models.Reservation{
+			FirstName: guest.FirstName,
+			LastName: guest.LastName,
+			Email: guest.Email,
+			Phone: guest.Phone,
+			StartDate: startDate,
+			EndDate: endDate,
+			RoomID: room.RoomId,
+			Adults: adults,
+			Children: room.Children,
+			Status: models.ReservationStatusPending,
+		})
+	}
+
+	// locking rooms in id order keeps two overlapping group bookings from deadlocking each other
+	sort.Slice(group.Reservations, func(i, j int) bool {
+		return group.Reservations[i].RoomID < group.Reservations[j].RoomID
+	})
+
+	ctx := withActor(r)
+
+	err = m.DB.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		var err error
+
+		group.ConfirmationCode, err = m.newConfirmationCode(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		group.ID, err = m.DB.InsertReservationGroup(ctx, tx, group)
+		if err != nil {
+			return err
+		}
+
+		// every room of the group is charged in the same currency, the first room's unless the organiser asks for another
+		currency := body.Currency
+		if currency == "" {
+			room, err := m.DB.GetRoomById(ctx, tx, group.Reservations[0].RoomID)
+			if err != nil {
+				return err
+			}
+			currency = room.Currency
+		}
+
+		for i := range group.Reservations {
+			group.Reservations[i].GroupID = group.ID
+			group.Reservations[i].Currency = currency
+
+			err = m.bookGroupRoom(ctx, tx, &group.Reservations[i])
+			if err != nil {
+				return fmt.Errorf("room %d: %w", group.Reservations[i].RoomID, err)
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		bookingError(w, err)
+		return
+	}
+
+	helpers.ClientResponseWriter(w, reservationGroupResponse(group), http.StatusCreated, "group reservation booked successfully")
+}
+
+// loadReservationGroup fetches a group with its reservations and checks the confirmation code the guest supplied
+func (m *Repository) loadReservationGroup(ctx context.Context, tx *sql.Tx, id int, code string) (models.ReservationGroup, error) {
+	group, err := m.DB.GetReservationGroupById(ctx, tx, id)
+	if err != nil {
+		return group, err
+	}
+
+	// the group id is guessable, so the organiser proves ownership with the confirmation code
+	if !helpers.ConfirmationCodeMatches(group.ConfirmationCode, code) {
+		return group, sql.ErrNoRows
+	}
+
+	group.Reservations, err = m.DB.GetReservationsByGroupId(ctx, tx, id)
+	if err != nil {
+		return group, err
+	}
+
+	return group, nil
+}
+
+func (m *Repository) GetReservationGroup(w http.ResponseWriter, r *http.Request) {
+	id, err := m.urlParamInt(r, "id", 3)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	group, err := m.loadReservationGroup(context.Background(), nil, id, r.URL.Query().Get("code"))
+	if errors.Is(err, sql.ErrNoRows) {
+		helpers.ClientError(w, err, http.StatusNotFound, "reservation group not found")
+		return
+	}
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, reservationGroupResponse(group), http.StatusOK, "reservation group retrieved successfully")
+}
+
+// CancelReservationGroup cancels every reservation of the group, charging their cancellation fees, and releases their rooms
+func (m *Repository) CancelReservationGroup(w http.ResponseWriter, r *http.Request) {
+	id, err := m.urlParamInt(r, "id", 3)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	var body dtos.CancelBody
+	requestBody, ok := r.Context().Value("validatedRequestBody").(*dtos.CancelBody)
+	if !ok || requestBody == nil {
+		helpers.ClientError(w, errors.New("failed to retrieve request body"), http.StatusBadRequest, "")
+		return
+	}
+	body = *requestBody
+
+	var group models.ReservationGroup
+	var released []models.Reservation
+
+	err = m.DB.Transaction(withActor(r), func(ctx context.Context, tx *sql.Tx) error {
+		var err error
+
+		group, err = m.loadReservationGroup(ctx, tx, id, body.ConfirmationCode)
+		if err != nil {
+			return err
+		}
+
+		if group.Status == models.ReservationStatusCancelled {
+			return errGroupCancelled
+		}
+
+		// the rooms are locked, always in the same order, and read again so a room cancelled or marked as a
+		// no-show on its own at the same time is not closed twice
+		for _, res := range group.Reservations {
+			err = m.DB.LockReservation(ctx, tx, res.ID)
+			if err != nil {
+				return err
+			}
+		}
+
+		group.Reservations, err = m.DB.GetReservationsByGroupId(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		released = released[:0]
+		for i := range group.Reservations {
+			// rooms whose guests never arrived keep their no-show charge, rooms cancelled on their own keep the fee
+			// they were cancelled with
+			switch group.Reservations[i].Status {
+			case models.ReservationStatusNoShow, models.ReservationStatusCancelled:
+				continue
+			}
+
+			err = m.closeReservation(ctx, tx, &group.Reservations[i], models.ReservationStatusCancelled)
+			if err != nil {
+				return err
+			}
+			released = append(released, group.Reservations[i])
+		}
+
+		group.Status = models.ReservationStatusCancelled
+		return m.DB.UpdateReservationGroupStatus(ctx, tx, group.ID, group.Status)
+	})
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			helpers.ClientError(w, err, http.StatusNotFound, "reservation group not found")
+		case errors.Is(err, errGroupCancelled):
+			helpers.ClientError(w, err, http.StatusConflict, "")
+		default:
+			helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		}
+		return
+	}
+
+	for _, res := range released {
+		m.offerReleasedDates(res)
+	}
+
+	helpers.ClientResponseWriter(w, reservationGroupResponse(group), http.StatusOK, "reservation group cancelled successfully")
+}
+
+// UpdateReservationGroup moves every room of the group to new dates and reprices them.
+// If any room is not free for the new dates the whole group keeps its current dates
+func (m *Repository) UpdateReservationGroup(w http.ResponseWriter, r *http.Request) {
+	id, err := m.urlParamInt(r, "id", 3)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	var body dtos.GroupUpdateBody
+	requestBody, ok := r.Context().Value("validatedRequestBody").(*dtos.GroupUpdateBody)
+	if !ok || requestBody == nil {
+		helpers.ClientError(w, errors.New("failed to retrieve request body"), http.StatusBadRequest, "")
+		return
+	}
+	body = *requestBody
+
+	layout := "2006-01-02"
+
+	startDate, err := time.Parse(layout, body.StartDate)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "")
+		return
+	}
+
+	endDate, err := time.Parse(layout, body.EndDate)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "")
+		return
+	}
+
+	if !endDate.After(startDate) {
+		helpers.ClientError(w, pricing.ErrInvalidStay, http.StatusBadRequest, "")
+		return
+	}
+
+	var group models.ReservationGroup
+
+	err = m.DB.Transaction(withActor(r), func(ctx context.Context, tx *sql.Tx) error {
+		var err error
+
+		group, err = m.loadReservationGroup(ctx, tx, id, body.ConfirmationCode)
+		if err != nil {
+			return err
+		}
+
+		if group.Status == models.ReservationStatusCancelled {
+			return errGroupCancelled
+		}
+
+		for i := range group.Reservations {
+			res := &group.Reservations[i]
+
+			// a room the guest never turned up to or that was cancelled on its own is closed, it keeps its dates
+			switch res.Status {
+			case models.ReservationStatusCancelled, models.ReservationStatusNoShow:
+				continue
+			}
+
+			// release the current dates first so a stay that overlaps them does not conflict with itself
+			err = m.DB.DeleteRoomRestrictionsByReservationId(ctx, tx, res.ID)
+			if err != nil {
+				return err
+			}
+
+			before := *res
+			res.StartDate = startDate
+			res.EndDate = endDate
+
+			err = m.bookGroupRoom(ctx, tx, res)
+			if err != nil {
+				return fmt.Errorf("room %d: %w", res.RoomID, err)
+			}
+
+			err = m.recordHistory(ctx, tx, models.HistoryUpdated, &before, *res)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		if errors.Is(err, errGroupCancelled) || errors.Is(err, errConfirmedRepriced) {
+			helpers.ClientError(w, err, http.StatusConflict, err.Error())
+			return
+		}
+		bookingError(w, err)
+		return
+	}
+
+	helpers.ClientResponseWriter(w, reservationGroupResponse(group), http.StatusOK, "reservation group updated successfully")
+}
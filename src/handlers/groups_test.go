@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/dtos"
+	"github.com/Orololuwa/go-backend-boilerplate/src/types"
+)
+
+func TestRepository_PostReservationGroup(t *testing.T){
+	organiser := dtos.GuestDetails{FirstName: "John", LastName: "Doe", Email: "johndoe@gmail.com", Phone: "08012345678"}
+	guest := &dtos.GuestDetails{FirstName: "Jane", LastName: "Roe", Email: "janeroe@gmail.com", Phone: "08087654321"}
+
+	var groupTests = []struct {
+		name string
+		rooms []dtos.GroupRoomBody
+		endDate string
+		expectedStatusCode int
+	}{
+		{"shared and own guest details", []dtos.GroupRoomBody{{RoomId: 27}, {RoomId: 15, Guest: guest}}, "2050-01-05", http.StatusCreated},
+		{"one room unavailable", []dtos.GroupRoomBody{{RoomId: 15}, {RoomId: 3}}, "2050-01-05", http.StatusConflict},
+		{"one room missing", []dtos.GroupRoomBody{{RoomId: 15}, {RoomId: 404}}, "2050-01-05", http.StatusNotFound},
+		{"failed availability check", []dtos.GroupRoomBody{{RoomId: 15}, {RoomId: 2}}, "2050-01-05", http.StatusInternalServerError},
+		{"duplicate room", []dtos.GroupRoomBody{{RoomId: 15}, {RoomId: 15}}, "2050-01-05", http.StatusBadRequest},
+		{"no rooms", []dtos.GroupRoomBody{}, "2050-01-05", http.StatusBadRequest},
+		{"invalid room guest", []dtos.GroupRoomBody{{RoomId: 15, Guest: &dtos.GuestDetails{FirstName: "Jane"}}}, "2050-01-05", http.StatusBadRequest},
+		{"end before start", []dtos.GroupRoomBody{{RoomId: 15}}, "2049-12-31", http.StatusBadRequest},
+	}
+
+	for _, e := range groupTests {
+		body := dtos.GroupReservationBody{
+			GroupName: "Conference",
+			StartDate: "2050-01-01",
+			EndDate: e.endDate,
+			Guest: organiser,
+			Rooms: e.rooms,
+		}
+
+		jsonData, err := json.Marshal(body)
+		if err != nil {
+			t.Log("Error:", err)
+			return
+		}
+
+		req, _ := http.NewRequest("POST", "/reservation/group", bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		res := httptest.NewRecorder()
+
+		handler := mdTest.ValidateReqBody(http.HandlerFunc(Repo.PostReservationGroup), &dtos.GroupReservationBody{})
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("PostReservationGroup handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+			continue
+		}
+
+		if res.Code != http.StatusCreated {
+			continue
+		}
+
+		var resp struct {
+			Data types.ReservationGroupResponse `json:"data"`
+		}
+		err = json.Unmarshal(res.Body.Bytes(), &resp)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(resp.Data.Reservations) != 2 {
+			t.Fatalf("PostReservationGroup handler expected 2 reservations, got %d", len(resp.Data.Reservations))
+		}
+
+		// rooms are booked in id order, so room 15 comes first with its own guest
+		if resp.Data.Reservations[0].LastName != "Roe" || resp.Data.Reservations[1].LastName != "Doe" {
+			t.Errorf("PostReservationGroup handler did not apply per-room guest details: %+v", resp.Data.Reservations)
+		}
+	}
+}
+
+func TestRepository_GetReservationGroup(t *testing.T){
+	var getTests = []struct {
+		name string
+		url string
+		expectedStatusCode int
+	}{
+		{"valid code", "/reservation/group/1?code=GRP-23456", http.StatusOK},
+		{"wrong code", "/reservation/group/1?code=WRONG", http.StatusNotFound},
+		{"group not found", "/reservation/group/404?code=GRP23456", http.StatusNotFound},
+		{"invalid id", "/reservation/group/one?code=GRP23456", http.StatusBadRequest},
+	}
+
+	for _, e := range getTests {
+		req, _ := http.NewRequest("GET", e.url, nil)
+		res := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(Repo.GetReservationGroup)
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("GetReservationGroup handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+}
+
+func TestRepository_CancelReservationGroup(t *testing.T){
+	var cancelTests = []struct {
+		name string
+		url string
+		code string
+		expectedStatusCode int
+	}{
+		{"cancel group", "/reservation/group/1/cancel", "GRP23456", http.StatusOK},
+		{"already cancelled", "/reservation/group/2/cancel", "GRP23456", http.StatusConflict},
+		{"wrong code", "/reservation/group/1/cancel", "WRONG", http.StatusNotFound},
+		{"missing code", "/reservation/group/1/cancel", "", http.StatusBadRequest},
+	}
+
+	for _, e := range cancelTests {
+		jsonData, _ := json.Marshal(dtos.CancelBody{ConfirmationCode: e.code})
+
+		req, _ := http.NewRequest("POST", e.url, bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		res := httptest.NewRecorder()
+
+		handler := mdTest.ValidateReqBody(http.HandlerFunc(Repo.CancelReservationGroup), &dtos.CancelBody{})
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("CancelReservationGroup handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+}
+
+func TestRepository_CancelReservationGroupWithCancelledRoom(t *testing.T){
+	jsonData, _ := json.Marshal(dtos.CancelBody{ConfirmationCode: "GRP23456"})
+
+	req, _ := http.NewRequest("POST", "/reservation/group/4/cancel", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	res := httptest.NewRecorder()
+
+	handler := mdTest.ValidateReqBody(http.HandlerFunc(Repo.CancelReservationGroup), &dtos.CancelBody{})
+	handler.ServeHTTP(res, req)
+
+	if res.Code != http.StatusOK {
+		t.Fatalf("CancelReservationGroup handler returned wrong response code: got %d, wanted %d", res.Code, http.StatusOK)
+	}
+
+	var resp struct {
+		Data types.ReservationGroupResponse `json:"data"`
+	}
+	_ = json.Unmarshal(res.Body.Bytes(), &resp)
+
+	if len(resp.Data.Reservations) != 2 {
+		t.Fatalf("CancelReservationGroup handler returned %d reservations, wanted 2", len(resp.Data.Reservations))
+	}
+
+	// the room cancelled on its own keeps the fee it was cancelled with
+	cancelled := resp.Data.Reservations[1]
+	if cancelled.Status != "cancelled" || cancelled.CancellationFee != 5000 || cancelled.RefundAmount != 0 {
+		t.Errorf("CancelReservationGroup handler returned the cancelled room as %s with a fee of %d and a refund of %d, wanted cancelled with 5000 and 0", cancelled.Status, cancelled.CancellationFee, cancelled.RefundAmount)
+	}
+	if resp.Data.Reservations[0].Status != "cancelled" {
+		t.Errorf("CancelReservationGroup handler left the other room %s, wanted cancelled", resp.Data.Reservations[0].Status)
+	}
+}
+
+func TestRepository_UpdateReservationGroup(t *testing.T){
+	var updateTests = []struct {
+		name string
+		url string
+		body dtos.GroupUpdateBody
+		expectedStatusCode int
+	}{
+		{"move group", "/reservation/group/1", dtos.GroupUpdateBody{ConfirmationCode: "GRP23456", StartDate: "2050-02-01", EndDate: "2050-02-04"}, http.StatusOK},
+		{"one room unavailable", "/reservation/group/3", dtos.GroupUpdateBody{ConfirmationCode: "GRP23456", StartDate: "2050-02-01", EndDate: "2050-02-04"}, http.StatusConflict},
+		{"cancelled group", "/reservation/group/2", dtos.GroupUpdateBody{ConfirmationCode: "GRP23456", StartDate: "2050-02-01", EndDate: "2050-02-04"}, http.StatusConflict},
+		{"paid room repriced", "/reservation/group/6", dtos.GroupUpdateBody{ConfirmationCode: "GRP23456", StartDate: "2050-02-01", EndDate: "2050-02-04"}, http.StatusConflict},
+		{"group not found", "/reservation/group/404", dtos.GroupUpdateBody{ConfirmationCode: "GRP23456", StartDate: "2050-02-01", EndDate: "2050-02-04"}, http.StatusNotFound},
+		{"end before start", "/reservation/group/1", dtos.GroupUpdateBody{ConfirmationCode: "GRP23456", StartDate: "2050-02-04", EndDate: "2050-02-01"}, http.StatusBadRequest},
+	}
+
+	for _, e := range updateTests {
+		jsonData, _ := json.Marshal(e.body)
+
+		req, _ := http.NewRequest("PUT", e.url, bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		res := httptest.NewRecorder()
+
+		handler := mdTest.ValidateReqBody(http.HandlerFunc(Repo.UpdateReservationGroup), &dtos.GroupUpdateBody{})
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("UpdateReservationGroup handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+}
+
+func TestRepository_UpdateReservationGroupWithNoShowRoom(t *testing.T){
+	jsonData, _ := json.Marshal(dtos.GroupUpdateBody{ConfirmationCode: "GRP23456", StartDate: "2050-02-01", EndDate: "2050-02-04"})
+
+	req, _ := http.NewRequest("PUT", "/reservation/group/5", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	res := httptest.NewRecorder()
+
+	handler := mdTest.ValidateReqBody(http.HandlerFunc(Repo.UpdateReservationGroup), &dtos.GroupUpdateBody{})
+	handler.ServeHTTP(res, req)
+
+	if res.Code != http.StatusOK {
+		t.Fatalf("UpdateReservationGroup handler returned wrong response code: got %d, wanted %d", res.Code, http.StatusOK)
+	}
+
+	var resp struct {
+		Data types.ReservationGroupResponse `json:"data"`
+	}
+	_ = json.Unmarshal(res.Body.Bytes(), &resp)
+
+	if len(resp.Data.Reservations) != 2 {
+		t.Fatalf("UpdateReservationGroup handler returned %d reservations, wanted 2", len(resp.Data.Reservations))
+	}
+
+	// the no-show is closed, so it keeps the dates the guest never turned up for
+	noShow := resp.Data.Reservations[0]
+	if noShow.StartDate != "2050-01-06" || noShow.EndDate != "2050-01-09" {
+		t.Errorf("UpdateReservationGroup handler moved the no-show room to %s - %s, wanted it left on 2050-01-06 - 2050-01-09", noShow.StartDate, noShow.EndDate)
+	}
+	if resp.Data.Reservations[1].StartDate != "2050-02-01" {
+		t.Errorf("UpdateReservationGroup handler left the other room on %s, wanted it moved to 2050-02-01", resp.Data.Reservations[1].StartDate)
+	}
+}
+
+func TestRepository_UpdateReservationGroupWithPromo(t *testing.T){
+	// two weeknights instead of three
+	jsonData, _ := json.Marshal(dtos.GroupUpdateBody{ConfirmationCode: "GRP23456", StartDate: "2050-02-01", EndDate: "2050-02-03"})
+
+	req, _ := http.NewRequest("PUT", "/reservation/group/7", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	res := httptest.NewRecorder()
+
+	handler := mdTest.ValidateReqBody(http.HandlerFunc(Repo.UpdateReservationGroup), &dtos.GroupUpdateBody{})
+	handler.ServeHTTP(res, req)
+
+	if res.Code != http.StatusOK {
+		t.Fatalf("UpdateReservationGroup handler returned wrong response code: got %d, wanted %d", res.Code, http.StatusOK)
+	}
+
+	var resp struct {
+		Data types.ReservationGroupResponse `json:"data"`
+	}
+	_ = json.Unmarshal(res.Body.Bytes(), &resp)
+
+	if len(resp.Data.Reservations) != 2 {
+		t.Fatalf("UpdateReservationGroup handler returned %d reservations, wanted 2", len(resp.Data.Reservations))
+	}
+
+	// 10% of the new 20000 stay, not the 3000 taken off the old one
+	if discount := resp.Data.Reservations[0].Discount; discount != 2000 {
+		t.Errorf("UpdateReservationGroup handler discounted the moved room by %d, wanted 2000", discount)
+	}
+	if discount := resp.Data.Reservations[1].Discount; discount != 0 {
+		t.Errorf("UpdateReservationGroup handler discounted the room booked without a code by %d, wanted 0", discount)
+	}
+}
@@ -0,0 +1,328 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/dtos"
+	"github.com/Orololuwa/go-backend-boilerplate/src/guests"
+	"github.com/Orololuwa/go-backend-boilerplate/src/helpers"
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+	"github.com/Orololuwa/go-backend-boilerplate/src/types"
+)
+
+// guestBackfillBatch is how many reservations BackfillGuests links in one transaction
+const guestBackfillBatch = 100
+
+var errInvalidSplit = errors.New("invalid split")
+
+// attachGuest links a new reservation to the profile of the guest who booked it, found by email or phone number,
+// and creates the profile on their first booking. An existing profile keeps the details it has
+func (m *Repository) attachGuest(ctx context.Context, tx *sql.Tx, res *models.Reservation) error {
+	emailKey, phoneKey := guests.Keys(res.Email, res.Phone, m.App.PhoneCountryCode)
+
+	// a booking matches on either key, so both are locked, always in the same order so two bookings cannot deadlock
+	keys := []string{emailKey}
+	if phoneKey != "" {
+		keys = append(keys, phoneKey)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		err := m.DB.LockGuestKey(ctx, tx, key)
+		if err != nil {
+			return err
+		}
+	}
+
+	guest, err := m.DB.FindGuest(ctx, tx, emailKey, phoneKey)
+	if err == nil {
+		res.GuestID = guest.ID
+		return nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	res.GuestID, err = m.DB.InsertGuest(ctx, tx, newGuest(*res, emailKey, phoneKey))
+	return err
+}
+
+func newGuest(res models.Reservation, emailKey, phoneKey string) models.Guest {
+	return models.Guest{
+		FirstName: res.FirstName,
+		LastName: res.LastName,
+		Email: res.Email,
+		Phone: res.Phone,
+		EmailKey: emailKey,
+		PhoneKey: phoneKey,
+	}
+}
+
+func (m *Repository) guestResponse(ctx context.Context, tx *sql.Tx, guest models.Guest) (types.GuestResponse, error) {
+	reservations, err := m.DB.GetReservations(ctx, tx, models.ReservationFilter{GuestID: guest.ID})
+	if err != nil {
+		return types.GuestResponse{}, err
+	}
+
+	resp := types.GuestResponse{
+		Guest: guest,
+		Stats: guests.Stats(reservations),
+		Reservations: make([]types.ReservationResponse, 0, len(reservations)),
+	}
+
+	for _, res := range reservations {
+		resp.Reservations = append(resp.Reservations, reservationResponse(res))
+	}
+
+	return resp, nil
+}
+
+// GetGuests lists the guest profiles for staff, q searches names, emails and phone numbers
+func (m *Repository) GetGuests(w http.ResponseWriter, r *http.Request) {
+	search := strings.TrimSpace(r.URL.Query().Get("q"))
+
+	list, err := m.DB.GetGuests(context.Background(), nil, search)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, list, http.StatusOK, "guests retrieved successfully")
+}
+
+// GetGuest returns a guest profile with their reservations, stays, nights and spend
+func (m *Repository) GetGuest(w http.ResponseWriter, r *http.Request) {
+	id, err := m.urlParamInt(r, "id", 3)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	guest, err := m.DB.GetGuestById(context.Background(), nil, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		helpers.ClientError(w, err, http.StatusNotFound, "guest not found")
+		return
+	}
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	resp, err := m.guestResponse(context.Background(), nil, guest)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, resp, http.StatusOK, "guest retrieved successfully")
+}
+
+// moveReservationGuest moves a reservation to another guest and records the change
+func (m *Repository) moveReservationGuest(ctx context.Context, tx *sql.Tx, before models.Reservation, fromGuestId, toGuestId int) error {
+	err := m.DB.UpdateReservationGuest(ctx, tx, before.ID, fromGuestId, toGuestId)
+	if err != nil {
+		return err
+	}
+
+	after := before
+	after.GuestID = toGuestId
+
+	return m.recordHistory(ctx, tx, models.HistoryUpdated, &before, after)
+}
+
+// MergeGuests moves the reservations of a duplicate profile to the guest in the path and deletes the duplicate
+func (m *Repository) MergeGuests(w http.ResponseWriter, r *http.Request) {
+	id, err := m.urlParamInt(r, "id", 3)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	var body dtos.GuestMergeBody
+	requestBody, ok := r.Context().Value("validatedRequestBody").(*dtos.GuestMergeBody)
+	if !ok || requestBody == nil {
+		helpers.ClientError(w, errors.New("failed to retrieve request body"), http.StatusBadRequest, "")
+		return
+	}
+	body = *requestBody
+
+	if body.GuestId == id {
+		helpers.ClientError(w, errors.New("a guest cannot be merged into itself"), http.StatusBadRequest, "")
+		return
+	}
+
+	var resp types.GuestResponse
+	err = m.DB.Transaction(withActor(r), func(ctx context.Context, tx *sql.Tx) error {
+		guest, err := m.DB.GetGuestById(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		moved, err := m.DB.GetReservations(ctx, tx, models.ReservationFilter{GuestID: body.GuestId})
+		if err != nil {
+			return err
+		}
+
+		err = m.DB.MergeGuests(ctx, tx, id, body.GuestId)
+		if err != nil {
+			return err
+		}
+
+		for _, before := range moved {
+			after := before
+			after.GuestID = id
+
+			err = m.recordHistory(ctx, tx, models.HistoryUpdated, &before, after)
+			if err != nil {
+				return err
+			}
+		}
+
+		resp, err = m.guestResponse(ctx, tx, guest)
+		return err
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		helpers.ClientError(w, err, http.StatusNotFound, "guest not found")
+		return
+	}
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, resp, http.StatusOK, "guests merged successfully")
+}
+
+// SplitGuest moves reservations that were matched to the wrong profile to a new one, made from the details of the
+// first reservation moved
+func (m *Repository) SplitGuest(w http.ResponseWriter, r *http.Request) {
+	id, err := m.urlParamInt(r, "id", 3)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	var body dtos.GuestSplitBody
+	requestBody, ok := r.Context().Value("validatedRequestBody").(*dtos.GuestSplitBody)
+	if !ok || requestBody == nil {
+		helpers.ClientError(w, errors.New("failed to retrieve request body"), http.StatusBadRequest, "")
+		return
+	}
+	body = *requestBody
+
+	var resp types.GuestResponse
+	err = m.DB.Transaction(withActor(r), func(ctx context.Context, tx *sql.Tx) error {
+		_, err := m.DB.GetGuestById(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		reservations, err := m.DB.GetReservations(ctx, tx, models.ReservationFilter{GuestID: id})
+		if err != nil {
+			return err
+		}
+
+		byId := make(map[int]models.Reservation, len(reservations))
+		for _, res := range reservations {
+			byId[res.ID] = res
+		}
+
+		moving := make(map[int]bool, len(body.ReservationIds))
+		for _, resId := range body.ReservationIds {
+			if _, ok := byId[resId]; !ok {
+				return fmt.Errorf("%w: reservation %d does not belong to guest %d", errInvalidSplit, resId, id)
+			}
+			moving[resId] = true
+		}
+
+		if len(moving) == len(reservations) {
+			return fmt.Errorf("%w: the guest would be left without reservations", errInvalidSplit)
+		}
+
+		first := byId[body.ReservationIds[0]]
+		emailKey, phoneKey := guests.Keys(first.Email, first.Phone, m.App.PhoneCountryCode)
+		guest := newGuest(first, emailKey, phoneKey)
+
+		guest.ID, err = m.DB.InsertGuest(ctx, tx, guest)
+		if err != nil {
+			return err
+		}
+
+		for resId := range moving {
+			err = m.moveReservationGuest(ctx, tx, byId[resId], id, guest.ID)
+			if err != nil {
+				return err
+			}
+		}
+
+		resp, err = m.guestResponse(ctx, tx, guest)
+		return err
+	})
+	if errors.Is(err, errInvalidSplit) {
+		helpers.ClientError(w, err, http.StatusBadRequest, "")
+		return
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		helpers.ClientError(w, err, http.StatusNotFound, "guest not found")
+		return
+	}
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, resp, http.StatusCreated, "guest split successfully")
+}
+
+// BackfillGuests attaches the reservations booked before guest profiles existed to one, a batch per transaction
+func (m *Repository) BackfillGuests(w http.ResponseWriter, r *http.Request) {
+	var result types.GuestBackfillResult
+
+	for {
+		linked := 0
+		err := m.DB.Transaction(withActor(r), func(ctx context.Context, tx *sql.Tx) error {
+			reservations, err := m.DB.GetReservationsWithoutGuest(ctx, tx, guestBackfillBatch)
+			if err != nil {
+				return err
+			}
+
+			for _, unlinked := range reservations {
+				before, err := m.DB.GetReservationById(ctx, tx, unlinked.ID)
+				if err != nil {
+					return err
+				}
+
+				res := before
+				err = m.attachGuest(ctx, tx, &res)
+				if err != nil {
+					return err
+				}
+
+				err = m.moveReservationGuest(ctx, tx, before, 0, res.GuestID)
+				if err != nil {
+					return err
+				}
+				linked++
+			}
+
+			return nil
+		})
+		if err != nil {
+			helpers.ClientError(w, err, http.StatusInternalServerError, "")
+			return
+		}
+
+		result.Linked += linked
+		if linked < guestBackfillBatch {
+			break
+		}
+	}
+
+	helpers.ClientResponseWriter(w, result, http.StatusOK, "guests backfilled successfully")
+}
@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/dtos"
+	"github.com/Orololuwa/go-backend-boilerplate/src/types"
+)
+
+func TestRepository_PostReservationGuest(t *testing.T){
+	var guestTests = []struct {
+		name string
+		email string
+		phone string
+		expectedStatusCode int
+		expectedGuestId int
+	}{
+		{"same email", "johndoe@gmail.com", "08011111111", http.StatusCreated, 1},
+		{"email written differently", "John.Doe+Spa@GoogleMail.com", "08011111111", http.StatusCreated, 1},
+		{"same phone in international format", "john@example.com", "+234 801 234 5678", http.StatusCreated, 1},
+		{"new guest", "someone@example.com", "08099999999", http.StatusCreated, 3},
+		{"failed guest lookup", "fail@example.com", "08011111111", http.StatusInternalServerError, 0},
+	}
+
+	for _, e := range guestTests {
+		body := dtos.ReservationBody{
+			FirstName: "John",
+			LastName: "Doe",
+			Email: e.email,
+			Phone: e.phone,
+			StartDate: "2050-01-06",
+			EndDate: "2050-01-09",
+			RoomId: 15,
+			Adults: 2,
+		}
+		jsonBody, _ := json.Marshal(body)
+
+		req, _ := http.NewRequest("POST", "/reservation", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(Repo.PostReservation)
+		handler.ServeHTTP(rr, req)
+
+		if rr.Code != e.expectedStatusCode {
+			t.Errorf("PostReservation handler returned wrong response code for %s: got %d, wanted %d", e.name, rr.Code, e.expectedStatusCode)
+			continue
+		}
+		if rr.Code != http.StatusCreated {
+			continue
+		}
+
+		var resp struct {
+			Data types.ReservationResponse `json:"data"`
+		}
+		_ = json.Unmarshal(rr.Body.Bytes(), &resp)
+
+		if resp.Data.GuestId != e.expectedGuestId {
+			t.Errorf("PostReservation handler attached %s to guest %d, wanted %d", e.name, resp.Data.GuestId, e.expectedGuestId)
+		}
+	}
+}
+
+func TestRepository_GetGuests(t *testing.T){
+	var guestsTests = []struct {
+		name string
+		url string
+		expectedStatusCode int
+	}{
+		{"success", "/admin/guest?q=doe", http.StatusOK},
+		{"failed search", "/admin/guest?q=fail", http.StatusInternalServerError},
+	}
+
+	for _, e := range guestsTests {
+		req, _ := http.NewRequest("GET", e.url, nil)
+		res := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(Repo.GetGuests)
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("GetGuests handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+}
+
+func TestRepository_GetGuest(t *testing.T){
+	var guestTests = []struct {
+		name string
+		url string
+		expectedStatusCode int
+	}{
+		{"success", "/admin/guest/1", http.StatusOK},
+		{"guest not found", "/admin/guest/404", http.StatusNotFound},
+		{"failed lookup", "/admin/guest/1000", http.StatusInternalServerError},
+		{"invalid id", "/admin/guest/invalid", http.StatusBadRequest},
+	}
+
+	for _, e := range guestTests {
+		req, _ := http.NewRequest("GET", e.url, nil)
+		res := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(Repo.GetGuest)
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("GetGuest handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+			continue
+		}
+		if res.Code != http.StatusOK {
+			continue
+		}
+
+		var resp struct {
+			Data types.GuestResponse `json:"data"`
+		}
+		_ = json.Unmarshal(res.Body.Bytes(), &resp)
+
+		// reservation 7 is a confirmed stay of two nights, reservation 8 is still pending
+		stats := resp.Data.Stats
+		if len(resp.Data.Reservations) != 2 || stats.Stays != 1 || stats.Nights != 2 || stats.Spend["USD"] != 20000 {
+			t.Errorf("GetGuest handler returned %d reservations, %d stays, %d nights and a spend of %v, wanted 2, 1, 2 and 20000 USD", len(resp.Data.Reservations), stats.Stays, stats.Nights, stats.Spend)
+		}
+	}
+}
+
+func TestRepository_MergeGuests(t *testing.T){
+	var mergeTests = []struct {
+		name string
+		url string
+		body string
+		expectedStatusCode int
+	}{
+		{"success", "/admin/guest/1/merge", `{"guestId":2}`, http.StatusOK},
+		{"into itself", "/admin/guest/1/merge", `{"guestId":1}`, http.StatusBadRequest},
+		{"target not found", "/admin/guest/404/merge", `{"guestId":2}`, http.StatusNotFound},
+		{"source not found", "/admin/guest/1/merge", `{"guestId":404}`, http.StatusNotFound},
+		{"missing guest", "/admin/guest/1/merge", `{}`, http.StatusBadRequest},
+		{"invalid id", "/admin/guest/invalid/merge", `{"guestId":2}`, http.StatusBadRequest},
+	}
+
+	for _, e := range mergeTests {
+		req, _ := http.NewRequest("POST", e.url, bytes.NewBufferString(e.body))
+		req.Header.Set("Content-Type", "application/json")
+		res := httptest.NewRecorder()
+
+		handler := mdTest.ValidateReqBody(http.HandlerFunc(Repo.MergeGuests), &dtos.GuestMergeBody{})
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("MergeGuests handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+}
+
+func TestRepository_SplitGuest(t *testing.T){
+	var splitTests = []struct {
+		name string
+		url string
+		body string
+		expectedStatusCode int
+	}{
+		{"success", "/admin/guest/1/split", `{"reservationIds":[8]}`, http.StatusCreated},
+		{"every reservation", "/admin/guest/1/split", `{"reservationIds":[7,8]}`, http.StatusBadRequest},
+		{"reservation of another guest", "/admin/guest/1/split", `{"reservationIds":[9]}`, http.StatusBadRequest},
+		{"no reservations", "/admin/guest/1/split", `{"reservationIds":[]}`, http.StatusBadRequest},
+		{"guest not found", "/admin/guest/404/split", `{"reservationIds":[8]}`, http.StatusNotFound},
+	}
+
+	for _, e := range splitTests {
+		req, _ := http.NewRequest("POST", e.url, bytes.NewBufferString(e.body))
+		req.Header.Set("Content-Type", "application/json")
+		res := httptest.NewRecorder()
+
+		handler := mdTest.ValidateReqBody(http.HandlerFunc(Repo.SplitGuest), &dtos.GuestSplitBody{})
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("SplitGuest handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+			continue
+		}
+		if res.Code != http.StatusCreated {
+			continue
+		}
+
+		var resp struct {
+			Data types.GuestResponse `json:"data"`
+		}
+		_ = json.Unmarshal(res.Body.Bytes(), &resp)
+
+		if resp.Data.ID != 3 || resp.Data.FirstName != "Jane" || resp.Data.EmailKey != "janedoe@gmail.com" {
+			t.Errorf("SplitGuest handler created guest %d, %s with the email key %s, wanted guest 3, Jane with janedoe@gmail.com", resp.Data.ID, resp.Data.FirstName, resp.Data.EmailKey)
+		}
+	}
+}
+
+func TestRepository_BackfillGuests(t *testing.T){
+	req, _ := http.NewRequest("POST", "/admin/guest/backfill", nil)
+	res := httptest.NewRecorder()
+
+	handler := http.HandlerFunc(Repo.BackfillGuests)
+	handler.ServeHTTP(res, req)
+
+	if res.Code != http.StatusOK {
+		t.Fatalf("BackfillGuests handler returned wrong response code: got %d, wanted %d", res.Code, http.StatusOK)
+	}
+
+	var resp struct {
+		Data types.GuestBackfillResult `json:"data"`
+	}
+	_ = json.Unmarshal(res.Body.Bytes(), &resp)
+
+	if resp.Data.Linked != 1 {
+		t.Errorf("BackfillGuests handler linked %d reservations, wanted 1", resp.Data.Linked)
+	}
+}
+
+func TestRepository_GuestHistoryFails(t *testing.T){
+	// moving a reservation to another guest is recorded in the same transaction, so failing to record it fails the move
+	var historyTests = []struct {
+		name string
+		url string
+		body string
+		handler http.Handler
+	}{
+		{"merge", "/admin/guest/2/merge", `{"guestId":1}`, mdTest.ValidateReqBody(http.HandlerFunc(Repo.MergeGuests), &dtos.GuestMergeBody{})},
+		{"split", "/admin/guest/1/split", `{"reservationIds":[8]}`, mdTest.ValidateReqBody(http.HandlerFunc(Repo.SplitGuest), &dtos.GuestSplitBody{})},
+		{"backfill", "/admin/guest/backfill", ``, http.HandlerFunc(Repo.BackfillGuests)},
+	}
+
+	for _, e := range historyTests {
+		req, _ := http.NewRequest("POST", e.url, bytes.NewBufferString(e.body))
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(context.WithValue(req.Context(), "claims", &types.JWTClaims{Email: "broken@example.com"}))
+		res := httptest.NewRecorder()
+
+		e.handler.ServeHTTP(res, req)
+
+		if res.Code != http.StatusInternalServerError {
+			t.Errorf("%s returned %d when the history could not be written, wanted %d", e.name, res.Code, http.StatusInternalServerError)
+		}
+	}
+}
@@ -11,14 +11,22 @@ import (
 	"strings"
 	"time"
 
+	"github.com/Orololuwa/go-backend-boilerplate/src/calendarsync"
 	"github.com/Orololuwa/go-backend-boilerplate/src/config"
 	"github.com/Orololuwa/go-backend-boilerplate/src/driver"
 	"github.com/Orololuwa/go-backend-boilerplate/src/dtos"
 	"github.com/Orololuwa/go-backend-boilerplate/src/helpers"
 	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+	"github.com/Orololuwa/go-backend-boilerplate/src/notifications"
+	"github.com/Orololuwa/go-backend-boilerplate/src/occupancy"
+	"github.com/Orololuwa/go-backend-boilerplate/src/payments"
+	"github.com/Orololuwa/go-backend-boilerplate/src/pricing"
+	"github.com/Orololuwa/go-backend-boilerplate/src/promo"
 	"github.com/Orololuwa/go-backend-boilerplate/src/repository"
+	"github.com/Orololuwa/go-backend-boilerplate/src/stayrules"
 	dbrepo "github.com/Orololuwa/go-backend-boilerplate/src/repository/db-repo"
 	"github.com/Orololuwa/go-backend-boilerplate/src/types"
+	"github.com/Orololuwa/go-backend-boilerplate/src/waitlist"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
 )
@@ -27,25 +35,48 @@ type Repository struct {
 	App *config.AppConfig
 	DB repository.DatabaseRepo
 	User repository.UserDBRepo
+	Payment repository.PaymentDBRepo
+	Gateway payments.PaymentGateway
+	Waitlist *waitlist.Service
+	CalendarSync *calendarsync.Service
 }
 
 var Repo *Repository
 
+var (
+	errRoomUnavailable = errors.New("room is not available for the selected dates")
+	errHoldExpired = errors.New("room hold has expired")
+	errHoldMismatch = errors.New("room hold does not match the reservation")
+	errPriceChanged = errors.New("the price has changed since it was quoted")
+)
+
 // NewRepo function initializes the Repo
-func NewRepo(a *config.AppConfig, db *driver.DB) *Repository {
+func NewRepo(a *config.AppConfig, db *driver.DB, gateway payments.PaymentGateway) *Repository {
+	dbRepo := dbrepo.NewPostgresDBRepo(db.SQL)
+
 	return &Repository{
 		App: a,
-		DB: dbrepo.NewPostgresDBRepo(db.SQL),
+		DB: dbRepo,
 		User: dbrepo.NewUserDBRepo(db.SQL),		
+		Payment: dbrepo.NewPaymentDBRepo(db.SQL),
+		Gateway: gateway,
+		Waitlist: waitlist.New(a, dbRepo, notifications.NewLogNotifier(a.InfoLog)),
+		CalendarSync: calendarsync.New(a, dbRepo),
 	}
 }
 
 // NewRepo function initializes the Repo
 func NewTestRepo(a *config.AppConfig) *Repository {
+	dbRepo := dbrepo.NewTestingDBRepo()
+
 	return &Repository{
 		App: a,
-		DB: dbrepo.NewTestingDBRepo(),
+		DB: dbRepo,
 		User: dbrepo.NewUserTestingDBRepo(),
+		Payment: dbrepo.NewPaymentTestingDBRepo(),
+		Gateway: payments.NewFakeGateway(a.PaymentWebhookSecret),
+		Waitlist: waitlist.New(a, dbRepo, notifications.NewMemoryNotifier()),
+		CalendarSync: calendarsync.New(a, dbRepo),
 	}
 }
 
@@ -53,6 +84,43 @@ func NewHandlers(r *Repository){
 	Repo = r;
 }
 
+// bookingError maps the errors returned while booking rooms inside a transaction to a client response
+func bookingError(w http.ResponseWriter, err error) {
+	var violationErr *stayrules.ViolationError
+	var promoErr *promo.Error
+
+	switch {
+	case errors.As(err, &violationErr):
+		helpers.ClientError(w, violationErr, http.StatusBadRequest, err.Error())
+	case errors.As(err, &promoErr) && promoErr.UsedUp():
+		helpers.ClientError(w, promoErr, http.StatusConflict, err.Error())
+	case errors.As(err, &promoErr):
+		helpers.ClientError(w, promoErr, http.StatusBadRequest, err.Error())
+	case errors.Is(err, sql.ErrNoRows):
+		helpers.ClientError(w, err, http.StatusNotFound, "room or hold not found")
+	case errors.Is(err, errRoomUnavailable), errors.Is(err, errHoldExpired), errors.Is(err, errPriceChanged):
+		helpers.ClientError(w, err, http.StatusConflict, "")
+	case errors.Is(err, errHoldMismatch), errors.Is(err, errOverOccupancy), errors.Is(err, errNoExchangeRate):
+		helpers.ClientError(w, err, http.StatusBadRequest, "")
+	default:
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+	}
+}
+
+// urlParamInt reads an integer path parameter. Tests call the handlers without the chi router,
+// so in the test environment the value is read from its position in the URL path instead
+func (m *Repository) urlParamInt(r *http.Request, key string, position int) (int, error) {
+	if m.App.GoEnv == "test" {
+		exploded := strings.Split(r.URL.Path, "/")
+		if position >= len(exploded) {
+			return 0, errors.New("missing URL param")
+		}
+		return strconv.Atoi(exploded[position])
+	}
+
+	return strconv.Atoi(chi.URLParam(r, key))
+}
+
 type jsonResponse struct {
 	Message string `json:"message"`
 	Data interface{} `json:"data"`
@@ -114,12 +182,23 @@ func (m *Repository) PostReservation(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !endDate.After(startDate) {
+		helpers.ClientError(w, pricing.ErrInvalidStay, http.StatusBadRequest, "")
+		return
+	}
+
 	// roomId, err := strconv.Atoi(body.RoomId)
 	// if err != nil {
 	// 	helpers.ClientError(w, err, http.StatusInternalServerError, "")
 	// 	return
 	// }
 
+	// bookings made before guests were counted are for a single adult
+	adults := body.Adults
+	if adults == 0 {
+		adults = 1
+	}
+
 	reservation := models.Reservation {
 		FirstName: body.FirstName,
 		LastName: body.LastName,
@@ -128,22 +207,143 @@ func (m *Repository) PostReservation(w http.ResponseWriter, r *http.Request) {
 		StartDate: startDate,
 		EndDate: endDate,
 		RoomID: body.RoomId,
+		Adults: adults,
+		Children: body.Children,
+		SpecialRequests: strings.TrimSpace(body.SpecialRequests),
+		Status: models.ReservationStatusPending,
 	}
 
-	ctx := context.Background()
+	ctx := withActor(r)
 
 	err = m.DB.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		err := m.DB.LockRoom(ctx, tx, body.RoomId)
+		if err != nil {
+			return err
+		}
+
+		// a hold already reserves the room for this guest, so release it before checking availability
+		if body.HoldToken != "" {
+			hold, err := m.DB.GetRoomHoldByToken(ctx, tx, body.HoldToken)
+			if err != nil {
+				return err
+			}
+
+			if !hold.ExpiresAt.After(time.Now()) {
+				return errHoldExpired
+			}
+
+			if hold.RoomID != body.RoomId || !hold.StartDate.Equal(startDate) || !hold.EndDate.Equal(endDate) {
+				return errHoldMismatch
+			}
+
+			err = m.DB.DeleteRoomRestriction(ctx, tx, hold.ID)
+			if err != nil {
+				return err
+			}
+
+			// the hold may be a waitlist offer, booking with it accepts the offer
+			err = m.DB.AcceptWaitlistOffer(ctx, tx, body.HoldToken)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = m.checkOccupancy(ctx, tx, body.RoomId, reservation.Guests())
+		if err != nil {
+			return err
+		}
+
+		err = m.checkStayRules(ctx, tx, body.RoomId, startDate, endDate)
+		if err != nil {
+			return err
+		}
+
+		available, err := m.DB.SearchAvailabilityForDatesByRoomId(ctx, tx, startDate, endDate, body.RoomId)
+		if err != nil {
+			return err
+		}
+
+		if !available {
+			return errRoomUnavailable
+		}
+
+		quote, err := m.quoteRoom(ctx, tx, body.RoomId, startDate, endDate)
+		if err != nil {
+			return err
+		}
+
+		x, err := m.roomExchange(ctx, tx, body.RoomId, body.Currency)
+		if err != nil {
+			return err
+		}
+		x.stamp(&reservation)
+
+		quote = x.quote(quote)
+		reservation.TotalPrice = quote.Total
+
+		promoCodeId := 0
+		if body.PromoCode != "" {
+			reservation.PromoCode = body.PromoCode
+			promoCodeId, err = m.applyPromo(ctx, tx, &reservation, quote)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = m.applyCharges(ctx, tx, &reservation, len(quote.Nights))
+		if err != nil {
+			return err
+		}
+
+		// a client that shows the guest a quote can ask us to refuse the booking if the price moved since
+		if body.QuotedTotal != 0 && body.QuotedTotal != reservation.TotalPrice {
+			return errPriceChanged
+		}
+
+		reservation.CancellationPolicy, err = m.stayCancellationPolicy(ctx, tx, body.RoomId, quote)
+		if err != nil {
+			return err
+		}
+
+		reservation.ConfirmationCode, err = m.newConfirmationCode(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		err = m.attachGuest(ctx, tx, &reservation)
+		if err != nil {
+			return err
+		}
+
 		newReservationId, err := m.DB.InsertReservation(ctx, tx, reservation)
 		if err != nil {
             return err
         }
+		reservation.ID = newReservationId
+
+		err = m.saveCharges(ctx, tx, reservation)
+		if err != nil {
+			return err
+		}
+
+		if promoCodeId != 0 {
+			err = m.redeemPromo(ctx, tx, promoCodeId, reservation)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = m.recordHistory(ctx, tx, models.HistoryCreated, nil, reservation)
+		if err != nil {
+			return err
+		}
 	
 		restriction := models.RoomRestriction{
 			StartDate: startDate,
 			EndDate: endDate,
 			RoomID: body.RoomId,
 			ReservationID: newReservationId,
-			RestrictionID: 1,
+			RestrictionID: models.RestrictionReservation,
 		}
 	
 		err = m.DB.InsertRoomRestriction(ctx, tx, restriction)
@@ -154,15 +354,12 @@ func (m *Repository) PostReservation(w http.ResponseWriter, r *http.Request) {
 		return nil
 	})
 
-
 	if err != nil {
-		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		bookingError(w, err)
 		return
 	}
 
-
-
-	helpers.ClientResponseWriter(w, nil, http.StatusCreated, "reservation booked successfully")
+	helpers.ClientResponseWriter(w, reservationResponse(reservation), http.StatusCreated, "reservation booked successfully")
 }
 
 func (m *Repository) SearchAvailability(w http.ResponseWriter, r *http.Request) {
@@ -196,6 +393,11 @@ func (m *Repository) SearchAvailability(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if body.Nights > 0 {
+		m.flexibleSearch(w, body, startDate, endDate)
+		return
+	}
+
 	ctx := context.Background()
 
 	rooms, err := m.DB.SearchAvailabilityForAllRooms(ctx, nil, startDate, endDate)
@@ -204,7 +406,75 @@ func (m *Repository) SearchAvailability(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	helpers.ClientResponseWriter(w, rooms, http.StatusFound, "rooms retrieved successfully")
+	rules, err := m.DB.GetStayRulesForDates(ctx, nil, 0, startDate, endDate)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	allRooms, err := m.DB.GetAllRooms(ctx, nil, 0, "", "", "")
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	data := types.AvailabilityResponse{
+		Rooms: make([]models.Room, 0, len(rooms)),
+		Excluded: make([]types.ExcludedRoom, 0),
+		Combinations: make([][]models.Room, 0),
+		Alternatives: make([]types.AlternativeDates, 0),
+	}
+
+	available := make(map[int]bool, len(rooms))
+	bookable := make([]models.Room, 0, len(rooms))
+	guests := body.Adults + body.Children
+	today := time.Now()
+
+	for _, room := range rooms {
+		available[room.ID] = true
+
+		violations := stayrules.Check(rules, room.ID, startDate, endDate, today)
+		if len(violations) == 0 {
+			bookable = append(bookable, room)
+		}
+		if !room.Sleeps(guests) {
+			violations = append(violations, occupancyReason(room))
+		}
+
+		if len(violations) > 0 {
+			data.Excluded = append(data.Excluded, types.ExcludedRoom{RoomId: room.ID, RoomName: room.RoomName, Reasons: violations})
+			continue
+		}
+		data.Rooms = append(data.Rooms, room)
+	}
+
+	// a party too large for any single room may still fit in several
+	if len(data.Rooms) == 0 {
+		data.Combinations = occupancy.Combinations(bookable, guests, maxCombinations)
+	}
+
+	for _, room := range allRooms {
+		if !available[room.ID] {
+			data.Excluded = append(data.Excluded, types.ExcludedRoom{RoomId: room.ID, RoomName: room.RoomName, Reasons: []stayrules.Violation{unavailableReason}})
+		}
+	}
+
+	// nearby dates are always suggested when the guest asks for them, and when nothing fits the dates they gave
+	flexDays := body.FlexDays
+	if flexDays == 0 && len(data.Rooms) == 0 && len(data.Combinations) == 0 {
+		flexDays = defaultFlexDays
+	}
+
+	nights := int(endDate.Sub(startDate).Hours() / 24)
+	if flexDays > 0 && nights > 0 && nights <= maxFlexibleDays {
+		data.Alternatives, err = m.alternativeStays(ctx, 0, startDate.AddDate(0, 0, -flexDays), endDate.AddDate(0, 0, flexDays), nights, guests, startDate, startDate)
+		if err != nil {
+			helpers.ClientError(w, err, http.StatusInternalServerError, "")
+			return
+		}
+	}
+
+	helpers.ClientResponseWriter(w, data, http.StatusFound, "rooms retrieved successfully")
 }
 
 func (m *Repository) SearchAvailabilityByRoomId(w http.ResponseWriter, r *http.Request){
@@ -257,7 +527,44 @@ func (m *Repository) SearchAvailabilityByRoomId(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	helpers.ClientResponseWriter(w, isRoomAvailable, http.StatusFound, "room retrieved successfully")
+	room, err := m.DB.GetRoomById(ctx, nil, id)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusNotFound, "")
+		return
+	}
+
+	rules, err := m.DB.GetStayRulesForDates(ctx, nil, id, startDate, endDate)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	data := types.RoomAvailabilityResponse{
+		Reasons: stayrules.Check(rules, id, startDate, endDate, time.Now()),
+		NearestWindows: make([]types.DateRange, 0),
+	}
+	if !room.Sleeps(body.Adults + body.Children) {
+		data.Reasons = append(data.Reasons, occupancyReason(room))
+	}
+	if !isRoomAvailable {
+		data.Reasons = append([]stayrules.Violation{unavailableReason}, data.Reasons...)
+	}
+	data.Available = len(data.Reasons) == 0
+
+	nights := int(endDate.Sub(startDate).Hours() / 24)
+	if !data.Available && nights > 0 && nights <= maxFlexibleDays {
+		alternatives, err := m.alternativeStays(ctx, id, startDate.AddDate(0, 0, -nearestWindowDays), endDate.AddDate(0, 0, nearestWindowDays), nights, body.Adults + body.Children, startDate, startDate)
+		if err != nil {
+			helpers.ClientError(w, err, http.StatusInternalServerError, "")
+			return
+		}
+
+		for _, a := range alternatives {
+			data.NearestWindows = append(data.NearestWindows, types.DateRange{StartDate: a.StartDate, EndDate: a.EndDate})
+		}
+	}
+
+	helpers.ClientResponseWriter(w, data, http.StatusFound, "room retrieved successfully")
 
 }
 
@@ -288,7 +595,8 @@ func (m *Repository) GetRoomById(w http.ResponseWriter, r *http.Request){
 	helpers.ClientResponseWriter(w, room, http.StatusOK, "room retrieved successfully")
 }
 
-func (m *Repository) GetAllRooms(w http.ResponseWriter, r *http.Request){
+// roomFilter reads the id and room_name query filters of the room list
+func roomFilter(r *http.Request) (int, string, error) {
 	var room_name string
 	var id int
 
@@ -297,12 +605,21 @@ func (m *Repository) GetAllRooms(w http.ResponseWriter, r *http.Request){
 	if r.URL.Query().Has("id"){
 		paramId, err := strconv.Atoi(r.URL.Query().Get("id"))
 		if err != nil {
-			helpers.ClientError(w, err, http.StatusNotFound, "")
-			return
+			return 0, room_name, err
 		}
 		id = paramId
 	}
 
+	return id, room_name, nil
+}
+
+func (m *Repository) GetAllRooms(w http.ResponseWriter, r *http.Request){
+	id, room_name, err := roomFilter(r)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusNotFound, "")
+		return
+	}
+
 	rooms, err := m.DB.GetAllRooms(context.Background(), nil, id, room_name, "", "")
 	if err != nil {
 		helpers.ClientError(w, err, http.StatusNotFound, "")
@@ -61,6 +61,8 @@ func TestRepository_PostReservation(t *testing.T){
     if err != nil {
         t.Log(err)
     }
+	body.StartDate = "2050-01-01"
+	body.EndDate = "2050-01-05"
 
     jsonBody, err := json.Marshal(body)
     if err != nil {
@@ -201,6 +203,8 @@ func TestRepository_PostReservation(t *testing.T){
     if err != nil {
         t.Log(err)
     }
+	body.StartDate = "2050-01-01"
+	body.EndDate = "2050-01-05"
 	body.RoomId = 2
 
     jsonBody, err = json.Marshal(body)
@@ -225,6 +229,8 @@ func TestRepository_PostReservation(t *testing.T){
     if err != nil {
         t.Log(err)
     }
+	body.StartDate = "2050-01-01"
+	body.EndDate = "2050-01-05"
 	body.RoomId = 1000
 
     jsonBody, err = json.Marshal(body)
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/helpers"
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+	"github.com/Orololuwa/go-backend-boilerplate/src/types"
+)
+
+// requestActor names who made the request in the history: the email of signed in staff, a guest otherwise
+func requestActor(r *http.Request) string {
+	claims, ok := r.Context().Value("claims").(*types.JWTClaims)
+	if ok && claims != nil && claims.Email != "" {
+		return claims.Email
+	}
+	return models.HistoryActorGuest
+}
+
+// withActor is the context of the changes a request makes, it carries the actor to the history.
+// Changes made without it, such as by payment webhooks, are recorded as made by the system
+func withActor(r *http.Request) context.Context {
+	return context.WithValue(context.Background(), "historyActor", requestActor(r))
+}
+
+func actorFrom(ctx context.Context) string {
+	if actor, ok := ctx.Value("historyActor").(string); ok {
+		return actor
+	}
+	return models.HistoryActorSystem
+}
+
+// recordHistory records a change to a reservation in the transaction of the change, so the history never disagrees
+// with the reservation. before is nil when the reservation was just created
+func (m *Repository) recordHistory(ctx context.Context, tx *sql.Tx, action string, before *models.Reservation, after models.Reservation) error {
+	h := models.ReservationHistory{
+		ReservationID: after.ID,
+		Action: action,
+		Actor: actorFrom(ctx),
+	}
+
+	var err error
+	if before != nil {
+		h.Before, err = json.Marshal(reservationResponse(*before))
+		if err != nil {
+			return err
+		}
+	}
+
+	h.After, err = json.Marshal(reservationResponse(after))
+	if err != nil {
+		return err
+	}
+
+	_, err = m.DB.InsertReservationHistory(ctx, tx, h)
+	return err
+}
+
+// updateReservationStatus moves the reservation to the status and records the change
+func (m *Repository) updateReservationStatus(ctx context.Context, tx *sql.Tx, id int, status string) error {
+	before, err := m.DB.GetReservationById(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	if before.Status == status {
+		return nil
+	}
+
+	err = m.DB.UpdateReservationStatus(ctx, tx, id, status)
+	if err != nil {
+		return err
+	}
+
+	after := before
+	after.Status = status
+	return m.recordHistory(ctx, tx, models.HistoryStatusChanged, &before, after)
+}
+
+// GetReservationHistory lists every change made to a reservation, oldest first
+func (m *Repository) GetReservationHistory(w http.ResponseWriter, r *http.Request) {
+	id, err := m.urlParamInt(r, "id", 2)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	ctx := context.Background()
+
+	_, err = m.DB.GetReservationById(ctx, nil, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		helpers.ClientError(w, err, http.StatusNotFound, "reservation not found")
+		return
+	}
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	history, err := m.DB.GetReservationHistory(ctx, nil, id)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, history, http.StatusOK, "reservation history retrieved successfully")
+}
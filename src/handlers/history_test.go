@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/dtos"
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+	"github.com/Orololuwa/go-backend-boilerplate/src/types"
+)
+
+func TestRepository_GetReservationHistory(t *testing.T){
+	var historyTests = []struct {
+		name string
+		url string
+		expectedStatusCode int
+	}{
+		{"success", "/reservation/1/history", http.StatusOK},
+		{"reservation not found", "/reservation/404/history", http.StatusNotFound},
+		{"failed reservation lookup", "/reservation/1000/history", http.StatusInternalServerError},
+		{"failed history lookup", "/reservation/500/history", http.StatusInternalServerError},
+		{"invalid id", "/reservation/invalid/history", http.StatusBadRequest},
+	}
+
+	for _, e := range historyTests {
+		req, _ := http.NewRequest("GET", e.url, nil)
+		res := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(Repo.GetReservationHistory)
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("GetReservationHistory handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+
+	req, _ := http.NewRequest("GET", "/reservation/1/history", nil)
+	res := httptest.NewRecorder()
+
+	http.HandlerFunc(Repo.GetReservationHistory).ServeHTTP(res, req)
+
+	var resp struct {
+		Data []models.ReservationHistory `json:"data"`
+	}
+	err := json.Unmarshal(res.Body.Bytes(), &resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(resp.Data) != 2 || resp.Data[0].Action != models.HistoryCreated || string(resp.Data[0].Before) != "null" {
+		t.Errorf("GetReservationHistory handler returned the wrong history: %+v", resp.Data)
+	}
+}
+
+func TestRequestActor(t *testing.T){
+	req, _ := http.NewRequest("POST", "/reservation/1/cancel", nil)
+	if actor := actorFrom(withActor(req)); actor != models.HistoryActorGuest {
+		t.Errorf("a request without claims was made by %q, wanted %q", actor, models.HistoryActorGuest)
+	}
+
+	req = req.WithContext(context.WithValue(req.Context(), "claims", &types.JWTClaims{Email: "staff@example.com"}))
+	if actor := actorFrom(withActor(req)); actor != "staff@example.com" {
+		t.Errorf("a request from signed in staff was made by %q, wanted their email", actor)
+	}
+
+	if actor := actorFrom(context.Background()); actor != models.HistoryActorSystem {
+		t.Errorf("a change outside of a request was made by %q, wanted %q", actor, models.HistoryActorSystem)
+	}
+}
+
+func TestRepository_CancelReservationHistoryFails(t *testing.T){
+	// the history is written in the transaction of the cancellation, so failing to record it fails the cancellation
+	jsonData, _ := json.Marshal(dtos.CancelBody{ConfirmationCode: "ABCD2345"})
+
+	req, _ := http.NewRequest("POST", "/reservation/1/cancel", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	req = req.WithContext(context.WithValue(req.Context(), "claims", &types.JWTClaims{Email: "broken@example.com"}))
+	res := httptest.NewRecorder()
+
+	handler := mdTest.ValidateReqBody(http.HandlerFunc(Repo.CancelReservation), &dtos.CancelBody{})
+	handler.ServeHTTP(res, req)
+
+	if res.Code != http.StatusInternalServerError {
+		t.Errorf("CancelReservation handler returned %d when the history could not be written, wanted %d", res.Code, http.StatusInternalServerError)
+	}
+}
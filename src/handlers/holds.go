@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/dtos"
+	"github.com/Orololuwa/go-backend-boilerplate/src/helpers"
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+	"github.com/Orololuwa/go-backend-boilerplate/src/stayrules"
+	"github.com/Orololuwa/go-backend-boilerplate/src/types"
+)
+
+const defaultHoldDuration = 10 * time.Minute
+
+func (m *Repository) holdDuration() time.Duration {
+	if m.App.HoldDuration > 0 {
+		return m.App.HoldDuration
+	}
+
+	return defaultHoldDuration
+}
+
+// PostRoomHold blocks a room for a short while so the guest can finish checkout without losing it
+func (m *Repository) PostRoomHold(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var body dtos.PostHoldBody
+	requestBody, ok := r.Context().Value("validatedRequestBody").(*dtos.PostHoldBody)
+	if !ok || requestBody == nil {
+		helpers.ClientError(w, errors.New("failed to retrieve request body"), http.StatusBadRequest, "")
+		return
+	}
+	body = *requestBody
+
+	layout := "2006-01-02"
+
+	startDate, err := time.Parse(layout, body.StartDate)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "")
+		return
+	}
+
+	endDate, err := time.Parse(layout, body.EndDate)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "")
+		return
+	}
+
+	if !endDate.After(startDate) {
+		helpers.ClientError(w, errors.New("endDate must be after startDate"), http.StatusBadRequest, "")
+		return
+	}
+
+	token, err := helpers.RandomToken(16)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	hold := models.RoomRestriction{
+		StartDate: startDate,
+		EndDate: endDate,
+		RoomID: body.RoomId,
+		RestrictionID: models.RestrictionHold,
+		HoldToken: token,
+		ExpiresAt: time.Now().Add(m.holdDuration()),
+	}
+
+	ctx := context.Background()
+
+	err = m.DB.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		err := m.DB.LockRoom(ctx, tx, body.RoomId)
+		if err != nil {
+			return err
+		}
+
+		err = m.checkStayRules(ctx, tx, body.RoomId, startDate, endDate)
+		if err != nil {
+			return err
+		}
+
+		available, err := m.DB.SearchAvailabilityForDatesByRoomId(ctx, tx, startDate, endDate, body.RoomId)
+		if err != nil {
+			return err
+		}
+
+		if !available {
+			return errRoomUnavailable
+		}
+
+		hold.ID, err = m.DB.InsertRoomHold(ctx, tx, hold)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		var violationErr *stayrules.ViolationError
+
+		switch {
+		case errors.As(err, &violationErr):
+			helpers.ClientError(w, violationErr, http.StatusBadRequest, "")
+		case errors.Is(err, sql.ErrNoRows):
+			helpers.ClientError(w, err, http.StatusNotFound, "room not found")
+		case errors.Is(err, errRoomUnavailable):
+			helpers.ClientError(w, err, http.StatusConflict, "")
+		default:
+			helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		}
+		return
+	}
+
+	data := types.RoomHoldResponse{
+		HoldToken: hold.HoldToken,
+		RoomId: hold.RoomID,
+		StartDate: body.StartDate,
+		EndDate: body.EndDate,
+		ExpiresAt: hold.ExpiresAt,
+	}
+
+	helpers.ClientResponseWriter(w, data, http.StatusCreated, "room held successfully")
+}
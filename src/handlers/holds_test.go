@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/dtos"
+)
+
+func TestRepository_PostRoomHold(t *testing.T){
+	reqBody := dtos.PostHoldBody{
+		StartDate: "2050-01-01",
+		EndDate: "2050-01-05",
+		RoomId: 15,
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		t.Log("Error:", err)
+		return
+	}
+
+	// test if I try to call a method other than POST
+	req, _ := http.NewRequest("PUT", "/reservation/hold", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	res := httptest.NewRecorder()
+
+	handler := mdTest.ValidateReqBody(http.HandlerFunc(Repo.PostRoomHold), &dtos.PostHoldBody{})
+	handler.ServeHTTP(res, req)
+
+	if res.Code != http.StatusMethodNotAllowed {
+		t.Errorf("PostRoomHold handler returned wrong response code for wrong http method: got %d, wanted %d", res.Code, http.StatusMethodNotAllowed)
+	}
+
+	// test for the right request body
+	req, _ = http.NewRequest("POST", "/reservation/hold", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	res = httptest.NewRecorder()
+
+	handler = mdTest.ValidateReqBody(http.HandlerFunc(Repo.PostRoomHold), &dtos.PostHoldBody{})
+	handler.ServeHTTP(res, req)
+
+	if res.Code != http.StatusCreated {
+		t.Errorf("PostRoomHold handler returned wrong response code: got %d, wanted %d", res.Code, http.StatusCreated)
+	}
+
+	// test for missing request body data in the context
+	req, _ = http.NewRequest("POST", "/reservation/hold", bytes.NewBuffer([]byte(``)))
+	req.Header.Set("Content-Type", "application/json")
+	res = httptest.NewRecorder()
+
+	handler = http.HandlerFunc(Repo.PostRoomHold)
+	handler.ServeHTTP(res, req)
+
+	if res.Code != http.StatusBadRequest {
+		t.Errorf("PostRoomHold handler returned wrong response code for missing request body: got %d, wanted %d", res.Code, http.StatusBadRequest)
+	}
+
+	var holdTests = []struct {
+		name string
+		body dtos.PostHoldBody
+		expectedStatusCode int
+	}{
+		{"invalid startDate", dtos.PostHoldBody{StartDate: "invalid", EndDate: "2050-01-05", RoomId: 15}, http.StatusBadRequest},
+		{"invalid endDate", dtos.PostHoldBody{StartDate: "2050-01-01", EndDate: "invalid", RoomId: 15}, http.StatusBadRequest},
+		{"endDate before startDate", dtos.PostHoldBody{StartDate: "2050-01-05", EndDate: "2050-01-01", RoomId: 15}, http.StatusBadRequest},
+		{"room not found", dtos.PostHoldBody{StartDate: "2050-01-01", EndDate: "2050-01-05", RoomId: 404}, http.StatusNotFound},
+		{"room already booked", dtos.PostHoldBody{StartDate: "2050-01-01", EndDate: "2050-01-05", RoomId: 3}, http.StatusConflict},
+		{"failed availability search", dtos.PostHoldBody{StartDate: "2050-01-01", EndDate: "2050-01-05", RoomId: 2}, http.StatusInternalServerError},
+		{"failed hold insert", dtos.PostHoldBody{StartDate: "2050-01-01", EndDate: "2050-01-05", RoomId: 1000}, http.StatusInternalServerError},
+	}
+
+	for _, e := range holdTests {
+		jsonData, err = json.Marshal(e.body)
+		if err != nil {
+			t.Log("Error:", err)
+			return
+		}
+
+		req, _ = http.NewRequest("POST", "/reservation/hold", bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		res = httptest.NewRecorder()
+
+		handler = mdTest.ValidateReqBody(http.HandlerFunc(Repo.PostRoomHold), &dtos.PostHoldBody{})
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("PostRoomHold handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+}
+
+func TestRepository_PostReservationWithHold(t *testing.T){
+	var holdTests = []struct {
+		name string
+		holdToken string
+		roomId int
+		expectedStatusCode int
+	}{
+		{"valid hold", "valid-hold", 15, http.StatusCreated},
+		{"missing hold", "missing", 15, http.StatusNotFound},
+		{"expired hold", "expired", 15, http.StatusConflict},
+		{"hold for another room", "valid-hold", 27, http.StatusBadRequest},
+	}
+
+	for _, e := range holdTests {
+		body := dtos.ReservationBody{
+			FirstName: "John",
+			LastName: "Doe",
+			Email: "johndoe@gmail.com",
+			Phone: "08012345678",
+			StartDate: "2050-01-01",
+			EndDate: "2050-01-05",
+			RoomId: e.roomId,
+			HoldToken: e.holdToken,
+		}
+
+		jsonBody, err := json.Marshal(body)
+		if err != nil {
+			t.Log("Error:", err)
+			return
+		}
+
+		req, _ := http.NewRequest("POST", "/reservation", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(Repo.PostReservation)
+		handler.ServeHTTP(rr, req)
+
+		if rr.Code != e.expectedStatusCode {
+			t.Errorf("PostReservation handler returned wrong response code for %s: got %d, wanted %d", e.name, rr.Code, e.expectedStatusCode)
+		}
+	}
+}
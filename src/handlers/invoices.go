@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/helpers"
+	"github.com/Orololuwa/go-backend-boilerplate/src/invoices"
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+)
+
+const defaultInvoiceSeries = "INV"
+
+// errInvoiceIssued rolls back a sequence number taken while another request issued the invoice
+var errInvoiceIssued = errors.New("the invoice was issued by another request")
+
+func (m *Repository) invoiceSeries() string {
+	if m.App.InvoiceSeries != "" {
+		return m.App.InvoiceSeries
+	}
+
+	return defaultInvoiceSeries
+}
+
+// issueInvoice returns the invoice of the reservation, issuing it the first time it is asked for. The number is
+// taken in the same transaction as the invoice is stored in, so a failed insert leaves no gap in the series
+func (m *Repository) issueInvoice(ctx context.Context, res models.Reservation) (models.Invoice, error) {
+	inv, err := m.DB.GetInvoiceByReservationId(ctx, nil, res.ID)
+	if err == nil || !errors.Is(err, sql.ErrNoRows) {
+		return inv, err
+	}
+
+	err = invoices.Issuable(res, time.Now().UTC().Truncate(24 * time.Hour))
+	if err != nil {
+		return inv, err
+	}
+
+	charges, err := m.DB.GetReservationCharges(ctx, nil, res.ID)
+	if err != nil {
+		return inv, err
+	}
+
+	payments, err := m.Payment.GetPaymentsByReservationId(ctx, nil, res.ID)
+	if err != nil {
+		return inv, err
+	}
+
+	series := m.invoiceSeries()
+
+	err = m.DB.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		sequence, err := m.DB.NextInvoiceSequence(ctx, tx, series)
+		if err != nil {
+			return err
+		}
+
+		// the series row is locked now, so a request that got here first has stored its invoice
+		_, err = m.DB.GetInvoiceByReservationId(ctx, tx, res.ID)
+		if err == nil {
+			return errInvoiceIssued
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		inv = invoices.Build(res, charges, payments, series, sequence, time.Now())
+
+		inv.ID, err = m.DB.InsertInvoice(ctx, tx, inv)
+		return err
+	})
+	if errors.Is(err, errInvoiceIssued) {
+		return m.DB.GetInvoiceByReservationId(ctx, nil, res.ID)
+	}
+
+	return inv, err
+}
+
+// GetReservationInvoice serves the invoice of a stay as an HTML page, or as a PDF download with ?format=pdf.
+// The guest proves ownership with the confirmation code, like the calendar download
+func (m *Repository) GetReservationInvoice(w http.ResponseWriter, r *http.Request) {
+	id, err := m.urlParamInt(r, "id", 2)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "html" && format != "pdf" {
+		helpers.ClientError(w, errors.New("format must be html or pdf"), http.StatusBadRequest, "")
+		return
+	}
+
+	res, err := m.DB.GetReservationById(context.Background(), nil, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		helpers.ClientError(w, err, http.StatusNotFound, "reservation not found")
+		return
+	}
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	if !helpers.ConfirmationCodeMatches(res.ConfirmationCode, r.URL.Query().Get("code")) {
+		helpers.ClientError(w, sql.ErrNoRows, http.StatusNotFound, "reservation not found")
+		return
+	}
+
+	inv, err := m.issueInvoice(context.Background(), res)
+	if errors.Is(err, invoices.ErrNotIssuable) {
+		helpers.ClientError(w, err, http.StatusConflict, "")
+		return
+	}
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	// render before writing the headers so a failure is still reported as an error
+	var out bytes.Buffer
+	contentType := "text/html; charset=utf-8"
+	if format == "pdf" {
+		contentType = "application/pdf"
+		err = invoices.RenderPDF(&out, inv, m.App.PropertyName)
+	}else{
+		err = invoices.RenderHTML(&out, inv, m.App.PropertyName)
+	}
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	if format == "pdf" {
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "invoice-" + inv.InvoiceNumber + ".pdf"))
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(out.Bytes())
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRepository_GetReservationInvoice(t *testing.T){
+	var invoiceTests = []struct {
+		name string
+		url string
+		expectedStatusCode int
+		expectedType string
+		expectedNumber string
+	}{
+		{"checked out stay", "/reservation/12/invoice?code=ABCD2345", http.StatusOK, "text/html; charset=utf-8", "INV-000042"},
+		{"checked out stay as a pdf", "/reservation/12/invoice?code=ABCD2345&format=pdf", http.StatusOK, "application/pdf", "INV-000042"},
+		{"invoice already issued", "/reservation/13/invoice?code=ABCD2345", http.StatusOK, "text/html; charset=utf-8", "INV-000007"},
+		{"stay not checked out", "/reservation/1/invoice?code=ABCD2345", http.StatusConflict, "", ""},
+		{"no-show without a fee", "/reservation/11/invoice?code=ABCD2345", http.StatusConflict, "", ""},
+		{"code as the guest typed it", "/reservation/12/invoice?code=abcd-2345", http.StatusOK, "text/html; charset=utf-8", "INV-000042"},
+		{"wrong code", "/reservation/12/invoice?code=WRONG", http.StatusNotFound, "", ""},
+		{"reservation not found", "/reservation/404/invoice?code=ABCD2345", http.StatusNotFound, "", ""},
+		{"unknown format", "/reservation/12/invoice?code=ABCD2345&format=doc", http.StatusBadRequest, "", ""},
+		{"failed invoice lookup", "/reservation/500/invoice?code=ABCD2345", http.StatusInternalServerError, "", ""},
+		{"failed insert", "/reservation/14/invoice?code=ABCD2345", http.StatusInternalServerError, "", ""},
+	}
+
+	for _, e := range invoiceTests {
+		req, _ := http.NewRequest("GET", e.url, nil)
+		res := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(Repo.GetReservationInvoice)
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("GetReservationInvoice handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+			continue
+		}
+		if res.Code != http.StatusOK {
+			continue
+		}
+
+		if got := res.Header().Get("Content-Type"); got != e.expectedType {
+			t.Errorf("GetReservationInvoice handler returned the content type %q for %s, wanted %q", got, e.name, e.expectedType)
+		}
+
+		if e.expectedType == "application/pdf" {
+			if !strings.Contains(res.Header().Get("Content-Disposition"), "invoice-" + e.expectedNumber + ".pdf") {
+				t.Errorf("GetReservationInvoice handler named the download %q for %s", res.Header().Get("Content-Disposition"), e.name)
+			}
+			if !strings.HasPrefix(res.Body.String(), "%PDF-") {
+				t.Errorf("GetReservationInvoice handler did not return a PDF for %s", e.name)
+			}
+			continue
+		}
+
+		if !strings.Contains(res.Body.String(), e.expectedNumber) {
+			t.Errorf("GetReservationInvoice handler did not return invoice %s for %s:\n%s", e.expectedNumber, e.name, res.Body.String())
+		}
+	}
+}
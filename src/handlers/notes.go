@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/dtos"
+	"github.com/Orololuwa/go-backend-boilerplate/src/helpers"
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+)
+
+var errNoSpecialRequests = errors.New("reservation has no special requests")
+
+// ResolveReservationRequests marks the special requests of a reservation as seen to, the guest sees that they were
+func (m *Repository) ResolveReservationRequests(w http.ResponseWriter, r *http.Request) {
+	id, err := m.urlParamInt(r, "id", 2)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	var res models.Reservation
+	err = m.DB.Transaction(withActor(r), func(ctx context.Context, tx *sql.Tx) error {
+		before, err := m.DB.GetReservationById(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if before.SpecialRequests == "" {
+			return errNoSpecialRequests
+		}
+
+		res = before
+		if before.RequestsResolved {
+			return nil
+		}
+
+		err = m.DB.UpdateReservationRequestsResolved(ctx, tx, id, true)
+		if err != nil {
+			return err
+		}
+		res.RequestsResolved = true
+
+		return m.recordHistory(ctx, tx, models.HistoryRequestsResolved, &before, res)
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		helpers.ClientError(w, err, http.StatusNotFound, "reservation not found")
+		return
+	}
+	if errors.Is(err, errNoSpecialRequests) {
+		helpers.ClientError(w, err, http.StatusBadRequest, "")
+		return
+	}
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, reservationResponse(res), http.StatusOK, "special requests resolved successfully")
+}
+
+// GetReservationNotes lists the internal notes staff keep on a reservation
+func (m *Repository) GetReservationNotes(w http.ResponseWriter, r *http.Request) {
+	id, err := m.urlParamInt(r, "id", 2)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	ctx := context.Background()
+
+	_, err = m.DB.GetReservationById(ctx, nil, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		helpers.ClientError(w, err, http.StatusNotFound, "reservation not found")
+		return
+	}
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	notes, err := m.DB.GetReservationNotes(ctx, nil, id)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, notes, http.StatusOK, "reservation notes retrieved successfully")
+}
+
+// PostReservationNote adds an internal note to a reservation, signed by the staff member who wrote it. Notes are
+// never part of a reservation response
+func (m *Repository) PostReservationNote(w http.ResponseWriter, r *http.Request) {
+	id, err := m.urlParamInt(r, "id", 2)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	var body dtos.ReservationNoteBody
+	requestBody, ok := r.Context().Value("validatedRequestBody").(*dtos.ReservationNoteBody)
+	if !ok || requestBody == nil {
+		helpers.ClientError(w, errors.New("failed to retrieve request body"), http.StatusBadRequest, "")
+		return
+	}
+	body = *requestBody
+
+	note := models.ReservationNote{
+		ReservationID: id,
+		Author: requestActor(r),
+		Body: strings.TrimSpace(body.Body),
+		CreatedAt: time.Now(),
+	}
+
+	if note.Body == "" {
+		helpers.ClientError(w, errors.New("note is empty"), http.StatusBadRequest, "")
+		return
+	}
+
+	ctx := context.Background()
+
+	_, err = m.DB.GetReservationById(ctx, nil, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		helpers.ClientError(w, err, http.StatusNotFound, "reservation not found")
+		return
+	}
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	note.ID, err = m.DB.InsertReservationNote(ctx, nil, note)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, note, http.StatusCreated, "reservation note added successfully")
+}
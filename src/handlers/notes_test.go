@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/dtos"
+	"github.com/Orololuwa/go-backend-boilerplate/src/types"
+)
+
+func TestRepository_PostReservationSpecialRequests(t *testing.T){
+	body := dtos.ReservationBody{
+		FirstName: "John",
+		LastName: "Doe",
+		Email: "johndoe@gmail.com",
+		Phone: "08012345678",
+		StartDate: "2050-01-06",
+		EndDate: "2050-01-09",
+		RoomId: 15,
+		Adults: 2,
+		SpecialRequests: "  Late check-in, around 11pm ",
+	}
+	jsonBody, _ := json.Marshal(body)
+
+	req, _ := http.NewRequest("POST", "/reservation", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+
+	handler := http.HandlerFunc(Repo.PostReservation)
+	handler.ServeHTTP(rr, req)
+
+	var resp struct {
+		Data types.ReservationResponse `json:"data"`
+	}
+	_ = json.Unmarshal(rr.Body.Bytes(), &resp)
+
+	if rr.Code != http.StatusCreated || resp.Data.SpecialRequests != "Late check-in, around 11pm" || resp.Data.RequestsResolved {
+		t.Errorf("PostReservation handler returned %d with the special requests %q, wanted 201 with unresolved requests", rr.Code, resp.Data.SpecialRequests)
+	}
+
+	body.SpecialRequests = strings.Repeat("a", 1001)
+	jsonBody, _ = json.Marshal(body)
+
+	req, _ = http.NewRequest("POST", "/reservation", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	rr = httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("PostReservation handler returned %d for special requests that are too long, wanted %d", rr.Code, http.StatusBadRequest)
+	}
+}
+
+func TestRepository_ResolveReservationRequests(t *testing.T){
+	var resolveTests = []struct {
+		name string
+		url string
+		expectedStatusCode int
+	}{
+		{"success", "/reservation/15/special-requests/resolve", http.StatusOK},
+		{"already resolved", "/reservation/16/special-requests/resolve", http.StatusOK},
+		{"no special requests", "/reservation/1/special-requests/resolve", http.StatusBadRequest},
+		{"reservation not found", "/reservation/404/special-requests/resolve", http.StatusNotFound},
+		{"failed lookup", "/reservation/1000/special-requests/resolve", http.StatusInternalServerError},
+		{"invalid id", "/reservation/invalid/special-requests/resolve", http.StatusBadRequest},
+	}
+
+	for _, e := range resolveTests {
+		req, _ := http.NewRequest("POST", e.url, nil)
+		res := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(Repo.ResolveReservationRequests)
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("ResolveReservationRequests handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+			continue
+		}
+		if res.Code != http.StatusOK {
+			continue
+		}
+
+		var resp struct {
+			Data types.ReservationResponse `json:"data"`
+		}
+		_ = json.Unmarshal(res.Body.Bytes(), &resp)
+
+		if !resp.Data.RequestsResolved {
+			t.Errorf("ResolveReservationRequests handler did not mark the requests resolved for %s", e.name)
+		}
+	}
+}
+
+func TestRepository_GetReservationNotes(t *testing.T){
+	var notesTests = []struct {
+		name string
+		url string
+		expectedStatusCode int
+	}{
+		{"success", "/reservation/15/notes", http.StatusOK},
+		{"reservation not found", "/reservation/404/notes", http.StatusNotFound},
+		{"invalid id", "/reservation/invalid/notes", http.StatusBadRequest},
+	}
+
+	for _, e := range notesTests {
+		req, _ := http.NewRequest("GET", e.url, nil)
+		res := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(Repo.GetReservationNotes)
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("GetReservationNotes handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+			continue
+		}
+
+		// notes are kept apart from the guest's special requests
+		if strings.Contains(res.Body.String(), "Late check-in") {
+			t.Errorf("GetReservationNotes handler returned the special requests for %s: %s", e.name, res.Body.String())
+		}
+	}
+}
+
+func TestRepository_PostReservationNote(t *testing.T){
+	var noteTests = []struct {
+		name string
+		url string
+		body string
+		expectedStatusCode int
+	}{
+		{"success", "/reservation/15/notes", `{"body":"Guest called, arriving by train"}`, http.StatusCreated},
+		{"blank note", "/reservation/15/notes", `{"body":"   "}`, http.StatusBadRequest},
+		{"missing body", "/reservation/15/notes", `{}`, http.StatusBadRequest},
+		{"reservation not found", "/reservation/404/notes", `{"body":"Guest called"}`, http.StatusNotFound},
+		{"failed insert", "/reservation/15/notes", `{"body":"fail"}`, http.StatusInternalServerError},
+		{"invalid id", "/reservation/invalid/notes", `{"body":"Guest called"}`, http.StatusBadRequest},
+	}
+
+	for _, e := range noteTests {
+		req, _ := http.NewRequest("POST", e.url, bytes.NewBufferString(e.body))
+		req.Header.Set("Content-Type", "application/json")
+		res := httptest.NewRecorder()
+
+		handler := mdTest.ValidateReqBody(http.HandlerFunc(Repo.PostReservationNote), &dtos.ReservationNoteBody{})
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("PostReservationNote handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+}
+
+func TestReservationResponseLeavesOutNotes(t *testing.T){
+	// the reservation a guest looks up carries their special requests and nothing staff wrote about them
+	req, _ := http.NewRequest("POST", "/reservation/15/special-requests/resolve", nil)
+	res := httptest.NewRecorder()
+
+	handler := http.HandlerFunc(Repo.ResolveReservationRequests)
+	handler.ServeHTTP(res, req)
+
+	if !strings.Contains(res.Body.String(), "Late check-in") || strings.Contains(res.Body.String(), "upgrade") || strings.Contains(res.Body.String(), "author") {
+		t.Errorf("reservation response leaked notes or lost the special requests: %s", res.Body.String())
+	}
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/dtos"
+	"github.com/Orololuwa/go-backend-boilerplate/src/helpers"
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+	"github.com/Orololuwa/go-backend-boilerplate/src/stayrules"
+)
+
+// how many room combinations a search suggests for a party no single room sleeps
+const maxCombinations = 3
+
+var errOverOccupancy = errors.New("the party is too large for the room")
+
+// occupancyReason is reported by the searches for rooms too small for the party
+func occupancyReason(room models.Room) stayrules.Violation {
+	return stayrules.Violation{
+		Code: "max_occupancy",
+		Message: fmt.Sprintf("room sleeps at most %d guests", room.MaxOccupancy),
+	}
+}
+
+// checkOccupancy refuses to put more guests in the room than it sleeps
+func (m *Repository) checkOccupancy(ctx context.Context, tx *sql.Tx, roomId, guests int) error {
+	room, err := m.DB.GetRoomById(ctx, tx, roomId)
+	if err != nil {
+		return err
+	}
+
+	if !room.Sleeps(guests) {
+		return fmt.Errorf("%w: room %d sleeps at most %d guests", errOverOccupancy, roomId, room.MaxOccupancy)
+	}
+
+	return nil
+}
+
+// UpdateRoomOccupancy sets the most guests, adults and children together, the room sleeps
+func (m *Repository) UpdateRoomOccupancy(w http.ResponseWriter, r *http.Request) {
+	roomId, err := m.urlParamInt(r, "id", 2)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	var body dtos.RoomOccupancyBody
+	requestBody, ok := r.Context().Value("validatedRequestBody").(*dtos.RoomOccupancyBody)
+	if !ok || requestBody == nil {
+		helpers.ClientError(w, errors.New("failed to retrieve request body"), http.StatusBadRequest, "")
+		return
+	}
+	body = *requestBody
+
+	err = m.DB.UpdateRoomOccupancy(context.Background(), nil, roomId, body.MaxOccupancy)
+	if errors.Is(err, sql.ErrNoRows) {
+		helpers.ClientError(w, err, http.StatusNotFound, "room not found")
+		return
+	}
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, body, http.StatusOK, "room occupancy updated successfully")
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/dtos"
+)
+
+func TestRepository_UpdateRoomOccupancy(t *testing.T){
+	var occupancyTests = []struct {
+		name string
+		url string
+		body dtos.RoomOccupancyBody
+		expectedStatusCode int
+	}{
+		{"success", "/room/15/occupancy", dtos.RoomOccupancyBody{MaxOccupancy: 4}, http.StatusOK},
+		{"empty room", "/room/15/occupancy", dtos.RoomOccupancyBody{MaxOccupancy: 0}, http.StatusBadRequest},
+		{"room not found", "/room/404/occupancy", dtos.RoomOccupancyBody{MaxOccupancy: 4}, http.StatusNotFound},
+		{"update fails", "/room/1000/occupancy", dtos.RoomOccupancyBody{MaxOccupancy: 4}, http.StatusInternalServerError},
+	}
+
+	for _, e := range occupancyTests {
+		jsonData, _ := json.Marshal(e.body)
+
+		req, _ := http.NewRequest("PUT", e.url, bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		res := httptest.NewRecorder()
+
+		handler := mdTest.ValidateReqBody(http.HandlerFunc(Repo.UpdateRoomOccupancy), &dtos.RoomOccupancyBody{})
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("UpdateRoomOccupancy handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+}
+
+func TestRepository_PostReservationOccupancy(t *testing.T){
+	// the test rooms sleep 4
+	var reservationTests = []struct {
+		name string
+		adults int
+		children int
+		expectedStatusCode int
+	}{
+		{"guests not given", 0, 0, http.StatusCreated},
+		{"family of four", 2, 2, http.StatusCreated},
+		{"family of six", 2, 4, http.StatusBadRequest},
+	}
+
+	for _, e := range reservationTests {
+		body := dtos.ReservationBody{
+			FirstName: "John",
+			LastName: "Doe",
+			Email: "john@doe.com",
+			Phone: "08012345678",
+			StartDate: "2050-01-01",
+			EndDate: "2050-01-05",
+			RoomId: 15,
+			Adults: e.adults,
+			Children: e.children,
+		}
+		jsonData, _ := json.Marshal(body)
+
+		req, _ := http.NewRequest("POST", "/reservation", bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		res := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(Repo.PostReservation)
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("PostReservation handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+}
+
+func TestRepository_SearchAvailabilityOccupancy(t *testing.T){
+	// rooms 15 and 20 are free and sleep 2 and 4, the stay is long enough for the rules of room 20
+	_, data := searchAvailability(dtos.PostAvailabilityBody{StartDate: "2050-01-04", EndDate: "2050-01-07", Adults: 3})
+	if len(data.Rooms) != 1 || data.Rooms[0].ID != 20 {
+		t.Errorf("SearchAvailability returned rooms %+v for a party of 3, wanted only room 20", data.Rooms)
+	}
+	if len(data.Excluded) != 1 || data.Excluded[0].RoomId != 15 || data.Excluded[0].Reasons[0].Code != "max_occupancy" {
+		t.Errorf("SearchAvailability returned excluded rooms %+v for a party of 3, wanted room 15 for its size", data.Excluded)
+	}
+	if len(data.Combinations) != 0 {
+		t.Errorf("SearchAvailability suggested %+v for a party a single room sleeps", data.Combinations)
+	}
+
+	_, data = searchAvailability(dtos.PostAvailabilityBody{StartDate: "2050-01-04", EndDate: "2050-01-07", Adults: 4, Children: 2})
+	if len(data.Rooms) != 0 {
+		t.Errorf("SearchAvailability returned rooms %+v for a party of 6, wanted none", data.Rooms)
+	}
+	if len(data.Combinations) != 1 || len(data.Combinations[0]) != 2 {
+		t.Fatalf("SearchAvailability suggested %+v for a party of 6, wanted rooms 20 and 15 together", data.Combinations)
+	}
+	if data.Combinations[0][0].ID != 20 || data.Combinations[0][1].ID != 15 {
+		t.Errorf("SearchAvailability suggested %+v for a party of 6, wanted rooms 20 and 15 together", data.Combinations)
+	}
+}
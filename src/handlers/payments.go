@@ -0,0 +1,410 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/dtos"
+	"github.com/Orololuwa/go-backend-boilerplate/src/helpers"
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+	"github.com/Orololuwa/go-backend-boilerplate/src/money"
+	"github.com/Orololuwa/go-backend-boilerplate/src/payments"
+)
+
+const paymentSignatureHeader = "X-Payment-Signature"
+
+var (
+	errNotAwaitingPayment = errors.New("reservation is not awaiting payment")
+	errNothingDue = errors.New("reservation has no amount due")
+	errRefundTooLarge = errors.New("refund exceeds the captured amount")
+	errReservationClosed = errors.New("reservation has been cancelled or was a no-show")
+)
+
+// gatewayError is a call to the payment gateway that failed, as opposed to one it declined
+type gatewayError struct {
+	err error
+}
+
+func (e gatewayError) Error() string {
+	return e.err.Error()
+}
+
+func (e gatewayError) Unwrap() error {
+	return e.err
+}
+
+// declinedError is a refund the payment gateway declined
+type declinedError struct {
+	reason string
+}
+
+func (e declinedError) Error() string {
+	return e.reason
+}
+
+// paymentError writes the response for an error of a payment transaction, notFound names what was missing
+func paymentError(w http.ResponseWriter, err error, notFound string) {
+	var gwErr gatewayError
+	var declined declinedError
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		helpers.ClientError(w, err, http.StatusNotFound, notFound)
+	case errors.Is(err, errNotAwaitingPayment), errors.Is(err, errNothingDue), errors.Is(err, errReservationClosed):
+		helpers.ClientError(w, err, http.StatusConflict, "")
+	case errors.Is(err, errRefundTooLarge):
+		helpers.ClientError(w, err, http.StatusBadRequest, "")
+	case errors.As(err, &gwErr):
+		helpers.ClientError(w, err, http.StatusBadGateway, "payment gateway error")
+	case errors.As(err, &declined):
+		helpers.ClientError(w, err, http.StatusPaymentRequired, "refund declined")
+	default:
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+	}
+}
+
+// PostReservationPayment authorizes the reservation total on the guest's payment method and confirms the booking when it is approved
+func (m *Repository) PostReservationPayment(w http.ResponseWriter, r *http.Request) {
+	id, err := m.urlParamInt(r, "id", 2)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	var body dtos.PaymentBody
+	requestBody, ok := r.Context().Value("validatedRequestBody").(*dtos.PaymentBody)
+	if !ok || requestBody == nil {
+		helpers.ClientError(w, errors.New("failed to retrieve request body"), http.StatusBadRequest, "")
+		return
+	}
+	body = *requestBody
+
+	var payment models.Payment
+	approved := false
+
+	// the reservation stays locked while the card is authorized, so a second request for it waits and then finds it
+	// confirmed instead of authorizing the card again
+	err = m.DB.Transaction(withActor(r), func(ctx context.Context, tx *sql.Tx) error {
+		err := m.DB.LockReservation(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		res, err := m.DB.GetReservationById(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		// the reservation id is guessable, so the guest proves ownership with the confirmation code
+		if !helpers.ConfirmationCodeMatches(res.ConfirmationCode, body.ConfirmationCode) {
+			return sql.ErrNoRows
+		}
+
+		if res.Status != models.ReservationStatusPending {
+			return errNotAwaitingPayment
+		}
+
+		if res.TotalPrice <= 0 {
+			return errNothingDue
+		}
+
+		result, err := m.Gateway.Authorize(ctx, payments.AuthorizeRequest{
+			Amount: res.TotalPrice,
+			Currency: res.Currency,
+			PaymentMethod: body.PaymentMethod,
+			Description: "Reservation " + res.ConfirmationCode,
+		})
+		if err != nil {
+			return gatewayError{err}
+		}
+		approved = result.Approved
+
+		payment = models.Payment{
+			ReservationID: res.ID,
+			Provider: m.Gateway.Name(),
+			Reference: result.Reference,
+			Amount: res.TotalPrice,
+			Currency: res.Currency,
+			Status: models.PaymentStatusAuthorized,
+		}
+
+		if !result.Approved {
+			payment.Status = models.PaymentStatusDeclined
+			payment.FailureReason = result.DeclineReason
+		}
+
+		payment.ID, err = m.Payment.InsertPayment(ctx, tx, payment)
+		if err != nil {
+			return err
+		}
+
+		if !result.Approved {
+			return nil
+		}
+
+		return m.updateReservationStatus(ctx, tx, res.ID, models.ReservationStatusConfirmed)
+	})
+
+	if err != nil {
+		paymentError(w, err, "reservation not found")
+		return
+	}
+
+	if !approved {
+		helpers.ClientResponseWriter(w, payment, http.StatusPaymentRequired, "payment declined")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, payment, http.StatusCreated, "payment authorized and reservation confirmed")
+}
+
+// CaptureReservationPayment collects the authorized funds. A failed capture moves a confirmed reservation back to pending
+func (m *Repository) CaptureReservationPayment(w http.ResponseWriter, r *http.Request) {
+	id, err := m.urlParamInt(r, "id", 2)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	var payment models.Payment
+	approved := false
+
+	// the reservation stays locked while the funds are captured, so a second capture waits and then finds no
+	// authorized payment left instead of capturing it again
+	err = m.DB.Transaction(withActor(r), func(ctx context.Context, tx *sql.Tx) error {
+		err := m.DB.LockReservation(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		res, err := m.DB.GetReservationById(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		switch res.Status {
+		case models.ReservationStatusCancelled, models.ReservationStatusNoShow:
+			return errReservationClosed
+		}
+
+		payment, err = m.Payment.GetLatestPaymentByReservationId(ctx, tx, id, models.PaymentStatusAuthorized)
+		if err != nil {
+			return err
+		}
+
+		result, err := m.Gateway.Capture(ctx, payment.Reference, payment.Amount)
+		if err != nil {
+			return gatewayError{err}
+		}
+		approved = result.Approved
+
+		if result.Approved {
+			payment.Status = models.PaymentStatusCaptured
+			payment.CapturedAmount = payment.Amount
+		}else{
+			payment.Status = models.PaymentStatusFailed
+			payment.FailureReason = result.DeclineReason
+		}
+
+		err = m.Payment.UpdatePayment(ctx, tx, payment)
+		if err != nil {
+			return err
+		}
+
+		if result.Approved || res.Status != models.ReservationStatusConfirmed {
+			return nil
+		}
+
+		return m.updateReservationStatus(ctx, tx, res.ID, models.ReservationStatusPending)
+	})
+
+	if err != nil {
+		paymentError(w, err, "no authorized payment for reservation")
+		return
+	}
+
+	if !approved {
+		helpers.ClientResponseWriter(w, payment, http.StatusPaymentRequired, "payment capture failed, reservation awaits payment")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, payment, http.StatusOK, "payment captured successfully")
+}
+
+// RefundReservationPayment returns part or all of the captured amount to the guest
+func (m *Repository) RefundReservationPayment(w http.ResponseWriter, r *http.Request) {
+	id, err := m.urlParamInt(r, "id", 2)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	var body dtos.RefundBody
+	requestBody, ok := r.Context().Value("validatedRequestBody").(*dtos.RefundBody)
+	if !ok || requestBody == nil {
+		helpers.ClientError(w, errors.New("failed to retrieve request body"), http.StatusBadRequest, "")
+		return
+	}
+	body = *requestBody
+
+	var payment models.Payment
+
+	// the reservation stays locked from the check of what is left to refund until the refund is saved, so two refunds
+	// cannot both pass the check
+	err = m.DB.Transaction(context.Background(), func(ctx context.Context, tx *sql.Tx) error {
+		err := m.DB.LockReservation(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		payment, err = m.Payment.GetLatestPaymentByReservationId(ctx, tx, id, models.PaymentStatusCaptured, models.PaymentStatusPartiallyRefunded)
+		if err != nil {
+			return err
+		}
+
+		if body.Amount > payment.CapturedAmount - payment.RefundedAmount {
+			return errRefundTooLarge
+		}
+
+		result, err := m.Gateway.Refund(ctx, payment.Reference, body.Amount)
+		if err != nil {
+			return gatewayError{err}
+		}
+
+		if !result.Approved {
+			return declinedError{result.DeclineReason}
+		}
+
+		applyRefund(&payment, payment.RefundedAmount + body.Amount)
+
+		return m.Payment.UpdatePayment(ctx, tx, payment)
+	})
+
+	if err != nil {
+		paymentError(w, err, "no captured payment for reservation")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, payment, http.StatusOK, "payment refunded successfully")
+}
+
+func applyRefund(payment *models.Payment, refunded money.Amount) {
+	payment.RefundedAmount = refunded
+	if payment.RefundedAmount >= payment.CapturedAmount {
+		payment.Status = models.PaymentStatusRefunded
+	}else{
+		payment.Status = models.PaymentStatusPartiallyRefunded
+	}
+}
+
+func (m *Repository) GetReservationPayments(w http.ResponseWriter, r *http.Request) {
+	id, err := m.urlParamInt(r, "id", 2)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	reservationPayments, err := m.Payment.GetPaymentsByReservationId(context.Background(), nil, id)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, reservationPayments, http.StatusOK, "payments retrieved successfully")
+}
+
+// PaymentWebhook applies asynchronous payment updates sent by the gateway after checking their signature
+func (m *Repository) PaymentWebhook(w http.ResponseWriter, r *http.Request) {
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "failed to read body")
+		return
+	}
+
+	event, err := m.Gateway.VerifyWebhook(payload, r.Header.Get(paymentSignatureHeader))
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusUnauthorized, "")
+		return
+	}
+
+	ctx := context.Background()
+
+	payment, err := m.Payment.GetPaymentByReference(ctx, nil, m.Gateway.Name(), event.Reference)
+	if errors.Is(err, sql.ErrNoRows) {
+		helpers.ClientError(w, err, http.StatusNotFound, "payment not found")
+		return
+	}
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	switch event.Type {
+	case payments.EventPaymentCaptured, payments.EventPaymentFailed, payments.EventPaymentRefunded:
+	default:
+		helpers.ClientResponseWriter(w, nil, http.StatusOK, "event ignored")
+		return
+	}
+
+	// the payment is read again once its reservation is locked, so the event applies on top of a capture or refund
+	// made at the same time instead of overwriting it
+	err = m.DB.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		err := m.DB.LockReservation(ctx, tx, payment.ReservationID)
+		if err != nil {
+			return err
+		}
+
+		payment, err = m.Payment.GetPaymentByReference(ctx, tx, m.Gateway.Name(), event.Reference)
+		if err != nil {
+			return err
+		}
+
+		moveToPending := false
+
+		switch event.Type {
+		case payments.EventPaymentCaptured:
+			payment.Status = models.PaymentStatusCaptured
+			payment.CapturedAmount = payment.Amount
+			if event.Amount > 0 {
+				payment.CapturedAmount = event.Amount
+			}
+		case payments.EventPaymentFailed:
+			payment.Status = models.PaymentStatusFailed
+			moveToPending = true
+		case payments.EventPaymentRefunded:
+			// the event carries the total refunded so far, which keeps replays of the same event harmless
+			applyRefund(&payment, event.Amount)
+		}
+
+		err = m.Payment.UpdatePayment(ctx, tx, payment)
+		if err != nil {
+			return err
+		}
+
+		if !moveToPending {
+			return nil
+		}
+
+		// only a booking the payment confirmed goes back to waiting for one, a cancelled one stays cancelled
+		res, err := m.DB.GetReservationById(ctx, tx, payment.ReservationID)
+		if err != nil {
+			return err
+		}
+
+		if res.Status != models.ReservationStatusConfirmed {
+			return nil
+		}
+
+		return m.updateReservationStatus(ctx, tx, res.ID, models.ReservationStatusPending)
+	})
+
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, nil, http.StatusOK, "event processed")
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/dtos"
+	"github.com/Orololuwa/go-backend-boilerplate/src/money"
+	"github.com/Orololuwa/go-backend-boilerplate/src/payments"
+)
+
+func TestRepository_PostReservationPayment(t *testing.T){
+	var paymentTests = []struct {
+		name string
+		url string
+		body dtos.PaymentBody
+		expectedStatusCode int
+	}{
+		{"approved payment", "/reservation/1/payment", dtos.PaymentBody{ConfirmationCode: "ABCD2345", PaymentMethod: payments.FakeTokenSuccess}, http.StatusCreated},
+		{"declined payment", "/reservation/1/payment", dtos.PaymentBody{ConfirmationCode: "ABCD2345", PaymentMethod: payments.FakeTokenDecline}, http.StatusPaymentRequired},
+		{"code as the guest typed it", "/reservation/1/payment", dtos.PaymentBody{ConfirmationCode: "abcd-2345", PaymentMethod: payments.FakeTokenSuccess}, http.StatusCreated},
+		{"wrong confirmation code", "/reservation/1/payment", dtos.PaymentBody{ConfirmationCode: "WRONG", PaymentMethod: payments.FakeTokenSuccess}, http.StatusNotFound},
+		{"already confirmed", "/reservation/2/payment", dtos.PaymentBody{ConfirmationCode: "ABCD2345", PaymentMethod: payments.FakeTokenSuccess}, http.StatusConflict},
+		{"nothing to pay", "/reservation/3/payment", dtos.PaymentBody{ConfirmationCode: "ABCD2345", PaymentMethod: payments.FakeTokenSuccess}, http.StatusConflict},
+		{"reservation not found", "/reservation/404/payment", dtos.PaymentBody{ConfirmationCode: "ABCD2345", PaymentMethod: payments.FakeTokenSuccess}, http.StatusNotFound},
+		{"failed reservation lookup", "/reservation/1000/payment", dtos.PaymentBody{ConfirmationCode: "ABCD2345", PaymentMethod: payments.FakeTokenSuccess}, http.StatusInternalServerError},
+		{"invalid id", "/reservation/one/payment", dtos.PaymentBody{ConfirmationCode: "ABCD2345", PaymentMethod: payments.FakeTokenSuccess}, http.StatusBadRequest},
+	}
+
+	for _, e := range paymentTests {
+		jsonData, err := json.Marshal(e.body)
+		if err != nil {
+			t.Log("Error:", err)
+			return
+		}
+
+		req, _ := http.NewRequest("POST", e.url, bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		res := httptest.NewRecorder()
+
+		handler := mdTest.ValidateReqBody(http.HandlerFunc(Repo.PostReservationPayment), &dtos.PaymentBody{})
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("PostReservationPayment handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+}
+
+func TestRepository_CaptureAndRefundPayment(t *testing.T){
+	var captureTests = []struct {
+		name string
+		url string
+		expectedStatusCode int
+	}{
+		{"successful capture", "/reservation/1/payment/capture", http.StatusOK},
+		{"failed capture", "/reservation/5/payment/capture", http.StatusPaymentRequired},
+		{"no authorized payment", "/reservation/404/payment/capture", http.StatusNotFound},
+		{"cancelled reservation", "/reservation/4/payment/capture", http.StatusConflict},
+		{"no-show", "/reservation/11/payment/capture", http.StatusConflict},
+	}
+
+	for _, e := range captureTests {
+		req, _ := http.NewRequest("POST", e.url, nil)
+		res := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(Repo.CaptureReservationPayment)
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("CaptureReservationPayment handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+
+	var refundTests = []struct {
+		name string
+		url string
+		amount money.Amount
+		expectedStatusCode int
+	}{
+		{"partial refund", "/reservation/1/payment/refund", 10000, http.StatusOK},
+		{"refund over the captured amount", "/reservation/1/payment/refund", 50000, http.StatusBadRequest},
+		{"declined refund", "/reservation/6/payment/refund", 10000, http.StatusPaymentRequired},
+		{"no captured payment", "/reservation/404/payment/refund", 10000, http.StatusNotFound},
+		{"invalid amount", "/reservation/1/payment/refund", 0, http.StatusBadRequest},
+	}
+
+	for _, e := range refundTests {
+		jsonData, _ := json.Marshal(dtos.RefundBody{Amount: e.amount})
+		req, _ := http.NewRequest("POST", e.url, bytes.NewBuffer(jsonData))
+		res := httptest.NewRecorder()
+
+		handler := mdTest.ValidateReqBody(http.HandlerFunc(Repo.RefundReservationPayment), &dtos.RefundBody{})
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("RefundReservationPayment handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+
+	// test listing the payments of a reservation
+	req, _ := http.NewRequest("GET", "/reservation/1/payment", nil)
+	res := httptest.NewRecorder()
+
+	handler := http.HandlerFunc(Repo.GetReservationPayments)
+	handler.ServeHTTP(res, req)
+
+	if res.Code != http.StatusOK {
+		t.Errorf("GetReservationPayments handler returned wrong response code: got %d, wanted %d", res.Code, http.StatusOK)
+	}
+}
+
+func TestRepository_PaymentWebhook(t *testing.T){
+	gateway := Repo.Gateway.(*payments.FakeGateway)
+
+	var webhookTests = []struct {
+		name string
+		event payments.WebhookEvent
+		signed bool
+		expectedStatusCode int
+	}{
+		{"captured event", payments.WebhookEvent{Type: payments.EventPaymentCaptured, Reference: "fake_success_1", Amount: 34000}, true, http.StatusOK},
+		{"failed event", payments.WebhookEvent{Type: payments.EventPaymentFailed, Reference: "fake_success_1"}, true, http.StatusOK},
+		{"failed event of a cancelled reservation", payments.WebhookEvent{Type: payments.EventPaymentFailed, Reference: "fake_cancelled_1"}, true, http.StatusOK},
+		{"refunded event", payments.WebhookEvent{Type: payments.EventPaymentRefunded, Reference: "fake_success_1", Amount: 34000}, true, http.StatusOK},
+		{"unknown event", payments.WebhookEvent{Type: "payment.disputed", Reference: "fake_success_1"}, true, http.StatusOK},
+		{"unknown payment", payments.WebhookEvent{Type: payments.EventPaymentCaptured, Reference: "unknown"}, true, http.StatusNotFound},
+		{"bad signature", payments.WebhookEvent{Type: payments.EventPaymentCaptured, Reference: "fake_success_1"}, false, http.StatusUnauthorized},
+	}
+
+	for _, e := range webhookTests {
+		payload, _ := json.Marshal(e.event)
+		req, _ := http.NewRequest("POST", "/payments/webhook", bytes.NewBuffer(payload))
+
+		signature := "00"
+		if e.signed {
+			signature = gateway.Sign(payload)
+		}
+		req.Header.Set(paymentSignatureHeader, signature)
+		res := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(Repo.PaymentWebhook)
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("PaymentWebhook handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+}
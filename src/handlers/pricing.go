@@ -0,0 +1,346 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/cancellation"
+	"github.com/Orololuwa/go-backend-boilerplate/src/dtos"
+	"github.com/Orololuwa/go-backend-boilerplate/src/helpers"
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+	"github.com/Orololuwa/go-backend-boilerplate/src/pricing"
+	"github.com/Orololuwa/go-backend-boilerplate/src/promo"
+	"github.com/Orololuwa/go-backend-boilerplate/src/types"
+)
+
+// quoteRoom prices a stay in a single room with its base and seasonal rates
+func (m *Repository) quoteRoom(ctx context.Context, tx *sql.Tx, roomId int, start, end time.Time) (pricing.Quote, error) {
+	room, err := m.DB.GetRoomById(ctx, tx, roomId)
+	if err != nil {
+		return pricing.Quote{}, err
+	}
+
+	rates, err := m.DB.GetRoomRatesForDates(ctx, tx, roomId, start, end)
+	if err != nil {
+		return pricing.Quote{}, err
+	}
+
+	return pricing.QuoteStay(room, rates, start, end)
+}
+
+func roomQuoteResponse(room models.Room, quote pricing.Quote, x exchange, policy *models.CancellationPolicy, available bool, start, end string) types.RoomQuoteResponse {
+	return types.RoomQuoteResponse{
+		RoomId: room.ID,
+		RoomName: room.RoomName,
+		Available: available,
+		StartDate: start,
+		EndDate: end,
+		Nights: quote.Nights,
+		Total: quote.Total,
+		Currency: x.To,
+		BaseCurrency: x.From,
+		ExchangeRate: x.Rate,
+		CancellationPolicy: policy,
+		CancellationTerms: cancellation.Describe(policy),
+	}
+}
+
+// PostQuote prices the stay for one room when a roomId is given, or for every available room otherwise. Prices are
+// converted from the base currency of each room into the currency asked for
+func (m *Repository) PostQuote(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var body dtos.QuoteBody
+	requestBody, ok := r.Context().Value("validatedRequestBody").(*dtos.QuoteBody)
+	if !ok || requestBody == nil {
+		helpers.ClientError(w, errors.New("failed to retrieve request body"), http.StatusBadRequest, "")
+		return
+	}
+	body = *requestBody
+
+	layout := "2006-01-02"
+
+	startDate, err := time.Parse(layout, body.StartDate)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "")
+		return
+	}
+
+	endDate, err := time.Parse(layout, body.EndDate)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "")
+		return
+	}
+
+	if !endDate.After(startDate) {
+		helpers.ClientError(w, pricing.ErrInvalidStay, http.StatusBadRequest, "")
+		return
+	}
+
+	ctx := context.Background()
+	quotes := make([]types.RoomQuoteResponse, 0)
+
+	// a code that does not apply is reported with the quotes, which are priced without it
+	var code models.PromoCode
+	var codeErr *promo.Error
+	if body.PromoCode != "" {
+		code, err = m.quotePromo(ctx, body.PromoCode)
+		if err != nil && !errors.As(err, &codeErr) {
+			helpers.ClientError(w, err, http.StatusInternalServerError, "")
+			return
+		}
+	}
+
+	rules, err := m.DB.GetChargeRules(ctx, nil)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	if body.RoomId != 0 {
+		room, err := m.DB.GetRoomById(ctx, nil, body.RoomId)
+		if err != nil {
+			helpers.ClientError(w, err, http.StatusNotFound, "room not found")
+			return
+		}
+
+		available, err := m.DB.SearchAvailabilityForDatesByRoomId(ctx, nil, startDate, endDate, body.RoomId)
+		if err != nil {
+			helpers.ClientError(w, err, http.StatusInternalServerError, "")
+			return
+		}
+
+		rates, err := m.DB.GetRoomRatesForDates(ctx, nil, body.RoomId, startDate, endDate)
+		if err != nil {
+			helpers.ClientError(w, err, http.StatusInternalServerError, "")
+			return
+		}
+
+		quote, err := pricing.QuoteStay(room, rates, startDate, endDate)
+		if err != nil {
+			helpers.ClientError(w, err, http.StatusBadRequest, "")
+			return
+		}
+
+		x, err := m.exchange(ctx, nil, room.Currency, body.Currency)
+		if errors.Is(err, errNoExchangeRate) {
+			helpers.ClientError(w, err, http.StatusBadRequest, "")
+			return
+		}
+		if err != nil {
+			helpers.ClientError(w, err, http.StatusInternalServerError, "")
+			return
+		}
+		quote = x.quote(quote)
+
+		policy, err := m.stayCancellationPolicy(ctx, nil, room.ID, quote)
+		if err != nil {
+			helpers.ClientError(w, err, http.StatusInternalServerError, "")
+			return
+		}
+
+		resp := roomQuoteResponse(room, quote, x, policy, available, body.StartDate, body.EndDate)
+		if body.PromoCode != "" {
+			withPromo(&resp, x.promoCode(code), codeErr, quote)
+		}
+		withCharges(&resp, x.chargeRules(rules), body.Adults + body.Children, len(quote.Nights))
+
+		quotes = append(quotes, resp)
+		helpers.ClientResponseWriter(w, quotes, http.StatusOK, "quote retrieved successfully")
+		return
+	}
+
+	rooms, err := m.DB.SearchAvailabilityForAllRooms(ctx, nil, startDate, endDate)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	// one query for the seasonal rates of every room instead of one per room
+	rates, err := m.DB.GetRoomRatesForDates(ctx, nil, 0, startDate, endDate)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	for _, room := range rooms {
+		if !room.Sleeps(body.Adults + body.Children) {
+			continue
+		}
+
+		quote, err := pricing.QuoteStay(room, rates, startDate, endDate)
+		if err != nil {
+			helpers.ClientError(w, err, http.StatusBadRequest, "")
+			return
+		}
+
+		x, err := m.exchange(ctx, nil, room.Currency, body.Currency)
+		if errors.Is(err, errNoExchangeRate) {
+			helpers.ClientError(w, err, http.StatusBadRequest, "")
+			return
+		}
+		if err != nil {
+			helpers.ClientError(w, err, http.StatusInternalServerError, "")
+			return
+		}
+		quote = x.quote(quote)
+
+		policy, err := m.stayCancellationPolicy(ctx, nil, room.ID, quote)
+		if err != nil {
+			helpers.ClientError(w, err, http.StatusInternalServerError, "")
+			return
+		}
+
+		resp := roomQuoteResponse(room, quote, x, policy, true, body.StartDate, body.EndDate)
+		if body.PromoCode != "" {
+			withPromo(&resp, x.promoCode(code), codeErr, quote)
+		}
+		withCharges(&resp, x.chargeRules(rules), body.Adults + body.Children, len(quote.Nights))
+
+		quotes = append(quotes, resp)
+	}
+
+	helpers.ClientResponseWriter(w, quotes, http.StatusOK, "quotes retrieved successfully")
+}
+
+// UpdateRoomRates sets the default weekday and weekend price of a room, and its base currency when one is given
+func (m *Repository) UpdateRoomRates(w http.ResponseWriter, r *http.Request) {
+	roomId, err := m.urlParamInt(r, "id", 2)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	var body dtos.RoomRatesBody
+	requestBody, ok := r.Context().Value("validatedRequestBody").(*dtos.RoomRatesBody)
+	if !ok || requestBody == nil {
+		helpers.ClientError(w, errors.New("failed to retrieve request body"), http.StatusBadRequest, "")
+		return
+	}
+	body = *requestBody
+
+	err = m.DB.UpdateRoomRates(context.Background(), nil, roomId, body.BaseRate, body.WeekendRate, body.Currency)
+	if errors.Is(err, sql.ErrNoRows) {
+		helpers.ClientError(w, err, http.StatusNotFound, "room not found")
+		return
+	}
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, body, http.StatusOK, "room rates updated successfully")
+}
+
+func (m *Repository) PostRoomRate(w http.ResponseWriter, r *http.Request) {
+	roomId, err := m.urlParamInt(r, "id", 2)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	var body dtos.RoomRateBody
+	requestBody, ok := r.Context().Value("validatedRequestBody").(*dtos.RoomRateBody)
+	if !ok || requestBody == nil {
+		helpers.ClientError(w, errors.New("failed to retrieve request body"), http.StatusBadRequest, "")
+		return
+	}
+	body = *requestBody
+
+	layout := "2006-01-02"
+
+	startDate, err := time.Parse(layout, body.StartDate)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "")
+		return
+	}
+
+	endDate, err := time.Parse(layout, body.EndDate)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "")
+		return
+	}
+
+	if !endDate.After(startDate) {
+		helpers.ClientError(w, errors.New("endDate must be after startDate"), http.StatusBadRequest, "")
+		return
+	}
+
+	rate := models.RoomRate{
+		RoomID: roomId,
+		RateName: body.RateName,
+		StartDate: startDate,
+		EndDate: endDate,
+		NightlyRate: body.NightlyRate,
+		WeekendRate: body.WeekendRate,
+		CancellationPolicyID: body.CancellationPolicyId,
+	}
+
+	if rate.CancellationPolicyID != 0 {
+		_, err = m.DB.GetCancellationPolicyById(context.Background(), nil, rate.CancellationPolicyID)
+		if errors.Is(err, sql.ErrNoRows) {
+			helpers.ClientError(w, err, http.StatusNotFound, "cancellation policy not found")
+			return
+		}
+		if err != nil {
+			helpers.ClientError(w, err, http.StatusInternalServerError, "")
+			return
+		}
+	}
+
+	rate.ID, err = m.DB.InsertRoomRate(context.Background(), nil, rate)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, rate, http.StatusCreated, "room rate created successfully")
+}
+
+func (m *Repository) GetRoomRates(w http.ResponseWriter, r *http.Request) {
+	roomId, err := m.urlParamInt(r, "id", 2)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	rates, err := m.DB.GetRoomRatesByRoomId(context.Background(), nil, roomId)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, rates, http.StatusOK, "room rates retrieved successfully")
+}
+
+func (m *Repository) DeleteRoomRate(w http.ResponseWriter, r *http.Request) {
+	roomId, err := m.urlParamInt(r, "id", 2)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	rateId, err := m.urlParamInt(r, "rateId", 4)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	err = m.DB.DeleteRoomRate(context.Background(), nil, roomId, rateId)
+	if errors.Is(err, sql.ErrNoRows) {
+		helpers.ClientError(w, err, http.StatusNotFound, "room rate not found")
+		return
+	}
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, nil, http.StatusOK, "room rate deleted successfully")
+}
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/dtos"
+	"github.com/Orololuwa/go-backend-boilerplate/src/money"
+	"github.com/Orololuwa/go-backend-boilerplate/src/types"
+)
+
+func TestRepository_PostQuote(t *testing.T){
+	var quoteTests = []struct {
+		name string
+		body dtos.QuoteBody
+		expectedStatusCode int
+	}{
+		{"single room", dtos.QuoteBody{PostAvailabilityBody: dtos.PostAvailabilityBody{StartDate: "2050-01-06", EndDate: "2050-01-09"}, RoomId: 15}, http.StatusOK},
+		{"all available rooms", dtos.QuoteBody{PostAvailabilityBody: dtos.PostAvailabilityBody{StartDate: "2050-01-06", EndDate: "2050-01-09"}}, http.StatusOK},
+		{"invalid startDate", dtos.QuoteBody{PostAvailabilityBody: dtos.PostAvailabilityBody{StartDate: "invalid", EndDate: "2050-01-09"}}, http.StatusBadRequest},
+		{"invalid endDate", dtos.QuoteBody{PostAvailabilityBody: dtos.PostAvailabilityBody{StartDate: "2050-01-06", EndDate: "invalid"}}, http.StatusBadRequest},
+		{"zero nights", dtos.QuoteBody{PostAvailabilityBody: dtos.PostAvailabilityBody{StartDate: "2050-01-06", EndDate: "2050-01-06"}}, http.StatusBadRequest},
+		{"room not found", dtos.QuoteBody{PostAvailabilityBody: dtos.PostAvailabilityBody{StartDate: "2050-01-06", EndDate: "2050-01-09"}, RoomId: 404}, http.StatusNotFound},
+		{"failed rate lookup", dtos.QuoteBody{PostAvailabilityBody: dtos.PostAvailabilityBody{StartDate: "2050-01-06", EndDate: "2050-01-09"}, RoomId: 500}, http.StatusInternalServerError},
+		{"failed availability search", dtos.QuoteBody{PostAvailabilityBody: dtos.PostAvailabilityBody{StartDate: "1955-01-06", EndDate: "1955-01-09"}}, http.StatusInternalServerError},
+	}
+
+	for _, e := range quoteTests {
+		jsonData, err := json.Marshal(e.body)
+		if err != nil {
+			t.Log("Error:", err)
+			return
+		}
+
+		req, _ := http.NewRequest("POST", "/quote", bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		res := httptest.NewRecorder()
+
+		handler := mdTest.ValidateReqBody(http.HandlerFunc(Repo.PostQuote), &dtos.QuoteBody{})
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("PostQuote handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+
+	// test the weekday and weekend breakdown for a single room, 2050-01-06 is a Thursday
+	jsonData, _ := json.Marshal(quoteTests[0].body)
+	req, _ := http.NewRequest("POST", "/quote", bytes.NewBuffer(jsonData))
+	res := httptest.NewRecorder()
+
+	handler := mdTest.ValidateReqBody(http.HandlerFunc(Repo.PostQuote), &dtos.QuoteBody{})
+	handler.ServeHTTP(res, req)
+
+	var resp struct {
+		Data []types.RoomQuoteResponse `json:"data"`
+	}
+	err := json.Unmarshal(res.Body.Bytes(), &resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(resp.Data) != 1 || resp.Data[0].Total != 34000 {
+		t.Errorf("PostQuote handler returned the wrong total: %+v", resp.Data)
+	}
+
+	// room 15 is booked under the test cancellation policy, the guest sees its terms with the price
+	terms := "Free cancellation until 2 days before arrival, 50% of the total after that, 100% from 1 day before arrival. No-shows are charged 100% of the total."
+	if len(resp.Data) == 1 && (resp.Data[0].CancellationPolicy == nil || resp.Data[0].CancellationTerms != terms) {
+		t.Errorf("PostQuote handler returned the wrong cancellation terms: %q", resp.Data[0].CancellationTerms)
+	}
+}
+
+func TestRepository_PostReservationQuotedTotal(t *testing.T){
+	var quotedTests = []struct {
+		name string
+		quotedTotal money.Amount
+		expectedStatusCode int
+	}{
+		{"matching quote", 34000, http.StatusCreated},
+		{"no quote", 0, http.StatusCreated},
+		{"stale quote", 30000, http.StatusConflict},
+	}
+
+	for _, e := range quotedTests {
+		body := dtos.ReservationBody{
+			FirstName: "John",
+			LastName: "Doe",
+			Email: "johndoe@gmail.com",
+			Phone: "08012345678",
+			StartDate: "2050-01-06",
+			EndDate: "2050-01-09",
+			RoomId: 15,
+			QuotedTotal: e.quotedTotal,
+		}
+
+		jsonBody, err := json.Marshal(body)
+		if err != nil {
+			t.Log("Error:", err)
+			return
+		}
+
+		req, _ := http.NewRequest("POST", "/reservation", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(Repo.PostReservation)
+		handler.ServeHTTP(rr, req)
+
+		if rr.Code != e.expectedStatusCode {
+			t.Errorf("PostReservation handler returned wrong response code for %s: got %d, wanted %d", e.name, rr.Code, e.expectedStatusCode)
+		}
+	}
+}
+
+func TestRepository_RoomRates(t *testing.T){
+	// test updating the base rates
+	jsonData, _ := json.Marshal(dtos.RoomRatesBody{BaseRate: 10000, WeekendRate: 12000})
+
+	var updateTests = []struct {
+		url string
+		expectedStatusCode int
+	}{
+		{"/room/1/rate", http.StatusOK},
+		{"/room/one/rate", http.StatusBadRequest},
+		{"/room/404/rate", http.StatusNotFound},
+		{"/room/1000/rate", http.StatusInternalServerError},
+	}
+
+	for _, e := range updateTests {
+		req, _ := http.NewRequest("PUT", e.url, bytes.NewBuffer(jsonData))
+		res := httptest.NewRecorder()
+
+		handler := mdTest.ValidateReqBody(http.HandlerFunc(Repo.UpdateRoomRates), &dtos.RoomRatesBody{})
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("UpdateRoomRates handler returned wrong response code for %s: got %d, wanted %d", e.url, res.Code, e.expectedStatusCode)
+		}
+	}
+
+	// test creating seasonal rates
+	var createTests = []struct {
+		name string
+		url string
+		body dtos.RoomRateBody
+		expectedStatusCode int
+	}{
+		{"valid rate", "/room/1/seasonal-rate", dtos.RoomRateBody{RateName: "Summer", StartDate: "2050-06-01", EndDate: "2050-09-01", NightlyRate: 15000}, http.StatusCreated},
+		{"inverted dates", "/room/1/seasonal-rate", dtos.RoomRateBody{StartDate: "2050-09-01", EndDate: "2050-06-01", NightlyRate: 15000}, http.StatusBadRequest},
+		{"missing rate", "/room/1/seasonal-rate", dtos.RoomRateBody{StartDate: "2050-06-01", EndDate: "2050-09-01"}, http.StatusBadRequest},
+		{"failed insert", "/room/1000/seasonal-rate", dtos.RoomRateBody{StartDate: "2050-06-01", EndDate: "2050-09-01", NightlyRate: 15000}, http.StatusInternalServerError},
+	}
+
+	for _, e := range createTests {
+		jsonData, _ := json.Marshal(e.body)
+		req, _ := http.NewRequest("POST", e.url, bytes.NewBuffer(jsonData))
+		res := httptest.NewRecorder()
+
+		handler := mdTest.ValidateReqBody(http.HandlerFunc(Repo.PostRoomRate), &dtos.RoomRateBody{})
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("PostRoomRate handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+
+	// test listing and deleting seasonal rates
+	var otherTests = []struct {
+		method string
+		url string
+		handler http.HandlerFunc
+		expectedStatusCode int
+	}{
+		{"GET", "/room/1/seasonal-rate", Repo.GetRoomRates, http.StatusOK},
+		{"GET", "/room/500/seasonal-rate", Repo.GetRoomRates, http.StatusInternalServerError},
+		{"DELETE", "/room/1/seasonal-rate/1", Repo.DeleteRoomRate, http.StatusOK},
+		{"DELETE", "/room/1/seasonal-rate/404", Repo.DeleteRoomRate, http.StatusNotFound},
+		{"DELETE", "/room/1/seasonal-rate/one", Repo.DeleteRoomRate, http.StatusBadRequest},
+	}
+
+	for _, e := range otherTests {
+		req, _ := http.NewRequest(e.method, e.url, nil)
+		res := httptest.NewRecorder()
+
+		e.handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("%s %s returned wrong response code: got %d, wanted %d", e.method, e.url, res.Code, e.expectedStatusCode)
+		}
+	}
+}
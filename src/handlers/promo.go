@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/dtos"
+	"github.com/Orololuwa/go-backend-boilerplate/src/guests"
+	"github.com/Orololuwa/go-backend-boilerplate/src/helpers"
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+	"github.com/Orololuwa/go-backend-boilerplate/src/pricing"
+	"github.com/Orololuwa/go-backend-boilerplate/src/promo"
+	"github.com/Orololuwa/go-backend-boilerplate/src/types"
+)
+
+var errPromoCodeExists = errors.New("a promo code with this code already exists")
+
+// applyPromo discounts the quoted stay of the reservation with its promo code. The code is locked until the
+// transaction ends so the usage caps hold under concurrent bookings, redeemPromo must record the use in the same
+// transaction. It returns the id of the code
+func (m *Repository) applyPromo(ctx context.Context, tx *sql.Tx, res *models.Reservation, quote pricing.Quote) (int, error) {
+	code := promo.Normalize(res.PromoCode)
+
+	p, err := m.DB.LockPromoCode(ctx, tx, code)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, promo.NotFound(code)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	err = promo.Check(p, res.RoomID, len(quote.Nights), time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	// the uses are counted by the email key guests are matched on, so J.Doe+spa@gmail.com is the same guest as jdoe@gmail.com
+	emailUses, err := m.DB.CountPromoRedemptionsByEmail(ctx, tx, p.ID, guests.NormalizeEmail(res.Email))
+	if err != nil {
+		return 0, err
+	}
+
+	err = promo.CheckUses(p, emailUses)
+	if err != nil {
+		return 0, err
+	}
+
+	// the rule is kept with the stay so moving it discounts the new dates the way the code did when it was redeemed
+	p = reservationExchange(*res).promoCode(p)
+	res.PromoCode = p.Code
+	res.PromoDiscountType = p.DiscountType
+	res.PromoDiscountValue = p.DiscountValue
+	res.Discount = promo.Discount(p, quote.Total)
+	res.TotalPrice = quote.Total - res.Discount
+
+	return p.ID, nil
+}
+
+// reservationPromo is the rule of the code a stay was booked with, as it was when the code was redeemed
+func reservationPromo(res models.Reservation) models.PromoCode {
+	return models.PromoCode{
+		Code: res.PromoCode,
+		DiscountType: res.PromoDiscountType,
+		DiscountValue: res.PromoDiscountValue,
+	}
+}
+
+// redeemPromo records the use of the code by the inserted reservation
+func (m *Repository) redeemPromo(ctx context.Context, tx *sql.Tx, promoCodeId int, res models.Reservation) error {
+	_, err := m.DB.InsertPromoRedemption(ctx, tx, models.PromoRedemption{
+		PromoCodeID: promoCodeId,
+		ReservationID: res.ID,
+		Email: res.Email,
+		EmailKey: guests.NormalizeEmail(res.Email),
+		Discount: res.Discount,
+	})
+	return err
+}
+
+// quotePromo looks up the code a quote is asked for. A code that cannot be found is returned as a promo error so
+// the quote still prices the stays without it
+func (m *Repository) quotePromo(ctx context.Context, code string) (models.PromoCode, error) {
+	code = promo.Normalize(code)
+
+	p, err := m.DB.GetPromoCodeByCode(ctx, nil, code)
+	if errors.Is(err, sql.ErrNoRows) {
+		return p, promo.NotFound(code)
+	}
+	if err != nil {
+		return p, err
+	}
+
+	// the cap per guest is checked when booking, a quote does not know who books
+	return p, promo.CheckUses(p, 0)
+}
+
+// withPromo discounts a room quote, or explains why the code does not apply to it
+func withPromo(q *types.RoomQuoteResponse, p models.PromoCode, promoErr *promo.Error, quote pricing.Quote) {
+	if promoErr == nil {
+		promoErr, _ = promo.Check(p, q.RoomId, len(quote.Nights), time.Now()).(*promo.Error)
+	}
+
+	if promoErr != nil {
+		q.PromoError = promoErr
+		return
+	}
+
+	q.PromoCode = p.Code
+	q.Discount = promo.Discount(p, quote.Total)
+	q.Total = quote.Total - q.Discount
+}
+
+// promoCode builds a code from the admin request body, it returns an error the client can fix when the body is invalid
+func promoCode(body dtos.PromoCodeBody) (models.PromoCode, error) {
+	layout := "2006-01-02"
+
+	p := models.PromoCode{
+		Code: promo.Normalize(body.Code),
+		DiscountType: body.DiscountType,
+		DiscountValue: body.DiscountValue,
+		MinNights: body.MinNights,
+		MaxUses: body.MaxUses,
+		MaxUsesPerEmail: body.MaxUsesPerEmail,
+		RoomIDs: body.RoomIds,
+	}
+
+	if p.DiscountType == models.DiscountPercent && p.DiscountValue > 100 {
+		return p, errors.New("a percent discount cannot be more than 100")
+	}
+
+	var err error
+	if body.ValidFrom != "" {
+		p.ValidFrom, err = time.Parse(layout, body.ValidFrom)
+		if err != nil {
+			return p, err
+		}
+	}
+
+	if body.ValidUntil != "" {
+		p.ValidUntil, err = time.Parse(layout, body.ValidUntil)
+		if err != nil {
+			return p, err
+		}
+	}
+
+	if !p.ValidFrom.IsZero() && !p.ValidUntil.IsZero() && p.ValidUntil.Before(p.ValidFrom) {
+		return p, errors.New("validUntil cannot be before validFrom")
+	}
+
+	return p, nil
+}
+
+func (m *Repository) GetPromoCodes(w http.ResponseWriter, r *http.Request) {
+	codes, err := m.DB.GetPromoCodes(context.Background(), nil)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, codes, http.StatusOK, "promo codes retrieved successfully")
+}
+
+// PostPromoCode creates a code. Codes are stored upper-case and guests can type them in any case
+func (m *Repository) PostPromoCode(w http.ResponseWriter, r *http.Request) {
+	var body dtos.PromoCodeBody
+	requestBody, ok := r.Context().Value("validatedRequestBody").(*dtos.PromoCodeBody)
+	if !ok || requestBody == nil {
+		helpers.ClientError(w, errors.New("failed to retrieve request body"), http.StatusBadRequest, "")
+		return
+	}
+	body = *requestBody
+
+	p, err := promoCode(body)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "")
+		return
+	}
+
+	ctx := context.Background()
+
+	_, err = m.DB.GetPromoCodeByCode(ctx, nil, p.Code)
+	if err == nil {
+		helpers.ClientError(w, errPromoCodeExists, http.StatusConflict, "")
+		return
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	p.ID, err = m.DB.InsertPromoCode(ctx, nil, p)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, p, http.StatusCreated, "promo code created successfully")
+}
+
+// UpdatePromoCode replaces the discount and conditions of a code. Reservations already booked keep their discount
+func (m *Repository) UpdatePromoCode(w http.ResponseWriter, r *http.Request) {
+	id, err := m.urlParamInt(r, "id", 2)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	var body dtos.PromoCodeBody
+	requestBody, ok := r.Context().Value("validatedRequestBody").(*dtos.PromoCodeBody)
+	if !ok || requestBody == nil {
+		helpers.ClientError(w, errors.New("failed to retrieve request body"), http.StatusBadRequest, "")
+		return
+	}
+	body = *requestBody
+
+	p, err := promoCode(body)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "")
+		return
+	}
+	p.ID = id
+
+	ctx := context.Background()
+
+	existing, err := m.DB.GetPromoCodeByCode(ctx, nil, p.Code)
+	if err == nil && existing.ID != id {
+		helpers.ClientError(w, errPromoCodeExists, http.StatusConflict, "")
+		return
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	err = m.DB.UpdatePromoCode(ctx, nil, p)
+	if errors.Is(err, sql.ErrNoRows) {
+		helpers.ClientError(w, err, http.StatusNotFound, "promo code not found")
+		return
+	}
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	p, err = m.DB.GetPromoCodeById(ctx, nil, id)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, p, http.StatusOK, "promo code updated successfully")
+}
+
+func (m *Repository) DeletePromoCode(w http.ResponseWriter, r *http.Request) {
+	id, err := m.urlParamInt(r, "id", 2)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	err = m.DB.DeletePromoCode(context.Background(), nil, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		helpers.ClientError(w, err, http.StatusNotFound, "promo code not found")
+		return
+	}
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, nil, http.StatusOK, "promo code deleted successfully")
+}
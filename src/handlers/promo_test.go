@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/dtos"
+	"github.com/Orololuwa/go-backend-boilerplate/src/money"
+	"github.com/Orololuwa/go-backend-boilerplate/src/promo"
+	"github.com/Orololuwa/go-backend-boilerplate/src/types"
+)
+
+func TestRepository_PostQuotePromo(t *testing.T){
+	// three nights in room 15 cost 34000
+	var quoteTests = []struct {
+		name string
+		promoCode string
+		expectedStatusCode int
+		expectedTotal money.Amount
+		expectedError string
+	}{
+		{"percent discount", "SAVE10", http.StatusOK, 30600, ""},
+		{"code typed in lower case", " save10 ", http.StatusOK, 30600, ""},
+		{"fixed discount", "FLAT5000", http.StatusOK, 29000, ""},
+		{"unknown code", "NOSUCHCODE", http.StatusOK, 34000, promo.CodeNotFound},
+		{"expired code", "EXPIRED", http.StatusOK, 34000, promo.CodeExpired},
+		{"stay too short", "LONGSTAY", http.StatusOK, 34000, promo.CodeMinNights},
+		{"room not eligible", "SUITE", http.StatusOK, 34000, promo.CodeRoomNotEligible},
+		{"used up", "SOLDOUT", http.StatusOK, 34000, promo.CodeUsedUp},
+		{"failed code lookup", "BROKEN", http.StatusInternalServerError, 0, ""},
+	}
+
+	for _, e := range quoteTests {
+		body := dtos.QuoteBody{PostAvailabilityBody: dtos.PostAvailabilityBody{StartDate: "2050-01-06", EndDate: "2050-01-09"}, RoomId: 15, PromoCode: e.promoCode}
+		jsonData, _ := json.Marshal(body)
+
+		req, _ := http.NewRequest("POST", "/quote", bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		res := httptest.NewRecorder()
+
+		handler := mdTest.ValidateReqBody(http.HandlerFunc(Repo.PostQuote), &dtos.QuoteBody{})
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("PostQuote handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+			continue
+		}
+		if res.Code != http.StatusOK {
+			continue
+		}
+
+		var resp struct {
+			Data []types.RoomQuoteResponse `json:"data"`
+		}
+		err := json.Unmarshal(res.Body.Bytes(), &resp)
+		if err != nil || len(resp.Data) != 1 {
+			t.Fatalf("PostQuote handler returned an unexpected body for %s: %s", e.name, res.Body.String())
+		}
+		quote := resp.Data[0]
+
+		if quote.Total != e.expectedTotal || quote.Total + quote.Discount != 34000 {
+			t.Errorf("PostQuote handler returned a total of %d with a discount of %d for %s, wanted %d", quote.Total, quote.Discount, e.name, e.expectedTotal)
+		}
+
+		promoErr := ""
+		if quote.PromoError != nil {
+			promoErr = quote.PromoError.Code
+		}
+		if promoErr != e.expectedError {
+			t.Errorf("PostQuote handler returned the promo error %q for %s, wanted %q", promoErr, e.name, e.expectedError)
+		}
+	}
+}
+
+func TestRepository_PostReservationPromo(t *testing.T){
+	var reservationTests = []struct {
+		name string
+		promoCode string
+		email string
+		quotedTotal money.Amount
+		expectedStatusCode int
+	}{
+		{"percent discount", "SAVE10", "johndoe@gmail.com", 0, http.StatusCreated},
+		{"matching discounted quote", "SAVE10", "johndoe@gmail.com", 30600, http.StatusCreated},
+		{"quote without the discount", "SAVE10", "johndoe@gmail.com", 34000, http.StatusConflict},
+		{"first use by the guest", "ONCE", "johndoe@gmail.com", 0, http.StatusCreated},
+		{"unknown code", "NOSUCHCODE", "johndoe@gmail.com", 0, http.StatusBadRequest},
+		{"expired code", "EXPIRED", "johndoe@gmail.com", 0, http.StatusBadRequest},
+		{"room not eligible", "SUITE", "johndoe@gmail.com", 0, http.StatusBadRequest},
+		{"used up", "SOLDOUT", "johndoe@gmail.com", 0, http.StatusConflict},
+		{"used up by the guest", "ONCE", "repeat@example.com", 0, http.StatusConflict},
+		{"used up by the guest with a tagged address", "ONCE", "Repeat+spa@Example.com", 0, http.StatusConflict},
+		{"failed code lookup", "BROKEN", "johndoe@gmail.com", 0, http.StatusInternalServerError},
+		{"failed redemption", "SAVE10", "unredeemable@example.com", 0, http.StatusInternalServerError},
+	}
+
+	for _, e := range reservationTests {
+		body := dtos.ReservationBody{
+			FirstName: "John",
+			LastName: "Doe",
+			Email: e.email,
+			Phone: "08012345678",
+			StartDate: "2050-01-06",
+			EndDate: "2050-01-09",
+			RoomId: 15,
+			QuotedTotal: e.quotedTotal,
+			PromoCode: e.promoCode,
+		}
+		jsonBody, _ := json.Marshal(body)
+
+		req, _ := http.NewRequest("POST", "/reservation", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(Repo.PostReservation)
+		handler.ServeHTTP(rr, req)
+
+		if rr.Code != e.expectedStatusCode {
+			t.Errorf("PostReservation handler returned wrong response code for %s: got %d, wanted %d", e.name, rr.Code, e.expectedStatusCode)
+			continue
+		}
+		if rr.Code != http.StatusCreated {
+			continue
+		}
+
+		var resp struct {
+			Data types.ReservationResponse `json:"data"`
+		}
+		_ = json.Unmarshal(rr.Body.Bytes(), &resp)
+
+		if resp.Data.PromoCode != e.promoCode || resp.Data.TotalPrice + resp.Data.Discount != 34000 || resp.Data.Discount == 0 {
+			t.Errorf("PostReservation handler returned the wrong discount for %s: %+v", e.name, resp.Data)
+		}
+	}
+}
+
+func TestRepository_PostPromoCode(t *testing.T){
+	var promoTests = []struct {
+		name string
+		body dtos.PromoCodeBody
+		expectedStatusCode int
+	}{
+		{"success", dtos.PromoCodeBody{Code: "Spring25", DiscountType: "percent", DiscountValue: 25, ValidFrom: "2050-03-01", ValidUntil: "2050-06-01", MinNights: 2, MaxUses: 100, MaxUsesPerEmail: 1, RoomIds: []int{15}}, http.StatusCreated},
+		{"fixed discount", dtos.PromoCodeBody{Code: "TENOFF", DiscountType: "fixed", DiscountValue: 1000}, http.StatusCreated},
+		{"existing code", dtos.PromoCodeBody{Code: "save10", DiscountType: "percent", DiscountValue: 10}, http.StatusConflict},
+		{"missing code", dtos.PromoCodeBody{DiscountType: "percent", DiscountValue: 10}, http.StatusBadRequest},
+		{"unknown discount type", dtos.PromoCodeBody{Code: "ODD", DiscountType: "double", DiscountValue: 10}, http.StatusBadRequest},
+		{"percent above the total", dtos.PromoCodeBody{Code: "TOOMUCH", DiscountType: "percent", DiscountValue: 150}, http.StatusBadRequest},
+		{"no discount", dtos.PromoCodeBody{Code: "NOTHING", DiscountType: "fixed"}, http.StatusBadRequest},
+		{"invalid date", dtos.PromoCodeBody{Code: "SPRING", DiscountType: "percent", DiscountValue: 10, ValidFrom: "invalid"}, http.StatusBadRequest},
+		{"single day window", dtos.PromoCodeBody{Code: "LEAPDAY", DiscountType: "percent", DiscountValue: 10, ValidFrom: "2052-02-29", ValidUntil: "2052-02-29"}, http.StatusCreated},
+		{"window ending before it starts", dtos.PromoCodeBody{Code: "SPRING", DiscountType: "percent", DiscountValue: 10, ValidFrom: "2050-06-01", ValidUntil: "2050-03-01"}, http.StatusBadRequest},
+		{"failed code lookup", dtos.PromoCodeBody{Code: "BROKEN", DiscountType: "percent", DiscountValue: 10}, http.StatusInternalServerError},
+		{"failed insert", dtos.PromoCodeBody{Code: "FAILED", DiscountType: "percent", DiscountValue: 10}, http.StatusInternalServerError},
+	}
+
+	for _, e := range promoTests {
+		jsonData, _ := json.Marshal(e.body)
+
+		req, _ := http.NewRequest("POST", "/promo-code", bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		res := httptest.NewRecorder()
+
+		handler := mdTest.ValidateReqBody(http.HandlerFunc(Repo.PostPromoCode), &dtos.PromoCodeBody{})
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("PostPromoCode handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+}
+
+func TestRepository_GetPromoCodes(t *testing.T){
+	req, _ := http.NewRequest("GET", "/promo-code", nil)
+	res := httptest.NewRecorder()
+
+	handler := http.HandlerFunc(Repo.GetPromoCodes)
+	handler.ServeHTTP(res, req)
+
+	if res.Code != http.StatusOK {
+		t.Errorf("GetPromoCodes handler returned wrong response code: got %d, wanted %d", res.Code, http.StatusOK)
+	}
+}
+
+func TestRepository_UpdatePromoCode(t *testing.T){
+	var updateTests = []struct {
+		name string
+		url string
+		body dtos.PromoCodeBody
+		expectedStatusCode int
+	}{
+		{"success", "/promo-code/1", dtos.PromoCodeBody{Code: "SAVE15", DiscountType: "percent", DiscountValue: 15}, http.StatusOK},
+		{"same code", "/promo-code/1", dtos.PromoCodeBody{Code: "SAVE10", DiscountType: "percent", DiscountValue: 12}, http.StatusOK},
+		{"code of another promo", "/promo-code/1", dtos.PromoCodeBody{Code: "FLAT5000", DiscountType: "fixed", DiscountValue: 5000}, http.StatusConflict},
+		{"promo code not found", "/promo-code/404", dtos.PromoCodeBody{Code: "SAVE15", DiscountType: "percent", DiscountValue: 15}, http.StatusNotFound},
+		{"failed update", "/promo-code/1000", dtos.PromoCodeBody{Code: "SAVE15", DiscountType: "percent", DiscountValue: 15}, http.StatusInternalServerError},
+		{"invalid body", "/promo-code/1", dtos.PromoCodeBody{Code: "SAVE15", DiscountType: "percent", DiscountValue: 150}, http.StatusBadRequest},
+		{"invalid id", "/promo-code/invalid", dtos.PromoCodeBody{Code: "SAVE15", DiscountType: "percent", DiscountValue: 15}, http.StatusBadRequest},
+	}
+
+	for _, e := range updateTests {
+		jsonData, _ := json.Marshal(e.body)
+
+		req, _ := http.NewRequest("PUT", e.url, bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		res := httptest.NewRecorder()
+
+		handler := mdTest.ValidateReqBody(http.HandlerFunc(Repo.UpdatePromoCode), &dtos.PromoCodeBody{})
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("UpdatePromoCode handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+}
+
+func TestRepository_DeletePromoCode(t *testing.T){
+	var deleteTests = []struct {
+		name string
+		url string
+		expectedStatusCode int
+	}{
+		{"success", "/promo-code/1", http.StatusOK},
+		{"promo code not found", "/promo-code/404", http.StatusNotFound},
+		{"invalid id", "/promo-code/invalid", http.StatusBadRequest},
+	}
+
+	for _, e := range deleteTests {
+		req, _ := http.NewRequest("DELETE", e.url, nil)
+		res := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(Repo.DeletePromoCode)
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("DeletePromoCode handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+}
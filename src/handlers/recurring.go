@@ -0,0 +1,274 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/dtos"
+	"github.com/Orololuwa/go-backend-boilerplate/src/helpers"
+	"github.com/Orololuwa/go-backend-boilerplate/src/ical"
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+	"github.com/Orololuwa/go-backend-boilerplate/src/types"
+)
+
+// how far ahead the occurrences of recurring blocks are listed when no end date is given
+const recurringBlockWindow = 90 * 24 * time.Hour
+
+var errNotAnOccurrence = errors.New("date is not an occurrence of the recurring block")
+
+func recurringBlockResponse(b models.RecurringBlock, start, end time.Time) (types.RecurringBlockResponse, error) {
+	layout := "2006-01-02"
+
+	occurrences, err := b.Occurrences(start, end)
+	if err != nil {
+		return types.RecurringBlockResponse{}, err
+	}
+
+	res := types.RecurringBlockResponse{
+		ID: b.ID,
+		RoomId: b.RoomID,
+		RestrictionId: b.RestrictionID,
+		RestrictionName: b.Restriction.RestrictionName,
+		StartDate: b.StartDate.Format(layout),
+		Nights: b.Nights,
+		RRule: b.RRule,
+		Note: b.Note,
+		Exceptions: make([]string, 0, len(b.Exceptions)),
+		Occurrences: make([]string, 0, len(occurrences)),
+	}
+
+	for _, e := range b.Exceptions {
+		res.Exceptions = append(res.Exceptions, e.Format(layout))
+	}
+	for _, o := range occurrences {
+		res.Occurrences = append(res.Occurrences, o.Format(layout))
+	}
+
+	return res, nil
+}
+
+// PostRecurringBlock blocks the room on every occurrence of an RRULE, such as "FREQ=WEEKLY;BYDAY=MO" for a weekly
+// deep clean. Occurrences are never stored, both availability searches expand them for the dates they are asked about.
+// Reservations already made on future occurrences are kept, the block only stops new bookings
+func (m *Repository) PostRecurringBlock(w http.ResponseWriter, r *http.Request) {
+	roomId, err := m.urlParamInt(r, "id", 2)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	var body dtos.RecurringBlockBody
+	requestBody, ok := r.Context().Value("validatedRequestBody").(*dtos.RecurringBlockBody)
+	if !ok || requestBody == nil {
+		helpers.ClientError(w, errors.New("failed to retrieve request body"), http.StatusBadRequest, "")
+		return
+	}
+	body = *requestBody
+
+	startDate, err := time.Parse("2006-01-02", body.StartDate)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "")
+		return
+	}
+
+	rule, err := ical.ParseRRule(body.RRule)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "")
+		return
+	}
+
+	nights := body.Nights
+	if nights == 0 {
+		nights = 1
+	}
+
+	ctx := context.Background()
+
+	restriction, ok := m.blockRestriction(ctx, w, body.RestrictionId)
+	if !ok {
+		return
+	}
+
+	_, err = m.DB.GetRoomById(ctx, nil, roomId)
+	if errors.Is(err, sql.ErrNoRows) {
+		helpers.ClientError(w, err, http.StatusNotFound, "room not found")
+		return
+	}
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	block := models.RecurringBlock{
+		RoomID: roomId,
+		RestrictionID: restriction.ID,
+		StartDate: startDate,
+		Nights: nights,
+		RRule: rule.String(),
+		Note: body.Note,
+		Exceptions: make([]time.Time, 0),
+		Restriction: restriction,
+	}
+
+	block.ID, err = m.DB.InsertRecurringBlock(ctx, nil, block)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	data, err := recurringBlockResponse(block, startDate, startDate.Add(recurringBlockWindow))
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, data, http.StatusCreated, "recurring block created successfully")
+}
+
+// GetRecurringBlocks lists the room's recurring blocks with their occurrences between the optional start and end
+// query dates, the next 90 days by default
+func (m *Repository) GetRecurringBlocks(w http.ResponseWriter, r *http.Request) {
+	roomId, err := m.urlParamInt(r, "id", 2)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	startDate, endDate, err := dateWindow(r, recurringBlockWindow)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "")
+		return
+	}
+
+	blocks, err := m.DB.GetRecurringBlocksByRoomId(context.Background(), nil, roomId)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	data := make([]types.RecurringBlockResponse, 0, len(blocks))
+	for _, b := range blocks {
+		res, err := recurringBlockResponse(b, startDate, endDate)
+		if err != nil {
+			helpers.ClientError(w, err, http.StatusInternalServerError, "")
+			return
+		}
+		data = append(data, res)
+	}
+
+	helpers.ClientResponseWriter(w, data, http.StatusOK, "recurring blocks retrieved successfully")
+}
+
+// DeleteRecurringBlock removes the block and every one of its future occurrences
+func (m *Repository) DeleteRecurringBlock(w http.ResponseWriter, r *http.Request) {
+	roomId, err := m.urlParamInt(r, "id", 2)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	blockId, err := m.urlParamInt(r, "blockId", 4)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	err = m.DB.DeleteRecurringBlock(context.Background(), nil, roomId, blockId)
+	if errors.Is(err, sql.ErrNoRows) {
+		helpers.ClientError(w, err, http.StatusNotFound, "recurring block not found")
+		return
+	}
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, nil, http.StatusOK, "recurring block deleted successfully")
+}
+
+// PostRecurringBlockException removes a single occurrence of the block, opening the room for those nights
+func (m *Repository) PostRecurringBlockException(w http.ResponseWriter, r *http.Request) {
+	roomId, err := m.urlParamInt(r, "id", 2)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	blockId, err := m.urlParamInt(r, "blockId", 4)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	var body dtos.RecurringBlockExceptionBody
+	requestBody, ok := r.Context().Value("validatedRequestBody").(*dtos.RecurringBlockExceptionBody)
+	if !ok || requestBody == nil {
+		helpers.ClientError(w, errors.New("failed to retrieve request body"), http.StatusBadRequest, "")
+		return
+	}
+	body = *requestBody
+
+	occurrence, err := time.Parse("2006-01-02", body.Date)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "")
+		return
+	}
+
+	ctx := context.Background()
+
+	block, err := m.DB.GetRecurringBlockById(ctx, nil, blockId)
+	if err == nil && block.RoomID != roomId {
+		err = sql.ErrNoRows
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		helpers.ClientError(w, err, http.StatusNotFound, "recurring block not found")
+		return
+	}
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	// the date must be the first night of an occurrence, removing part of one would leave the rest dangling
+	occurrences, err := block.Occurrences(occurrence, occurrence.AddDate(0, 0, 1))
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	found := false
+	for _, o := range occurrences {
+		found = found || o.Equal(occurrence)
+	}
+	if !found && !block.IsException(occurrence) {
+		helpers.ClientError(w, errNotAnOccurrence, http.StatusBadRequest, "")
+		return
+	}
+
+	err = m.DB.InsertRecurringBlockException(ctx, nil, block.ID, occurrence)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	if !block.IsException(occurrence) {
+		block.Exceptions = append(block.Exceptions, occurrence)
+	}
+
+	// the released nights may be what someone on the waitlist is waiting for
+	_, err = m.Waitlist.OfferReleased(ctx, block.RoomID, occurrence, occurrence.AddDate(0, 0, block.Nights))
+	if err != nil {
+		m.App.ErrorLog.Println(err)
+	}
+
+	data, err := recurringBlockResponse(block, occurrence, occurrence.Add(recurringBlockWindow))
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, data, http.StatusOK, "occurrence removed successfully")
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/dtos"
+	"github.com/Orololuwa/go-backend-boilerplate/src/types"
+)
+
+func TestRepository_PostRecurringBlock(t *testing.T){
+	var blockTests = []struct {
+		name string
+		url string
+		body dtos.RecurringBlockBody
+		expectedStatusCode int
+	}{
+		{"every monday", "/room/15/recurring-block", dtos.RecurringBlockBody{StartDate: "2050-01-03", RRule: "FREQ=WEEKLY;BYDAY=MO", RestrictionId: 5}, http.StatusCreated},
+		{"every january", "/room/15/recurring-block", dtos.RecurringBlockBody{StartDate: "2050-01-01", Nights: 31, RRule: "RRULE:FREQ=YEARLY"}, http.StatusCreated},
+		{"invalid rule", "/room/15/recurring-block", dtos.RecurringBlockBody{StartDate: "2050-01-03", RRule: "FREQ=SOMETIMES"}, http.StatusBadRequest},
+		{"unsupported rule", "/room/15/recurring-block", dtos.RecurringBlockBody{StartDate: "2050-01-03", RRule: "FREQ=HOURLY"}, http.StatusBadRequest},
+		{"invalid date", "/room/15/recurring-block", dtos.RecurringBlockBody{StartDate: "invalid", RRule: "FREQ=DAILY"}, http.StatusBadRequest},
+		{"managed type", "/room/15/recurring-block", dtos.RecurringBlockBody{StartDate: "2050-01-03", RRule: "FREQ=DAILY", RestrictionId: 1}, http.StatusBadRequest},
+		{"room not found", "/room/404/recurring-block", dtos.RecurringBlockBody{StartDate: "2050-01-03", RRule: "FREQ=DAILY"}, http.StatusNotFound},
+	}
+
+	for _, e := range blockTests {
+		jsonData, _ := json.Marshal(e.body)
+
+		req, _ := http.NewRequest("POST", e.url, bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		res := httptest.NewRecorder()
+
+		handler := mdTest.ValidateReqBody(http.HandlerFunc(Repo.PostRecurringBlock), &dtos.RecurringBlockBody{})
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("PostRecurringBlock handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+}
+
+func TestRepository_GetRecurringBlocks(t *testing.T){
+	req, _ := http.NewRequest("GET", "/room/15/recurring-block?start=2050-01-01&end=2050-01-25", nil)
+	res := httptest.NewRecorder()
+
+	handler := http.HandlerFunc(Repo.GetRecurringBlocks)
+	handler.ServeHTTP(res, req)
+
+	if res.Code != http.StatusOK {
+		t.Fatalf("GetRecurringBlocks handler returned wrong response code: got %d, wanted %d", res.Code, http.StatusOK)
+	}
+
+	var body struct {
+		Data []types.RecurringBlockResponse `json:"data"`
+	}
+	json.Unmarshal(res.Body.Bytes(), &body)
+
+	// the mondays of the window, without the one removed as an exception
+	expected := []string{"2050-01-03", "2050-01-17", "2050-01-24"}
+	if len(body.Data) != 1 || len(body.Data[0].Occurrences) != len(expected) {
+		t.Fatalf("GetRecurringBlocks returned %+v, wanted occurrences %v", body.Data, expected)
+	}
+	for i, o := range body.Data[0].Occurrences {
+		if o != expected[i] {
+			t.Errorf("GetRecurringBlocks returned occurrence %s, wanted %s", o, expected[i])
+		}
+	}
+
+	var blockTests = []struct {
+		name string
+		url string
+		expectedStatusCode int
+	}{
+		{"invalid date", "/room/15/recurring-block?end=invalid", http.StatusBadRequest},
+		{"query fails", "/room/1000/recurring-block", http.StatusInternalServerError},
+	}
+
+	for _, e := range blockTests {
+		req, _ := http.NewRequest("GET", e.url, nil)
+		res := httptest.NewRecorder()
+
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("GetRecurringBlocks handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+}
+
+func TestRepository_DeleteRecurringBlock(t *testing.T){
+	var blockTests = []struct {
+		name string
+		url string
+		expectedStatusCode int
+	}{
+		{"success", "/room/15/recurring-block/1", http.StatusOK},
+		{"not found", "/room/15/recurring-block/404", http.StatusNotFound},
+	}
+
+	for _, e := range blockTests {
+		req, _ := http.NewRequest("DELETE", e.url, nil)
+		res := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(Repo.DeleteRecurringBlock)
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("DeleteRecurringBlock handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+}
+
+func TestRepository_PostRecurringBlockException(t *testing.T){
+	var exceptionTests = []struct {
+		name string
+		url string
+		date string
+		expectedStatusCode int
+	}{
+		{"occurrence", "/room/15/recurring-block/1/exception", "2050-01-17", http.StatusOK},
+		{"already removed", "/room/15/recurring-block/1/exception", "2050-01-10", http.StatusOK},
+		{"not an occurrence", "/room/15/recurring-block/1/exception", "2050-01-18", http.StatusBadRequest},
+		{"before the block starts", "/room/15/recurring-block/1/exception", "2049-12-27", http.StatusBadRequest},
+		{"block of another room", "/room/16/recurring-block/1/exception", "2050-01-17", http.StatusNotFound},
+		{"block not found", "/room/15/recurring-block/404/exception", "2050-01-17", http.StatusNotFound},
+		{"invalid date", "/room/15/recurring-block/1/exception", "invalid", http.StatusBadRequest},
+	}
+
+	for _, e := range exceptionTests {
+		jsonData, _ := json.Marshal(dtos.RecurringBlockExceptionBody{Date: e.date})
+
+		req, _ := http.NewRequest("POST", e.url, bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		res := httptest.NewRecorder()
+
+		handler := mdTest.ValidateReqBody(http.HandlerFunc(Repo.PostRecurringBlockException), &dtos.RecurringBlockExceptionBody{})
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("PostRecurringBlockException handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+}
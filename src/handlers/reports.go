@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/helpers"
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+	"github.com/Orololuwa/go-backend-boilerplate/src/reports"
+	"github.com/Orololuwa/go-backend-boilerplate/src/types"
+)
+
+const (
+	// the longest range a report covers, and the range reported on when no end date is given
+	maxReportDays = 366
+	reportWindow = 30 * 24 * time.Hour
+)
+
+var errReportRange = errors.New("a report covers at most 366 days")
+
+// report is a report ready to be written, its rows and total as they are sent in JSON and as CSV records
+type report struct {
+	name string
+	header []string
+	rows interface{}
+	records []reports.Row
+	total interface{}
+	totals []reports.Row
+}
+
+// roomReports loads the counts of every room between the start and end query dates, the next 30 days by default.
+// It writes the error and returns false when the dates are wrong or the counts cannot be loaded
+func (m *Repository) roomReports(w http.ResponseWriter, r *http.Request) ([]models.RoomReport, time.Time, time.Time, int, bool) {
+	startDate, endDate, err := dateWindow(r, reportWindow)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "")
+		return nil, startDate, endDate, 0, false
+	}
+
+	if !endDate.After(startDate) {
+		helpers.ClientError(w, errors.New("end must be after start"), http.StatusBadRequest, "")
+		return nil, startDate, endDate, 0, false
+	}
+
+	days := int(endDate.Sub(startDate).Hours() / 24)
+	if days > maxReportDays {
+		helpers.ClientError(w, errReportRange, http.StatusBadRequest, "")
+		return nil, startDate, endDate, 0, false
+	}
+
+	rooms, err := m.DB.GetRoomReports(context.Background(), nil, startDate, endDate)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return nil, startDate, endDate, 0, false
+	}
+
+	return rooms, startDate, endDate, days, true
+}
+
+// wantsCSV tells whether the report was asked for as CSV, with ?format=csv or an Accept header
+func wantsCSV(r *http.Request) bool {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return format == "csv"
+	}
+	return strings.Contains(r.Header.Get("Accept"), "text/csv")
+}
+
+// writeReport writes the report as a CSV download or as JSON
+func writeReport(w http.ResponseWriter, r *http.Request, rep report, startDate, endDate time.Time, days int) {
+	layout := "2006-01-02"
+
+	if wantsCSV(r) {
+		var buf bytes.Buffer
+		err := reports.WriteCSV(&buf, rep.header, rep.records, rep.totals...)
+		if err != nil {
+			helpers.ClientError(w, err, http.StatusInternalServerError, "")
+			return
+		}
+
+		filename := fmt.Sprintf("%s-%s-%s.csv", rep.name, startDate.Format(layout), endDate.Format(layout))
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		w.WriteHeader(http.StatusOK)
+		w.Write(buf.Bytes())
+		return
+	}
+
+	data := types.ReportResponse{
+		StartDate: startDate.Format(layout),
+		EndDate: endDate.Format(layout),
+		Days: days,
+		Rooms: rep.rows,
+		Total: rep.total,
+	}
+
+	helpers.ClientResponseWriter(w, data, http.StatusOK, rep.name + " report retrieved successfully")
+}
+
+// GetOccupancyReport returns the occupancy rate of every room between the start and end query dates, end exclusive.
+// Nights blocked by staff or an imported calendar are taken out of the nights a room could be sold
+func (m *Repository) GetOccupancyReport(w http.ResponseWriter, r *http.Request) {
+	rooms, startDate, endDate, days, ok := m.roomReports(w, r)
+	if !ok {
+		return
+	}
+
+	rows, total := reports.Occupancy(rooms, days)
+
+	records := make([]reports.Row, len(rows))
+	for i, row := range rows {
+		records[i] = row
+	}
+
+	writeReport(w, r, report{"occupancy", reports.OccupancyHeader, rows, records, total, []reports.Row{total}}, startDate, endDate, days)
+}
+
+// GetRevenueReport returns the room revenue, ADR and RevPAR of every room between the start and end query dates.
+// A stay's price is spread evenly over its nights so stays crossing the range count only the nights inside it.
+// The total is a list, one per currency the rooms are priced in
+func (m *Repository) GetRevenueReport(w http.ResponseWriter, r *http.Request) {
+	rooms, startDate, endDate, days, ok := m.roomReports(w, r)
+	if !ok {
+		return
+	}
+
+	rows, totals := reports.Revenue(rooms, days)
+
+	records := make([]reports.Row, len(rows))
+	for i, row := range rows {
+		records[i] = row
+	}
+
+	totalRecords := make([]reports.Row, len(totals))
+	for i, total := range totals {
+		totalRecords[i] = total
+	}
+
+	writeReport(w, r, report{"revenue", reports.RevenueHeader, rows, records, totals, totalRecords}, startDate, endDate, days)
+}
+
+// GetStaysReport returns the average length of stay and lead time of the reservations arriving between the start and end query dates
+func (m *Repository) GetStaysReport(w http.ResponseWriter, r *http.Request) {
+	rooms, startDate, endDate, days, ok := m.roomReports(w, r)
+	if !ok {
+		return
+	}
+
+	rows, total := reports.Stays(rooms)
+
+	records := make([]reports.Row, len(rows))
+	for i, row := range rows {
+		records[i] = row
+	}
+
+	writeReport(w, r, report{"stays", reports.StayHeader, rows, records, total, []reports.Row{total}}, startDate, endDate, days)
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/reports"
+)
+
+func TestRepository_GetOccupancyReport(t *testing.T){
+	req, _ := http.NewRequest("GET", "/admin/reports/occupancy?start=2050-01-01&end=2050-01-11", nil)
+	res := httptest.NewRecorder()
+
+	handler := http.HandlerFunc(Repo.GetOccupancyReport)
+	handler.ServeHTTP(res, req)
+
+	if res.Code != http.StatusOK {
+		t.Fatalf("GetOccupancyReport handler returned wrong response code: got %d, wanted %d", res.Code, http.StatusOK)
+	}
+
+	var body struct {
+		Data struct {
+			Days int `json:"days"`
+			Rooms []reports.OccupancyRow `json:"rooms"`
+			Total reports.OccupancyRow `json:"total"`
+		} `json:"data"`
+	}
+	json.Unmarshal(res.Body.Bytes(), &body)
+
+	if body.Data.Days != 10 || len(body.Data.Rooms) != 2 {
+		t.Fatalf("GetOccupancyReport returned %d days and %d rooms, wanted 10 days and 2 rooms", body.Data.Days, len(body.Data.Rooms))
+	}
+	if body.Data.Rooms[0].OccupancyRate != 0.6 || body.Data.Rooms[1].AvailableNights != 8 {
+		t.Errorf("GetOccupancyReport returned %+v, wanted room 15 at 0.6 and 8 nights to sell in room 16", body.Data.Rooms)
+	}
+	if body.Data.Total.AvailableNights != 18 || body.Data.Total.SoldNights != 8 {
+		t.Errorf("GetOccupancyReport returned total %+v, wanted 8 of 18 nights sold", body.Data.Total)
+	}
+
+	var reportTests = []struct {
+		name string
+		url string
+		expectedStatusCode int
+	}{
+		{"default window", "/admin/reports/occupancy", http.StatusOK},
+		{"a year", "/admin/reports/occupancy?start=2050-01-01&end=2051-01-01", http.StatusOK},
+		{"more than 366 days", "/admin/reports/occupancy?start=2050-01-01&end=2051-01-03", http.StatusBadRequest},
+		{"end before start", "/admin/reports/occupancy?start=2050-01-11&end=2050-01-01", http.StatusBadRequest},
+		{"invalid date", "/admin/reports/occupancy?start=invalid", http.StatusBadRequest},
+		{"query fails", "/admin/reports/occupancy?start=1959-01-01&end=1959-01-11", http.StatusInternalServerError},
+	}
+
+	for _, e := range reportTests {
+		req, _ := http.NewRequest("GET", e.url, nil)
+		res := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(Repo.GetOccupancyReport)
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("GetOccupancyReport handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+}
+
+func TestRepository_GetRevenueReport(t *testing.T){
+	req, _ := http.NewRequest("GET", "/admin/reports/revenue?start=2050-01-01&end=2050-01-11", nil)
+	res := httptest.NewRecorder()
+
+	handler := http.HandlerFunc(Repo.GetRevenueReport)
+	handler.ServeHTTP(res, req)
+
+	var body struct {
+		Data struct {
+			Rooms []reports.RevenueRow `json:"rooms"`
+			Total []reports.RevenueRow `json:"total"`
+		} `json:"data"`
+	}
+	json.Unmarshal(res.Body.Bytes(), &body)
+
+	if res.Code != http.StatusOK || len(body.Data.Rooms) != 2 {
+		t.Fatalf("GetRevenueReport returned %d with %+v, wanted 2 rooms", res.Code, body.Data.Rooms)
+	}
+	if body.Data.Rooms[0].ADR != 10000 || body.Data.Rooms[0].RevPAR != 6000 {
+		t.Errorf("GetRevenueReport returned %+v for room 15, wanted an ADR of 10000 and a RevPAR of 6000", body.Data.Rooms[0])
+	}
+	if len(body.Data.Total) != 1 || body.Data.Total[0].Currency != "USD" || body.Data.Total[0].Revenue != 84000 {
+		t.Errorf("GetRevenueReport returned totals %+v, wanted 84000 USD revenue", body.Data.Total)
+	}
+}
+
+func TestRepository_GetStaysReportCSV(t *testing.T){
+	var csvTests = []struct {
+		name string
+		url string
+		accept string
+	}{
+		{"format query", "/admin/reports/stays?start=2050-01-01&end=2050-01-11&format=csv", ""},
+		{"accept header", "/admin/reports/stays?start=2050-01-01&end=2050-01-11", "text/csv"},
+	}
+
+	expected := "room_id,room_name,reservations,average_length_of_stay,average_lead_days\n" +
+		"15,Test Room,2,3.50,15.00\n" +
+		"16,Other Room,1,2.00,3.00\n" +
+		"0,All rooms,3,3.00,11.00\n"
+
+	for _, e := range csvTests {
+		req, _ := http.NewRequest("GET", e.url, nil)
+		if e.accept != "" {
+			req.Header.Set("Accept", e.accept)
+		}
+		res := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(Repo.GetStaysReport)
+		handler.ServeHTTP(res, req)
+
+		if res.Code != http.StatusOK {
+			t.Fatalf("GetStaysReport handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, http.StatusOK)
+		}
+		if !strings.HasPrefix(res.Header().Get("Content-Type"), "text/csv") {
+			t.Errorf("GetStaysReport returned content type %q for %s, wanted text/csv", res.Header().Get("Content-Type"), e.name)
+		}
+		if !strings.Contains(res.Header().Get("Content-Disposition"), "stays-2050-01-01-2050-01-11.csv") {
+			t.Errorf("GetStaysReport returned content disposition %q for %s", res.Header().Get("Content-Disposition"), e.name)
+		}
+		if res.Body.String() != expected {
+			t.Errorf("GetStaysReport wrote %q for %s, wanted %q", res.Body.String(), e.name, expected)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/helpers"
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+	"github.com/Orololuwa/go-backend-boilerplate/src/types"
+)
+
+const confirmationCodeAttempts = 5
+
+// newConfirmationCode draws codes until it finds one that is not already taken
+func (m *Repository) newConfirmationCode(ctx context.Context, tx *sql.Tx) (string, error) {
+	for i := 0; i < confirmationCodeAttempts; i++ {
+		code, err := helpers.ConfirmationCode()
+		if err != nil {
+			return "", err
+		}
+
+		exists, err := m.DB.ConfirmationCodeExists(ctx, tx, code)
+		if err != nil {
+			return "", err
+		}
+
+		if !exists {
+			return code, nil
+		}
+	}
+
+	return "", errors.New("failed to generate a unique confirmation code")
+}
+
+func reservationResponse(res models.Reservation) types.ReservationResponse {
+	layout := "2006-01-02"
+
+	return types.ReservationResponse{
+		ID: res.ID,
+		ConfirmationCode: res.ConfirmationCode,
+		FirstName: res.FirstName,
+		LastName: res.LastName,
+		Email: res.Email,
+		Phone: res.Phone,
+		StartDate: res.StartDate.Format(layout),
+		EndDate: res.EndDate.Format(layout),
+		RoomId: res.RoomID,
+		RoomName: res.Room.RoomName,
+		Adults: res.Adults,
+		Children: res.Children,
+		TotalPrice: res.TotalPrice,
+		Currency: res.Currency,
+		BaseCurrency: res.BaseCurrency,
+		ExchangeRate: res.ExchangeRate,
+		GuestId: res.GuestID,
+		SpecialRequests: res.SpecialRequests,
+		RequestsResolved: res.RequestsResolved,
+		Status: res.Status,
+		CancellationFee: res.CancellationFee,
+		RefundAmount: res.RefundAmount,
+		CancellationPolicy: res.CancellationPolicy,
+		PromoCode: res.PromoCode,
+		Discount: res.Discount,
+		Charges: res.Charges,
+	}
+}
+
+// LookupReservation lets a guest find their booking with the confirmation code and their last name
+func (m *Repository) LookupReservation(w http.ResponseWriter, r *http.Request) {
+	code := helpers.NormalizeConfirmationCode(r.URL.Query().Get("code"))
+	lastName := strings.TrimSpace(r.URL.Query().Get("lastName"))
+
+	if code == "" || lastName == "" {
+		helpers.ClientError(w, errors.New("code and lastName are required"), http.StatusBadRequest, "")
+		return
+	}
+
+	res, err := m.DB.GetReservationByConfirmationCode(context.Background(), nil, code, lastName)
+	if errors.Is(err, sql.ErrNoRows) {
+		helpers.ClientError(w, err, http.StatusNotFound, "reservation not found")
+		return
+	}
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	res.Charges, err = m.DB.GetReservationCharges(context.Background(), nil, res.ID)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, reservationResponse(res), http.StatusOK, "reservation retrieved successfully")
+}
+
+// reservationFilter reads the room_id, guest_id, status, start, end, arriving_from and unresolved_requests query
+// filters of the reservation list. Start and end keep the stays overlapping the dates. The upcoming arrivals with
+// requests still to see to are ?arriving_from=<today>&unresolved_requests=true
+func reservationFilter(r *http.Request) (models.ReservationFilter, error) {
+	var filter models.ReservationFilter
+	query := r.URL.Query()
+	layout := "2006-01-02"
+
+	if query.Has("room_id") {
+		roomId, err := strconv.Atoi(query.Get("room_id"))
+		if err != nil {
+			return filter, err
+		}
+		filter.RoomID = roomId
+	}
+
+	if query.Has("guest_id") {
+		guestId, err := strconv.Atoi(query.Get("guest_id"))
+		if err != nil {
+			return filter, err
+		}
+		filter.GuestID = guestId
+	}
+
+	filter.Status = query.Get("status")
+
+	if v := query.Get("start"); v != "" {
+		start, err := time.Parse(layout, v)
+		if err != nil {
+			return filter, err
+		}
+		filter.Start = start
+	}
+
+	if v := query.Get("end"); v != "" {
+		end, err := time.Parse(layout, v)
+		if err != nil {
+			return filter, err
+		}
+		filter.End = end
+	}
+
+	if v := query.Get("arriving_from"); v != "" {
+		arrivingFrom, err := time.Parse(layout, v)
+		if err != nil {
+			return filter, err
+		}
+		filter.ArrivingFrom = arrivingFrom
+	}
+
+	if query.Has("unresolved_requests") {
+		unresolved, err := strconv.ParseBool(query.Get("unresolved_requests"))
+		if err != nil {
+			return filter, err
+		}
+		filter.UnresolvedRequests = unresolved
+	}
+
+	return filter, nil
+}
+
+// GetReservations lists the reservations matching the query filters for staff, newest stays first
+func (m *Repository) GetReservations(w http.ResponseWriter, r *http.Request) {
+	filter, err := reservationFilter(r)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "")
+		return
+	}
+
+	reservations, err := m.DB.GetReservations(context.Background(), nil, filter)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	data := make([]types.ReservationResponse, 0, len(reservations))
+	for _, res := range reservations {
+		data = append(data, reservationResponse(res))
+	}
+
+	helpers.ClientResponseWriter(w, data, http.StatusOK, "reservations retrieved successfully")
+}
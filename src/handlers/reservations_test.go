@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/dtos"
+	"github.com/Orololuwa/go-backend-boilerplate/src/types"
+)
+
+func TestRepository_PostReservationConfirmationCode(t *testing.T){
+	body := dtos.ReservationBody{
+		FirstName: "John",
+		LastName: "Doe",
+		Email: "johndoe@gmail.com",
+		Phone: "08012345678",
+		StartDate: "2050-01-01",
+		EndDate: "2050-01-05",
+		RoomId: 15,
+	}
+
+	jsonBody, err := json.Marshal(body)
+	if err != nil {
+		t.Log("Error:", err)
+		return
+	}
+
+	req, _ := http.NewRequest("POST", "/reservation", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+
+	handler := http.HandlerFunc(Repo.PostReservation)
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusCreated {
+		t.Errorf("PostReservation handler returned wrong response code: got %d, wanted %d", rr.Code, http.StatusCreated)
+	}
+
+	var resp struct {
+		Data types.ReservationResponse `json:"data"`
+	}
+	err = json.Unmarshal(rr.Body.Bytes(), &resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(resp.Data.ConfirmationCode) != 8 {
+		t.Errorf("PostReservation handler returned an invalid confirmation code: %q", resp.Data.ConfirmationCode)
+	}
+}
+
+func TestRepository_LookupReservation(t *testing.T){
+	var lookupTests = []struct {
+		name string
+		code string
+		lastName string
+		expectedStatusCode int
+	}{
+		{"valid lookup", "ABCD-2345", "Doe", http.StatusOK},
+		{"missing code", "", "Doe", http.StatusBadRequest},
+		{"missing last name", "ABCD2345", "", http.StatusBadRequest},
+		{"unknown code", "NOTFOUND", "Doe", http.StatusNotFound},
+		{"failed db lookup", "FAILING", "Doe", http.StatusInternalServerError},
+	}
+
+	for _, e := range lookupTests {
+		req, _ := http.NewRequest("GET", "/reservation/lookup", nil)
+		params := url.Values{}
+		params.Add("code", e.code)
+		params.Add("lastName", e.lastName)
+		req.URL.RawQuery = params.Encode()
+		res := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(Repo.LookupReservation)
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("LookupReservation handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+}
@@ -0,0 +1,332 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/dtos"
+	"github.com/Orololuwa/go-backend-boilerplate/src/helpers"
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+	"github.com/Orololuwa/go-backend-boilerplate/src/types"
+)
+
+// how far ahead the room blocks are listed when no end date is given
+const roomBlockWindow = 365 * 24 * time.Hour
+
+var (
+	errSystemRestriction = errors.New("restriction type is used by the app and cannot be deleted")
+	errRestrictionInUse = errors.New("restriction type is still used by room restrictions")
+	errManagedRestriction = errors.New("restriction type cannot be used for a room block")
+)
+
+func roomBlockResponse(b models.RoomRestriction) types.RoomBlockResponse {
+	return types.RoomBlockResponse{
+		ID: b.ID,
+		RoomId: b.RoomID,
+		RestrictionId: b.RestrictionID,
+		RestrictionName: b.Restriction.RestrictionName,
+		StartDate: b.StartDate.Format("2006-01-02"),
+		EndDate: b.EndDate.Format("2006-01-02"),
+		Note: b.Note,
+	}
+}
+
+// dateWindow reads the optional start and end query dates. The window starts today and lasts length by default
+func dateWindow(r *http.Request, length time.Duration) (time.Time, time.Time, error) {
+	layout := "2006-01-02"
+
+	start := time.Now().UTC().Truncate(24 * time.Hour)
+	if v := r.URL.Query().Get("start"); v != "" {
+		t, err := time.Parse(layout, v)
+		if err != nil {
+			return start, start, err
+		}
+		start = t
+	}
+
+	end := start.Add(length)
+	if v := r.URL.Query().Get("end"); v != "" {
+		t, err := time.Parse(layout, v)
+		if err != nil {
+			return start, end, err
+		}
+		end = t
+	}
+
+	return start, end, nil
+}
+
+// blockRestriction looks up the restriction type of a staff block, an owner block when none is given.
+// Reservations, holds and imported blocks have their own lifecycle and are never created by hand
+func (m *Repository) blockRestriction(ctx context.Context, w http.ResponseWriter, id int) (models.Restriction, bool) {
+	if id == 0 {
+		id = models.RestrictionOwnerBlock
+	}
+
+	if models.IsManagedRestriction(id) {
+		helpers.ClientError(w, errManagedRestriction, http.StatusBadRequest, "")
+		return models.Restriction{}, false
+	}
+
+	restriction, err := m.DB.GetRestrictionById(ctx, nil, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		helpers.ClientError(w, err, http.StatusBadRequest, "restriction not found")
+		return restriction, false
+	}
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return restriction, false
+	}
+
+	return restriction, true
+}
+
+func (m *Repository) GetRestrictions(w http.ResponseWriter, r *http.Request) {
+	restrictions, err := m.DB.GetRestrictions(context.Background(), nil)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, restrictions, http.StatusOK, "restrictions retrieved successfully")
+}
+
+func (m *Repository) PostRestriction(w http.ResponseWriter, r *http.Request) {
+	var body dtos.RestrictionBody
+	requestBody, ok := r.Context().Value("validatedRequestBody").(*dtos.RestrictionBody)
+	if !ok || requestBody == nil {
+		helpers.ClientError(w, errors.New("failed to retrieve request body"), http.StatusBadRequest, "")
+		return
+	}
+	body = *requestBody
+
+	restriction := models.Restriction{
+		RestrictionName: body.RestrictionName,
+	}
+
+	var err error
+	restriction.ID, err = m.DB.InsertRestriction(context.Background(), nil, restriction)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, restriction, http.StatusCreated, "restriction created successfully")
+}
+
+// UpdateRestriction renames a restriction type. System types can be renamed, their ids are what the app relies on
+func (m *Repository) UpdateRestriction(w http.ResponseWriter, r *http.Request) {
+	id, err := m.urlParamInt(r, "id", 2)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	var body dtos.RestrictionBody
+	requestBody, ok := r.Context().Value("validatedRequestBody").(*dtos.RestrictionBody)
+	if !ok || requestBody == nil {
+		helpers.ClientError(w, errors.New("failed to retrieve request body"), http.StatusBadRequest, "")
+		return
+	}
+	body = *requestBody
+
+	restriction := models.Restriction{
+		ID: id,
+		RestrictionName: body.RestrictionName,
+	}
+
+	err = m.DB.UpdateRestriction(context.Background(), nil, restriction)
+	if errors.Is(err, sql.ErrNoRows) {
+		helpers.ClientError(w, err, http.StatusNotFound, "restriction not found")
+		return
+	}
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, restriction, http.StatusOK, "restriction updated successfully")
+}
+
+// DeleteRestriction removes a restriction type that the app does not rely on and no room restriction uses
+func (m *Repository) DeleteRestriction(w http.ResponseWriter, r *http.Request) {
+	id, err := m.urlParamInt(r, "id", 2)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	if models.IsSystemRestriction(id) {
+		helpers.ClientError(w, errSystemRestriction, http.StatusConflict, "")
+		return
+	}
+
+	ctx := context.Background()
+
+	inUse, err := m.DB.RestrictionInUse(ctx, nil, id)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	if inUse {
+		helpers.ClientError(w, errRestrictionInUse, http.StatusConflict, "")
+		return
+	}
+
+	err = m.DB.DeleteRestriction(ctx, nil, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		helpers.ClientError(w, err, http.StatusNotFound, "restriction not found")
+		return
+	}
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, nil, http.StatusOK, "restriction deleted successfully")
+}
+
+// PostRoomBlock takes the room off sale for the dates without a reservation, for the owner's own use or maintenance.
+// Both availability searches exclude every room restriction, so the block is honoured without further changes
+func (m *Repository) PostRoomBlock(w http.ResponseWriter, r *http.Request) {
+	roomId, err := m.urlParamInt(r, "id", 2)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	var body dtos.RoomBlockBody
+	requestBody, ok := r.Context().Value("validatedRequestBody").(*dtos.RoomBlockBody)
+	if !ok || requestBody == nil {
+		helpers.ClientError(w, errors.New("failed to retrieve request body"), http.StatusBadRequest, "")
+		return
+	}
+	body = *requestBody
+
+	layout := "2006-01-02"
+
+	startDate, err := time.Parse(layout, body.StartDate)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "")
+		return
+	}
+
+	endDate, err := time.Parse(layout, body.EndDate)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "")
+		return
+	}
+
+	if !endDate.After(startDate) {
+		helpers.ClientError(w, errors.New("endDate must be after startDate"), http.StatusBadRequest, "")
+		return
+	}
+
+	ctx := context.Background()
+
+	restriction, ok := m.blockRestriction(ctx, w, body.RestrictionId)
+	if !ok {
+		return
+	}
+
+	block := models.RoomRestriction{
+		StartDate: startDate,
+		EndDate: endDate,
+		RoomID: roomId,
+		RestrictionID: restriction.ID,
+		Note: body.Note,
+		Restriction: restriction,
+	}
+
+	err = m.DB.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		err := m.DB.LockRoom(ctx, tx, roomId)
+		if err != nil {
+			return err
+		}
+
+		available, err := m.DB.SearchAvailabilityForDatesByRoomId(ctx, tx, startDate, endDate, roomId)
+		if err != nil {
+			return err
+		}
+
+		if !available {
+			return errRoomUnavailable
+		}
+
+		block.ID, err = m.DB.InsertRoomBlock(ctx, tx, block)
+		return err
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		helpers.ClientError(w, err, http.StatusNotFound, "room not found")
+		return
+	}
+	if errors.Is(err, errRoomUnavailable) {
+		helpers.ClientError(w, err, http.StatusConflict, "")
+		return
+	}
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, roomBlockResponse(block), http.StatusCreated, "room block created successfully")
+}
+
+// GetRoomBlocks lists the room's staff blocks between the optional start and end query dates, from today by default
+func (m *Repository) GetRoomBlocks(w http.ResponseWriter, r *http.Request) {
+	roomId, err := m.urlParamInt(r, "id", 2)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	startDate, endDate, err := dateWindow(r, roomBlockWindow)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "")
+		return
+	}
+
+	blocks, err := m.DB.GetRoomBlocks(context.Background(), nil, roomId, startDate, endDate)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	data := make([]types.RoomBlockResponse, 0, len(blocks))
+	for _, b := range blocks {
+		data = append(data, roomBlockResponse(b))
+	}
+
+	helpers.ClientResponseWriter(w, data, http.StatusOK, "room blocks retrieved successfully")
+}
+
+// DeleteRoomBlock releases a staff block. Reservations, holds and imported blocks are not found here
+func (m *Repository) DeleteRoomBlock(w http.ResponseWriter, r *http.Request) {
+	roomId, err := m.urlParamInt(r, "id", 2)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	blockId, err := m.urlParamInt(r, "blockId", 4)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	err = m.DB.DeleteRoomBlock(context.Background(), nil, roomId, blockId)
+	if errors.Is(err, sql.ErrNoRows) {
+		helpers.ClientError(w, err, http.StatusNotFound, "room block not found")
+		return
+	}
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, nil, http.StatusOK, "room block deleted successfully")
+}
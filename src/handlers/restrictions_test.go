@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/dtos"
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+)
+
+func TestRepository_PostRestriction(t *testing.T){
+	var restrictionTests = []struct {
+		name string
+		body dtos.RestrictionBody
+		expectedStatusCode int
+	}{
+		{"success", dtos.RestrictionBody{RestrictionName: "Deep clean"}, http.StatusCreated},
+		{"missing name", dtos.RestrictionBody{}, http.StatusBadRequest},
+		{"insert fails", dtos.RestrictionBody{RestrictionName: "fail"}, http.StatusInternalServerError},
+	}
+
+	for _, e := range restrictionTests {
+		jsonData, _ := json.Marshal(e.body)
+
+		req, _ := http.NewRequest("POST", "/restriction", bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		res := httptest.NewRecorder()
+
+		handler := mdTest.ValidateReqBody(http.HandlerFunc(Repo.PostRestriction), &dtos.RestrictionBody{})
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("PostRestriction handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+}
+
+func TestRepository_UpdateRestriction(t *testing.T){
+	var restrictionTests = []struct {
+		name string
+		url string
+		expectedStatusCode int
+	}{
+		{"success", "/restriction/5", http.StatusOK},
+		{"not found", "/restriction/404", http.StatusNotFound},
+		{"invalid id", "/restriction/abc", http.StatusBadRequest},
+	}
+
+	for _, e := range restrictionTests {
+		jsonData, _ := json.Marshal(dtos.RestrictionBody{RestrictionName: "Renovation"})
+
+		req, _ := http.NewRequest("PUT", e.url, bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		res := httptest.NewRecorder()
+
+		handler := mdTest.ValidateReqBody(http.HandlerFunc(Repo.UpdateRestriction), &dtos.RestrictionBody{})
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("UpdateRestriction handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+}
+
+func TestRepository_DeleteRestriction(t *testing.T){
+	var restrictionTests = []struct {
+		name string
+		url string
+		expectedStatusCode int
+	}{
+		{"success", "/restriction/6", http.StatusOK},
+		{"system type", "/restriction/1", http.StatusConflict},
+		{"type in use", "/restriction/5", http.StatusConflict},
+		{"not found", "/restriction/404", http.StatusNotFound},
+	}
+
+	for _, e := range restrictionTests {
+		req, _ := http.NewRequest("DELETE", e.url, nil)
+		res := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(Repo.DeleteRestriction)
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("DeleteRestriction handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+}
+
+func TestRepository_PostRoomBlock(t *testing.T){
+	var blockTests = []struct {
+		name string
+		url string
+		body dtos.RoomBlockBody
+		expectedStatusCode int
+	}{
+		{"owner block by default", "/room/15/block", dtos.RoomBlockBody{StartDate: "2050-02-01", EndDate: "2050-02-03"}, http.StatusCreated},
+		{"maintenance with note", "/room/15/block", dtos.RoomBlockBody{StartDate: "2050-02-01", EndDate: "2050-02-03", RestrictionId: 5, Note: "Repainting"}, http.StatusCreated},
+		{"managed type", "/room/15/block", dtos.RoomBlockBody{StartDate: "2050-02-01", EndDate: "2050-02-03", RestrictionId: models.RestrictionHold}, http.StatusBadRequest},
+		{"unknown type", "/room/15/block", dtos.RoomBlockBody{StartDate: "2050-02-01", EndDate: "2050-02-03", RestrictionId: 404}, http.StatusBadRequest},
+		{"end before start", "/room/15/block", dtos.RoomBlockBody{StartDate: "2050-02-03", EndDate: "2050-02-01"}, http.StatusBadRequest},
+		{"invalid date", "/room/15/block", dtos.RoomBlockBody{StartDate: "invalid", EndDate: "2050-02-03"}, http.StatusBadRequest},
+		{"room unavailable", "/room/3/block", dtos.RoomBlockBody{StartDate: "2050-02-01", EndDate: "2050-02-03"}, http.StatusConflict},
+		{"room not found", "/room/404/block", dtos.RoomBlockBody{StartDate: "2050-02-01", EndDate: "2050-02-03"}, http.StatusNotFound},
+		{"insert fails", "/room/1000/block", dtos.RoomBlockBody{StartDate: "2050-02-01", EndDate: "2050-02-03"}, http.StatusInternalServerError},
+	}
+
+	for _, e := range blockTests {
+		jsonData, _ := json.Marshal(e.body)
+
+		req, _ := http.NewRequest("POST", e.url, bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		res := httptest.NewRecorder()
+
+		handler := mdTest.ValidateReqBody(http.HandlerFunc(Repo.PostRoomBlock), &dtos.RoomBlockBody{})
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("PostRoomBlock handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+}
+
+func TestRepository_GetRoomBlocks(t *testing.T){
+	var blockTests = []struct {
+		name string
+		url string
+		expectedStatusCode int
+	}{
+		{"default window", "/room/15/block", http.StatusOK},
+		{"with dates", "/room/15/block?start=2050-01-01&end=2050-03-01", http.StatusOK},
+		{"invalid date", "/room/15/block?start=invalid", http.StatusBadRequest},
+		{"query fails", "/room/1000/block", http.StatusInternalServerError},
+	}
+
+	for _, e := range blockTests {
+		req, _ := http.NewRequest("GET", e.url, nil)
+		res := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(Repo.GetRoomBlocks)
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("GetRoomBlocks handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+}
+
+func TestRepository_DeleteRoomBlock(t *testing.T){
+	var blockTests = []struct {
+		name string
+		url string
+		expectedStatusCode int
+	}{
+		{"success", "/room/15/block/2", http.StatusOK},
+		{"not found", "/room/15/block/404", http.StatusNotFound},
+		{"missing block id", "/room/15/block", http.StatusBadRequest},
+	}
+
+	for _, e := range blockTests {
+		req, _ := http.NewRequest("DELETE", e.url, nil)
+		res := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(Repo.DeleteRoomBlock)
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("DeleteRoomBlock handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+}
@@ -28,6 +28,7 @@ func TestMain (m *testing.M){
 
 	validate := validator.New(validator.WithRequiredStructEnabled())
 	testApp.Validate = validate
+	testApp.PhoneCountryCode = "234"
 
 	repo := NewTestRepo(&testApp)
 	NewHandlers(repo)
@@ -46,10 +47,86 @@ func getRoutes() http.Handler {
 
 	mux.Get("/health", Repo.Health)
 	mux.Post("/reservation", Repo.PostReservation)
+	mux.Get("/reservation", Repo.GetReservations)
+	mux.Get("/reservation/lookup", Repo.LookupReservation)
+	mux.Post("/reservation/{id}/cancel", Repo.CancelReservation)
+	mux.Post("/reservation/{id}/no-show", Repo.PostNoShow)
+	mux.Get("/reservation/{id}/history", Repo.GetReservationHistory)
+	mux.Post("/reservation/{id}/special-requests/resolve", Repo.ResolveReservationRequests)
+	mux.Get("/reservation/{id}/notes", Repo.GetReservationNotes)
+	mux.Post("/reservation/{id}/notes", Repo.PostReservationNote)
+	mux.Post("/reservation/hold", Repo.PostRoomHold)
+	mux.Get("/reservation/{id}/calendar.ics", Repo.GetReservationCalendar)
+	mux.Get("/reservation/{id}/invoice", Repo.GetReservationInvoice)
+	mux.Post("/reservation/group", Repo.PostReservationGroup)
+	mux.Get("/reservation/group/{id}", Repo.GetReservationGroup)
+	mux.Put("/reservation/group/{id}", Repo.UpdateReservationGroup)
+	mux.Post("/reservation/group/{id}/cancel", Repo.CancelReservationGroup)
+	mux.Post("/reservation/{id}/payment", Repo.PostReservationPayment)
+	mux.Get("/reservation/{id}/payment", Repo.GetReservationPayments)
+	mux.Post("/reservation/{id}/payment/capture", Repo.CaptureReservationPayment)
+	mux.Post("/reservation/{id}/payment/refund", Repo.RefundReservationPayment)
+	mux.Post("/waitlist", Repo.PostWaitlist)
+	mux.Get("/waitlist", Repo.GetWaitlist)
+	mux.Post("/payments/webhook", Repo.PaymentWebhook)
 	mux.Post("/search-availability", Repo.SearchAvailability)
 	mux.Post("/search-availability/{id}", Repo.SearchAvailabilityByRoomId)
 	mux.Get("/room", Repo.GetAllRooms)
 	mux.Get("/room/{id}", Repo.GetRoomById)
+	mux.Get("/room/{id}/seasonal-rate", Repo.GetRoomRates)
+	mux.Get("/room/{id}/calendar.ics", Repo.GetRoomCalendar)
+	mux.Post("/room/{id}/calendar-token", Repo.RotateRoomCalendarToken)
+	mux.Get("/room/{id}/calendar-feed", Repo.GetCalendarFeeds)
+	mux.Post("/room/{id}/calendar-feed", Repo.PostCalendarFeed)
+	mux.Delete("/room/{id}/calendar-feed/{feedId}", Repo.DeleteCalendarFeed)
+	mux.Post("/room/{id}/calendar-feed/{feedId}/sync", Repo.SyncCalendarFeed)
+	mux.Post("/room/{id}/calendar-feed/{feedId}/upload", Repo.UploadCalendarFeed)
+	mux.Put("/room/{id}/rate", Repo.UpdateRoomRates)
+	mux.Put("/room/{id}/occupancy", Repo.UpdateRoomOccupancy)
+	mux.Put("/room/{id}/cancellation-policy", Repo.UpdateRoomCancellationPolicy)
+	mux.Post("/room/{id}/seasonal-rate", Repo.PostRoomRate)
+	mux.Delete("/room/{id}/seasonal-rate/{rateId}", Repo.DeleteRoomRate)
+	mux.Get("/room/{id}/block", Repo.GetRoomBlocks)
+	mux.Post("/room/{id}/block", Repo.PostRoomBlock)
+	mux.Delete("/room/{id}/block/{blockId}", Repo.DeleteRoomBlock)
+	mux.Get("/room/{id}/recurring-block", Repo.GetRecurringBlocks)
+	mux.Post("/room/{id}/recurring-block", Repo.PostRecurringBlock)
+	mux.Delete("/room/{id}/recurring-block/{blockId}", Repo.DeleteRecurringBlock)
+	mux.Post("/room/{id}/recurring-block/{blockId}/exception", Repo.PostRecurringBlockException)
+	mux.Get("/room/{id}/stay-rule", Repo.GetStayRules)
+	mux.Post("/room/{id}/stay-rule", Repo.PostStayRule)
+	mux.Delete("/room/{id}/stay-rule/{ruleId}", Repo.DeleteStayRule)
+	mux.Get("/restriction", Repo.GetRestrictions)
+	mux.Post("/restriction", Repo.PostRestriction)
+	mux.Put("/restriction/{id}", Repo.UpdateRestriction)
+	mux.Delete("/restriction/{id}", Repo.DeleteRestriction)
+	mux.Get("/cancellation-policy", Repo.GetCancellationPolicies)
+	mux.Post("/cancellation-policy", Repo.PostCancellationPolicy)
+	mux.Delete("/cancellation-policy/{id}", Repo.DeleteCancellationPolicy)
+	mux.Get("/promo-code", Repo.GetPromoCodes)
+	mux.Post("/promo-code", Repo.PostPromoCode)
+	mux.Put("/promo-code/{id}", Repo.UpdatePromoCode)
+	mux.Delete("/promo-code/{id}", Repo.DeletePromoCode)
+	mux.Get("/charge-rule", Repo.GetChargeRules)
+	mux.Post("/charge-rule", Repo.PostChargeRule)
+	mux.Delete("/charge-rule/{id}", Repo.DeleteChargeRule)
+	mux.Get("/exchange-rate", Repo.GetExchangeRates)
+	mux.Put("/exchange-rate", Repo.PutExchangeRate)
+	mux.Delete("/exchange-rate/{id}", Repo.DeleteExchangeRate)
+	mux.Get("/admin/calendar", Repo.GetCalendarGrid)
+	mux.Get("/admin/guest", Repo.GetGuests)
+	mux.Post("/admin/guest/backfill", Repo.BackfillGuests)
+	mux.Get("/admin/guest/{id}", Repo.GetGuest)
+	mux.Post("/admin/guest/{id}/merge", Repo.MergeGuests)
+	mux.Post("/admin/guest/{id}/split", Repo.SplitGuest)
+	mux.Get("/admin/reports/occupancy", Repo.GetOccupancyReport)
+	mux.Get("/admin/reports/revenue", Repo.GetRevenueReport)
+	mux.Get("/admin/reports/stays", Repo.GetStaysReport)
+	mux.Get("/admin/export/rooms", Repo.ExportRooms)
+	mux.Get("/admin/export/reservations", Repo.ExportReservations)
+	mux.Post("/admin/import/rooms", Repo.ImportRooms)
+	mux.Post("/admin/import/reservations", Repo.ImportReservations)
+	mux.Post("/quote", Repo.PostQuote)
 
 	return mux;
 }
\ No newline at end of file
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/dtos"
+	"github.com/Orololuwa/go-backend-boilerplate/src/helpers"
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+	"github.com/Orololuwa/go-backend-boilerplate/src/stayrules"
+)
+
+var errInvalidNights = errors.New("maxNights must not be less than minNights")
+
+// unavailableReason is reported by the searches for rooms that are already booked or blocked
+var unavailableReason = stayrules.Violation{Code: "unavailable", Message: errRoomUnavailable.Error()}
+
+// checkStayRules returns a *stayrules.ViolationError when a stay in the room breaks one of its rules
+func (m *Repository) checkStayRules(ctx context.Context, tx *sql.Tx, roomId int, start, end time.Time) error {
+	rules, err := m.DB.GetStayRulesForDates(ctx, tx, roomId, start, end)
+	if err != nil {
+		return err
+	}
+
+	return stayrules.Validate(rules, roomId, start, end, time.Now())
+}
+
+// PostStayRule limits the stays arriving in a date range: their length, the weekdays guests can arrive and leave on,
+// and how far ahead they can be booked. When rules overlap the newest one applies
+func (m *Repository) PostStayRule(w http.ResponseWriter, r *http.Request) {
+	roomId, err := m.urlParamInt(r, "id", 2)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	var body dtos.StayRuleBody
+	requestBody, ok := r.Context().Value("validatedRequestBody").(*dtos.StayRuleBody)
+	if !ok || requestBody == nil {
+		helpers.ClientError(w, errors.New("failed to retrieve request body"), http.StatusBadRequest, "")
+		return
+	}
+	body = *requestBody
+
+	layout := "2006-01-02"
+
+	startDate, err := time.Parse(layout, body.StartDate)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "")
+		return
+	}
+
+	endDate, err := time.Parse(layout, body.EndDate)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "")
+		return
+	}
+
+	if !endDate.After(startDate) {
+		helpers.ClientError(w, errors.New("endDate must be after startDate"), http.StatusBadRequest, "")
+		return
+	}
+
+	if body.MaxNights > 0 && body.MaxNights < body.MinNights {
+		helpers.ClientError(w, errInvalidNights, http.StatusBadRequest, "")
+		return
+	}
+
+	closedToArrival, err := models.ParseWeekdays(body.ClosedToArrival)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "")
+		return
+	}
+
+	closedToDeparture, err := models.ParseWeekdays(body.ClosedToDeparture)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "")
+		return
+	}
+
+	ctx := context.Background()
+
+	_, err = m.DB.GetRoomById(ctx, nil, roomId)
+	if errors.Is(err, sql.ErrNoRows) {
+		helpers.ClientError(w, err, http.StatusNotFound, "room not found")
+		return
+	}
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	rule := models.StayRule{
+		RoomID: roomId,
+		RuleName: body.RuleName,
+		StartDate: startDate,
+		EndDate: endDate,
+		MinNights: body.MinNights,
+		MaxNights: body.MaxNights,
+		ClosedToArrival: closedToArrival,
+		ClosedToDeparture: closedToDeparture,
+		MinLeadDays: body.MinLeadDays,
+		MaxLeadDays: body.MaxLeadDays,
+	}
+
+	rule.ID, err = m.DB.InsertStayRule(ctx, nil, rule)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, rule, http.StatusCreated, "stay rule created successfully")
+}
+
+func (m *Repository) GetStayRules(w http.ResponseWriter, r *http.Request) {
+	roomId, err := m.urlParamInt(r, "id", 2)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	rules, err := m.DB.GetStayRulesByRoomId(context.Background(), nil, roomId)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, rules, http.StatusOK, "stay rules retrieved successfully")
+}
+
+func (m *Repository) DeleteStayRule(w http.ResponseWriter, r *http.Request) {
+	roomId, err := m.urlParamInt(r, "id", 2)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	ruleId, err := m.urlParamInt(r, "ruleId", 4)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	err = m.DB.DeleteStayRule(context.Background(), nil, roomId, ruleId)
+	if errors.Is(err, sql.ErrNoRows) {
+		helpers.ClientError(w, err, http.StatusNotFound, "stay rule not found")
+		return
+	}
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, nil, http.StatusOK, "stay rule deleted successfully")
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/dtos"
+	"github.com/Orololuwa/go-backend-boilerplate/src/stayrules"
+	"github.com/Orololuwa/go-backend-boilerplate/src/types"
+)
+
+func TestRepository_PostStayRule(t *testing.T){
+	var ruleTests = []struct {
+		name string
+		url string
+		body dtos.StayRuleBody
+		expectedStatusCode int
+	}{
+		{"success", "/room/20/stay-rule", dtos.StayRuleBody{StartDate: "2050-01-01", EndDate: "2051-01-01", MinNights: 3, MaxNights: 14, ClosedToArrival: []string{"sunday"}, ClosedToDeparture: []string{"sat"}}, http.StatusCreated},
+		{"lead time only", "/room/20/stay-rule", dtos.StayRuleBody{StartDate: "2050-01-01", EndDate: "2051-01-01", MinLeadDays: 1, MaxLeadDays: 365}, http.StatusCreated},
+		{"end before start", "/room/20/stay-rule", dtos.StayRuleBody{StartDate: "2051-01-01", EndDate: "2050-01-01"}, http.StatusBadRequest},
+		{"max below min", "/room/20/stay-rule", dtos.StayRuleBody{StartDate: "2050-01-01", EndDate: "2051-01-01", MinNights: 7, MaxNights: 3}, http.StatusBadRequest},
+		{"invalid weekday", "/room/20/stay-rule", dtos.StayRuleBody{StartDate: "2050-01-01", EndDate: "2051-01-01", ClosedToArrival: []string{"someday"}}, http.StatusBadRequest},
+		{"invalid date", "/room/20/stay-rule", dtos.StayRuleBody{StartDate: "invalid", EndDate: "2051-01-01"}, http.StatusBadRequest},
+		{"room not found", "/room/404/stay-rule", dtos.StayRuleBody{StartDate: "2050-01-01", EndDate: "2051-01-01"}, http.StatusNotFound},
+	}
+
+	for _, e := range ruleTests {
+		jsonData, _ := json.Marshal(e.body)
+
+		req, _ := http.NewRequest("POST", e.url, bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		res := httptest.NewRecorder()
+
+		handler := mdTest.ValidateReqBody(http.HandlerFunc(Repo.PostStayRule), &dtos.StayRuleBody{})
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("PostStayRule handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+}
+
+func TestRepository_GetStayRules(t *testing.T){
+	var ruleTests = []struct {
+		name string
+		url string
+		expectedStatusCode int
+	}{
+		{"success", "/room/20/stay-rule", http.StatusOK},
+		{"query fails", "/room/500/stay-rule", http.StatusInternalServerError},
+	}
+
+	for _, e := range ruleTests {
+		req, _ := http.NewRequest("GET", e.url, nil)
+		res := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(Repo.GetStayRules)
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("GetStayRules handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+}
+
+func TestRepository_DeleteStayRule(t *testing.T){
+	var ruleTests = []struct {
+		name string
+		url string
+		expectedStatusCode int
+	}{
+		{"success", "/room/20/stay-rule/1", http.StatusOK},
+		{"not found", "/room/20/stay-rule/404", http.StatusNotFound},
+	}
+
+	for _, e := range ruleTests {
+		req, _ := http.NewRequest("DELETE", e.url, nil)
+		res := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(Repo.DeleteStayRule)
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("DeleteStayRule handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+}
+
+func TestRepository_PostReservationStayRules(t *testing.T){
+	// room 20 takes stays of at least 3 nights in 2050 and no arrivals on a sunday, 2050-01-02 is a sunday
+	var reservationTests = []struct {
+		name string
+		startDate string
+		endDate string
+		expectedStatusCode int
+	}{
+		{"allowed", "2050-01-04", "2050-01-07", http.StatusCreated},
+		{"too short", "2050-01-04", "2050-01-05", http.StatusBadRequest},
+		{"sunday arrival", "2050-01-02", "2050-01-05", http.StatusBadRequest},
+	}
+
+	for _, e := range reservationTests {
+		body := dtos.ReservationBody{
+			FirstName: "John",
+			LastName: "Doe",
+			Email: "john@doe.com",
+			Phone: "08012345678",
+			StartDate: e.startDate,
+			EndDate: e.endDate,
+			RoomId: 20,
+		}
+		jsonData, _ := json.Marshal(body)
+
+		req, _ := http.NewRequest("POST", "/reservation", bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		res := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(Repo.PostReservation)
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("PostReservation handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+}
+
+func TestRepository_SearchAvailabilityStayRules(t *testing.T){
+	jsonData, _ := json.Marshal(dtos.PostAvailabilityBody{StartDate: "2050-01-04", EndDate: "2050-01-05"})
+
+	req, _ := http.NewRequest("POST", "/search-availability", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	res := httptest.NewRecorder()
+
+	handler := mdTest.ValidateReqBody(http.HandlerFunc(Repo.SearchAvailability), &dtos.PostAvailabilityBody{})
+	handler.ServeHTTP(res, req)
+
+	var body struct {
+		Data types.AvailabilityResponse `json:"data"`
+	}
+	json.Unmarshal(res.Body.Bytes(), &body)
+
+	if len(body.Data.Rooms) != 1 || body.Data.Rooms[0].ID != 15 {
+		t.Errorf("SearchAvailability returned rooms %+v, wanted only room 15", body.Data.Rooms)
+	}
+	if len(body.Data.Excluded) != 1 || body.Data.Excluded[0].RoomId != 20 || body.Data.Excluded[0].Reasons[0].Code != stayrules.CodeMinNights {
+		t.Errorf("SearchAvailability returned excluded rooms %+v, wanted room 20 for its minimum stay", body.Data.Excluded)
+	}
+
+	req, _ = http.NewRequest("POST", "/search-availability/20", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	req.RequestURI = "/search-availability/20"
+	res = httptest.NewRecorder()
+
+	handler = mdTest.ValidateReqBody(http.HandlerFunc(Repo.SearchAvailabilityByRoomId), &dtos.PostAvailabilityBody{})
+	handler.ServeHTTP(res, req)
+
+	var roomBody struct {
+		Data types.RoomAvailabilityResponse `json:"data"`
+	}
+	json.Unmarshal(res.Body.Bytes(), &roomBody)
+
+	if roomBody.Data.Available || len(roomBody.Data.Reasons) != 1 || roomBody.Data.Reasons[0].Code != stayrules.CodeMinNights {
+		t.Errorf("SearchAvailabilityByRoomId returned %+v, wanted room 20 excluded for its minimum stay", roomBody.Data)
+	}
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/dtos"
+	"github.com/Orololuwa/go-backend-boilerplate/src/helpers"
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+	"github.com/Orololuwa/go-backend-boilerplate/src/pricing"
+)
+
+var errReservationCancelled = errors.New("reservation has already been cancelled")
+
+// offerReleasedDates hands the dates of a cancelled reservation to the waitlist.
+// The cancellation has already been committed, so failures are only logged
+func (m *Repository) offerReleasedDates(res models.Reservation) {
+	_, err := m.Waitlist.OfferReleased(context.Background(), res.RoomID, res.StartDate, res.EndDate)
+	if err != nil {
+		m.App.ErrorLog.Println(err)
+	}
+}
+
+// PostWaitlist records a guest who wants to be offered the dates if they free up
+func (m *Repository) PostWaitlist(w http.ResponseWriter, r *http.Request) {
+	var body dtos.WaitlistBody
+	requestBody, ok := r.Context().Value("validatedRequestBody").(*dtos.WaitlistBody)
+	if !ok || requestBody == nil {
+		helpers.ClientError(w, errors.New("failed to retrieve request body"), http.StatusBadRequest, "")
+		return
+	}
+	body = *requestBody
+
+	layout := "2006-01-02"
+
+	startDate, err := time.Parse(layout, body.StartDate)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "")
+		return
+	}
+
+	endDate, err := time.Parse(layout, body.EndDate)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "")
+		return
+	}
+
+	if !endDate.After(startDate) {
+		helpers.ClientError(w, pricing.ErrInvalidStay, http.StatusBadRequest, "")
+		return
+	}
+
+	ctx := context.Background()
+
+	if body.RoomId != 0 {
+		_, err = m.DB.GetRoomById(ctx, nil, body.RoomId)
+		if errors.Is(err, sql.ErrNoRows) {
+			helpers.ClientError(w, err, http.StatusNotFound, "room not found")
+			return
+		}
+		if err != nil {
+			helpers.ClientError(w, err, http.StatusInternalServerError, "")
+			return
+		}
+	}
+
+	entry := models.WaitlistEntry{
+		RoomID: body.RoomId,
+		StartDate: startDate,
+		EndDate: endDate,
+		FirstName: body.FirstName,
+		LastName: body.LastName,
+		Email: body.Email,
+		Phone: body.Phone,
+		Status: models.WaitlistStatusWaiting,
+	}
+
+	entry.ID, err = m.DB.InsertWaitlistEntry(ctx, nil, entry)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, entry, http.StatusCreated, "added to the waitlist successfully")
+}
+
+// GetWaitlist lists the waitlist for staff, optionally filtered by ?status=
+func (m *Repository) GetWaitlist(w http.ResponseWriter, r *http.Request) {
+	entries, err := m.DB.GetWaitlistEntries(context.Background(), nil, r.URL.Query().Get("status"))
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	helpers.ClientResponseWriter(w, entries, http.StatusOK, "waitlist retrieved successfully")
+}
+
+// CancelReservation cancels a single reservation, charging the fee of its cancellation policy, releases its room
+// and offers the dates to the waitlist
+func (m *Repository) CancelReservation(w http.ResponseWriter, r *http.Request) {
+	id, err := m.urlParamInt(r, "id", 2)
+	if err != nil {
+		helpers.ClientError(w, err, http.StatusBadRequest, "missing URL param")
+		return
+	}
+
+	var body dtos.CancelBody
+	requestBody, ok := r.Context().Value("validatedRequestBody").(*dtos.CancelBody)
+	if !ok || requestBody == nil {
+		helpers.ClientError(w, errors.New("failed to retrieve request body"), http.StatusBadRequest, "")
+		return
+	}
+	body = *requestBody
+
+	var res models.Reservation
+
+	err = m.DB.Transaction(withActor(r), func(ctx context.Context, tx *sql.Tx) error {
+		var err error
+
+		// the reservation stays locked from the check of its status until it is closed, so two cancellations, or a
+		// cancellation and a no-show, cannot both close it
+		err = m.DB.LockReservation(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		res, err = m.DB.GetReservationById(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		// the reservation id is guessable, so the guest proves ownership with the confirmation code
+		if !helpers.ConfirmationCodeMatches(res.ConfirmationCode, body.ConfirmationCode) {
+			return sql.ErrNoRows
+		}
+
+		switch res.Status {
+		case models.ReservationStatusCancelled:
+			return errReservationCancelled
+		case models.ReservationStatusNoShow:
+			return errReservationNoShow
+		}
+
+		return m.closeReservation(ctx, tx, &res, models.ReservationStatusCancelled)
+	})
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			helpers.ClientError(w, err, http.StatusNotFound, "reservation not found")
+		case errors.Is(err, errReservationCancelled), errors.Is(err, errReservationNoShow):
+			helpers.ClientError(w, err, http.StatusConflict, "")
+		default:
+			helpers.ClientError(w, err, http.StatusInternalServerError, "")
+		}
+		return
+	}
+
+	m.offerReleasedDates(res)
+
+	helpers.ClientResponseWriter(w, reservationResponse(res), http.StatusOK, "reservation cancelled successfully")
+}
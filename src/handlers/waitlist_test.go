@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/dtos"
+)
+
+func TestRepository_PostWaitlist(t *testing.T){
+	guest := dtos.GuestDetails{FirstName: "Jane", LastName: "Roe", Email: "janeroe@gmail.com", Phone: "08087654321"}
+
+	var waitlistTests = []struct {
+		name string
+		body dtos.WaitlistBody
+		expectedStatusCode int
+	}{
+		{"specific room", dtos.WaitlistBody{RoomId: 15, StartDate: "2050-01-01", EndDate: "2050-01-05", GuestDetails: guest}, http.StatusCreated},
+		{"any room", dtos.WaitlistBody{StartDate: "2050-01-01", EndDate: "2050-01-05", GuestDetails: guest}, http.StatusCreated},
+		{"room not found", dtos.WaitlistBody{RoomId: 404, StartDate: "2050-01-01", EndDate: "2050-01-05", GuestDetails: guest}, http.StatusNotFound},
+		{"end before start", dtos.WaitlistBody{RoomId: 15, StartDate: "2050-01-05", EndDate: "2050-01-01", GuestDetails: guest}, http.StatusBadRequest},
+		{"missing contact details", dtos.WaitlistBody{RoomId: 15, StartDate: "2050-01-01", EndDate: "2050-01-05"}, http.StatusBadRequest},
+	}
+
+	for _, e := range waitlistTests {
+		jsonData, _ := json.Marshal(e.body)
+
+		req, _ := http.NewRequest("POST", "/waitlist", bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		res := httptest.NewRecorder()
+
+		handler := mdTest.ValidateReqBody(http.HandlerFunc(Repo.PostWaitlist), &dtos.WaitlistBody{})
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("PostWaitlist handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+}
+
+func TestRepository_CancelReservation(t *testing.T){
+	var cancelTests = []struct {
+		name string
+		url string
+		code string
+		expectedStatusCode int
+	}{
+		{"cancel reservation", "/reservation/1/cancel", "ABCD2345", http.StatusOK},
+		{"already cancelled", "/reservation/4/cancel", "ABCD2345", http.StatusConflict},
+		{"no-show", "/reservation/11/cancel", "ABCD2345", http.StatusConflict},
+		{"code as the guest typed it", "/reservation/1/cancel", " abcd-2345", http.StatusOK},
+		{"wrong code", "/reservation/1/cancel", "WRONG", http.StatusNotFound},
+		{"reservation not found", "/reservation/404/cancel", "ABCD2345", http.StatusNotFound},
+		{"failed lookup", "/reservation/1000/cancel", "ABCD2345", http.StatusInternalServerError},
+	}
+
+	for _, e := range cancelTests {
+		jsonData, _ := json.Marshal(dtos.CancelBody{ConfirmationCode: e.code})
+
+		req, _ := http.NewRequest("POST", e.url, bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		res := httptest.NewRecorder()
+
+		handler := mdTest.ValidateReqBody(http.HandlerFunc(Repo.CancelReservation), &dtos.CancelBody{})
+		handler.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("CancelReservation handler returned wrong response code for %s: got %d, wanted %d", e.name, res.Code, e.expectedStatusCode)
+		}
+	}
+}
@@ -1,10 +1,15 @@
 package helpers
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"math/big"
 	"net/http"
 	"runtime/debug"
+	"strings"
 
 	"github.com/Orololuwa/go-backend-boilerplate/src/config"
 	"github.com/theritikchoure/logx"
@@ -54,4 +59,47 @@ func ClientResponseWriter(w http.ResponseWriter, data interface{}, status int, m
     w.Header().Set("Content-Type", "application/json")
     w.WriteHeader(status)
     w.Write(jsonResponse)
+}
+
+// RandomToken returns a hex encoded random string built from n random bytes
+func RandomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+const confirmationCodeAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+const confirmationCodeLength = 8
+
+// ConfirmationCode returns a random, non-sequential booking reference without characters guests tend to misread
+func ConfirmationCode() (string, error) {
+	b := make([]byte, confirmationCodeLength)
+	max := big.NewInt(int64(len(confirmationCodeAlphabet)))
+
+	for i := range b {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b[i] = confirmationCodeAlphabet[n.Int64()]
+	}
+
+	return string(b), nil
+}
+
+// NormalizeConfirmationCode turns a code the way a guest typed it, in lower case or with dashes, into the code issued
+func NormalizeConfirmationCode(code string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+}
+
+// ConfirmationCodeMatches tells whether the code a guest supplied is the one issued, in constant time so the code
+// cannot be guessed a character at a time
+func ConfirmationCodeMatches(issued, supplied string) bool {
+	if issued == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(issued), []byte(NormalizeConfirmationCode(supplied))) == 1
 }
\ No newline at end of file
@@ -0,0 +1,24 @@
+package helpers
+
+import "testing"
+
+func TestConfirmationCodeMatches(t *testing.T){
+	var matchTests = []struct {
+		name string
+		issued string
+		supplied string
+		expected bool
+	}{
+		{"same code", "ABCD2345", "ABCD2345", true},
+		{"lower case with a dash and spaces", "ABCD2345", " abcd-2345 ", true},
+		{"wrong code", "ABCD2345", "ABCD2346", false},
+		{"shorter code", "ABCD2345", "ABCD", false},
+		{"no code issued", "", "", false},
+	}
+
+	for _, e := range matchTests {
+		if got := ConfirmationCodeMatches(e.issued, e.supplied); got != e.expected {
+			t.Errorf("%s: ConfirmationCodeMatches returned %t, wanted %t", e.name, got, e.expected)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package ical
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	dateLayout = "20060102"
+	dateTimeLayout = "20060102T150405Z"
+
+	// lines longer than this many octets are folded, RFC 5545 section 3.1
+	maxLineOctets = 75
+)
+
+// Event statuses, RFC 5545 section 3.8.1.11
+const (
+	StatusTentative = "TENTATIVE"
+	StatusConfirmed = "CONFIRMED"
+	StatusCancelled = "CANCELLED"
+)
+
+// Event is an all-day event. End is exclusive, which matches how stays are stored: the guest leaves on End.
+// UID must not change between exports so calendar apps update the event instead of adding a second one
+type Event struct {
+	UID string
+	Summary string
+	Description string
+	Start time.Time
+	End time.Time
+	Status string
+	Sequence int
+	LastModified time.Time
+}
+
+type Calendar struct {
+	ProdID string
+	Name string
+	// Method is only set for attachments sent to a guest, feeds leave it empty
+	Method string
+	Events []Event
+}
+
+// EscapeText escapes a TEXT value, RFC 5545 section 3.3.11
+func EscapeText(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\\\")
+	s = strings.ReplaceAll(s, ";", "\\;")
+	s = strings.ReplaceAll(s, ",", "\\,")
+	s = strings.ReplaceAll(s, "\r\n", "\\n")
+	s = strings.ReplaceAll(s, "\n", "\\n")
+	return s
+}
+
+// fold splits a content line into chunks of at most 75 octets without breaking a UTF-8 sequence.
+// Continuation lines start with a single space
+func fold(line string) string {
+	if len(line) <= maxLineOctets {
+		return line + "\r\n"
+	}
+
+	var b strings.Builder
+	limit := maxLineOctets
+
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+
+		// the leading space of a continuation line counts towards its length
+		limit = maxLineOctets - 1
+	}
+
+	b.WriteString(line)
+	b.WriteString("\r\n")
+
+	return b.String()
+}
+
+// Marshal renders the calendar as an RFC 5545 iCalendar object. now is used for DTSTAMP
+func (c Calendar) Marshal(now time.Time) []byte {
+	var buf bytes.Buffer
+
+	line := func(format string, args ...interface{}) {
+		buf.WriteString(fold(fmt.Sprintf(format, args...)))
+	}
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:%s", c.ProdID)
+	line("CALSCALE:GREGORIAN")
+	if c.Method != "" {
+		line("METHOD:%s", c.Method)
+	}
+	if c.Name != "" {
+		line("X-WR-CALNAME:%s", EscapeText(c.Name))
+	}
+
+	for _, e := range c.Events {
+		line("BEGIN:VEVENT")
+		line("UID:%s", e.UID)
+		line("DTSTAMP:%s", now.UTC().Format(dateTimeLayout))
+		line("DTSTART;VALUE=DATE:%s", e.Start.Format(dateLayout))
+		line("DTEND;VALUE=DATE:%s", e.End.Format(dateLayout))
+		line("SUMMARY:%s", EscapeText(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION:%s", EscapeText(e.Description))
+		}
+		if e.Status != "" {
+			line("STATUS:%s", e.Status)
+		}
+		line("SEQUENCE:%d", e.Sequence)
+		if !e.LastModified.IsZero() {
+			line("LAST-MODIFIED:%s", e.LastModified.UTC().Format(dateTimeLayout))
+		}
+		line("TRANSP:OPAQUE")
+		line("END:VEVENT")
+	}
+
+	line("END:VCALENDAR")
+
+	return buf.Bytes()
+}
@@ -0,0 +1,58 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestMarshal(t *testing.T){
+	cal := Calendar{
+		ProdID: "-//test//EN",
+		Name: "Room 1",
+		Events: []Event{
+			{
+				UID: "reservation-1@test",
+				Summary: "Reserved: Doe, John; VIP",
+				Description: strings.Repeat("é", 60),
+				Start: time.Date(2050, time.January, 6, 0, 0, 0, 0, time.UTC),
+				End: time.Date(2050, time.January, 9, 0, 0, 0, 0, time.UTC),
+				Status: StatusConfirmed,
+			},
+		},
+	}
+
+	out := string(cal.Marshal(time.Date(2050, time.January, 1, 12, 0, 0, 0, time.UTC)))
+
+	if !strings.HasPrefix(out, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n") || !strings.HasSuffix(out, "END:VCALENDAR\r\n") {
+		t.Fatalf("Marshal produced an invalid calendar:\n%s", out)
+	}
+
+	for _, expected := range []string{
+		"UID:reservation-1@test\r\n",
+		"DTSTAMP:20500101T120000Z\r\n",
+		"DTSTART;VALUE=DATE:20500106\r\n",
+		"DTEND;VALUE=DATE:20500109\r\n",
+		"SUMMARY:Reserved: Doe\\, John\\; VIP\r\n",
+		"STATUS:CONFIRMED\r\n",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("Marshal output is missing %q", expected)
+		}
+	}
+
+	// test that long lines are folded at 75 octets without splitting a character
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("Marshal produced a line of %d octets: %q", len(line), line)
+		}
+		if !utf8.ValidString(line) {
+			t.Errorf("Marshal split a UTF-8 sequence: %q", line)
+		}
+	}
+
+	if strings.Count(out, "\n") != strings.Count(out, "\r\n") {
+		t.Error("Marshal used a bare LF as line ending")
+	}
+}
@@ -0,0 +1,184 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+var ErrNotCalendar = errors.New("input is not an iCalendar object")
+
+// contentLine is a single unfolded property, NAME;PARAM=VALUE:value
+type contentLine struct {
+	name string
+	params map[string]string
+	value string
+}
+
+func parseContentLine(raw string) (contentLine, bool) {
+	colon := strings.Index(raw, ":")
+	if colon < 0 {
+		return contentLine{}, false
+	}
+
+	line := contentLine{value: raw[colon+1:], params: make(map[string]string)}
+
+	parts := strings.Split(raw[:colon], ";")
+	line.name = strings.ToUpper(parts[0])
+
+	for _, p := range parts[1:] {
+		kv := strings.SplitN(p, "=", 2)
+		if len(kv) == 2 {
+			line.params[strings.ToUpper(kv[0])] = strings.Trim(kv[1], `"`)
+		}
+	}
+
+	return line, true
+}
+
+// unfold joins continuation lines back onto the line they belong to, RFC 5545 section 3.1
+func unfold(r io.Reader) ([]string, error) {
+	lines := make([]string, 0)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		text := strings.TrimRight(scanner.Text(), "\r")
+
+		if (strings.HasPrefix(text, " ") || strings.HasPrefix(text, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += text[1:]
+			continue
+		}
+
+		if text != "" {
+			lines = append(lines, text)
+		}
+	}
+
+	return lines, scanner.Err()
+}
+
+func unescapeText(s string) string {
+	replacer := strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+	return replacer.Replace(s)
+}
+
+// parseDate reads a DATE or DATE-TIME value and returns the calendar day it falls on in its own time zone
+func parseDate(line contentLine) (time.Time, error) {
+	value := line.value
+
+	if line.params["VALUE"] == "DATE" || len(value) == len(dateLayout) {
+		return time.Parse(dateLayout, value)
+	}
+
+	loc := time.UTC
+	if tzid, ok := line.params["TZID"]; ok {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+
+	var t time.Time
+	var err error
+	if strings.HasSuffix(value, "Z") {
+		t, err = time.Parse(dateTimeLayout, value)
+	}else{
+		t, err = time.ParseInLocation("20060102T150405", value, loc)
+	}
+	if err != nil {
+		return t, err
+	}
+
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+}
+
+// Parse reads the VEVENTs of an iCalendar object as all-day events. Date-times are reduced to the day they fall on,
+// events without an end last one day and cancelled events are left out
+func Parse(r io.Reader) ([]Event, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(lines) == 0 || !strings.EqualFold(lines[0], "BEGIN:VCALENDAR") {
+		return nil, ErrNotCalendar
+	}
+
+	events := make([]Event, 0)
+	var current *Event
+	depth := 0
+
+	for i, raw := range lines {
+		line, ok := parseContentLine(raw)
+		if !ok {
+			return nil, fmt.Errorf("line %d: malformed content line", i + 1)
+		}
+
+		switch {
+		case line.name == "BEGIN" && strings.EqualFold(line.value, "VEVENT"):
+			current = &Event{}
+			depth = 0
+			continue
+		case current == nil:
+			continue
+		case line.name == "BEGIN":
+			// nested components such as VALARM carry properties that are not the event's own
+			depth++
+			continue
+		case line.name == "END" && depth > 0:
+			depth--
+			continue
+		case depth > 0:
+			continue
+		}
+
+		switch line.name {
+		case "UID":
+			current.UID = line.value
+		case "SUMMARY":
+			current.Summary = unescapeText(line.value)
+		case "DESCRIPTION":
+			current.Description = unescapeText(line.value)
+		case "STATUS":
+			current.Status = strings.ToUpper(line.value)
+		case "DTSTART":
+			current.Start, err = parseDate(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid DTSTART: %w", i + 1, err)
+			}
+		case "DTEND":
+			current.End, err = parseDate(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid DTEND: %w", i + 1, err)
+			}
+		case "END":
+			if !strings.EqualFold(line.value, "VEVENT") {
+				continue
+			}
+
+			event := *current
+			current = nil
+
+			if event.Start.IsZero() {
+				return nil, fmt.Errorf("line %d: event without DTSTART", i + 1)
+			}
+			if !event.End.After(event.Start) {
+				event.End = event.Start.AddDate(0, 0, 1)
+			}
+			if event.Status == StatusCancelled {
+				continue
+			}
+			if event.UID == "" {
+				// every source we import from sets a UID, this only keeps a broken file from failing the whole sync
+				event.UID = fmt.Sprintf("%s-%s-%s", event.Start.Format(dateLayout), event.End.Format(dateLayout), event.Summary)
+			}
+
+			events = append(events, event)
+		}
+	}
+
+	return events, nil
+}
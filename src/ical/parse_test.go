@@ -0,0 +1,95 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const testFeed = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"PRODID:-//Other platform//EN\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:stay-1@other\r\n" +
+	"DTSTART;VALUE=DATE:20500106\r\n" +
+	"DTEND;VALUE=DATE:20500109\r\n" +
+	"SUMMARY:Reserved\\, not\r\n" +
+	"  available\r\n" +
+	"BEGIN:VALARM\r\n" +
+	"DESCRIPTION:reminder\r\n" +
+	"END:VALARM\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:stay-2@other\r\n" +
+	"DTSTART;TZID=Europe/London:20500201T150000\r\n" +
+	"DTEND:20500203T100000Z\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:stay-3@other\r\n" +
+	"DTSTART:20500301\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:cancelled@other\r\n" +
+	"DTSTART;VALUE=DATE:20500401\r\n" +
+	"DTEND;VALUE=DATE:20500402\r\n" +
+	"STATUS:CANCELLED\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParse(t *testing.T){
+	events, err := Parse(strings.NewReader(testFeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(events) != 3 {
+		t.Fatalf("Parse expected 3 events without the cancelled one, got %d", len(events))
+	}
+
+	var parseTests = []struct {
+		uid string
+		start string
+		end string
+	}{
+		{"stay-1@other", "2050-01-06", "2050-01-09"},
+		{"stay-2@other", "2050-02-01", "2050-02-03"},
+		{"stay-3@other", "2050-03-01", "2050-03-02"},
+	}
+
+	for i, e := range parseTests {
+		if events[i].UID != e.uid {
+			t.Errorf("Parse expected event %d to have UID %s, got %s", i, e.uid, events[i].UID)
+		}
+		if events[i].Start.Format("2006-01-02") != e.start || events[i].End.Format("2006-01-02") != e.end {
+			t.Errorf("Parse expected %s to run from %s to %s, got %s to %s", e.uid, e.start, e.end, events[i].Start.Format("2006-01-02"), events[i].End.Format("2006-01-02"))
+		}
+	}
+
+	// test that folded lines are joined and text is unescaped, and that the alarm did not overwrite the event
+	if events[0].Summary != "Reserved, not available" || events[0].Description != "" {
+		t.Errorf("Parse returned summary %q and description %q", events[0].Summary, events[0].Description)
+	}
+
+	_, err = Parse(strings.NewReader("not a calendar"))
+	if err != ErrNotCalendar {
+		t.Errorf("Parse expected ErrNotCalendar, got %v", err)
+	}
+}
+
+func TestParseRoundTrip(t *testing.T){
+	cal := Calendar{
+		ProdID: "-//test//EN",
+		Events: []Event{
+			{UID: "a@test", Summary: "Owner block; kitchen, repairs", Start: time.Date(2050, 1, 6, 0, 0, 0, 0, time.UTC), End: time.Date(2050, 1, 8, 0, 0, 0, 0, time.UTC)},
+		},
+	}
+
+	events, err := Parse(strings.NewReader(string(cal.Marshal(time.Now()))))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(events) != 1 || events[0].Summary != cal.Events[0].Summary || !events[0].End.Equal(cal.Events[0].End) {
+		t.Errorf("Parse did not read back the exported event: %+v", events)
+	}
+}
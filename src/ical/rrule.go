@@ -0,0 +1,487 @@
+package ical
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidRule = errors.New("invalid recurrence rule")
+	ErrUnsupportedRule = errors.New("unsupported recurrence rule")
+)
+
+// Recurrence frequencies. Rules repeating more than once a day have no meaning for room blocks and are rejected
+const (
+	FreqDaily = "DAILY"
+	FreqWeekly = "WEEKLY"
+	FreqMonthly = "MONTHLY"
+	FreqYearly = "YEARLY"
+)
+
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+func weekdayCode(d time.Weekday) string {
+	return strings.ToUpper(d.String()[:2])
+}
+
+// WeekdayNum is a BYDAY entry. Nth picks the nth such weekday of the month, counting from the end when negative,
+// and 0 means every one of them
+type WeekdayNum struct {
+	Nth int
+	Weekday time.Weekday
+}
+
+func (w WeekdayNum) String() string {
+	if w.Nth == 0 {
+		return weekdayCode(w.Weekday)
+	}
+	return strconv.Itoa(w.Nth) + weekdayCode(w.Weekday)
+}
+
+// RRule is a recurrence rule, RFC 5545 section 3.3.10, evaluated on whole days.
+// BYSETPOS, BYYEARDAY, BYWEEKNO and the parts below a day are not supported
+type RRule struct {
+	Freq string
+	Interval int
+	Count int
+	Until time.Time
+	ByDay []WeekdayNum
+	ByMonthDay []int
+	ByMonth []time.Month
+	WeekStart time.Weekday
+}
+
+func parseRuleInt(name, value string, min, max int) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < min || n > max || n == 0 {
+		return 0, fmt.Errorf("%w: %s=%s", ErrInvalidRule, name, value)
+	}
+	return n, nil
+}
+
+func parseWeekdayNum(value string) (WeekdayNum, error) {
+	if len(value) < 2 {
+		return WeekdayNum{}, fmt.Errorf("%w: BYDAY=%s", ErrInvalidRule, value)
+	}
+
+	day, ok := weekdayCodes[value[len(value)-2:]]
+	if !ok {
+		return WeekdayNum{}, fmt.Errorf("%w: BYDAY=%s", ErrInvalidRule, value)
+	}
+
+	w := WeekdayNum{Weekday: day}
+	if ordinal := value[:len(value)-2]; ordinal != "" {
+		n, err := parseRuleInt("BYDAY", strings.TrimPrefix(ordinal, "+"), -5, 5)
+		if err != nil {
+			return w, err
+		}
+		w.Nth = n
+	}
+
+	return w, nil
+}
+
+// ParseRRule reads the value of an RRULE property, with or without the "RRULE:" prefix
+func ParseRRule(s string) (RRule, error) {
+	rule := RRule{Interval: 1, WeekStart: time.Monday}
+
+	s = strings.TrimSpace(s)
+	if len(s) >= 6 && strings.EqualFold(s[:6], "RRULE:") {
+		s = s[6:]
+	}
+	if s == "" {
+		return rule, fmt.Errorf("%w: empty rule", ErrInvalidRule)
+	}
+
+	seen := make(map[string]bool)
+
+	for _, part := range strings.Split(s, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		name = strings.ToUpper(strings.TrimSpace(name))
+		value = strings.ToUpper(strings.TrimSpace(value))
+		if !ok || name == "" || value == "" {
+			return rule, fmt.Errorf("%w: malformed part %q", ErrInvalidRule, part)
+		}
+		if seen[name] {
+			return rule, fmt.Errorf("%w: %s given twice", ErrInvalidRule, name)
+		}
+		seen[name] = true
+
+		var err error
+		switch name {
+		case "FREQ":
+			switch value {
+			case FreqDaily, FreqWeekly, FreqMonthly, FreqYearly:
+				rule.Freq = value
+			case "SECONDLY", "MINUTELY", "HOURLY":
+				err = fmt.Errorf("%w: FREQ=%s", ErrUnsupportedRule, value)
+			default:
+				err = fmt.Errorf("%w: FREQ=%s", ErrInvalidRule, value)
+			}
+		case "INTERVAL":
+			rule.Interval, err = parseRuleInt(name, value, 1, 1000)
+		case "COUNT":
+			rule.Count, err = parseRuleInt(name, value, 1, 10000)
+		case "UNTIL":
+			// a DATE-TIME limit is reduced to its day like every other date in the rule
+			if len(value) > len(dateLayout) && value[len(dateLayout)] == 'T' {
+				value = value[:len(dateLayout)]
+			}
+			rule.Until, err = time.Parse(dateLayout, value)
+			if err != nil {
+				err = fmt.Errorf("%w: UNTIL=%s", ErrInvalidRule, value)
+			}
+		case "BYDAY":
+			for _, v := range strings.Split(value, ",") {
+				w, err := parseWeekdayNum(v)
+				if err != nil {
+					return rule, err
+				}
+				rule.ByDay = append(rule.ByDay, w)
+			}
+		case "BYMONTHDAY":
+			for _, v := range strings.Split(value, ",") {
+				n, err := parseRuleInt(name, v, -31, 31)
+				if err != nil {
+					return rule, err
+				}
+				rule.ByMonthDay = append(rule.ByMonthDay, n)
+			}
+		case "BYMONTH":
+			for _, v := range strings.Split(value, ",") {
+				n, err := parseRuleInt(name, v, 1, 12)
+				if err != nil {
+					return rule, err
+				}
+				rule.ByMonth = append(rule.ByMonth, time.Month(n))
+			}
+		case "WKST":
+			day, ok := weekdayCodes[value]
+			if !ok {
+				err = fmt.Errorf("%w: WKST=%s", ErrInvalidRule, value)
+			}
+			rule.WeekStart = day
+		case "BYSETPOS", "BYYEARDAY", "BYWEEKNO", "BYHOUR", "BYMINUTE", "BYSECOND":
+			err = fmt.Errorf("%w: %s", ErrUnsupportedRule, name)
+		default:
+			err = fmt.Errorf("%w: unknown part %s", ErrInvalidRule, name)
+		}
+
+		if err != nil {
+			return rule, err
+		}
+	}
+
+	if rule.Freq == "" {
+		return rule, fmt.Errorf("%w: FREQ is required", ErrInvalidRule)
+	}
+
+	if rule.Count > 0 && !rule.Until.IsZero() {
+		return rule, fmt.Errorf("%w: COUNT and UNTIL cannot be combined", ErrInvalidRule)
+	}
+
+	if rule.Freq == FreqWeekly && len(rule.ByMonthDay) > 0 {
+		return rule, fmt.Errorf("%w: BYMONTHDAY cannot be used with FREQ=WEEKLY", ErrInvalidRule)
+	}
+
+	for _, w := range rule.ByDay {
+		if w.Nth == 0 {
+			continue
+		}
+		if rule.Freq != FreqMonthly && rule.Freq != FreqYearly {
+			return rule, fmt.Errorf("%w: BYDAY=%s needs FREQ=MONTHLY or YEARLY", ErrInvalidRule, w)
+		}
+		// an ordinal counts within the month here, which needs BYMONTH to mean the same for a yearly rule
+		if rule.Freq == FreqYearly && len(rule.ByMonth) == 0 {
+			return rule, fmt.Errorf("%w: BYDAY=%s without BYMONTH", ErrUnsupportedRule, w)
+		}
+	}
+
+	return rule, nil
+}
+
+// String returns the rule in its canonical form, the one stored with a block
+func (r RRule) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL=" + strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT=" + strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL=" + r.Until.Format(dateLayout))
+	}
+	if len(r.ByMonth) > 0 {
+		values := make([]string, len(r.ByMonth))
+		for i, m := range r.ByMonth {
+			values[i] = strconv.Itoa(int(m))
+		}
+		parts = append(parts, "BYMONTH=" + strings.Join(values, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		values := make([]string, len(r.ByMonthDay))
+		for i, d := range r.ByMonthDay {
+			values[i] = strconv.Itoa(d)
+		}
+		parts = append(parts, "BYMONTHDAY=" + strings.Join(values, ","))
+	}
+	if len(r.ByDay) > 0 {
+		values := make([]string, len(r.ByDay))
+		for i, w := range r.ByDay {
+			values[i] = w.String()
+		}
+		parts = append(parts, "BYDAY=" + strings.Join(values, ","))
+	}
+	if r.WeekStart != time.Monday {
+		parts = append(parts, "WKST=" + weekdayCode(r.WeekStart))
+	}
+
+	return strings.Join(parts, ";")
+}
+
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func daysBetween(from, to time.Time) int {
+	return int(to.Sub(from).Hours() / 24)
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month + 1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// anchor is the start of the period dtstart falls in
+func (r RRule) anchor(dtstart time.Time) time.Time {
+	switch r.Freq {
+	case FreqWeekly:
+		offset := (int(dtstart.Weekday()) - int(r.WeekStart) + 7) % 7
+		return dtstart.AddDate(0, 0, -offset)
+	case FreqMonthly:
+		return time.Date(dtstart.Year(), dtstart.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case FreqYearly:
+		return time.Date(dtstart.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return dtstart
+	}
+}
+
+// periodStart returns the start of the nth period the rule repeats on, periods skipped by INTERVAL are not counted
+func (r RRule) periodStart(anchor time.Time, n int) time.Time {
+	step := n * r.Interval
+
+	switch r.Freq {
+	case FreqWeekly:
+		return anchor.AddDate(0, 0, 7 * step)
+	case FreqMonthly:
+		return anchor.AddDate(0, step, 0)
+	case FreqYearly:
+		return anchor.AddDate(step, 0, 0)
+	default:
+		return anchor.AddDate(0, 0, step)
+	}
+}
+
+// periodsBefore is a lower bound on the number of periods between the anchor and t
+func (r RRule) periodsBefore(anchor, t time.Time) int {
+	var units int
+
+	switch r.Freq {
+	case FreqWeekly:
+		units = daysBetween(anchor, t) / 7
+	case FreqMonthly:
+		units = (t.Year() - anchor.Year()) * 12 + int(t.Month()) - int(anchor.Month())
+	case FreqYearly:
+		units = t.Year() - anchor.Year()
+	default:
+		units = daysBetween(anchor, t)
+	}
+
+	n := units / r.Interval - 1
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+func containsMonth(months []time.Month, m time.Month) bool {
+	for _, v := range months {
+		if v == m {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesMonthDay checks the day against BYMONTHDAY, where negative values count from the end of the month
+func (r RRule) matchesMonthDay(d time.Time) bool {
+	last := daysIn(d.Year(), d.Month())
+	for _, md := range r.ByMonthDay {
+		if md == d.Day() || last + 1 + md == d.Day() {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesWeekday checks the day against BYDAY, with ordinals counted within the month
+func (r RRule) matchesWeekday(d time.Time) bool {
+	last := daysIn(d.Year(), d.Month())
+	for _, w := range r.ByDay {
+		if w.Weekday != d.Weekday() {
+			continue
+		}
+		switch {
+		case w.Nth == 0:
+			return true
+		case w.Nth > 0 && (d.Day() - 1) / 7 + 1 == w.Nth:
+			return true
+		case w.Nth < 0 && (last - d.Day()) / 7 + 1 == -w.Nth:
+			return true
+		}
+	}
+	return false
+}
+
+// monthDays returns the days of the month the rule selects, in order
+func (r RRule) monthDays(year int, month time.Month, dtstart time.Time) []time.Time {
+	days := make([]time.Time, 0)
+	last := daysIn(year, month)
+
+	// without BYMONTHDAY or BYDAY the rule falls on the day of the month of dtstart, skipping months too short for it
+	if len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 {
+		if dtstart.Day() <= last {
+			days = append(days, time.Date(year, month, dtstart.Day(), 0, 0, 0, 0, time.UTC))
+		}
+		return days
+	}
+
+	for i := 1; i <= last; i++ {
+		d := time.Date(year, month, i, 0, 0, 0, 0, time.UTC)
+		if len(r.ByMonthDay) > 0 && !r.matchesMonthDay(d) {
+			continue
+		}
+		if len(r.ByDay) > 0 && !r.matchesWeekday(d) {
+			continue
+		}
+		days = append(days, d)
+	}
+
+	return days
+}
+
+// expand returns the candidate days of the period starting at start, in order
+func (r RRule) expand(start, dtstart time.Time) []time.Time {
+	days := make([]time.Time, 0)
+
+	switch r.Freq {
+	case FreqDaily:
+		if len(r.ByMonth) > 0 && !containsMonth(r.ByMonth, start.Month()) {
+			return days
+		}
+		if len(r.ByMonthDay) > 0 && !r.matchesMonthDay(start) {
+			return days
+		}
+		if len(r.ByDay) > 0 && !r.matchesWeekday(start) {
+			return days
+		}
+		days = append(days, start)
+	case FreqWeekly:
+		for i := 0; i < 7; i++ {
+			d := start.AddDate(0, 0, i)
+			if len(r.ByMonth) > 0 && !containsMonth(r.ByMonth, d.Month()) {
+				continue
+			}
+			if len(r.ByDay) > 0 && !r.matchesWeekday(d) {
+				continue
+			}
+			if len(r.ByDay) == 0 && d.Weekday() != dtstart.Weekday() {
+				continue
+			}
+			days = append(days, d)
+		}
+	case FreqMonthly:
+		if len(r.ByMonth) > 0 && !containsMonth(r.ByMonth, start.Month()) {
+			return days
+		}
+		days = append(days, r.monthDays(start.Year(), start.Month(), dtstart)...)
+	case FreqYearly:
+		months := r.ByMonth
+		if len(months) == 0 {
+			months = []time.Month{dtstart.Month()}
+			// a day of the month or weekday without BYMONTH applies to every month of the year
+			if len(r.ByMonthDay) > 0 || len(r.ByDay) > 0 {
+				months = []time.Month{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+			}
+		}
+		for _, m := range months {
+			days = append(days, r.monthDays(start.Year(), m, dtstart)...)
+		}
+		sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	}
+
+	return days
+}
+
+// Between returns the occurrences that start on or after from and before to. dtstart is the first day the rule
+// can fall on and, like RFC 5545 expects, the first occurrence when it matches the rule. Only the calendar day
+// of each date is used, so the result never depends on the time of day or zone of the arguments
+func (r RRule) Between(dtstart, from, to time.Time) []time.Time {
+	occurrences := make([]time.Time, 0)
+
+	dtstart, from, to = day(dtstart), day(from), day(to)
+	if !to.After(from) {
+		return occurrences
+	}
+	if r.Interval < 1 {
+		r.Interval = 1
+	}
+
+	anchor := r.anchor(dtstart)
+
+	// COUNT needs every occurrence from dtstart, otherwise the periods before from can be skipped
+	n := 0
+	if r.Count == 0 && from.After(anchor) {
+		n = r.periodsBefore(anchor, from)
+	}
+
+	count := 0
+	for ; ; n++ {
+		start := r.periodStart(anchor, n)
+		if !start.Before(to) || (!r.Until.IsZero() && start.After(r.Until)) {
+			return occurrences
+		}
+
+		for _, d := range r.expand(start, dtstart) {
+			if d.Before(dtstart) {
+				continue
+			}
+			if !d.Before(to) || (!r.Until.IsZero() && d.After(r.Until)) {
+				return occurrences
+			}
+
+			count++
+			if r.Count > 0 && count > r.Count {
+				return occurrences
+			}
+
+			if !d.Before(from) {
+				occurrences = append(occurrences, d)
+			}
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package ical
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestParseRRule(t *testing.T){
+	var ruleTests = []struct {
+		name string
+		rule string
+		canonical string
+		err error
+	}{
+		{"weekly", "FREQ=WEEKLY;BYDAY=MO", "FREQ=WEEKLY;BYDAY=MO", nil},
+		{"with prefix and lower case", "RRULE:freq=monthly;byday=-1fr", "FREQ=MONTHLY;BYDAY=-1FR", nil},
+		{"full", "FREQ=YEARLY;INTERVAL=2;UNTIL=20551231T000000Z;BYMONTH=1,7;WKST=SU", "FREQ=YEARLY;INTERVAL=2;UNTIL=20551231;BYMONTH=1,7;WKST=SU", nil},
+		{"missing freq", "BYDAY=MO", "", ErrInvalidRule},
+		{"unknown freq", "FREQ=FORTNIGHTLY", "", ErrInvalidRule},
+		{"hourly", "FREQ=HOURLY", "", ErrUnsupportedRule},
+		{"setpos", "FREQ=MONTHLY;BYDAY=MO;BYSETPOS=1", "", ErrUnsupportedRule},
+		{"count and until", "FREQ=DAILY;COUNT=2;UNTIL=20500101", "", ErrInvalidRule},
+		{"zero interval", "FREQ=DAILY;INTERVAL=0", "", ErrInvalidRule},
+		{"bad weekday", "FREQ=WEEKLY;BYDAY=XX", "", ErrInvalidRule},
+		{"ordinal on weekly", "FREQ=WEEKLY;BYDAY=1MO", "", ErrInvalidRule},
+		{"monthday on weekly", "FREQ=WEEKLY;BYMONTHDAY=1", "", ErrInvalidRule},
+		{"duplicate part", "FREQ=DAILY;FREQ=WEEKLY", "", ErrInvalidRule},
+		{"malformed part", "FREQ=DAILY;COUNT", "", ErrInvalidRule},
+	}
+
+	for _, e := range ruleTests {
+		rule, err := ParseRRule(e.rule)
+		if e.err != nil {
+			if !errors.Is(err, e.err) {
+				t.Errorf("%s: got error %v, wanted %v", e.name, err, e.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", e.name, err)
+			continue
+		}
+		if rule.String() != e.canonical {
+			t.Errorf("%s: got %q, wanted %q", e.name, rule.String(), e.canonical)
+		}
+	}
+}
+
+func TestRRule_Between(t *testing.T){
+	var ruleTests = []struct {
+		name string
+		rule string
+		dtstart time.Time
+		from time.Time
+		to time.Time
+		expected []time.Time
+	}{
+		{
+			"every monday",
+			"FREQ=WEEKLY;BYDAY=MO",
+			date(2050, time.January, 1),
+			date(2050, time.January, 1), date(2050, time.January, 20),
+			[]time.Time{date(2050, time.January, 3), date(2050, time.January, 10), date(2050, time.January, 17)},
+		},
+		{
+			"weekly on the weekday of dtstart",
+			"FREQ=WEEKLY;INTERVAL=2",
+			date(2050, time.January, 4),
+			date(2050, time.January, 1), date(2050, time.February, 1),
+			[]time.Time{date(2050, time.January, 4), date(2050, time.January, 18)},
+		},
+		{
+			"every january first, far from dtstart",
+			"FREQ=YEARLY",
+			date(2026, time.January, 1),
+			date(2049, time.December, 1), date(2051, time.February, 1),
+			[]time.Time{date(2050, time.January, 1), date(2051, time.January, 1)},
+		},
+		{
+			"every day of january",
+			"FREQ=DAILY;BYMONTH=1",
+			date(2049, time.December, 30),
+			date(2049, time.December, 30), date(2050, time.January, 3),
+			[]time.Time{date(2050, time.January, 1), date(2050, time.January, 2)},
+		},
+		{
+			"last friday of the month",
+			"FREQ=MONTHLY;BYDAY=-1FR",
+			date(2050, time.January, 1),
+			date(2050, time.January, 1), date(2050, time.April, 1),
+			[]time.Time{date(2050, time.January, 28), date(2050, time.February, 25), date(2050, time.March, 25)},
+		},
+		{
+			"31st skips short months",
+			"FREQ=MONTHLY",
+			date(2050, time.January, 31),
+			date(2050, time.January, 1), date(2050, time.May, 1),
+			[]time.Time{date(2050, time.January, 31), date(2050, time.March, 31)},
+		},
+		{
+			"last day of the month",
+			"FREQ=MONTHLY;BYMONTHDAY=-1",
+			date(2050, time.January, 1),
+			date(2050, time.February, 1), date(2050, time.March, 1),
+			[]time.Time{date(2050, time.February, 28)},
+		},
+		{
+			"count is taken from dtstart",
+			"FREQ=DAILY;COUNT=3",
+			date(2050, time.January, 1),
+			date(2050, time.January, 2), date(2050, time.January, 10),
+			[]time.Time{date(2050, time.January, 2), date(2050, time.January, 3)},
+		},
+		{
+			"until is inclusive",
+			"FREQ=WEEKLY;UNTIL=20500110",
+			date(2050, time.January, 3),
+			date(2050, time.January, 1), date(2050, time.February, 1),
+			[]time.Time{date(2050, time.January, 3), date(2050, time.January, 10)},
+		},
+		{
+			"nothing before dtstart",
+			"FREQ=DAILY",
+			date(2050, time.January, 10),
+			date(2050, time.January, 1), date(2050, time.January, 11),
+			[]time.Time{date(2050, time.January, 10)},
+		},
+		{
+			"second tuesday of july",
+			"FREQ=YEARLY;BYMONTH=7;BYDAY=2TU",
+			date(2050, time.January, 1),
+			date(2050, time.January, 1), date(2052, time.January, 1),
+			[]time.Time{date(2050, time.July, 12), date(2051, time.July, 11)},
+		},
+	}
+
+	for _, e := range ruleTests {
+		rule, err := ParseRRule(e.rule)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", e.name, err)
+			continue
+		}
+
+		got := rule.Between(e.dtstart, e.from, e.to)
+		if len(got) != len(e.expected) {
+			t.Errorf("%s: got %v, wanted %v", e.name, got, e.expected)
+			continue
+		}
+		for i := range got {
+			if !got[i].Equal(e.expected[i]) {
+				t.Errorf("%s: got %v, wanted %v", e.name, got, e.expected)
+				break
+			}
+		}
+	}
+}
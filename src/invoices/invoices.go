@@ -0,0 +1,109 @@
+package invoices
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+	"github.com/Orololuwa/go-backend-boilerplate/src/money"
+)
+
+// ErrNotIssuable is returned for a reservation that cannot be invoiced yet
+var ErrNotIssuable = errors.New("an invoice is issued once the guest has checked out, or for the fee of a cancelled stay")
+
+// Issuable returns ErrNotIssuable unless the guest of the confirmed stay has checked out by today, or the stay was
+// cancelled or not turned up for with a fee to invoice
+func Issuable(res models.Reservation, today time.Time) error {
+	switch res.Status {
+	case models.ReservationStatusConfirmed:
+		if !today.Before(res.EndDate) {
+			return nil
+		}
+	case models.ReservationStatusCancelled, models.ReservationStatusNoShow:
+		if res.CancellationFee > 0 {
+			return nil
+		}
+	}
+	return ErrNotIssuable
+}
+
+// Number formats the invoice number of the sequence in the series, SERIES-000042
+func Number(series string, sequence int) string {
+	return fmt.Sprintf("%s-%06d", series, sequence)
+}
+
+func formatPercent(hundredths int64) string {
+	s := strings.TrimRight(fmt.Sprintf("%d.%02d", hundredths / 100, hundredths % 100), "0")
+	return strings.TrimSuffix(s, ".") + "%"
+}
+
+// Build makes the invoice of a reservation from the tax and fee lines stored with it and its payments. Nothing is
+// priced again: the room line is what remains of the total once the stored charges are taken out and the discount
+// put back. A cancelled stay or a no-show is invoiced for its fee only
+func Build(res models.Reservation, charges []models.ReservationCharge, payments []models.Payment, series string, sequence int, issuedAt time.Time) models.Invoice {
+	inv := models.Invoice{
+		InvoiceNumber: Number(series, sequence),
+		Series: series,
+		Sequence: sequence,
+		ReservationID: res.ID,
+		ConfirmationCode: res.ConfirmationCode,
+		GuestName: res.FirstName + " " + res.LastName,
+		Email: res.Email,
+		RoomName: res.Room.RoomName,
+		Currency: res.Currency,
+		StartDate: res.StartDate,
+		EndDate: res.EndDate,
+		IssuedAt: issuedAt,
+	}
+	if inv.RoomName == "" {
+		inv.RoomName = fmt.Sprintf("Room %d", res.RoomID)
+	}
+
+	switch res.Status {
+	case models.ReservationStatusCancelled, models.ReservationStatusNoShow:
+		description := "Cancellation fee"
+		if res.Status == models.ReservationStatusNoShow {
+			description = "No-show charge"
+		}
+		inv.Lines = []models.InvoiceLine{{Description: description, Quantity: 1, Amount: res.CancellationFee}}
+		inv.Subtotal = res.CancellationFee
+	default:
+		var taxes money.Amount
+		for _, c := range charges {
+			taxes += c.Amount
+		}
+
+		nights := int(res.EndDate.Sub(res.StartDate).Hours() / 24)
+		inv.Lines = append(inv.Lines, models.InvoiceLine{
+			Description: fmt.Sprintf("%s, %s to %s", inv.RoomName, res.StartDate.Format("2006-01-02"), res.EndDate.Format("2006-01-02")),
+			Quantity: nights,
+			Amount: res.TotalPrice - taxes + res.Discount,
+		})
+
+		if res.Discount != 0 {
+			inv.Lines = append(inv.Lines, models.InvoiceLine{Description: "Promo code " + res.PromoCode, Quantity: 1, Amount: -res.Discount})
+		}
+
+		for _, c := range charges {
+			description := c.ChargeName
+			if c.Kind == models.ChargePercent {
+				description += " " + formatPercent(c.Rate)
+			}
+			inv.Lines = append(inv.Lines, models.InvoiceLine{Description: description, Quantity: c.Quantity, Amount: c.Amount, Tax: true})
+		}
+
+		inv.Subtotal = res.TotalPrice - taxes
+		inv.TaxesAndFees = taxes
+	}
+	inv.Total = inv.Subtotal + inv.TaxesAndFees
+
+	for _, p := range payments {
+		inv.Paid += p.CapturedAmount
+		inv.Refunded += p.RefundedAmount
+	}
+	inv.BalanceDue = inv.Total - inv.Paid + inv.Refunded
+
+	return inv
+}
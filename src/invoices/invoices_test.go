@@ -0,0 +1,150 @@
+package invoices
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+	"github.com/Orololuwa/go-backend-boilerplate/src/money"
+)
+
+func checkedOut() models.Reservation {
+	return models.Reservation{
+		ID: 12,
+		ConfirmationCode: "ABCD2345",
+		FirstName: "John",
+		LastName: "Doe",
+		Email: "johndoe@gmail.com",
+		StartDate: time.Date(2050, time.January, 6, 0, 0, 0, 0, time.UTC),
+		EndDate: time.Date(2050, time.January, 9, 0, 0, 0, 0, time.UTC),
+		RoomID: 20,
+		Room: models.Room{ID: 20, RoomName: "Garden Suite"},
+		TotalPrice: 35738,
+		PromoCode: "SAVE10",
+		Discount: 3600,
+		Status: models.ReservationStatusConfirmed,
+	}
+}
+
+var testCharges = []models.ReservationCharge{
+	{ChargeName: "City tax", Kind: models.ChargePerPersonNight, Rate: 150, Quantity: 6, Amount: 900},
+	{ChargeName: "VAT", Kind: models.ChargePercent, Rate: 750, Quantity: 1, Amount: 2438},
+}
+
+func TestIssuable(t *testing.T){
+	today := time.Date(2050, time.January, 9, 0, 0, 0, 0, time.UTC)
+
+	var issuableTests = []struct {
+		name string
+		status string
+		fee money.Amount
+		today time.Time
+		expected error
+	}{
+		{"checked out today", models.ReservationStatusConfirmed, 0, today, nil},
+		{"still staying", models.ReservationStatusConfirmed, 0, today.AddDate(0, 0, -1), ErrNotIssuable},
+		{"pending", models.ReservationStatusPending, 0, today, ErrNotIssuable},
+		{"cancelled with a fee", models.ReservationStatusCancelled, 5000, today.AddDate(0, 0, -10), nil},
+		{"cancelled for free", models.ReservationStatusCancelled, 0, today, ErrNotIssuable},
+		{"no-show with a fee", models.ReservationStatusNoShow, 12000, today, nil},
+	}
+
+	for _, e := range issuableTests {
+		res := checkedOut()
+		res.Status = e.status
+		res.CancellationFee = e.fee
+
+		if got := Issuable(res, e.today); got != e.expected {
+			t.Errorf("Issuable returned %v for %s, wanted %v", got, e.name, e.expected)
+		}
+	}
+}
+
+func TestBuild(t *testing.T){
+	payments := []models.Payment{{CapturedAmount: 35738, RefundedAmount: 1000}}
+	inv := Build(checkedOut(), testCharges, payments, "INV", 42, time.Now())
+
+	if inv.InvoiceNumber != "INV-000042" || inv.Sequence != 42 || inv.GuestName != "John Doe" {
+		t.Errorf("Build returned the invoice %s %d for %s", inv.InvoiceNumber, inv.Sequence, inv.GuestName)
+	}
+
+	expected := []models.InvoiceLine{
+		{Description: "Garden Suite, 2050-01-06 to 2050-01-09", Quantity: 3, Amount: 36000},
+		{Description: "Promo code SAVE10", Quantity: 1, Amount: -3600},
+		{Description: "City tax", Quantity: 6, Amount: 900, Tax: true},
+		{Description: "VAT 7.5%", Quantity: 1, Amount: 2438, Tax: true},
+	}
+	if len(inv.Lines) != len(expected) {
+		t.Fatalf("Build returned the lines %+v, wanted %d", inv.Lines, len(expected))
+	}
+	for i := range expected {
+		if inv.Lines[i] != expected[i] {
+			t.Errorf("Build returned the line %+v, wanted %+v", inv.Lines[i], expected[i])
+		}
+	}
+
+	if inv.Subtotal != 32400 || inv.TaxesAndFees != 3338 || inv.Total != 35738 {
+		t.Errorf("Build returned a subtotal of %d, taxes of %d and a total of %d", inv.Subtotal, inv.TaxesAndFees, inv.Total)
+	}
+
+	if inv.Paid != 35738 || inv.Refunded != 1000 || inv.BalanceDue != 1000 {
+		t.Errorf("Build returned %d paid, %d refunded and %d due", inv.Paid, inv.Refunded, inv.BalanceDue)
+	}
+}
+
+func TestBuildCancelled(t *testing.T){
+	res := checkedOut()
+	res.Status = models.ReservationStatusNoShow
+	res.CancellationFee = 12000
+
+	inv := Build(res, testCharges, nil, "INV", 1, time.Now())
+
+	if len(inv.Lines) != 1 || inv.Lines[0].Description != "No-show charge" || inv.Total != 12000 || inv.BalanceDue != 12000 {
+		t.Errorf("Build returned the lines %+v with a total of %d and %d due", inv.Lines, inv.Total, inv.BalanceDue)
+	}
+}
+
+func TestRenderHTML(t *testing.T){
+	res := checkedOut()
+	res.FirstName = "<script>"
+
+	var out bytes.Buffer
+	err := RenderHTML(&out, Build(res, testCharges, nil, "INV", 42, time.Now()), "Seaside & Co")
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := out.String()
+
+	for _, want := range []string{"Seaside &amp; Co Invoice INV-000042", "&lt;script&gt; Doe", "VAT 7.5%", "357.38"} {
+		if !strings.Contains(page, want) {
+			t.Errorf("RenderHTML did not write %q:\n%s", want, page)
+		}
+	}
+	if strings.Contains(page, "<script>") {
+		t.Errorf("RenderHTML did not escape the guest name")
+	}
+}
+
+func TestRenderPDF(t *testing.T){
+	inv := Build(checkedOut(), testCharges, nil, "INV", 42, time.Now())
+	for i := 0; i < 60; i++ {
+		inv.Lines = append(inv.Lines, models.InvoiceLine{Description: "Minibar", Quantity: 1, Amount: 500})
+	}
+
+	var out bytes.Buffer
+	err := RenderPDF(&out, inv, "Seaside")
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := out.String()
+
+	if !strings.HasPrefix(doc, "%PDF-") || !strings.Contains(doc, "(Seaside Invoice INV-000042) Tj") {
+		t.Errorf("RenderPDF did not write the invoice")
+	}
+
+	if !strings.Contains(doc, "/Count 2") {
+		t.Errorf("RenderPDF did not continue the lines on a second page")
+	}
+}
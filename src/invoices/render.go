@@ -0,0 +1,174 @@
+package invoices
+
+import (
+	"html/template"
+	"io"
+	"strconv"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+	"github.com/Orololuwa/go-backend-boilerplate/src/money"
+	"github.com/Orololuwa/go-backend-boilerplate/src/pdf"
+)
+
+var htmlTemplate = template.Must(template.New("invoice").Funcs(template.FuncMap{
+	"amount": money.Format,
+	"date": func(inv models.Invoice, which string) string {
+		switch which {
+		case "start":
+			return inv.StartDate.Format("2006-01-02")
+		case "end":
+			return inv.EndDate.Format("2006-01-02")
+		}
+		return inv.IssuedAt.Format("2006-01-02")
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Invoice {{.Invoice.InvoiceNumber}}</title>
+<style>
+body { font-family: Helvetica, Arial, sans-serif; color: #222; max-width: 720px; margin: 40px auto; }
+table { width: 100%; border-collapse: collapse; }
+th, td { padding: 6px 4px; border-bottom: 1px solid #ddd; text-align: left; }
+.amount { text-align: right; white-space: nowrap; }
+.total td { font-weight: bold; border-bottom: none; }
+</style>
+</head>
+<body>
+<h1>{{if .Property}}{{.Property}} {{end}}Invoice {{.Invoice.InvoiceNumber}}</h1>
+<p>Issued {{date .Invoice "issued"}}</p>
+<p>
+{{.Invoice.GuestName}}<br>
+{{.Invoice.Email}}<br>
+Reservation {{.Invoice.ConfirmationCode}}, {{.Invoice.RoomName}}, {{date .Invoice "start"}} to {{date .Invoice "end"}}
+</p>
+<table>
+<thead><tr><th>Description</th><th class="amount">Quantity</th><th class="amount">Amount{{if .Invoice.Currency}} ({{.Invoice.Currency}}){{end}}</th></tr></thead>
+<tbody>
+{{range .Invoice.Lines}}<tr><td>{{.Description}}</td><td class="amount">{{.Quantity}}</td><td class="amount">{{amount .Amount $.Invoice.Currency}}</td></tr>
+{{end}}</tbody>
+<tfoot>
+<tr><td colspan="2">Subtotal</td><td class="amount">{{amount .Invoice.Subtotal .Invoice.Currency}}</td></tr>
+<tr><td colspan="2">Taxes and fees</td><td class="amount">{{amount .Invoice.TaxesAndFees .Invoice.Currency}}</td></tr>
+<tr class="total"><td colspan="2">Total</td><td class="amount">{{amount .Invoice.Total .Invoice.Currency}}</td></tr>
+<tr><td colspan="2">Paid</td><td class="amount">{{amount .Invoice.Paid .Invoice.Currency}}</td></tr>
+{{if .Invoice.Refunded}}<tr><td colspan="2">Refunded</td><td class="amount">{{amount .Invoice.Refunded .Invoice.Currency}}</td></tr>
+{{end}}<tr class="total"><td colspan="2">Balance due</td><td class="amount">{{amount .Invoice.BalanceDue .Invoice.Currency}}</td></tr>
+</tfoot>
+</table>
+</body>
+</html>
+`))
+
+// RenderHTML writes the invoice as an HTML page. property names the issuer in the title and may be empty
+func RenderHTML(w io.Writer, inv models.Invoice, property string) error {
+	return htmlTemplate.Execute(w, struct {
+		Invoice models.Invoice
+		Property string
+	}{inv, property})
+}
+
+// layout of the PDF, in points
+const (
+	marginLeft = 50.0
+	marginRight = pdf.PageWidth - 50
+	marginTop = pdf.PageHeight - 60
+	marginBottom = 60.0
+	quantityRight = marginRight - 110
+	lineHeight = 16.0
+)
+
+// RenderPDF writes the invoice as an A4 PDF, continuing on new pages when the lines do not fit
+func RenderPDF(w io.Writer, inv models.Invoice, property string) error {
+	d := pdf.New()
+	y := marginTop
+
+	title := "Invoice " + inv.InvoiceNumber
+	if property != "" {
+		title = property + " " + title
+	}
+
+	d.Text(marginLeft, y, pdf.HelveticaBold, 18, title)
+	y -= 24
+	d.Text(marginLeft, y, pdf.Helvetica, 10, "Issued " + inv.IssuedAt.Format("2006-01-02"))
+	y -= 2 * lineHeight
+
+	for _, s := range []string{
+		inv.GuestName,
+		inv.Email,
+		"Reservation " + inv.ConfirmationCode + ", " + inv.RoomName + ", " + inv.StartDate.Format("2006-01-02") + " to " + inv.EndDate.Format("2006-01-02"),
+	} {
+		d.Text(marginLeft, y, pdf.Helvetica, 10, s)
+		y -= lineHeight
+	}
+	y -= lineHeight
+
+	header := func() {
+		d.Text(marginLeft, y, pdf.HelveticaBold, 10, "Description")
+		d.Text(quantityRight - 45, y, pdf.HelveticaBold, 10, "Quantity")
+		if inv.Currency != "" {
+			d.TextRight(marginRight, y, 10, inv.Currency)
+		}else{
+			d.Text(marginRight - 40, y, pdf.HelveticaBold, 10, "Amount")
+		}
+		d.Line(marginLeft, y - 5, marginRight, y - 5)
+		y -= lineHeight + 4
+	}
+	header()
+
+	for _, line := range inv.Lines {
+		if y < marginBottom {
+			d.AddPage()
+			y = marginTop
+			header()
+		}
+
+		d.Text(marginLeft, y, pdf.Helvetica, 10, line.Description)
+		d.TextRight(quantityRight, y, 10, strconv.Itoa(line.Quantity))
+		d.TextRight(marginRight, y, 10, money.Format(line.Amount, inv.Currency))
+		y -= lineHeight
+	}
+
+	totals := []struct {
+		label string
+		amount money.Amount
+		bold bool
+	}{
+		{"Subtotal", inv.Subtotal, false},
+		{"Taxes and fees", inv.TaxesAndFees, false},
+		{"Total", inv.Total, true},
+		{"Paid", inv.Paid, false},
+	}
+	if inv.Refunded != 0 {
+		totals = append(totals, struct {
+			label string
+			amount money.Amount
+			bold bool
+		}{"Refunded", inv.Refunded, false})
+	}
+	totals = append(totals, struct {
+		label string
+		amount money.Amount
+		bold bool
+	}{"Balance due", inv.BalanceDue, true})
+
+	if y - float64(len(totals) + 1) * lineHeight < marginBottom {
+		d.AddPage()
+		y = marginTop
+	}
+
+	d.Line(marginLeft, y + lineHeight - 5, marginRight, y + lineHeight - 5)
+	y -= 4
+	for _, t := range totals {
+		font := pdf.Helvetica
+		if t.bold {
+			font = pdf.HelveticaBold
+		}
+		d.Text(marginLeft, y, font, 10, t.label)
+		d.TextRight(marginRight, y, 10, money.Format(t.amount, inv.Currency))
+		y -= lineHeight
+	}
+
+	_, err := d.WriteTo(w)
+	return err
+}
@@ -0,0 +1,32 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/calendarsync"
+	"github.com/Orololuwa/go-backend-boilerplate/src/config"
+)
+
+// SyncCalendarFeeds imports every external calendar feed every interval until the context is cancelled
+func SyncCalendarFeeds(ctx context.Context, a *config.AppConfig, s *calendarsync.Service, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			failed, err := s.SyncAll(ctx)
+			if err != nil {
+				a.ErrorLog.Println(err)
+				continue
+			}
+
+			if failed > 0 {
+				a.InfoLog.Printf("%d calendar feeds failed to sync", failed)
+			}
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/config"
+	"github.com/Orololuwa/go-backend-boilerplate/src/repository"
+)
+
+// SweepExpiredHolds releases lapsed room holds every interval until the context is cancelled
+func SweepExpiredHolds(ctx context.Context, a *config.AppConfig, db repository.DatabaseRepo, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			released, err := db.DeleteExpiredHolds(ctx, nil, time.Now())
+			if err != nil {
+				a.ErrorLog.Println(err)
+				continue
+			}
+
+			if released > 0 {
+				a.InfoLog.Printf("released %d expired room holds", released)
+			}
+		}
+	}
+}
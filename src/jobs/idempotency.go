@@ -0,0 +1,32 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/config"
+	"github.com/Orololuwa/go-backend-boilerplate/src/repository"
+)
+
+// SweepExpiredIdempotencyKeys removes stored idempotent responses once their TTL has passed
+func SweepExpiredIdempotencyKeys(ctx context.Context, a *config.AppConfig, store repository.IdempotencyRepo, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := store.DeleteExpiredIdempotencyRecords(ctx, nil, time.Now())
+			if err != nil {
+				a.ErrorLog.Println(err)
+				continue
+			}
+
+			if deleted > 0 {
+				a.InfoLog.Printf("deleted %d expired idempotency keys", deleted)
+			}
+		}
+	}
+}
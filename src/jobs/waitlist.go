@@ -0,0 +1,32 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/config"
+	"github.com/Orololuwa/go-backend-boilerplate/src/waitlist"
+)
+
+// ExpireWaitlistOffers passes lapsed waitlist offers on to the next guest every interval until the context is cancelled
+func ExpireWaitlistOffers(ctx context.Context, a *config.AppConfig, w *waitlist.Service, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			expired, err := w.ExpireOffers(ctx, time.Now())
+			if err != nil {
+				a.ErrorLog.Println(err)
+				continue
+			}
+
+			if expired > 0 {
+				a.InfoLog.Printf("expired %d waitlist offers", expired)
+			}
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/helpers"
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+)
+
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+const defaultIdempotencyTTL = 24 * time.Hour
+const defaultIdempotencyLease = time.Minute
+const maxIdempotencyKeyLength = 255
+
+// responseRecorder passes the response through while keeping a copy of the status and body
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body bytes.Buffer
+}
+
+func (rec *responseRecorder) WriteHeader(code int) {
+	rec.status = code
+	rec.ResponseWriter.WriteHeader(code)
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	rec.body.Write(b)
+	return rec.ResponseWriter.Write(b)
+}
+
+func (m *Middleware) idempotencyTTL() time.Duration {
+	if m.App.IdempotencyTTL > 0 {
+		return m.App.IdempotencyTTL
+	}
+
+	return defaultIdempotencyTTL
+}
+
+// idempotencyLease is how long a request holds its key in flight, a retry after it takes the key over
+func (m *Middleware) idempotencyLease() time.Duration {
+	if m.App.IdempotencyLease > 0 {
+		return m.App.IdempotencyLease
+	}
+
+	return defaultIdempotencyLease
+}
+
+// requestFingerprint identifies the payload a key was first used with
+func requestFingerprint(r *http.Request, payload []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + "\n" + r.URL.Path + "\n"))
+	hash.Write(payload)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Idempotency replays the stored response when a request is retried with the same Idempotency-Key header.
+// Requests without the header are passed through untouched
+func (m *Middleware) Idempotency(next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        key := r.Header.Get(IdempotencyKeyHeader)
+        if key == "" {
+            next.ServeHTTP(w, r)
+            return
+        }
+
+        if len(key) > maxIdempotencyKeyLength {
+            helpers.ClientError(w, errors.New("Idempotency-Key is too long"), http.StatusBadRequest, "")
+            return
+        }
+
+        payload, err := io.ReadAll(r.Body)
+        if err != nil {
+            helpers.ClientError(w, err, http.StatusBadRequest, "failed to read body")
+            return
+        }
+        r.Body.Close()
+        r.Body = io.NopCloser(bytes.NewReader(payload))
+
+        fingerprint := requestFingerprint(r, payload)
+        ctx := context.Background()
+
+        // the lease token tells this request's record from the one of a retry that took the key over after the lease
+        leaseToken, err := helpers.RandomToken(16)
+        if err != nil {
+            helpers.ClientError(w, err, http.StatusInternalServerError, "")
+            return
+        }
+
+        reserved, err := m.IdempotencyStore.ReserveIdempotencyKey(ctx, nil, models.IdempotencyRecord{
+            Key: key,
+            Fingerprint: fingerprint,
+            LockedUntil: time.Now().Add(m.idempotencyLease()),
+            LeaseToken: leaseToken,
+            ExpiresAt: time.Now().Add(m.idempotencyTTL()),
+        })
+        if err != nil {
+            helpers.ClientError(w, err, http.StatusInternalServerError, "")
+            return
+        }
+
+        if !reserved {
+            rec, err := m.IdempotencyStore.GetIdempotencyRecord(ctx, nil, key)
+            if errors.Is(err, sql.ErrNoRows) {
+                helpers.ClientError(w, errors.New("a request with this Idempotency-Key is still being processed"), http.StatusConflict, "")
+                return
+            }
+            if err != nil {
+                helpers.ClientError(w, err, http.StatusInternalServerError, "")
+                return
+            }
+
+            if rec.Fingerprint != fingerprint {
+                helpers.ClientError(w, errors.New("Idempotency-Key has already been used with a different request"), http.StatusUnprocessableEntity, "")
+                return
+            }
+
+            if rec.StatusCode == 0 {
+                helpers.ClientError(w, errors.New("a request with this Idempotency-Key is still being processed"), http.StatusConflict, "")
+                return
+            }
+
+            if rec.ContentType != "" {
+                w.Header().Set("Content-Type", rec.ContentType)
+            }
+            w.Header().Set("Idempotent-Replayed", "true")
+            w.WriteHeader(rec.StatusCode)
+            w.Write(rec.ResponseBody)
+            return
+        }
+
+        recorder := &responseRecorder{ResponseWriter: w}
+        next.ServeHTTP(recorder, r)
+
+        if recorder.status == 0 {
+            recorder.status = http.StatusOK
+        }
+
+        // server errors are not stored so the client can retry with the same key
+        if recorder.status >= http.StatusInternalServerError {
+            if err := m.IdempotencyStore.DeleteIdempotencyRecord(ctx, nil, key, leaseToken); err != nil {
+                m.App.ErrorLog.Println(err)
+            }
+            return
+        }
+
+        err = m.IdempotencyStore.CompleteIdempotencyRecord(ctx, nil, key, leaseToken, recorder.status, recorder.Header().Get("Content-Type"), recorder.body.Bytes())
+        if err != nil {
+            m.App.ErrorLog.Println(err)
+        }
+    })
+}
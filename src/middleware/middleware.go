@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"reflect"
 
 	"github.com/Orololuwa/go-backend-boilerplate/src/config"
 	"github.com/Orololuwa/go-backend-boilerplate/src/driver"
@@ -19,12 +20,14 @@ import (
 type Middleware struct {
     App *config.AppConfig
 	DB repository.DatabaseRepo
+	IdempotencyStore repository.IdempotencyRepo
 }
 
 func New(a *config.AppConfig, db *driver.DB) *Middleware {
     return &Middleware{
         App: a,
         DB: dbrepo.NewPostgresDBRepo(db.SQL),
+        IdempotencyStore: dbrepo.NewIdempotencyDBRepo(db.SQL),
     }
 }
 
@@ -32,27 +35,31 @@ func NewTest(a *config.AppConfig) *Middleware {
     return &Middleware{
         App: a,
         DB: dbrepo.NewTestingDBRepo(),
+        IdempotencyStore: dbrepo.NewIdempotencyMemoryRepo(),
     }
 }
 
 func (m *Middleware) ValidateReqBody(next http.Handler, requestBodyStruct interface{}) http.Handler {
     return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
+        // decode into a fresh value per request so concurrent requests never share the same struct
+        body := reflect.New(reflect.TypeOf(requestBodyStruct).Elem()).Interface()
+
         decoder := json.NewDecoder(r.Body)
-        if err := decoder.Decode(requestBodyStruct); err != nil {
+        if err := decoder.Decode(body); err != nil {
 			helpers.ClientError(w, err, http.StatusBadRequest, "failed to decode body")
             return
         }
 
 		defer r.Body.Close()
 
-        if err := m.App.Validate.Struct(requestBodyStruct); err != nil {
+        if err := m.App.Validate.Struct(body); err != nil {
             errors := err.(validator.ValidationErrors)
 			helpers.ClientError(w, err, http.StatusBadRequest, errors.Error())
             return
         }
 
-		ctx := context.WithValue(r.Context(), "validatedRequestBody", requestBodyStruct)
+		ctx := context.WithValue(r.Context(), "validatedRequestBody", body)
 		r = r.WithContext(ctx)
 
         next.ServeHTTP(w, r)
@@ -83,6 +90,8 @@ func (m *Middleware) Authorization(next http.Handler) http.Handler {
             return
         }
 
-        next.ServeHTTP(w, r)
+        // handlers read the claims to know which staff member made a change
+        ctx := context.WithValue(r.Context(), "claims", claims)
+        next.ServeHTTP(w, r.WithContext(ctx))
     })
 }
\ No newline at end of file
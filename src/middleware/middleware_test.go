@@ -2,13 +2,16 @@ package middleware
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/Orololuwa/go-backend-boilerplate/src/helpers"
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
 	"github.com/go-faker/faker/v4"
 )
 
@@ -123,4 +126,222 @@ func TestAuthorizationMiddleware(t *testing.T){
 	if res.Code != http.StatusOK {
 		t.Errorf("Authorization expected status code %d for invalid token, got %d", http.StatusOK, res.Code)
 	}
-}
\ No newline at end of file
+}
+
+func TestIdempotencyMiddleware(t *testing.T){
+	calls := 0
+	countingHandler := func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		helpers.ClientResponseWriter(w, calls, http.StatusCreated, "created")
+	}
+
+	failingHandler := func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+
+	// use a fresh in-memory store so keys from earlier runs do not leak into this one
+	md := NewTest(mdTest.App)
+	handlerChain := md.Idempotency(http.HandlerFunc(countingHandler))
+
+	// test that requests without a key are not deduplicated
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest("POST", "/route", bytes.NewBufferString(`{"roomId":1}`))
+		res := httptest.NewRecorder()
+		handlerChain.ServeHTTP(res, req)
+	}
+
+	if calls != 2 {
+		t.Errorf("Idempotency expected requests without a key to reach the handler twice, got %d calls", calls)
+	}
+
+	// test that a retried key replays the stored response
+	calls = 0
+	var bodies []string
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest("POST", "/route", bytes.NewBufferString(`{"roomId":1}`))
+		req.Header.Set(IdempotencyKeyHeader, "replay-key")
+		res := httptest.NewRecorder()
+		handlerChain.ServeHTTP(res, req)
+
+		if res.Code != http.StatusCreated {
+			t.Errorf("Idempotency expected status code %d, got %d", http.StatusCreated, res.Code)
+		}
+		bodies = append(bodies, res.Body.String())
+
+		if i == 1 && res.Header().Get("Idempotent-Replayed") != "true" {
+			t.Error("Idempotency expected the replayed response to be flagged")
+		}
+	}
+
+	if calls != 1 {
+		t.Errorf("Idempotency expected the handler to run once for a repeated key, got %d calls", calls)
+	}
+
+	if bodies[0] != bodies[1] {
+		t.Errorf("Idempotency expected the replayed body %s to equal the original %s", bodies[1], bodies[0])
+	}
+
+	// test that a key reused with a different payload is rejected
+	req := httptest.NewRequest("POST", "/route", bytes.NewBufferString(`{"roomId":2}`))
+	req.Header.Set(IdempotencyKeyHeader, "replay-key")
+	res := httptest.NewRecorder()
+	handlerChain.ServeHTTP(res, req)
+
+	if res.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Idempotency expected status code %d for a reused key with a different payload, got %d", http.StatusUnprocessableEntity, res.Code)
+	}
+
+	// test that server errors are not stored
+	calls = 0
+	handlerChain = md.Idempotency(http.HandlerFunc(failingHandler))
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest("POST", "/route", bytes.NewBufferString(`{"roomId":1}`))
+		req.Header.Set(IdempotencyKeyHeader, "failing-key")
+		res := httptest.NewRecorder()
+		handlerChain.ServeHTTP(res, req)
+	}
+
+	if calls != 2 {
+		t.Errorf("Idempotency expected a failed request to be retried, got %d calls", calls)
+	}
+}
+
+func TestIdempotencyLease(t *testing.T){
+	calls := 0
+	countingHandler := func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		helpers.ClientResponseWriter(w, calls, http.StatusCreated, "created")
+	}
+
+	md := NewTest(mdTest.App)
+	handlerChain := md.Idempotency(http.HandlerFunc(countingHandler))
+
+	// hold a key in flight the way a request that died before it finished leaves it
+	hold := func(key string, lockedUntil time.Time) {
+		req := httptest.NewRequest("POST", "/route", nil)
+		_, err := md.IdempotencyStore.ReserveIdempotencyKey(context.Background(), nil, models.IdempotencyRecord{
+			Key: key,
+			Fingerprint: requestFingerprint(req, []byte(`{"roomId":1}`)),
+			LockedUntil: lockedUntil,
+			ExpiresAt: time.Now().Add(time.Hour),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var leaseTests = []struct {
+		name string
+		key string
+		lockedUntil time.Time
+		payload string
+		expectedStatusCode int
+	}{
+		{"request still in flight", "live-key", time.Now().Add(time.Minute), `{"roomId":1}`, http.StatusConflict},
+		{"lease ran out", "stuck-key", time.Now().Add(-time.Second), `{"roomId":1}`, http.StatusCreated},
+		{"lease ran out on another request", "other-key", time.Now().Add(-time.Second), `{"roomId":2}`, http.StatusUnprocessableEntity},
+	}
+
+	for _, e := range leaseTests {
+		hold(e.key, e.lockedUntil)
+
+		req := httptest.NewRequest("POST", "/route", bytes.NewBufferString(e.payload))
+		req.Header.Set(IdempotencyKeyHeader, e.key)
+		res := httptest.NewRecorder()
+		handlerChain.ServeHTTP(res, req)
+
+		if res.Code != e.expectedStatusCode {
+			t.Errorf("Idempotency returned %d for %s, wanted %d", res.Code, e.name, e.expectedStatusCode)
+		}
+	}
+
+	if calls != 1 {
+		t.Errorf("Idempotency expected only the request whose lease ran out to reach the handler, got %d calls", calls)
+	}
+}
+
+func TestIdempotencyLeaseTakeover(t *testing.T){
+	md := NewTest(mdTest.App)
+	ctx := context.Background()
+
+	reserve := func(token string, lockedUntil time.Time) {
+		reserved, err := md.IdempotencyStore.ReserveIdempotencyKey(ctx, nil, models.IdempotencyRecord{
+			Key: "takeover-key",
+			Fingerprint: "fingerprint",
+			LockedUntil: lockedUntil,
+			LeaseToken: token,
+			ExpiresAt: time.Now().Add(time.Hour),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reserved {
+			t.Fatalf("Idempotency expected %s to reserve the key", token)
+		}
+	}
+
+	// the original request's lease runs out and a retry takes the key over
+	reserve("original", time.Now().Add(-time.Second))
+	reserve("retry", time.Now().Add(time.Minute))
+
+	// the original finishing late must neither replace the retry's response nor release its key
+	if err := md.IdempotencyStore.CompleteIdempotencyRecord(ctx, nil, "takeover-key", "original", http.StatusCreated, "application/json", []byte(`{"stale":true}`)); err == nil {
+		t.Error("Idempotency expected the original request's completion to be refused after the takeover")
+	}
+	if err := md.IdempotencyStore.DeleteIdempotencyRecord(ctx, nil, "takeover-key", "original"); err != nil {
+		t.Fatal(err)
+	}
+
+	rec, err := md.IdempotencyStore.GetIdempotencyRecord(ctx, nil, "takeover-key")
+	if err != nil {
+		t.Fatalf("Idempotency expected the retry's record to survive the original's delete: %s", err)
+	}
+	if rec.LeaseToken != "retry" || rec.StatusCode != 0 {
+		t.Errorf("Idempotency expected the retry to still hold the key, got token %q and status %d", rec.LeaseToken, rec.StatusCode)
+	}
+
+	if err := md.IdempotencyStore.CompleteIdempotencyRecord(ctx, nil, "takeover-key", "retry", http.StatusCreated, "application/json", []byte(`{}`)); err != nil {
+		t.Errorf("Idempotency expected the retry to complete its record: %s", err)
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T){
+	handlerChain := mdTest.RateLimit(http.HandlerFunc(middlewareHandler), 2, time.Minute)
+
+	// test that requests within the limit pass through
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest("GET", "/route", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		res := httptest.NewRecorder()
+		handlerChain.ServeHTTP(res, req)
+
+		if res.Code != http.StatusOK {
+			t.Errorf("RateLimit expected status code %d within the limit, got %d", http.StatusOK, res.Code)
+		}
+	}
+
+	// test that the next request from the same client is rejected
+	req := httptest.NewRequest("GET", "/route", nil)
+	req.RemoteAddr = "10.0.0.1:5678"
+	res := httptest.NewRecorder()
+	handlerChain.ServeHTTP(res, req)
+
+	if res.Code != http.StatusTooManyRequests {
+		t.Errorf("RateLimit expected status code %d over the limit, got %d", http.StatusTooManyRequests, res.Code)
+	}
+
+	if res.Header().Get("Retry-After") == "" {
+		t.Error("RateLimit expected a Retry-After header over the limit")
+	}
+
+	// test that other clients are counted separately
+	req = httptest.NewRequest("GET", "/route", nil)
+	req.RemoteAddr = "10.0.0.2:1234"
+	res = httptest.NewRecorder()
+	handlerChain.ServeHTTP(res, req)
+
+	if res.Code != http.StatusOK {
+		t.Errorf("RateLimit expected status code %d for another client, got %d", http.StatusOK, res.Code)
+	}
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/helpers"
+)
+
+type rateWindow struct {
+	count int
+	start time.Time
+}
+
+// clientIP returns the host part of the remote address, falling back to the raw value
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// RateLimit allows each client IP at most the given number of requests per window and answers 429 beyond that
+func (m *Middleware) RateLimit(next http.Handler, requests int, window time.Duration) http.Handler {
+	var mu sync.Mutex
+	clients := make(map[string]*rateWindow)
+	lastPrune := time.Now()
+
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        ip := clientIP(r)
+        now := time.Now()
+
+        mu.Lock()
+        // drop windows that have closed so the map does not grow with every client ever seen
+        if now.Sub(lastPrune) > window {
+            for key, c := range clients {
+                if now.Sub(c.start) > window {
+                    delete(clients, key)
+                }
+            }
+            lastPrune = now
+        }
+
+        c, ok := clients[ip]
+        if !ok || now.Sub(c.start) > window {
+            c = &rateWindow{start: now}
+            clients[ip] = c
+        }
+        c.count++
+        count := c.count
+        retryAfter := window - now.Sub(c.start)
+        mu.Unlock()
+
+        if count > requests {
+            w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+            helpers.ClientError(w, errors.New("too many requests"), http.StatusTooManyRequests, "")
+            return
+        }
+
+        next.ServeHTTP(w, r)
+    })
+}
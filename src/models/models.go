@@ -1,6 +1,14 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/ical"
+	"github.com/Orololuwa/go-backend-boilerplate/src/money"
+)
 
 // Users is the user's model
 type User struct {
@@ -17,20 +25,68 @@ type User struct {
 type Room struct {
 	ID int `json:"id"`
 	RoomName string `json:"roomName"`
+	BaseRate money.Amount `json:"baseRate"`
+	WeekendRate money.Amount `json:"weekendRate"`
+	Currency string `json:"currency"`
+	MaxOccupancy int `json:"maxOccupancy"`
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
 
 }
 
+// Sleeps reports whether the room takes a party of guests, a MaxOccupancy of 0 is not enforced
+func (r Room) Sleeps(guests int) bool {
+	return r.MaxOccupancy == 0 || guests <= r.MaxOccupancy
+}
+
+// Restriction ids seeded in the restrictions table
+const (
+	RestrictionReservation = 1
+	RestrictionOwnerBlock = 2
+	RestrictionHold = 3
+	RestrictionExternalBlock = 4
+)
+
 type Restriction struct {
-	ID int
-	RestrictionName string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID int `json:"id"`
+	RestrictionName string `json:"restrictionName"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
 }
 
+// IsSystemRestriction reports whether the app relies on the restriction type, these cannot be deleted
+func IsSystemRestriction(id int) bool {
+	switch id {
+	case RestrictionReservation, RestrictionOwnerBlock, RestrictionHold, RestrictionExternalBlock:
+		return true
+	}
+	return false
+}
+
+// IsManagedRestriction reports whether rows of the restriction type are created and removed by the app,
+// so staff cannot create blocks with it
+func IsManagedRestriction(id int) bool {
+	switch id {
+	case RestrictionReservation, RestrictionHold, RestrictionExternalBlock:
+		return true
+	}
+	return false
+}
+
+// Reservation statuses
+const (
+	ReservationStatusPending = "pending"
+	ReservationStatusConfirmed = "confirmed"
+	ReservationStatusCancelled = "cancelled"
+	ReservationStatusNoShow = "no_show"
+)
+
+// Reservation amounts are in Currency, the currency the guest is charged in. They were converted from the room's
+// BaseCurrency at ExchangeRate when the stay was booked, and moving the stay keeps that rate. PromoDiscountType and
+// PromoDiscountValue are the rule of PromoCode when it was redeemed, a fixed value already in Currency
 type Reservation struct {
 	ID int
+	ConfirmationCode string
 	FirstName string
 	LastName  string
 	Email     string
@@ -38,11 +94,269 @@ type Reservation struct {
 	StartDate time.Time
 	EndDate time.Time
 	RoomID int
+	Adults int
+	Children int
+	TotalPrice money.Amount
+	Status string
+	GroupID int
+	CancellationPolicy *CancellationPolicy
+	CancellationFee money.Amount
+	RefundAmount money.Amount
+	PromoCode string
+	Discount money.Amount
+	PromoDiscountType string
+	PromoDiscountValue int64
+	Charges []ReservationCharge
+	Currency string
+	BaseCurrency string
+	ExchangeRate money.Rate
+	GuestID int
+	SpecialRequests string
+	RequestsResolved bool
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	Room Room
 }
 
+func (r Reservation) Guests() int {
+	return r.Adults + r.Children
+}
+
+// Reservation history actions
+const (
+	HistoryCreated = "created"
+	HistoryUpdated = "updated"
+	HistoryCancelled = "cancelled"
+	HistoryStatusChanged = "status_changed"
+	HistoryRequestsResolved = "requests_resolved"
+)
+
+// Actors of changes not made by signed in staff
+const (
+	HistoryActorGuest = "guest"
+	HistoryActorSystem = "system"
+)
+
+// ReservationHistory records one change to a reservation and is never updated. Before and After are the reservation
+// as JSON, Before is null for the creation. Actor is the email of the staff member who made the change, or guest or system
+type ReservationHistory struct {
+	ID int `json:"id"`
+	ReservationID int `json:"reservationId"`
+	Action string `json:"action"`
+	Actor string `json:"actor"`
+	Before json.RawMessage `json:"before"`
+	After json.RawMessage `json:"after"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ReservationNote is a note staff keep on a reservation. Notes are internal and never shown to the guest, Author is
+// the email of the staff member who wrote it
+type ReservationNote struct {
+	ID int `json:"id"`
+	ReservationID int `json:"reservationId"`
+	Author string `json:"author"`
+	Body string `json:"body"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Guest is the profile of a person who booked, built from the contact details of their reservations. EmailKey and
+// PhoneKey are the normalised email and E.164 phone number bookings are matched to the profile on
+type Guest struct {
+	ID int `json:"id"`
+	FirstName string `json:"firstName"`
+	LastName string `json:"lastName"`
+	Email string `json:"email"`
+	Phone string `json:"phone"`
+	EmailKey string `json:"emailKey"`
+	PhoneKey string `json:"phoneKey"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// GuestStats sums up the reservations of a guest. Spend is per currency charged
+type GuestStats struct {
+	Reservations int `json:"reservations"`
+	Stays int `json:"stays"`
+	Nights int `json:"nights"`
+	Cancellations int `json:"cancellations"`
+	Spend map[string]money.Amount `json:"spend"`
+	LastStay *time.Time `json:"lastStay,omitempty"`
+}
+
+// ReservationFilter narrows the reservations listed and exported. Zero fields are not filtered on,
+// Start and End keep the stays overlapping the dates, ArrivingFrom the stays starting on or after it.
+// UnresolvedRequests keeps the pending and confirmed stays with special requests staff have not seen to
+type ReservationFilter struct {
+	RoomID int
+	GuestID int
+	Status string
+	Start time.Time
+	End time.Time
+	ArrivingFrom time.Time
+	UnresolvedRequests bool
+}
+
+// ReservationGroup ties together the reservations of a multi-room booking, the contact is the organiser
+type ReservationGroup struct {
+	ID int
+	ConfirmationCode string
+	GroupName string
+	FirstName string
+	LastName string
+	Email string
+	Phone string
+	Status string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Reservations []Reservation
+}
+
+// RoomRate is a seasonal price override for a room, EndDate is exclusive.
+// Amounts are in minor units and a WeekendRate of 0 falls back to NightlyRate
+type RoomRate struct {
+	ID int `json:"id"`
+	RoomID int `json:"roomId"`
+	RateName string `json:"rateName"`
+	StartDate time.Time `json:"startDate"`
+	EndDate time.Time `json:"endDate"`
+	NightlyRate money.Amount `json:"nightlyRate"`
+	WeekendRate money.Amount `json:"weekendRate"`
+	CancellationPolicyID int `json:"cancellationPolicyId,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// CancellationWindow charges FeePercent of the stay total for cancellations made less than HoursBefore hours before arrival
+type CancellationWindow struct {
+	HoursBefore int `json:"hoursBefore"`
+	FeePercent int `json:"feePercent"`
+}
+
+// CancellationPolicy sets what cancelling or not showing up costs. It is attached to a room or a seasonal rate and
+// copied onto each reservation when it is booked, so later changes to the policy do not apply to existing bookings
+type CancellationPolicy struct {
+	ID int `json:"id"`
+	PolicyName string `json:"policyName"`
+	Windows []CancellationWindow `json:"windows"`
+	NoShowPercent int `json:"noShowPercent"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Promo code discount types
+const (
+	DiscountPercent = "percent"
+	DiscountFixed = "fixed"
+)
+
+// PromoCode discounts the stays booked with it. DiscountValue is a percentage of the stay total for percent discounts
+// and an amount in minor units for fixed ones. The code can be redeemed from ValidFrom through ValidUntil, both inclusive.
+// Zero conditions are not checked: no dates is always valid, no RoomIDs every room and a cap of 0 unlimited.
+// Uses counts the redemptions so far
+type PromoCode struct {
+	ID int `json:"id"`
+	Code string `json:"code"`
+	DiscountType string `json:"discountType"`
+	DiscountValue int64 `json:"discountValue"`
+	ValidFrom time.Time `json:"validFrom"`
+	ValidUntil time.Time `json:"validUntil"`
+	MinNights int `json:"minNights"`
+	MaxUses int `json:"maxUses"`
+	MaxUsesPerEmail int `json:"maxUsesPerEmail"`
+	RoomIDs []int `json:"roomIds"`
+	Uses int `json:"uses"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Tax and fee kinds
+const (
+	ChargePerPersonNight = "per_person_night"
+	ChargePercent = "percent"
+	ChargeFlat = "flat"
+)
+
+// ChargeRule is a tax or fee added to the price of stays. Amount is in minor units per guest per night for
+// per_person_night charges and per stay for flat ones, and in hundredths of a percent for percent charges, so 750 is
+// 7.5%. A rule without a RoomID applies to every room
+type ChargeRule struct {
+	ID int `json:"id"`
+	RuleName string `json:"ruleName"`
+	Kind string `json:"kind"`
+	Amount int64 `json:"amount"`
+	RoomID int `json:"roomId"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// ReservationCharge is a tax or fee line of a stay: the rule's Rate times Quantity, the guest nights of
+// per_person_night charges and 1 otherwise. Lines are stored when booking so later rule changes do not alter them
+type ReservationCharge struct {
+	ID int `json:"id"`
+	ReservationID int `json:"reservationId"`
+	ChargeRuleID int `json:"chargeRuleId"`
+	ChargeName string `json:"chargeName"`
+	Kind string `json:"kind"`
+	Rate int64 `json:"rate"`
+	Quantity int `json:"quantity"`
+	Amount money.Amount `json:"amount"`
+}
+
+// ExchangeRate is what one unit of BaseCurrency is worth in QuoteCurrency. Rates are kept by staff, quotes and
+// bookings in QuoteCurrency for rooms priced in BaseCurrency use the rate of the time
+type ExchangeRate struct {
+	ID int `json:"id"`
+	BaseCurrency string `json:"baseCurrency"`
+	QuoteCurrency string `json:"quoteCurrency"`
+	Rate money.Rate `json:"rate"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Invoice is issued once for a reservation, from the lines it was booked with and what the guest paid. Its number
+// is Sequence in its Series, which has no gaps. Amounts are in minor units, a negative BalanceDue is owed to the guest
+type Invoice struct {
+	ID int `json:"id"`
+	InvoiceNumber string `json:"invoiceNumber"`
+	Series string `json:"series"`
+	Sequence int `json:"sequence"`
+	ReservationID int `json:"reservationId"`
+	ConfirmationCode string `json:"confirmationCode"`
+	GuestName string `json:"guestName"`
+	Email string `json:"email"`
+	RoomName string `json:"roomName"`
+	StartDate time.Time `json:"startDate"`
+	EndDate time.Time `json:"endDate"`
+	Currency string `json:"currency"`
+	Lines []InvoiceLine `json:"lines"`
+	Subtotal money.Amount `json:"subtotal"`
+	TaxesAndFees money.Amount `json:"taxesAndFees"`
+	Total money.Amount `json:"total"`
+	Paid money.Amount `json:"paid"`
+	Refunded money.Amount `json:"refunded"`
+	BalanceDue money.Amount `json:"balanceDue"`
+	IssuedAt time.Time `json:"issuedAt"`
+}
+
+// InvoiceLine is a line of an invoice. Discounts are negative
+type InvoiceLine struct {
+	Description string `json:"description"`
+	Quantity int `json:"quantity"`
+	Amount money.Amount `json:"amount"`
+	Tax bool `json:"tax"`
+}
+
+// PromoRedemption records a promo code used by a reservation
+type PromoRedemption struct {
+	ID int
+	PromoCodeID int
+	ReservationID int
+	Email string
+	EmailKey string
+	Discount money.Amount
+	CreatedAt time.Time
+}
+
 type RoomRestriction struct {
 	ID int
 	StartDate time.Time
@@ -50,9 +364,244 @@ type RoomRestriction struct {
 	RoomID int
 	ReservationID int
 	RestrictionID int
+	HoldToken string
+	ExpiresAt time.Time
+	FeedID int
+	ExternalUID string
+	Note string
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	Room Room
 	Reservation Reservation
 	Restriction Restriction
 }
+
+// IdempotencyRecord stores the outcome of a request made with an Idempotency-Key header.
+// A StatusCode of 0 means the original request is still being processed, until LockedUntil. A request that has not
+// finished by then is taken to have died, and a retry of it may take the key over. LeaseToken identifies the request
+// holding the key, only that request may complete or delete the record
+type IdempotencyRecord struct {
+	ID int
+	Key string
+	Fingerprint string
+	StatusCode int
+	ResponseBody []byte
+	ContentType string
+	LockedUntil time.Time
+	LeaseToken string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Payment statuses
+const (
+	PaymentStatusAuthorized = "authorized"
+	PaymentStatusDeclined = "declined"
+	PaymentStatusCaptured = "captured"
+	PaymentStatusFailed = "failed"
+	PaymentStatusPartiallyRefunded = "partially_refunded"
+	PaymentStatusRefunded = "refunded"
+)
+
+type Payment struct {
+	ID int `json:"id"`
+	ReservationID int `json:"reservationId"`
+	Provider string `json:"provider"`
+	Reference string `json:"reference"`
+	Amount money.Amount `json:"amount"`
+	CapturedAmount money.Amount `json:"capturedAmount"`
+	RefundedAmount money.Amount `json:"refundedAmount"`
+	Currency string `json:"currency"`
+	Status string `json:"status"`
+	FailureReason string `json:"failureReason,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Waitlist entry statuses
+const (
+	WaitlistStatusWaiting = "waiting"
+	WaitlistStatusOffered = "offered"
+	WaitlistStatusAccepted = "accepted"
+	WaitlistStatusExpired = "expired"
+)
+
+// WaitlistEntry is a guest waiting for dates that were not available. A RoomID of 0 accepts any room.
+// While an offer is open the room is held under OfferToken until OfferExpiresAt
+type WaitlistEntry struct {
+	ID int `json:"id"`
+	RoomID int `json:"roomId"`
+	StartDate time.Time `json:"startDate"`
+	EndDate time.Time `json:"endDate"`
+	FirstName string `json:"firstName"`
+	LastName string `json:"lastName"`
+	Email string `json:"email"`
+	Phone string `json:"phone"`
+	Status string `json:"status"`
+	OfferRoomID int `json:"offerRoomId,omitempty"`
+	OfferToken string `json:"-"`
+	OfferExpiresAt time.Time `json:"offerExpiresAt,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Calendar feed sync statuses
+const (
+	CalendarFeedStatusPending = "pending"
+	CalendarFeedStatusOK = "ok"
+	CalendarFeedStatusError = "error"
+)
+
+// CalendarFeed is an external calendar whose events block the room. Feeds without a URL are only updated by uploading a file
+type CalendarFeed struct {
+	ID int `json:"id"`
+	RoomID int `json:"roomId"`
+	Name string `json:"name"`
+	URL string `json:"url"`
+	LastStatus string `json:"lastStatus"`
+	LastError string `json:"lastError,omitempty"`
+	LastSyncedAt time.Time `json:"lastSyncedAt"`
+	EventCount int `json:"eventCount"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// RoomReport holds a room's counts over a date range. Night counts only cover nights inside the range,
+// and Revenue is the share of each booking's price earned on those nights, in the room's Currency. Reservations,
+// StayNights and LeadDays count the bookings arriving in the range
+type RoomReport struct {
+	RoomID int
+	RoomName string
+	Currency string
+	SoldNights int
+	BlockedNights int
+	Revenue money.Amount
+	Charges money.Amount
+	Reservations int
+	StayNights int
+	LeadDays int
+}
+
+// RoomCalendar is a room with the restrictions and recurring blocks that cover a range of dates
+type RoomCalendar struct {
+	Room Room
+	Restrictions []RoomRestriction
+	RecurringBlocks []RecurringBlock
+}
+
+// RecurringBlock takes the room off sale for Nights nights from every occurrence of an RRULE. Occurrences are
+// expanded when availability is checked rather than stored, Exceptions are the occurrences staff removed
+type RecurringBlock struct {
+	ID int
+	RoomID int
+	RestrictionID int
+	StartDate time.Time
+	Nights int
+	RRule string
+	Note string
+	Exceptions []time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Restriction Restriction
+}
+
+func (b RecurringBlock) IsException(day time.Time) bool {
+	for _, e := range b.Exceptions {
+		if e.Equal(day) {
+			return true
+		}
+	}
+	return false
+}
+
+// Occurrences returns the start days of the occurrences blocking any night between start and end
+func (b RecurringBlock) Occurrences(start, end time.Time) ([]time.Time, error) {
+	rule, err := ical.ParseRRule(b.RRule)
+	if err != nil {
+		return nil, err
+	}
+
+	nights := b.Nights
+	if nights < 1 {
+		nights = 1
+	}
+
+	// an occurrence that started up to nights-1 days before start still covers it
+	candidates := rule.Between(b.StartDate, start.AddDate(0, 0, 1 - nights), end)
+
+	occurrences := make([]time.Time, 0, len(candidates))
+	for _, o := range candidates {
+		if !b.IsException(o) {
+			occurrences = append(occurrences, o)
+		}
+	}
+
+	return occurrences, nil
+}
+
+// Weekdays is a set of days of the week, bit 0 is Sunday like time.Weekday
+type Weekdays int
+
+func (w Weekdays) Has(d time.Weekday) bool {
+	return w & (1 << uint(d)) != 0
+}
+
+func (w Weekdays) Days() []time.Weekday {
+	days := make([]time.Weekday, 0)
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if w.Has(d) {
+			days = append(days, d)
+		}
+	}
+	return days
+}
+
+// MarshalJSON writes the set as lower case day names, the form the API accepts
+func (w Weekdays) MarshalJSON() ([]byte, error) {
+	names := make([]string, 0)
+	for _, d := range w.Days() {
+		names = append(names, strings.ToLower(d.String()))
+	}
+	return json.Marshal(names)
+}
+
+// ParseWeekdays reads day names such as "sunday" or "sun", in any case
+func ParseWeekdays(names []string) (Weekdays, error) {
+	var w Weekdays
+
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		found := false
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			full := strings.ToLower(d.String())
+			if name == full || name == full[:3] {
+				w |= 1 << uint(d)
+				found = true
+			}
+		}
+		if !found {
+			return w, fmt.Errorf("invalid day of the week %q", name)
+		}
+	}
+
+	return w, nil
+}
+
+// StayRule limits the stays that can be booked in a room. Every rule but ClosedToDeparture applies to stays arriving
+// between StartDate and EndDate, ClosedToDeparture to stays leaving in that window. A zero limit is not enforced
+type StayRule struct {
+	ID int `json:"id"`
+	RoomID int `json:"roomId"`
+	RuleName string `json:"ruleName"`
+	StartDate time.Time `json:"startDate"`
+	EndDate time.Time `json:"endDate"`
+	MinNights int `json:"minNights"`
+	MaxNights int `json:"maxNights"`
+	ClosedToArrival Weekdays `json:"closedToArrival"`
+	ClosedToDeparture Weekdays `json:"closedToDeparture"`
+	MinLeadDays int `json:"minLeadDays"`
+	MaxLeadDays int `json:"maxLeadDays"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
@@ -0,0 +1,192 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Amount is a sum of money in the minor units of its currency, cents for USD and kobo for NGN. Amounts are whole
+// numbers so that prices add up exactly. In JSON an amount is a number of minor units
+type Amount int64
+
+// exponents lists the currencies whose minor unit is not a hundredth of the major unit
+var exponents = map[string]int{
+	"BHD": 3,
+	"CLP": 0,
+	"ISK": 0,
+	"JOD": 3,
+	"JPY": 0,
+	"KRW": 0,
+	"KWD": 3,
+	"OMR": 3,
+	"TND": 3,
+	"UGX": 0,
+	"VND": 0,
+	"XAF": 0,
+	"XOF": 0,
+}
+
+// Exponent returns the number of digits of the minor unit of an ISO 4217 currency, 2 for most of them
+func Exponent(currency string) int {
+	if e, ok := exponents[currency]; ok {
+		return e
+	}
+	return 2
+}
+
+// Exponents returns the currencies Exponent does not return 2 for, so queries can convert amounts the same way
+func Exponents() map[string]int {
+	out := make(map[string]int, len(exponents))
+	for c, e := range exponents {
+		out[c] = e
+	}
+	return out
+}
+
+// Format writes an amount with the decimals of its currency and thousands separators, 40,138.00
+func Format(a Amount, currency string) string {
+	sign := ""
+	n := int64(a)
+	if n < 0 {
+		sign = "-"
+		n = -n
+	}
+
+	exp := Exponent(currency)
+	div := int64(1)
+	for i := 0; i < exp; i++ {
+		div *= 10
+	}
+
+	units := strconv.FormatInt(n / div, 10)
+	for i := len(units) - 3; i > 0; i -= 3 {
+		units = units[:i] + "," + units[i:]
+	}
+
+	if exp == 0 {
+		return sign + units
+	}
+	return fmt.Sprintf("%s%s.%0*d", sign, units, exp, n % div)
+}
+
+// Share returns num / den of the amount rounded half away from zero to the minor unit, so a.Share(750, 10000)
+// is 7.5% of a
+func (a Amount) Share(num, den int64) Amount {
+	if den == 0 {
+		return 0
+	}
+
+	n := int64(a) * num
+	neg := (n < 0) != (den < 0)
+	if n < 0 {
+		n = -n
+	}
+	if den < 0 {
+		den = -den
+	}
+
+	q := (n + den / 2) / den
+	if neg {
+		q = -q
+	}
+	return Amount(q)
+}
+
+// RateScale is the Rate of one unit for one unit
+const RateScale = 1000000
+
+// Rate is an exchange rate, the units of the quote currency one unit of the base currency buys, in millionths.
+// It is written in JSON as a decimal string, "1523.5", so it never goes through a float
+type Rate int64
+
+var ErrInvalidRate = errors.New("rate must be a positive decimal with at most 6 decimal places")
+
+// ParseRate reads a decimal rate such as 1523.5
+func ParseRate(s string) (Rate, error) {
+	whole, frac, _ := strings.Cut(s, ".")
+	if whole == "" || len(frac) > 6 || strings.Trim(whole + frac, "0123456789") != "" {
+		return 0, ErrInvalidRate
+	}
+
+	frac += strings.Repeat("0", 6 - len(frac))
+	n, err := strconv.ParseInt(whole + frac, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, ErrInvalidRate
+	}
+
+	return Rate(n), nil
+}
+
+func (r Rate) String() string {
+	s := fmt.Sprintf("%d.%06d", int64(r) / RateScale, int64(r) % RateScale)
+	return strings.TrimSuffix(strings.TrimRight(s, "0"), ".")
+}
+
+func (r Rate) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(r.String())), nil
+}
+
+// MarshalText writes the rate as a decimal, so a CSV export shows 0.92 and not 920000
+func (r Rate) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+func (r *Rate) UnmarshalText(b []byte) error {
+	rate, err := ParseRate(string(b))
+	if err != nil {
+		return err
+	}
+
+	*r = rate
+	return nil
+}
+
+// UnmarshalJSON takes the rate as a string or as a number, whose digits are read as they were written
+func (r *Rate) UnmarshalJSON(b []byte) error {
+	s := string(b)
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+
+	return r.UnmarshalText([]byte(s))
+}
+
+// Convert changes an amount in from into to at the rate, rounding half away from zero to the minor unit of to
+func Convert(a Amount, from, to string, rate Rate) Amount {
+	shift := Exponent(to) - Exponent(from)
+	if rate == RateScale && shift == 0 {
+		return a
+	}
+
+	num := new(big.Int).Mul(big.NewInt(int64(a)), big.NewInt(int64(rate)))
+	den := big.NewInt(RateScale)
+
+	pow := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(shift))), nil)
+	if shift > 0 {
+		num.Mul(num, pow)
+	}else{
+		den.Mul(den, pow)
+	}
+
+	neg := num.Sign() < 0
+	num.Abs(num)
+
+	// add half the divisor before dividing to round half up, on the absolute value
+	half := new(big.Int).Quo(den, big.NewInt(2))
+	q := new(big.Int).Quo(num.Add(num, half), den)
+	if neg {
+		q.Neg(q)
+	}
+
+	return Amount(q.Int64())
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
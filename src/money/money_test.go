@@ -0,0 +1,102 @@
+package money
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestFormat(t *testing.T){
+	var formatTests = []struct {
+		amount Amount
+		currency string
+		expected string
+	}{
+		{0, "USD", "0.00"},
+		{5, "USD", "0.05"},
+		{4013800, "NGN", "40,138.00"},
+		{-3600, "EUR", "-36.00"},
+		{15000, "JPY", "15,000"},
+		{1234567, "KWD", "1,234.567"},
+	}
+
+	for _, e := range formatTests {
+		if got := Format(e.amount, e.currency); got != e.expected {
+			t.Errorf("Format(%d, %s) returned %q, wanted %q", e.amount, e.currency, got, e.expected)
+		}
+	}
+}
+
+func TestParseRate(t *testing.T){
+	var rateTests = []struct {
+		in string
+		expected Rate
+		valid bool
+	}{
+		{"1", 1000000, true},
+		{"0.92", 920000, true},
+		{"1523.5", 1523500000, true},
+		{"0.000001", 1, true},
+		{"0", 0, false},
+		{"-1.5", 0, false},
+		{"1.2345678", 0, false},
+		{"1e3", 0, false},
+		{".5", 0, false},
+		{"", 0, false},
+	}
+
+	for _, e := range rateTests {
+		got, err := ParseRate(e.in)
+		if (err == nil) != e.valid || got != e.expected {
+			t.Errorf("ParseRate(%q) returned %d, %v", e.in, got, err)
+		}
+	}
+}
+
+func TestRateJSON(t *testing.T){
+	var body struct {
+		Rate Rate `json:"rate"`
+	}
+
+	for _, in := range []string{`{"rate":"0.92"}`, `{"rate":0.92}`} {
+		err := json.Unmarshal([]byte(in), &body)
+		if err != nil || body.Rate != 920000 {
+			t.Errorf("Unmarshal(%s) returned %d, %v", in, body.Rate, err)
+		}
+	}
+
+	out, _ := json.Marshal(body)
+	if string(out) != `{"rate":"0.92"}` {
+		t.Errorf("Marshal returned %s", out)
+	}
+
+	if err := json.Unmarshal([]byte(`{"rate":"abc"}`), &body); err == nil {
+		t.Errorf("Unmarshal took an invalid rate")
+	}
+}
+
+func TestConvert(t *testing.T){
+	var convertTests = []struct {
+		name string
+		amount Amount
+		from string
+		to string
+		rate string
+		expected Amount
+	}{
+		{"same currency", 34000, "USD", "USD", "1", 34000},
+		{"dollars to euros", 34000, "USD", "EUR", "0.92", 31280},
+		{"rounds half up", 5, "USD", "EUR", "0.5", 3},
+		{"rounds half away from zero", -5, "USD", "EUR", "0.5", -3},
+		{"dollars to yen", 34000, "USD", "JPY", "151.25", 51425},
+		{"yen to dollars", 51425, "JPY", "USD", "0.006612", 34002},
+		{"dollars to dinars", 100, "USD", "KWD", "0.3075", 308},
+		{"dollars to naira", 40138, "USD", "NGN", "1523.5", 61150243},
+	}
+
+	for _, e := range convertTests {
+		rate, _ := ParseRate(e.rate)
+		if got := Convert(e.amount, e.from, e.to, rate); got != e.expected {
+			t.Errorf("Convert returned %d for %s, wanted %d", got, e.name, e.expected)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package notifications
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+// Message is a notification addressed to a guest. To is an email address
+type Message struct {
+	To string
+	Subject string
+	Body string
+}
+
+// Notifier is implemented by every channel the app can reach guests through
+type Notifier interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// LogNotifier writes messages to a logger instead of delivering them, for local development
+type LogNotifier struct {
+	log *log.Logger
+}
+
+func NewLogNotifier(logger *log.Logger) *LogNotifier {
+	return &LogNotifier{
+		log: logger,
+	}
+}
+
+func (n *LogNotifier) Send(ctx context.Context, msg Message) error {
+	n.log.Printf("notification to %s: %s\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// MemoryNotifier keeps every message it is asked to send so tests can inspect them
+type MemoryNotifier struct {
+	mu sync.Mutex
+	messages []Message
+}
+
+func NewMemoryNotifier() *MemoryNotifier {
+	return &MemoryNotifier{}
+}
+
+func (n *MemoryNotifier) Send(ctx context.Context, msg Message) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.messages = append(n.messages, msg)
+	return nil
+}
+
+// Messages returns a copy of the messages sent so far
+func (n *MemoryNotifier) Messages() []Message {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return append([]Message(nil), n.messages...)
+}
@@ -0,0 +1,138 @@
+package occupancy
+
+import (
+	"sort"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+	"github.com/Orololuwa/go-backend-boilerplate/src/money"
+)
+
+// MaxRooms is the most rooms a suggested combination is made of
+const MaxRooms = 4
+
+type suggestion struct {
+	rooms []models.Room
+	spare int
+	price money.Amount
+	currency string
+}
+
+// Combinations suggests up to limit sets of rooms that together sleep the guests, for parties too large for any
+// single room. Sets with fewer rooms come first, then the ones leaving fewer beds empty, then the cheaper ones.
+// Rooms of the same size are interchangeable, so every set is made of the cheapest rooms of each size. Prices in
+// different currencies cannot be compared, so a set is made of rooms priced in one currency
+func Combinations(rooms []models.Room, guests, limit int) [][]models.Room {
+	combinations := make([][]models.Room, 0)
+	if guests <= 0 || limit <= 0 {
+		return combinations
+	}
+
+	byCurrency := make(map[string][]models.Room)
+	currencies := make([]string, 0)
+	for _, room := range rooms {
+		// rooms without a limit sleep any party on their own
+		if room.MaxOccupancy <= 0 {
+			continue
+		}
+		if _, ok := byCurrency[room.Currency]; !ok {
+			currencies = append(currencies, room.Currency)
+		}
+		byCurrency[room.Currency] = append(byCurrency[room.Currency], room)
+	}
+	sort.Strings(currencies)
+
+	for count := 2; count <= MaxRooms; count++ {
+		found := make([]suggestion, 0)
+		for _, currency := range currencies {
+			found = append(found, suggest(byCurrency[currency], guests, count)...)
+		}
+
+		if len(found) == 0 {
+			continue
+		}
+
+		sort.SliceStable(found, func(i, j int) bool {
+			if found[i].spare != found[j].spare {
+				return found[i].spare < found[j].spare
+			}
+			if found[i].currency != found[j].currency {
+				return found[i].currency < found[j].currency
+			}
+			return found[i].price < found[j].price
+		})
+
+		for i := 0; i < len(found) && i < limit; i++ {
+			combinations = append(combinations, found[i].rooms)
+		}
+		break
+	}
+
+	return combinations
+}
+
+// suggest finds the sets of count rooms that sleep the guests, the rooms all priced in the same currency
+func suggest(rooms []models.Room, guests, count int) []suggestion {
+	bySize := make(map[int][]models.Room)
+	for _, room := range rooms {
+		bySize[room.MaxOccupancy] = append(bySize[room.MaxOccupancy], room)
+	}
+
+	sizes := make([]int, 0, len(bySize))
+	for size, same := range bySize {
+		sort.SliceStable(same, func(i, j int) bool {
+			if same[i].BaseRate != same[j].BaseRate {
+				return same[i].BaseRate < same[j].BaseRate
+			}
+			return same[i].ID < same[j].ID
+		})
+		sizes = append(sizes, size)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(sizes)))
+
+	found := make([]suggestion, 0)
+	picked := make([]int, len(sizes))
+
+	// pick how many rooms of each size to use, largest sizes first
+	var pick func(i, left, sleeps int)
+	pick = func(i, left, sleeps int) {
+		if left == 0 {
+			if sleeps >= guests {
+				found = append(found, build(bySize, sizes, picked, guests))
+			}
+			return
+		}
+		if i == len(sizes) || sleeps + left*sizes[i] < guests {
+			return
+		}
+
+		most := len(bySize[sizes[i]])
+		if most > left {
+			most = left
+		}
+		for n := most; n >= 0; n-- {
+			picked[i] = n
+			pick(i+1, left-n, sleeps+n*sizes[i])
+		}
+		picked[i] = 0
+	}
+	pick(0, count, 0)
+
+	return found
+}
+
+func build(bySize map[int][]models.Room, sizes, picked []int, guests int) suggestion {
+	var s suggestion
+	sleeps := 0
+
+	for i, n := range picked {
+		for _, room := range bySize[sizes[i]][:n] {
+			s.rooms = append(s.rooms, room)
+			s.price += room.BaseRate
+			s.currency = room.Currency
+			sleeps += room.MaxOccupancy
+		}
+	}
+	s.spare = sleeps - guests
+
+	return s
+}
@@ -0,0 +1,61 @@
+package occupancy
+
+import (
+	"testing"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+)
+
+func ids(rooms []models.Room) []int {
+	i := make([]int, len(rooms))
+	for n, room := range rooms {
+		i[n] = room.ID
+	}
+	return i
+}
+
+func TestCombinations(t *testing.T){
+	rooms := []models.Room{
+		{ID: 1, MaxOccupancy: 2, BaseRate: 10000, Currency: "USD"},
+		{ID: 2, MaxOccupancy: 2, BaseRate: 8000, Currency: "USD"},
+		{ID: 3, MaxOccupancy: 4, BaseRate: 15000, Currency: "USD"},
+		{ID: 4, MaxOccupancy: 3, BaseRate: 12000, Currency: "USD"},
+		// cheaper in number only, and never in a set with the rooms priced in dollars
+		{ID: 5, MaxOccupancy: 2, BaseRate: 500, Currency: "JPY"},
+	}
+
+	var combinationTests = []struct {
+		name string
+		guests int
+		limit int
+		expected [][]int
+	}{
+		{"fewest empty beds first", 6, 2, [][]int{{3, 2}, {3, 4}}},
+		{"cheapest of the same size", 4, 1, [][]int{{2, 1}}},
+		{"three rooms", 9, 3, [][]int{{3, 4, 2}}},
+		{"one currency per set", 5, 3, [][]int{{4, 2}, {3, 2}, {3, 4}}},
+		{"too many guests", 12, 3, [][]int{}},
+		{"no guests", 0, 3, [][]int{}},
+	}
+
+	for _, e := range combinationTests {
+		got := Combinations(rooms, e.guests, e.limit)
+		if len(got) != len(e.expected) {
+			t.Errorf("%s: got %d combinations, wanted %v", e.name, len(got), e.expected)
+			continue
+		}
+		for i, c := range got {
+			gotIds := ids(c)
+			if len(gotIds) != len(e.expected[i]) {
+				t.Errorf("%s: got rooms %v, wanted %v", e.name, gotIds, e.expected[i])
+				continue
+			}
+			for n := range gotIds {
+				if gotIds[n] != e.expected[i][n] {
+					t.Errorf("%s: got rooms %v, wanted %v", e.name, gotIds, e.expected[i])
+					break
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package payments
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/helpers"
+	"github.com/Orololuwa/go-backend-boilerplate/src/money"
+)
+
+// Payment method tokens understood by the fake gateway. Any other token is authorized
+const (
+	FakeTokenSuccess = "tok_success"
+	FakeTokenDecline = "tok_decline"
+	FakeTokenCaptureFail = "tok_capture_fail"
+	FakeTokenRefundFail = "tok_refund_fail"
+)
+
+// FakeGateway is a deterministic in-process gateway for tests and local development.
+// The outcome of every call is decided by the payment method token, which is kept in the reference
+type FakeGateway struct {
+	secret []byte
+}
+
+func NewFakeGateway(webhookSecret string) *FakeGateway {
+	return &FakeGateway{
+		secret: []byte(webhookSecret),
+	}
+}
+
+func (g *FakeGateway) Name() string {
+	return "fake"
+}
+
+// newReference is random so references stay unique across restarts of the process
+func (g *FakeGateway) newReference(token string) (string, error) {
+	suffix, err := helpers.RandomToken(8)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("fake_%s_%s", strings.TrimPrefix(token, "tok_"), suffix), nil
+}
+
+func (g *FakeGateway) Authorize(ctx context.Context, req AuthorizeRequest) (Result, error) {
+	if req.Amount <= 0 {
+		return Result{}, fmt.Errorf("invalid amount %d", req.Amount)
+	}
+
+	reference, err := g.newReference(req.PaymentMethod)
+	if err != nil {
+		return Result{}, err
+	}
+
+	result := Result{Reference: reference, Approved: true}
+
+	if req.PaymentMethod == FakeTokenDecline {
+		result.Approved = false
+		result.DeclineReason = "card declined"
+	}
+
+	return result, nil
+}
+
+func (g *FakeGateway) Capture(ctx context.Context, reference string, amount money.Amount) (Result, error) {
+	result := Result{Reference: reference, Approved: true}
+
+	if strings.HasPrefix(reference, "fake_capture_fail_") {
+		result.Approved = false
+		result.DeclineReason = "insufficient funds"
+	}
+
+	return result, nil
+}
+
+func (g *FakeGateway) Refund(ctx context.Context, reference string, amount money.Amount) (Result, error) {
+	result := Result{Reference: reference, Approved: true}
+
+	if strings.HasPrefix(reference, "fake_refund_fail_") {
+		result.Approved = false
+		result.DeclineReason = "refund rejected"
+	}
+
+	return result, nil
+}
+
+// Sign returns the signature the fake gateway expects for a webhook payload
+func (g *FakeGateway) Sign(payload []byte) string {
+	mac := hmac.New(sha256.New, g.secret)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (g *FakeGateway) VerifyWebhook(payload []byte, signature string) (WebhookEvent, error) {
+	var event WebhookEvent
+
+	expected, err := hex.DecodeString(g.Sign(payload))
+	if err != nil {
+		return event, err
+	}
+
+	given, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(expected, given) {
+		return event, ErrInvalidSignature
+	}
+
+	err = json.Unmarshal(payload, &event)
+	if err != nil {
+		return event, err
+	}
+
+	return event, nil
+}
\ No newline at end of file
@@ -0,0 +1,116 @@
+package payments
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestFakeGateway_Authorize(t *testing.T){
+	var authorizeTests = []struct {
+		name string
+		token string
+		approved bool
+		prefix string
+	}{
+		{"success", FakeTokenSuccess, true, "fake_success_"},
+		{"declined", FakeTokenDecline, false, "fake_decline_"},
+		{"capture will fail", FakeTokenCaptureFail, true, "fake_capture_fail_"},
+	}
+
+	g := NewFakeGateway("secret")
+
+	for _, e := range authorizeTests {
+		result, err := g.Authorize(context.Background(), AuthorizeRequest{Amount: 34000, Currency: "USD", PaymentMethod: e.token})
+		if err != nil {
+			t.Errorf("Authorize returned an error for %s: %v", e.name, err)
+			continue
+		}
+		if result.Approved != e.approved || !strings.HasPrefix(result.Reference, e.prefix) {
+			t.Errorf("Authorize returned %s approved %t for %s, wanted a %s reference approved %t", result.Reference, result.Approved, e.name, e.prefix, e.approved)
+		}
+	}
+
+	_, err := g.Authorize(context.Background(), AuthorizeRequest{Amount: 0, PaymentMethod: FakeTokenSuccess})
+	if err == nil {
+		t.Error("Authorize accepted an amount of 0")
+	}
+}
+
+func TestFakeGateway_ReferencesAreUniqueAcrossGateways(t *testing.T){
+	// a restarted process is a new gateway, its references must not repeat the ones already saved
+	seen := make(map[string]bool)
+
+	for i := 0; i < 50; i++ {
+		result, err := NewFakeGateway("secret").Authorize(context.Background(), AuthorizeRequest{Amount: 100, PaymentMethod: FakeTokenSuccess})
+		if err != nil {
+			t.Fatalf("Authorize returned an error: %v", err)
+		}
+		if seen[result.Reference] {
+			t.Fatalf("Authorize returned the reference %s twice", result.Reference)
+		}
+		seen[result.Reference] = true
+	}
+}
+
+func TestFakeGateway_CaptureAndRefund(t *testing.T){
+	g := NewFakeGateway("secret")
+	ctx := context.Background()
+
+	capture, _ := g.Authorize(ctx, AuthorizeRequest{Amount: 100, PaymentMethod: FakeTokenCaptureFail})
+	if result, _ := g.Capture(ctx, capture.Reference, 100); result.Approved {
+		t.Errorf("Capture approved %s, wanted it declined", capture.Reference)
+	}
+
+	refund, _ := g.Authorize(ctx, AuthorizeRequest{Amount: 100, PaymentMethod: FakeTokenRefundFail})
+	if result, _ := g.Capture(ctx, refund.Reference, 100); !result.Approved {
+		t.Errorf("Capture declined %s, wanted it approved", refund.Reference)
+	}
+	if result, _ := g.Refund(ctx, refund.Reference, 100); result.Approved {
+		t.Errorf("Refund approved %s, wanted it declined", refund.Reference)
+	}
+}
+
+func TestFakeGateway_VerifyWebhook(t *testing.T){
+	g := NewFakeGateway("secret")
+	payload, _ := json.Marshal(WebhookEvent{Type: EventPaymentCaptured, Reference: "fake_success_1", Amount: 34000})
+
+	event, err := g.VerifyWebhook(payload, g.Sign(payload))
+	if err != nil || event.Type != EventPaymentCaptured || event.Amount != 34000 {
+		t.Errorf("VerifyWebhook returned %+v and %v for a signed payload", event, err)
+	}
+
+	_, err = g.VerifyWebhook(payload, NewFakeGateway("other").Sign(payload))
+	if err != ErrInvalidSignature {
+		t.Errorf("VerifyWebhook returned %v for a payload signed with another secret, wanted %v", err, ErrInvalidSignature)
+	}
+
+	_, err = g.VerifyWebhook(payload, "not-hex")
+	if err != ErrInvalidSignature {
+		t.Errorf("VerifyWebhook returned %v for a signature that is not hex, wanted %v", err, ErrInvalidSignature)
+	}
+}
+
+func TestNewGateway(t *testing.T){
+	var gatewayTests = []struct {
+		name string
+		gateway string
+		goEnv string
+		expected error
+	}{
+		{"fake in development", GatewayFake, "development", nil},
+		{"fake by default in tests", "", "test", nil},
+		{"fake in production", GatewayFake, "production", ErrFakeGateway},
+		{"fake by default in production", "", "production", ErrFakeGateway},
+		{"unknown gateway", "acme", "development", ErrUnknownGateway},
+	}
+
+	for _, e := range gatewayTests {
+		_, err := NewGateway(e.gateway, "secret", e.goEnv)
+		if !errors.Is(err, e.expected) {
+			t.Errorf("NewGateway returned %v for %s, wanted %v", err, e.name, e.expected)
+		}
+	}
+}
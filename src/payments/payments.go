@@ -0,0 +1,71 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/money"
+)
+
+// AuthorizeRequest asks the gateway to reserve funds on the guest's payment method, in the minor units of Currency
+type AuthorizeRequest struct {
+	Amount money.Amount
+	Currency string
+	PaymentMethod string
+	Description string
+}
+
+// Result is the gateway's answer to an authorize, capture or refund call.
+// A declined operation is not an error, Approved is false and DeclineReason explains why
+type Result struct {
+	Reference string
+	Approved bool
+	DeclineReason string
+}
+
+const (
+	EventPaymentCaptured = "payment.captured"
+	EventPaymentFailed = "payment.failed"
+	EventPaymentRefunded = "payment.refunded"
+)
+
+// WebhookEvent is a verified notification sent by the gateway about a payment
+type WebhookEvent struct {
+	Type string `json:"type"`
+	Reference string `json:"reference"`
+	Amount money.Amount `json:"amount"`
+}
+
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+var (
+	ErrUnknownGateway = errors.New("unknown payment gateway")
+	ErrFakeGateway = errors.New("the fake payment gateway approves any payment and only runs in development and tests")
+)
+
+// GatewayFake names the fake gateway, the default in development and tests
+const GatewayFake = "fake"
+
+// PaymentGateway is implemented by every payment provider the app can talk to
+type PaymentGateway interface {
+	Name() string
+	Authorize(ctx context.Context, req AuthorizeRequest) (Result, error)
+	Capture(ctx context.Context, reference string, amount money.Amount) (Result, error)
+	Refund(ctx context.Context, reference string, amount money.Amount) (Result, error)
+	VerifyWebhook(payload []byte, signature string) (WebhookEvent, error)
+}
+
+// NewGateway returns the gateway the app is configured with. The fake gateway is refused outside development and
+// tests, so a misconfigured server does not take bookings it never charges
+func NewGateway(name, webhookSecret, goEnv string) (PaymentGateway, error) {
+	switch name {
+	case GatewayFake, "":
+		if goEnv != "development" && goEnv != "test" {
+			return nil, ErrFakeGateway
+		}
+		return NewFakeGateway(webhookSecret), nil
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnknownGateway, name)
+	}
+}
\ No newline at end of file
@@ -0,0 +1,176 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// A4 page size in points, 1/72 inch. Coordinates start at the bottom left of the page
+const (
+	PageWidth = 595.0
+	PageHeight = 842.0
+)
+
+// Fonts are the standard Type 1 fonts every PDF reader has, so nothing is embedded
+type Font string
+
+const (
+	Helvetica Font = "F1"
+	HelveticaBold Font = "F2"
+	// Courier is the only font whose glyphs all share one width, 600/1000 of the size
+	Courier Font = "F3"
+)
+
+var baseFonts = []struct {
+	name Font
+	base string
+}{
+	{Helvetica, "Helvetica"},
+	{HelveticaBold, "Helvetica-Bold"},
+	{Courier, "Courier"},
+}
+
+// Document is a PDF of text and lines, written page by page. It has a page once AddPage is called
+type Document struct {
+	pages []*bytes.Buffer
+}
+
+func New() *Document {
+	return &Document{}
+}
+
+// AddPage starts a new page, the drawing calls that follow go on it
+func (d *Document) AddPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+}
+
+func (d *Document) page() *bytes.Buffer {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+	return d.pages[len(d.pages) - 1]
+}
+
+func number(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// Text writes s with its baseline starting at x, y
+func (d *Document) Text(x, y float64, font Font, size float64, s string) {
+	fmt.Fprintf(d.page(), "BT /%s %s Tf %s %s Td (%s) Tj ET\n", font, number(size), number(x), number(y), escape(s))
+}
+
+// TextRight writes s in Courier so that it ends at x, for columns of amounts
+func (d *Document) TextRight(x, y float64, size float64, s string) {
+	width := float64(len(encode(s))) * size * 0.6
+	d.Text(x - width, y, Courier, size, s)
+}
+
+// Line draws a thin line from x1, y1 to x2, y2
+func (d *Document) Line(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(d.page(), "0.5 w %s %s m %s %s l S\n", number(x1), number(y1), number(x2), number(y2))
+}
+
+// encode maps s to WinAnsiEncoding, the encoding of the standard fonts. Characters it does not have become '?'
+func encode(s string) []byte {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r < 0x80:
+			out = append(out, byte(r))
+		case r >= 0xA0 && r <= 0xFF:
+			out = append(out, byte(r))
+		default:
+			if b, ok := winAnsi[r]; ok {
+				out = append(out, b)
+			}else{
+				out = append(out, '?')
+			}
+		}
+	}
+	return out
+}
+
+// winAnsi holds the characters of WinAnsiEncoding outside Latin-1 that invoices are likely to have
+var winAnsi = map[rune]byte{
+	'€': 0x80,
+	'‘': 0x91,
+	'’': 0x92,
+	'“': 0x93,
+	'”': 0x94,
+	'•': 0x95,
+	'–': 0x96,
+	'—': 0x97,
+	'™': 0x99,
+}
+
+// escape writes s as the content of a literal string. Bytes outside ASCII are written as octal escapes so
+// content streams stay 7-bit
+func escape(s string) string {
+	var b bytes.Buffer
+	for _, c := range encode(s) {
+		switch {
+		case c == '\\' || c == '(' || c == ')':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 0x20 || c >= 0x7F:
+			fmt.Fprintf(&b, "\\%03o", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// WriteTo writes the document. Object 1 is the catalog, 2 the page tree, the fonts follow and then each page
+// with its content stream
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	var out bytes.Buffer
+	var offsets []int
+
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	firstFont := 3
+	firstPage := firstFont + len(baseFonts)
+
+	out.WriteString("%PDF-1.4\n")
+
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+
+	var kids bytes.Buffer
+	for i := range d.pages {
+		fmt.Fprintf(&kids, "%d 0 R ", firstPage + 2 * i)
+	}
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", bytes.TrimSpace(kids.Bytes()), len(d.pages)))
+
+	var fonts bytes.Buffer
+	for i, f := range baseFonts {
+		object(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", f.base))
+		fmt.Fprintf(&fonts, "/%s %d 0 R ", f.name, firstFont + i)
+	}
+
+	for i, content := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << %s>> >> /Contents %d 0 R >>",
+			number(PageWidth), number(PageHeight), fonts.String(), firstPage + 2 * i + 1))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.Bytes()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets) + 1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets) + 1, xref)
+
+	n, err := w.Write(out.Bytes())
+	return int64(n), err
+}
@@ -0,0 +1,90 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestWriteTo(t *testing.T){
+	d := New()
+	d.Text(50, 800, HelveticaBold, 18, "Invoice (copy)")
+	d.Line(50, 790, 545, 790)
+	d.TextRight(545, 770, 10, "340.00")
+	d.AddPage()
+	d.Text(50, 800, Helvetica, 10, "Page two")
+
+	var out bytes.Buffer
+	_, err := d.WriteTo(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := out.String()
+
+	if !strings.HasPrefix(doc, "%PDF-1.4\n") || !strings.HasSuffix(doc, "%%EOF\n") {
+		t.Fatalf("WriteTo did not write a PDF header and trailer: %q", doc)
+	}
+
+	if !strings.Contains(doc, "/Count 2") {
+		t.Errorf("WriteTo did not write two pages")
+	}
+
+	if !strings.Contains(doc, `(Invoice \(copy\)) Tj`) {
+		t.Errorf("WriteTo did not escape the parentheses of the text")
+	}
+
+	// every xref entry points at the start of its object
+	startxref := regexp.MustCompile(`startxref\n(\d+)\n`).FindStringSubmatch(doc)
+	if startxref == nil {
+		t.Fatal("WriteTo wrote no startxref")
+	}
+	xref, _ := strconv.Atoi(startxref[1])
+	if !strings.HasPrefix(doc[xref:], "xref\n") {
+		t.Fatalf("startxref points at %q", doc[xref:xref + 10])
+	}
+
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllStringSubmatch(doc[xref:], -1)
+	if len(entries) != 9 {
+		t.Fatalf("WriteTo wrote %d objects, wanted 9", len(entries))
+	}
+	for i, e := range entries {
+		offset, _ := strconv.Atoi(e[1])
+		if want := fmt.Sprintf("%d 0 obj", i + 1); !strings.HasPrefix(doc[offset:], want) {
+			t.Errorf("xref entry %d points at %q, wanted %q", i + 1, doc[offset:offset + 10], want)
+		}
+	}
+}
+
+func TestTextRight(t *testing.T){
+	d := New()
+	d.TextRight(100, 10, 10, "12.50")
+
+	var out bytes.Buffer
+	d.WriteTo(&out)
+
+	// five characters of 6 points end at 100
+	if !strings.Contains(out.String(), "BT /F3 10 Tf 70 10 Td (12.50) Tj ET") {
+		t.Errorf("TextRight did not align the text to the right: %s", out.String())
+	}
+}
+
+func TestEscape(t *testing.T){
+	var escapeTests = []struct {
+		in string
+		expected string
+	}{
+		{`a\b`, `a\\b`},
+		{"Café", `Caf\351`},
+		{"€ 10 – 12", `\200 10 \226 12`},
+		{"₦5,000", `?5,000`},
+	}
+
+	for _, e := range escapeTests {
+		if got := escape(e.in); got != e.expected {
+			t.Errorf("escape(%q) returned %q, wanted %q", e.in, got, e.expected)
+		}
+	}
+}
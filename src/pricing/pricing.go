@@ -0,0 +1,88 @@
+package pricing
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+	"github.com/Orololuwa/go-backend-boilerplate/src/money"
+)
+
+// Night is the price of a single night, amounts are in minor units
+type Night struct {
+	Date time.Time `json:"date"`
+	Amount money.Amount `json:"amount"`
+	Weekend bool `json:"weekend"`
+	RateID int `json:"rateId,omitempty"`
+}
+
+type Quote struct {
+	RoomID int `json:"roomId"`
+	Nights []Night `json:"nights"`
+	Total money.Amount `json:"total"`
+}
+
+var ErrInvalidStay = errors.New("endDate must be after startDate")
+
+// IsWeekendNight reports whether the night starting on date is a Friday or Saturday night
+func IsWeekendNight(date time.Time) bool {
+	day := date.Weekday()
+	return day == time.Friday || day == time.Saturday
+}
+
+// rateFor picks the seasonal override covering the night. When seasons overlap the most recently created one wins
+func rateFor(rates []models.RoomRate, roomId int, date time.Time) (models.RoomRate, bool) {
+	var found models.RoomRate
+	ok := false
+
+	for _, rate := range rates {
+		if rate.RoomID != roomId {
+			continue
+		}
+
+		if date.Before(rate.StartDate) || !date.Before(rate.EndDate) {
+			continue
+		}
+
+		if !ok || rate.ID > found.ID {
+			found = rate
+			ok = true
+		}
+	}
+
+	return found, ok
+}
+
+func nightlyAmount(nightly, weekend money.Amount, isWeekend bool) money.Amount {
+	if isWeekend && weekend > 0 {
+		return weekend
+	}
+
+	return nightly
+}
+
+// QuoteStay prices every night from start up to but excluding end.
+// Rates that belong to other rooms are ignored, so the same slice can be shared across rooms
+func QuoteStay(room models.Room, rates []models.RoomRate, start, end time.Time) (Quote, error) {
+	quote := Quote{RoomID: room.ID, Nights: make([]Night, 0)}
+
+	if !end.After(start) {
+		return quote, ErrInvalidStay
+	}
+
+	for date := start; date.Before(end); date = date.AddDate(0, 0, 1) {
+		night := Night{Date: date, Weekend: IsWeekendNight(date)}
+
+		if rate, ok := rateFor(rates, room.ID, date); ok {
+			night.Amount = nightlyAmount(rate.NightlyRate, rate.WeekendRate, night.Weekend)
+			night.RateID = rate.ID
+		}else{
+			night.Amount = nightlyAmount(room.BaseRate, room.WeekendRate, night.Weekend)
+		}
+
+		quote.Nights = append(quote.Nights, night)
+		quote.Total += night.Amount
+	}
+
+	return quote, nil
+}
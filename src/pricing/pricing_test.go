@@ -0,0 +1,61 @@
+package pricing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+	"github.com/Orololuwa/go-backend-boilerplate/src/money"
+)
+
+func date(s string) time.Time {
+	d, _ := time.Parse("2006-01-02", s)
+	return d
+}
+
+func TestQuoteStay(t *testing.T){
+	room := models.Room{ID: 1, BaseRate: 10000, WeekendRate: 15000}
+
+	// 2050-01-06 is a Thursday, so the stay covers Thu, Fri and Sat nights
+	quote, err := QuoteStay(room, nil, date("2050-01-06"), date("2050-01-09"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(quote.Nights) != 3 {
+		t.Fatalf("QuoteStay expected 3 nights, got %d", len(quote.Nights))
+	}
+
+	if quote.Total != 40000 {
+		t.Errorf("QuoteStay expected a total of 40000 with weekend pricing, got %d", quote.Total)
+	}
+
+	// test that seasonal overrides apply only to the nights they cover and the newest one wins
+	rates := []models.RoomRate{
+		{ID: 1, RoomID: 1, StartDate: date("2050-01-07"), EndDate: date("2050-01-09"), NightlyRate: 20000},
+		{ID: 2, RoomID: 1, StartDate: date("2050-01-08"), EndDate: date("2050-01-09"), NightlyRate: 30000, WeekendRate: 35000},
+		{ID: 3, RoomID: 2, StartDate: date("2050-01-01"), EndDate: date("2050-02-01"), NightlyRate: 1},
+	}
+
+	quote, err = QuoteStay(room, rates, date("2050-01-06"), date("2050-01-09"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []money.Amount{10000, 20000, 35000}
+	for i, night := range quote.Nights {
+		if night.Amount != expected[i] {
+			t.Errorf("QuoteStay expected night %d to cost %d, got %d", i, expected[i], night.Amount)
+		}
+	}
+
+	if quote.Total != 65000 {
+		t.Errorf("QuoteStay expected a total of 65000 with seasonal rates, got %d", quote.Total)
+	}
+
+	// test that an empty or inverted stay is rejected
+	_, err = QuoteStay(room, nil, date("2050-01-06"), date("2050-01-06"))
+	if err != ErrInvalidStay {
+		t.Errorf("QuoteStay expected ErrInvalidStay for a zero night stay, got %v", err)
+	}
+}
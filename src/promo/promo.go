@@ -0,0 +1,117 @@
+package promo
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+	"github.com/Orololuwa/go-backend-boilerplate/src/money"
+)
+
+// Reasons a code is refused
+const (
+	CodeNotFound = "not_found"
+	CodeNotStarted = "not_started"
+	CodeExpired = "expired"
+	CodeMinNights = "min_nights"
+	CodeRoomNotEligible = "room_not_eligible"
+	CodeUsedUp = "used_up"
+	CodeUsedUpByEmail = "used_up_by_email"
+)
+
+// Error refuses a promo code. It is marshalled into error responses as is
+type Error struct {
+	Code string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return "promo code cannot be used: " + e.Message
+}
+
+// UsedUp reports whether the code was refused for reaching one of its usage caps
+func (e *Error) UsedUp() bool {
+	return e.Code == CodeUsedUp || e.Code == CodeUsedUpByEmail
+}
+
+// NotFound refuses a code that does not exist
+func NotFound(code string) *Error {
+	return &Error{Code: CodeNotFound, Message: fmt.Sprintf("%s is not a valid code", code)}
+}
+
+// Normalize trims and upper-cases a code so guests can type it in any case
+func Normalize(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Check returns why the code cannot discount a stay of nights nights in the room booked on today, nil when it can.
+// The usage caps are checked separately by CheckUses
+func Check(p models.PromoCode, roomId, nights int, today time.Time) error {
+	today = day(today)
+
+	if !p.ValidFrom.IsZero() && today.Before(day(p.ValidFrom)) {
+		return &Error{Code: CodeNotStarted, Message: fmt.Sprintf("%s can be used from %s", p.Code, p.ValidFrom.Format("2006-01-02"))}
+	}
+
+	if !p.ValidUntil.IsZero() && today.After(day(p.ValidUntil)) {
+		return &Error{Code: CodeExpired, Message: fmt.Sprintf("%s has expired", p.Code)}
+	}
+
+	if p.MinNights > 0 && nights < p.MinNights {
+		return &Error{Code: CodeMinNights, Message: fmt.Sprintf("%s is for stays of at least %d nights", p.Code, p.MinNights)}
+	}
+
+	if len(p.RoomIDs) > 0 {
+		eligible := false
+		for _, id := range p.RoomIDs {
+			if id == roomId {
+				eligible = true
+				break
+			}
+		}
+		if !eligible {
+			return &Error{Code: CodeRoomNotEligible, Message: fmt.Sprintf("%s cannot be used for room %d", p.Code, roomId)}
+		}
+	}
+
+	return nil
+}
+
+// CheckUses returns an error when the code or the guest, who used it emailUses times already, reached a usage cap
+func CheckUses(p models.PromoCode, emailUses int) error {
+	if p.MaxUses > 0 && p.Uses >= p.MaxUses {
+		return &Error{Code: CodeUsedUp, Message: fmt.Sprintf("%s has been used the most times it can be", p.Code)}
+	}
+
+	if p.MaxUsesPerEmail > 0 && emailUses >= p.MaxUsesPerEmail {
+		return &Error{Code: CodeUsedUpByEmail, Message: fmt.Sprintf("%s has been used the most times it can be by this guest", p.Code)}
+	}
+
+	return nil
+}
+
+// Discount is what the code takes off a stay total, never more than the total.
+// Percentages are rounded to the nearest minor unit
+func Discount(p models.PromoCode, total money.Amount) money.Amount {
+	var discount money.Amount
+
+	switch p.DiscountType {
+	case models.DiscountPercent:
+		discount = total.Share(p.DiscountValue, 100)
+	case models.DiscountFixed:
+		discount = money.Amount(p.DiscountValue)
+	}
+
+	if discount > total {
+		return total
+	}
+	if discount < 0 {
+		return 0
+	}
+	return discount
+}
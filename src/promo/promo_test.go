@@ -0,0 +1,127 @@
+package promo
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+	"github.com/Orololuwa/go-backend-boilerplate/src/money"
+)
+
+var summer = models.PromoCode{
+	Code: "SUMMER",
+	DiscountType: models.DiscountPercent,
+	DiscountValue: 10,
+	ValidFrom: time.Date(2050, time.June, 1, 0, 0, 0, 0, time.UTC),
+	ValidUntil: time.Date(2050, time.August, 31, 0, 0, 0, 0, time.UTC),
+	MinNights: 2,
+	MaxUses: 100,
+	MaxUsesPerEmail: 1,
+	RoomIDs: []int{15, 16},
+}
+
+func TestCheck(t *testing.T){
+	var checkTests = []struct {
+		name string
+		roomId int
+		nights int
+		today time.Time
+		expected string
+	}{
+		{"valid stay", 15, 3, time.Date(2050, time.July, 1, 9, 30, 0, 0, time.UTC), ""},
+		{"first day", 16, 2, time.Date(2050, time.June, 1, 0, 0, 0, 0, time.UTC), ""},
+		{"last day", 15, 2, time.Date(2050, time.August, 31, 0, 0, 0, 0, time.UTC), ""},
+		{"last day late in the evening", 15, 2, time.Date(2050, time.August, 31, 23, 0, 0, 0, time.UTC), ""},
+		{"before the window", 15, 3, time.Date(2050, time.May, 31, 0, 0, 0, 0, time.UTC), CodeNotStarted},
+		{"after the window", 15, 3, time.Date(2050, time.September, 1, 0, 0, 0, 0, time.UTC), CodeExpired},
+		{"short stay", 15, 1, time.Date(2050, time.July, 1, 0, 0, 0, 0, time.UTC), CodeMinNights},
+		{"other room", 20, 3, time.Date(2050, time.July, 1, 0, 0, 0, 0, time.UTC), CodeRoomNotEligible},
+	}
+
+	for _, e := range checkTests {
+		err := Check(summer, e.roomId, e.nights, e.today)
+
+		code := ""
+		var promoErr *Error
+		if errors.As(err, &promoErr) {
+			code = promoErr.Code
+		}else if err != nil {
+			t.Fatalf("Check returned an unexpected error for the %s: %v", e.name, err)
+		}
+
+		if code != e.expected {
+			t.Errorf("Check returned %q for the %s, wanted %q", code, e.name, e.expected)
+		}
+	}
+
+	// a code without conditions is valid for any stay
+	if err := Check(models.PromoCode{Code: "ANY"}, 20, 1, time.Now()); err != nil {
+		t.Errorf("Check refused a code without conditions: %v", err)
+	}
+}
+
+func TestCheckUses(t *testing.T){
+	var usesTests = []struct {
+		name string
+		uses int
+		emailUses int
+		expected string
+	}{
+		{"unused", 0, 0, ""},
+		{"used by others", 99, 0, ""},
+		{"total cap", 100, 0, CodeUsedUp},
+		{"cap per email", 10, 1, CodeUsedUpByEmail},
+	}
+
+	for _, e := range usesTests {
+		p := summer
+		p.Uses = e.uses
+
+		err := CheckUses(p, e.emailUses)
+
+		code := ""
+		var promoErr *Error
+		if errors.As(err, &promoErr) {
+			code = promoErr.Code
+			if !promoErr.UsedUp() {
+				t.Errorf("CheckUses returned %q for the %s, which is not a usage cap", code, e.name)
+			}
+		}
+
+		if code != e.expected {
+			t.Errorf("CheckUses returned %q for the %s, wanted %q", code, e.name, e.expected)
+		}
+	}
+
+	if err := CheckUses(models.PromoCode{Uses: 1000}, 1000); err != nil {
+		t.Errorf("CheckUses refused a code without caps: %v", err)
+	}
+}
+
+func TestDiscount(t *testing.T){
+	var discountTests = []struct {
+		name string
+		p models.PromoCode
+		total money.Amount
+		expected money.Amount
+	}{
+		{"percent", summer, 34000, 3400},
+		{"percent rounded", models.PromoCode{DiscountType: models.DiscountPercent, DiscountValue: 15}, 10010, 1502},
+		{"fixed", models.PromoCode{DiscountType: models.DiscountFixed, DiscountValue: 5000}, 34000, 5000},
+		{"fixed above the total", models.PromoCode{DiscountType: models.DiscountFixed, DiscountValue: 50000}, 34000, 34000},
+		{"whole stay", models.PromoCode{DiscountType: models.DiscountPercent, DiscountValue: 100}, 34000, 34000},
+	}
+
+	for _, e := range discountTests {
+		if got := Discount(e.p, e.total); got != e.expected {
+			t.Errorf("Discount returned %d for the %s discount, wanted %d", got, e.name, e.expected)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T){
+	if got := Normalize("  summer50 "); got != "SUMMER50" {
+		t.Errorf("Normalize returned %q, wanted SUMMER50", got)
+	}
+}
@@ -0,0 +1,240 @@
+package reports
+
+import (
+	"encoding/csv"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+	"github.com/Orololuwa/go-backend-boilerplate/src/money"
+)
+
+// Row is a line of a report that can be written as CSV
+type Row interface {
+	Record() []string
+}
+
+// TotalName names the row adding up every room
+const TotalName = "All rooms"
+
+// OccupancyRow is the share of the nights a room could be sold that it was. Blocked nights cannot be sold
+type OccupancyRow struct {
+	RoomId int `json:"roomId"`
+	RoomName string `json:"roomName"`
+	AvailableNights int `json:"availableNights"`
+	SoldNights int `json:"soldNights"`
+	BlockedNights int `json:"blockedNights"`
+	OccupancyRate float64 `json:"occupancyRate"`
+}
+
+var OccupancyHeader = []string{"room_id", "room_name", "available_nights", "sold_nights", "blocked_nights", "occupancy_rate"}
+
+func (r OccupancyRow) Record() []string {
+	return []string{
+		strconv.Itoa(r.RoomId),
+		r.RoomName,
+		strconv.Itoa(r.AvailableNights),
+		strconv.Itoa(r.SoldNights),
+		strconv.Itoa(r.BlockedNights),
+		strconv.FormatFloat(r.OccupancyRate, 'f', 4, 64),
+	}
+}
+
+// RevenueRow is the room revenue with its average daily rate, per night sold,
+// and its revenue per available room night. The taxes and fees collected with the stays are not revenue and are
+// reported apart. Amounts are in minor units of Currency
+type RevenueRow struct {
+	RoomId int `json:"roomId"`
+	RoomName string `json:"roomName"`
+	Currency string `json:"currency"`
+	AvailableNights int `json:"availableNights"`
+	SoldNights int `json:"soldNights"`
+	Revenue money.Amount `json:"revenue"`
+	ADR money.Amount `json:"adr"`
+	RevPAR money.Amount `json:"revpar"`
+	TaxesAndFees money.Amount `json:"taxesAndFees"`
+}
+
+var RevenueHeader = []string{"room_id", "room_name", "currency", "available_nights", "sold_nights", "revenue", "adr", "revpar", "taxes_and_fees"}
+
+func (r RevenueRow) Record() []string {
+	return []string{
+		strconv.Itoa(r.RoomId),
+		r.RoomName,
+		r.Currency,
+		strconv.Itoa(r.AvailableNights),
+		strconv.Itoa(r.SoldNights),
+		strconv.FormatInt(int64(r.Revenue), 10),
+		strconv.FormatInt(int64(r.ADR), 10),
+		strconv.FormatInt(int64(r.RevPAR), 10),
+		strconv.FormatInt(int64(r.TaxesAndFees), 10),
+	}
+}
+
+// StayRow describes the bookings arriving in the range: how many nights they last and how many days ahead they were made
+type StayRow struct {
+	RoomId int `json:"roomId"`
+	RoomName string `json:"roomName"`
+	Reservations int `json:"reservations"`
+	AverageLengthOfStay float64 `json:"averageLengthOfStay"`
+	AverageLeadDays float64 `json:"averageLeadDays"`
+}
+
+var StayHeader = []string{"room_id", "room_name", "reservations", "average_length_of_stay", "average_lead_days"}
+
+func (r StayRow) Record() []string {
+	return []string{
+		strconv.Itoa(r.RoomId),
+		r.RoomName,
+		strconv.Itoa(r.Reservations),
+		strconv.FormatFloat(r.AverageLengthOfStay, 'f', 2, 64),
+		strconv.FormatFloat(r.AverageLeadDays, 'f', 2, 64),
+	}
+}
+
+func ratio(a, b int, places int) float64 {
+	if b == 0 {
+		return 0
+	}
+	scale := math.Pow(10, float64(places))
+	return math.Round(float64(a) / float64(b) * scale) / scale
+}
+
+func perNight(amount money.Amount, nights int) money.Amount {
+	return amount.Share(1, int64(nights))
+}
+
+// total adds up the counts of every room
+func total(rooms []models.RoomReport) models.RoomReport {
+	t := models.RoomReport{RoomName: TotalName}
+	for _, r := range rooms {
+		t.SoldNights += r.SoldNights
+		t.BlockedNights += r.BlockedNights
+		t.Revenue += r.Revenue
+		t.Charges += r.Charges
+		t.Reservations += r.Reservations
+		t.StayNights += r.StayNights
+		t.LeadDays += r.LeadDays
+	}
+	return t
+}
+
+// byCurrency splits the rooms by the currency they are priced in, the currencies in order
+func byCurrency(rooms []models.RoomReport) ([]string, map[string][]models.RoomReport) {
+	split := make(map[string][]models.RoomReport)
+	currencies := make([]string, 0)
+	for _, r := range rooms {
+		if _, ok := split[r.Currency]; !ok {
+			currencies = append(currencies, r.Currency)
+		}
+		split[r.Currency] = append(split[r.Currency], r)
+	}
+	sort.Strings(currencies)
+
+	return currencies, split
+}
+
+// Occupancy builds the occupancy of each room over a range of days, and of all of them together
+func Occupancy(rooms []models.RoomReport, days int) ([]OccupancyRow, OccupancyRow) {
+	row := func(r models.RoomReport, nights int) OccupancyRow {
+		available := nights - r.BlockedNights
+		return OccupancyRow{
+			RoomId: r.RoomID,
+			RoomName: r.RoomName,
+			AvailableNights: available,
+			SoldNights: r.SoldNights,
+			BlockedNights: r.BlockedNights,
+			OccupancyRate: ratio(r.SoldNights, available, 4),
+		}
+	}
+
+	rows := make([]OccupancyRow, 0, len(rooms))
+	for _, r := range rooms {
+		rows = append(rows, row(r, days))
+	}
+
+	return rows, row(total(rooms), days * len(rooms))
+}
+
+// Revenue builds the revenue of each room over a range of days, and of all of them together. Amounts in different
+// currencies are not added up, there is a total for the rooms priced in each currency
+func Revenue(rooms []models.RoomReport, days int) ([]RevenueRow, []RevenueRow) {
+	row := func(r models.RoomReport, nights int) RevenueRow {
+		available := nights - r.BlockedNights
+		return RevenueRow{
+			RoomId: r.RoomID,
+			RoomName: r.RoomName,
+			Currency: r.Currency,
+			AvailableNights: available,
+			SoldNights: r.SoldNights,
+			Revenue: r.Revenue,
+			ADR: perNight(r.Revenue, r.SoldNights),
+			RevPAR: perNight(r.Revenue, available),
+			TaxesAndFees: r.Charges,
+		}
+	}
+
+	rows := make([]RevenueRow, 0, len(rooms))
+	for _, r := range rooms {
+		rows = append(rows, row(r, days))
+	}
+
+	currencies, split := byCurrency(rooms)
+	totals := make([]RevenueRow, 0, len(currencies))
+	for _, currency := range currencies {
+		t := total(split[currency])
+		t.Currency = currency
+		totals = append(totals, row(t, days * len(split[currency])))
+	}
+
+	return rows, totals
+}
+
+// Stays builds the length of stay and lead time of the bookings of each room, and of all of them together
+func Stays(rooms []models.RoomReport) ([]StayRow, StayRow) {
+	row := func(r models.RoomReport) StayRow {
+		return StayRow{
+			RoomId: r.RoomID,
+			RoomName: r.RoomName,
+			Reservations: r.Reservations,
+			AverageLengthOfStay: ratio(r.StayNights, r.Reservations, 2),
+			AverageLeadDays: ratio(r.LeadDays, r.Reservations, 2),
+		}
+	}
+
+	rows := make([]StayRow, 0, len(rooms))
+	for _, r := range rooms {
+		rows = append(rows, row(r))
+	}
+
+	return rows, row(total(rooms))
+}
+
+// WriteCSV writes the header, the rows and the totals as CSV
+func WriteCSV(w io.Writer, header []string, rows []Row, totals ...Row) error {
+	out := csv.NewWriter(w)
+
+	err := out.Write(header)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		err = out.Write(row.Record())
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, total := range totals {
+		err = out.Write(total.Record())
+		if err != nil {
+			return err
+		}
+	}
+
+	out.Flush()
+	return out.Error()
+}
@@ -0,0 +1,91 @@
+package reports
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+)
+
+var rooms = []models.RoomReport{
+	{RoomID: 1, RoomName: "One", Currency: "USD", SoldNights: 6, Revenue: 60000, Charges: 4500, Reservations: 2, StayNights: 7, LeadDays: 30},
+	{RoomID: 2, RoomName: "Two", Currency: "USD", SoldNights: 2, BlockedNights: 2, Revenue: 24000, Charges: 1800, Reservations: 1, StayNights: 2, LeadDays: 3},
+	{RoomID: 3, RoomName: "Three", Currency: "EUR", BlockedNights: 10},
+}
+
+func TestOccupancy(t *testing.T){
+	rows, total := Occupancy(rooms, 10)
+
+	expected := []OccupancyRow{
+		{RoomId: 1, RoomName: "One", AvailableNights: 10, SoldNights: 6, OccupancyRate: 0.6},
+		{RoomId: 2, RoomName: "Two", AvailableNights: 8, SoldNights: 2, BlockedNights: 2, OccupancyRate: 0.25},
+		{RoomId: 3, RoomName: "Three", AvailableNights: 0, BlockedNights: 10, OccupancyRate: 0},
+	}
+	for i, e := range expected {
+		if rows[i] != e {
+			t.Errorf("Occupancy returned %+v, wanted %+v", rows[i], e)
+		}
+	}
+
+	wantedTotal := OccupancyRow{RoomName: TotalName, AvailableNights: 18, SoldNights: 8, BlockedNights: 12, OccupancyRate: 0.4444}
+	if total != wantedTotal {
+		t.Errorf("Occupancy returned total %+v, wanted %+v", total, wantedTotal)
+	}
+}
+
+func TestRevenue(t *testing.T){
+	rows, totals := Revenue(rooms, 10)
+
+	if rows[0].ADR != 10000 || rows[0].RevPAR != 6000 {
+		t.Errorf("Revenue returned %+v for room 1, wanted an ADR of 10000 and a RevPAR of 6000", rows[0])
+	}
+	if rows[2].ADR != 0 || rows[2].RevPAR != 0 {
+		t.Errorf("Revenue returned %+v for a room that could not be sold, wanted zero rates", rows[2])
+	}
+
+	if len(totals) != 2 || totals[0].Currency != "EUR" || totals[1].Currency != "USD" {
+		t.Fatalf("Revenue returned totals %+v, wanted one in EUR and one in USD", totals)
+	}
+	if totals[0].Revenue != 0 || totals[0].AvailableNights != 0 {
+		t.Errorf("Revenue returned total %+v for the rooms priced in EUR, wanted nothing sold or available", totals[0])
+	}
+
+	// 84000 over 8 nights sold and 18 available
+	total := totals[1]
+	if total.Revenue != 84000 || total.ADR != 10500 || total.RevPAR != 4667 || total.TaxesAndFees != 6300 {
+		t.Errorf("Revenue returned total %+v, wanted 84000 USD revenue, 10500 ADR, 4667 RevPAR and 6300 taxes and fees", total)
+	}
+}
+
+func TestStays(t *testing.T){
+	rows, total := Stays(rooms)
+
+	if rows[0].AverageLengthOfStay != 3.5 || rows[0].AverageLeadDays != 15 {
+		t.Errorf("Stays returned %+v for room 1, wanted 3.5 nights booked 15 days ahead", rows[0])
+	}
+	if total.Reservations != 3 || total.AverageLengthOfStay != 3 || total.AverageLeadDays != 11 {
+		t.Errorf("Stays returned total %+v, wanted 3 reservations of 3 nights booked 11 days ahead", total)
+	}
+}
+
+func TestWriteCSV(t *testing.T){
+	rows, total := Stays(rooms[:1])
+
+	records := make([]Row, len(rows))
+	for i, r := range rows {
+		records[i] = r
+	}
+
+	var buf bytes.Buffer
+	err := WriteCSV(&buf, StayHeader, records, total)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "room_id,room_name,reservations,average_length_of_stay,average_lead_days\n" +
+		"1,One,2,3.50,15.00\n" +
+		"0,All rooms,2,3.50,15.00\n"
+	if buf.String() != expected {
+		t.Errorf("WriteCSV wrote %q, wanted %q", buf.String(), expected)
+	}
+}
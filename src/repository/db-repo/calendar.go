@@ -0,0 +1,230 @@
+package dbrepo
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+)
+
+// GetRoomCalendarToken returns the secret that authorises the room's calendar feed, empty when none was issued
+func (m *postgresDBRepo) GetRoomCalendarToken(ctx context.Context, tx *sql.Tx, roomId int) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var token sql.NullString
+
+	query := `select calendar_token from rooms where id = $1`
+
+	var err error
+	if tx != nil {
+		err = tx.QueryRowContext(ctx, query, roomId).Scan(&token)
+	}else{
+		err = m.DB.QueryRowContext(ctx, query, roomId).Scan(&token)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return token.String, nil
+}
+
+// UpdateRoomCalendarToken replaces the room's calendar secret, which invalidates feed URLs handed out before
+func (m *postgresDBRepo) UpdateRoomCalendarToken(ctx context.Context, tx *sql.Tx, roomId int, token string) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	stmt := `update rooms set calendar_token = $1, updated_at = $2 where id = $3`
+
+	var result sql.Result
+	var err error
+	if tx != nil {
+		result, err = tx.ExecContext(ctx, stmt, token, time.Now(), roomId)
+	}else{
+		result, err = m.DB.ExecContext(ctx, stmt, token, time.Now(), roomId)
+	}
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// GetRoomRestrictionsForCalendar returns the room's restrictions ending on or after from, with their reservation when there is one.
+// Expired holds are left out. The feed is shared outside the property, so the confirmation code is not read
+func (m *postgresDBRepo) GetRoomRestrictionsForCalendar(ctx context.Context, tx *sql.Tx, roomId int, from time.Time) ([]models.RoomRestriction, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var restrictions = make([]models.RoomRestriction, 0)
+
+	query := `
+		select
+			rr.id, rr.start_date, rr.end_date, rr.room_id, rr.restriction_id, rr.note, rr.created_at, rr.updated_at,
+			rs.restriction_name, coalesce(r.id, 0), coalesce(r.first_name, ''), coalesce(r.last_name, ''),
+			coalesce(r.status, ''), coalesce(r.created_at, rr.created_at), coalesce(r.updated_at, rr.updated_at)
+		from
+			room_restrictions rr
+			join restrictions rs on (rs.id = rr.restriction_id)
+			left join reservations r on (r.id = rr.reservation_id)
+		where
+			rr.room_id = $1 and rr.end_date >= $2 and (rr.expires_at is null or rr.expires_at > $3)
+		order by
+			rr.start_date, rr.id
+	`
+
+	var rows *sql.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.QueryContext(ctx, query, roomId, from, time.Now())
+	}else{
+		rows, err = m.DB.QueryContext(ctx, query, roomId, from, time.Now())
+	}
+	if err != nil {
+		return restrictions, err
+	}
+	defer rows.Close()
+
+	for rows.Next(){
+		var rr models.RoomRestriction
+		err := rows.Scan(
+			&rr.ID,
+			&rr.StartDate,
+			&rr.EndDate,
+			&rr.RoomID,
+			&rr.RestrictionID,
+			&rr.Note,
+			&rr.CreatedAt,
+			&rr.UpdatedAt,
+			&rr.Restriction.RestrictionName,
+			&rr.Reservation.ID,
+			&rr.Reservation.FirstName,
+			&rr.Reservation.LastName,
+			&rr.Reservation.Status,
+			&rr.Reservation.CreatedAt,
+			&rr.Reservation.UpdatedAt,
+		)
+		if err != nil {
+			return restrictions, err
+		}
+		rr.ReservationID = rr.Reservation.ID
+		rr.Restriction.ID = rr.RestrictionID
+		restrictions = append(restrictions, rr)
+	}
+
+	if err = rows.Err(); err != nil {
+		return restrictions, err
+	}
+
+	return restrictions, nil
+}
+
+// GetCalendarGrid returns the room, every room when roomId is 0, with the restrictions and recurring blocks covering
+// a night between start and end. Rooms and their restrictions are read in a single query, expired holds are left out
+func (m *postgresDBRepo) GetCalendarGrid(ctx context.Context, tx *sql.Tx, roomId int, start, end time.Time) ([]models.RoomCalendar, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var calendars = make([]models.RoomCalendar, 0)
+
+	query := `
+		select
+			r.id, r.room_name, r.base_rate, r.weekend_rate, r.currency, r.max_occupancy,
+			coalesce(rr.id, 0), coalesce(rr.start_date, $1::date), coalesce(rr.end_date, $1::date),
+			coalesce(rr.restriction_id, 0), coalesce(rr.reservation_id, 0), coalesce(rs.restriction_name, '')
+		from
+			rooms r
+			left join room_restrictions rr on (
+				rr.room_id = r.id and $1 < rr.end_date and $2 > rr.start_date
+				and (rr.expires_at is null or rr.expires_at > $3)
+			)
+			left join restrictions rs on (rs.id = rr.restriction_id)
+		where
+			$4 = 0 or r.id = $4
+		order by
+			r.id, rr.start_date, rr.id
+	`
+
+	var rows *sql.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.QueryContext(ctx, query, start, end, time.Now(), roomId)
+	}else{
+		rows, err = m.DB.QueryContext(ctx, query, start, end, time.Now(), roomId)
+	}
+	if err != nil {
+		return calendars, err
+	}
+	defer rows.Close()
+
+	byRoom := make(map[int]int)
+
+	for rows.Next(){
+		var room models.Room
+		var rr models.RoomRestriction
+		err := rows.Scan(
+			&room.ID,
+			&room.RoomName,
+			&room.BaseRate,
+			&room.WeekendRate,
+			&room.Currency,
+			&room.MaxOccupancy,
+			&rr.ID,
+			&rr.StartDate,
+			&rr.EndDate,
+			&rr.RestrictionID,
+			&rr.ReservationID,
+			&rr.Restriction.RestrictionName,
+		)
+		if err != nil {
+			return calendars, err
+		}
+
+		i, ok := byRoom[room.ID]
+		if !ok {
+			i = len(calendars)
+			byRoom[room.ID] = i
+			calendars = append(calendars, models.RoomCalendar{
+				Room: room,
+				Restrictions: make([]models.RoomRestriction, 0),
+				RecurringBlocks: make([]models.RecurringBlock, 0),
+			})
+		}
+
+		// a room without restrictions comes back as a single row of nulls
+		if rr.ID == 0 {
+			continue
+		}
+		rr.RoomID = room.ID
+		rr.Restriction.ID = rr.RestrictionID
+		calendars[i].Restrictions = append(calendars[i].Restrictions, rr)
+	}
+
+	if err = rows.Err(); err != nil {
+		return calendars, err
+	}
+
+	blocks, err := m.recurringBlocksBefore(ctx, tx, roomId, end)
+	if err != nil {
+		return calendars, err
+	}
+
+	for _, b := range blocks {
+		if i, ok := byRoom[b.RoomID]; ok {
+			calendars[i].RecurringBlocks = append(calendars[i].RecurringBlocks, b)
+		}
+	}
+
+	return calendars, nil
+}
@@ -0,0 +1,281 @@
+package dbrepo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+	"github.com/Orololuwa/go-backend-boilerplate/src/money"
+)
+
+// policySnapshot encodes the policy copied onto a reservation, a reservation without one is stored as NULL
+func policySnapshot(p *models.CancellationPolicy) (interface{}, error) {
+	if p == nil {
+		return nil, nil
+	}
+
+	b, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// policyColumn scans the policy snapshot of a reservation, leaving it nil when the column is NULL
+type policyColumn struct {
+	policy **models.CancellationPolicy
+}
+
+func (c policyColumn) Scan(src interface{}) error {
+	*c.policy = nil
+
+	var b []byte
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case string:
+		b = []byte(v)
+	case []byte:
+		b = v
+	default:
+		return fmt.Errorf("cannot scan %T into a cancellation policy", src)
+	}
+
+	var p models.CancellationPolicy
+	err := json.Unmarshal(b, &p)
+	if err != nil {
+		return err
+	}
+	*c.policy = &p
+	return nil
+}
+
+func scanCancellationPolicy(row interface{ Scan(...interface{}) error }, p *models.CancellationPolicy) error {
+	var windows string
+
+	err := row.Scan(&p.ID, &p.PolicyName, &windows, &p.NoShowPercent, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal([]byte(windows), &p.Windows)
+}
+
+func (m *postgresDBRepo) InsertCancellationPolicy(ctx context.Context, tx *sql.Tx, p models.CancellationPolicy) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var newId int
+
+	windows, err := json.Marshal(p.Windows)
+	if err != nil {
+		return 0, err
+	}
+
+	stmt := `insert into cancellation_policies (policy_name, windows, no_show_percent, created_at, updated_at)
+			values ($1, $2, $3, $4, $5) returning id`
+
+	if tx != nil {
+		err = tx.QueryRowContext(ctx, stmt, p.PolicyName, string(windows), p.NoShowPercent, time.Now(), time.Now()).Scan(&newId)
+	}else{
+		err = m.DB.QueryRowContext(ctx, stmt, p.PolicyName, string(windows), p.NoShowPercent, time.Now(), time.Now()).Scan(&newId)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return newId, nil
+}
+
+func (m *postgresDBRepo) GetCancellationPolicies(ctx context.Context, tx *sql.Tx) ([]models.CancellationPolicy, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var policies = make([]models.CancellationPolicy, 0)
+
+	query := `
+		select
+			id, policy_name, windows, no_show_percent, created_at, updated_at
+		from
+			cancellation_policies
+		order by
+			id
+	`
+
+	var rows *sql.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.QueryContext(ctx, query)
+	}else{
+		rows, err = m.DB.QueryContext(ctx, query)
+	}
+	if err != nil {
+		return policies, err
+	}
+	defer rows.Close()
+
+	for rows.Next(){
+		var p models.CancellationPolicy
+		err := scanCancellationPolicy(rows, &p)
+		if err != nil {
+			return policies, err
+		}
+		policies = append(policies, p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return policies, err
+	}
+
+	return policies, nil
+}
+
+func (m *postgresDBRepo) GetCancellationPolicyById(ctx context.Context, tx *sql.Tx, id int) (models.CancellationPolicy, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var p models.CancellationPolicy
+
+	query := `
+		select
+			id, policy_name, windows, no_show_percent, created_at, updated_at
+		from
+			cancellation_policies
+		where
+			id = $1
+	`
+
+	var row *sql.Row
+	if tx != nil {
+		row = tx.QueryRowContext(ctx, query, id)
+	}else{
+		row = m.DB.QueryRowContext(ctx, query, id)
+	}
+
+	err := scanCancellationPolicy(row, &p)
+	if err != nil {
+		return p, err
+	}
+
+	return p, nil
+}
+
+// GetCancellationPolicyForStay returns the policy a stay in the room is booked under: the policy of the seasonal
+// rate when the stay is priced by one and it has a policy, the policy of the room otherwise. A rateId of 0 is the
+// room's own rates. It returns sql.ErrNoRows when neither has a policy
+func (m *postgresDBRepo) GetCancellationPolicyForStay(ctx context.Context, tx *sql.Tx, roomId, rateId int) (models.CancellationPolicy, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var p models.CancellationPolicy
+
+	query := `
+		select
+			cp.id, cp.policy_name, cp.windows, cp.no_show_percent, cp.created_at, cp.updated_at
+		from
+			rooms r
+			left join room_rates rt on (rt.id = $2 and rt.room_id = r.id)
+			join cancellation_policies cp on (cp.id = coalesce(rt.cancellation_policy_id, r.cancellation_policy_id))
+		where
+			r.id = $1
+	`
+
+	var row *sql.Row
+	if tx != nil {
+		row = tx.QueryRowContext(ctx, query, roomId, rateId)
+	}else{
+		row = m.DB.QueryRowContext(ctx, query, roomId, rateId)
+	}
+
+	err := scanCancellationPolicy(row, &p)
+	if err != nil {
+		return p, err
+	}
+
+	return p, nil
+}
+
+// DeleteCancellationPolicy removes the policy from the rooms and rates it is attached to. Reservations keep their copy
+func (m *postgresDBRepo) DeleteCancellationPolicy(ctx context.Context, tx *sql.Tx, id int) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	stmt := `delete from cancellation_policies where id = $1`
+
+	var result sql.Result
+	var err error
+	if tx != nil {
+		result, err = tx.ExecContext(ctx, stmt, id)
+	}else{
+		result, err = m.DB.ExecContext(ctx, stmt, id)
+	}
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// UpdateRoomCancellationPolicy attaches the policy to the room, a policyId of 0 detaches the room's policy
+func (m *postgresDBRepo) UpdateRoomCancellationPolicy(ctx context.Context, tx *sql.Tx, roomId, policyId int) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	stmt := `update rooms set cancellation_policy_id = $1, updated_at = $2 where id = $3`
+
+	var result sql.Result
+	var err error
+	if tx != nil {
+		result, err = tx.ExecContext(ctx, stmt, nullableInt(policyId), time.Now(), roomId)
+	}else{
+		result, err = m.DB.ExecContext(ctx, stmt, nullableInt(policyId), time.Now(), roomId)
+	}
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// UpdateReservationCancellation moves the reservation to a cancelled or no-show status and records what it cost
+func (m *postgresDBRepo) UpdateReservationCancellation(ctx context.Context, tx *sql.Tx, id int, status string, fee, refund money.Amount) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	stmt := `update reservations set status = $1, cancellation_fee = $2, refund_amount = $3, updated_at = $4 where id = $5`
+
+	var err error
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, stmt, status, fee, refund, time.Now(), id)
+	}else{
+		_, err = m.DB.ExecContext(ctx, stmt, status, fee, refund, time.Now(), id)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
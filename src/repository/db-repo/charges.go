@@ -0,0 +1,222 @@
+package dbrepo
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+)
+
+func (m *postgresDBRepo) InsertChargeRule(ctx context.Context, tx *sql.Tx, rule models.ChargeRule) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var newId int
+
+	stmt := `insert into charge_rules (rule_name, kind, amount, room_id, created_at, updated_at)
+			values ($1, $2, $3, $4, $5, $6) returning id`
+
+	var err error
+	if tx != nil {
+		err = tx.QueryRowContext(ctx, stmt, rule.RuleName, rule.Kind, rule.Amount, nullableInt(rule.RoomID), time.Now(), time.Now()).Scan(&newId)
+	}else{
+		err = m.DB.QueryRowContext(ctx, stmt, rule.RuleName, rule.Kind, rule.Amount, nullableInt(rule.RoomID), time.Now(), time.Now()).Scan(&newId)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return newId, nil
+}
+
+// GetChargeRules returns the rules of every room, in the order they were created which is the order they are charged in
+func (m *postgresDBRepo) GetChargeRules(ctx context.Context, tx *sql.Tx) ([]models.ChargeRule, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var rules = make([]models.ChargeRule, 0)
+
+	query := `
+		select
+			id, rule_name, kind, amount, coalesce(room_id, 0), created_at, updated_at
+		from
+			charge_rules
+		order by
+			id
+	`
+
+	var rows *sql.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.QueryContext(ctx, query)
+	}else{
+		rows, err = m.DB.QueryContext(ctx, query)
+	}
+	if err != nil {
+		return rules, err
+	}
+	defer rows.Close()
+
+	for rows.Next(){
+		var rule models.ChargeRule
+		err := rows.Scan(
+			&rule.ID,
+			&rule.RuleName,
+			&rule.Kind,
+			&rule.Amount,
+			&rule.RoomID,
+			&rule.CreatedAt,
+			&rule.UpdatedAt,
+		)
+		if err != nil {
+			return rules, err
+		}
+		rules = append(rules, rule)
+	}
+
+	if err = rows.Err(); err != nil {
+		return rules, err
+	}
+
+	return rules, nil
+}
+
+// DeleteChargeRule stops charging the rule on new stays. Reservations keep the lines they were booked with
+func (m *postgresDBRepo) DeleteChargeRule(ctx context.Context, tx *sql.Tx, id int) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	stmt := `delete from charge_rules where id = $1`
+
+	var result sql.Result
+	var err error
+	if tx != nil {
+		result, err = tx.ExecContext(ctx, stmt, id)
+	}else{
+		result, err = m.DB.ExecContext(ctx, stmt, id)
+	}
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// InsertReservationCharges stores the tax and fee lines of a reservation
+func (m *postgresDBRepo) InsertReservationCharges(ctx context.Context, tx *sql.Tx, reservationId int, lines []models.ReservationCharge) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	stmt := `insert into reservation_charges (reservation_id, charge_rule_id, charge_name, kind, rate, quantity, amount,
+			created_at, updated_at)
+			values ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+
+	for _, line := range lines {
+		args := []interface{}{
+			reservationId,
+			nullableInt(line.ChargeRuleID),
+			line.ChargeName,
+			line.Kind,
+			line.Rate,
+			line.Quantity,
+			line.Amount,
+			time.Now(),
+			time.Now(),
+		}
+
+		var err error
+		if tx != nil {
+			_, err = tx.ExecContext(ctx, stmt, args...)
+		}else{
+			_, err = m.DB.ExecContext(ctx, stmt, args...)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeleteReservationCharges removes the lines of a reservation so they can be stored again for new dates
+func (m *postgresDBRepo) DeleteReservationCharges(ctx context.Context, tx *sql.Tx, reservationId int) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	stmt := `delete from reservation_charges where reservation_id = $1`
+
+	var err error
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, stmt, reservationId)
+	}else{
+		_, err = m.DB.ExecContext(ctx, stmt, reservationId)
+	}
+
+	return err
+}
+
+// GetReservationCharges returns the tax and fee lines a reservation was booked with, in the order they were charged
+func (m *postgresDBRepo) GetReservationCharges(ctx context.Context, tx *sql.Tx, reservationId int) ([]models.ReservationCharge, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var lines = make([]models.ReservationCharge, 0)
+
+	query := `
+		select
+			id, reservation_id, coalesce(charge_rule_id, 0), charge_name, kind, rate, quantity, amount
+		from
+			reservation_charges
+		where
+			reservation_id = $1
+		order by
+			id
+	`
+
+	var rows *sql.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.QueryContext(ctx, query, reservationId)
+	}else{
+		rows, err = m.DB.QueryContext(ctx, query, reservationId)
+	}
+	if err != nil {
+		return lines, err
+	}
+	defer rows.Close()
+
+	for rows.Next(){
+		var line models.ReservationCharge
+		err := rows.Scan(
+			&line.ID,
+			&line.ReservationID,
+			&line.ChargeRuleID,
+			&line.ChargeName,
+			&line.Kind,
+			&line.Rate,
+			&line.Quantity,
+			&line.Amount,
+		)
+		if err != nil {
+			return lines, err
+		}
+		lines = append(lines, line)
+	}
+
+	if err = rows.Err(); err != nil {
+		return lines, err
+	}
+
+	return lines, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"log"
+	"time"
 
 	"github.com/Orololuwa/go-backend-boilerplate/src/repository"
 )
@@ -30,24 +31,40 @@ func (m *postgresDBRepo) Transaction(ctx context.Context, operation func(context
     if err != nil {
         return err
     }
-	
-    defer func() error{
-        if err != nil {
-            tx.Rollback()
-            return err
-        }
-
-        if err := tx.Commit(); err != nil {
-            return err
-        }
-
-		log.Println("Transaction completed successfully")
-        return nil
-    }()
 
     if err := operation(ctx, tx); err != nil {
+        tx.Rollback()
         return err
     }
 
+    if err := tx.Commit(); err != nil {
+        return err
+    }
+
+    log.Println("Transaction completed successfully")
     return nil
+}
+
+// nullableInt maps the zero value to NULL for optional foreign keys
+func nullableInt(v int) interface{} {
+	if v == 0 {
+		return nil
+	}
+	return v
+}
+
+// nullableTime maps the zero value to NULL for optional timestamps
+func nullableTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t
+}
+
+// nullableString maps the empty string to NULL for optional columns
+func nullableString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
 }
\ No newline at end of file
@@ -0,0 +1,135 @@
+package dbrepo
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+)
+
+// UpsertExchangeRate sets the rate of the currency pair, replacing the one staff set before
+func (m *postgresDBRepo) UpsertExchangeRate(ctx context.Context, tx *sql.Tx, rate models.ExchangeRate) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var id int
+
+	stmt := `insert into exchange_rates (base_currency, quote_currency, rate, created_at, updated_at)
+			values ($1, $2, $3, $4, $5)
+			on conflict (base_currency, quote_currency) do update set rate = excluded.rate, updated_at = excluded.updated_at
+			returning id`
+
+	var err error
+	if tx != nil {
+		err = tx.QueryRowContext(ctx, stmt, rate.BaseCurrency, rate.QuoteCurrency, rate.Rate, time.Now(), time.Now()).Scan(&id)
+	}else{
+		err = m.DB.QueryRowContext(ctx, stmt, rate.BaseCurrency, rate.QuoteCurrency, rate.Rate, time.Now(), time.Now()).Scan(&id)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (m *postgresDBRepo) GetExchangeRates(ctx context.Context, tx *sql.Tx) ([]models.ExchangeRate, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var rates = make([]models.ExchangeRate, 0)
+
+	query := `
+		select
+			id, base_currency, quote_currency, rate, created_at, updated_at
+		from
+			exchange_rates
+		order by
+			base_currency, quote_currency
+	`
+
+	var rows *sql.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.QueryContext(ctx, query)
+	}else{
+		rows, err = m.DB.QueryContext(ctx, query)
+	}
+	if err != nil {
+		return rates, err
+	}
+	defer rows.Close()
+
+	for rows.Next(){
+		var rate models.ExchangeRate
+		err := rows.Scan(&rate.ID, &rate.BaseCurrency, &rate.QuoteCurrency, &rate.Rate, &rate.CreatedAt, &rate.UpdatedAt)
+		if err != nil {
+			return rates, err
+		}
+		rates = append(rates, rate)
+	}
+
+	if err = rows.Err(); err != nil {
+		return rates, err
+	}
+
+	return rates, nil
+}
+
+// GetExchangeRate returns the rate staff set for converting base into quote
+func (m *postgresDBRepo) GetExchangeRate(ctx context.Context, tx *sql.Tx, base, quote string) (models.ExchangeRate, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var rate models.ExchangeRate
+
+	query := `
+		select
+			id, base_currency, quote_currency, rate, created_at, updated_at
+		from
+			exchange_rates
+		where
+			base_currency = $1 and quote_currency = $2
+	`
+
+	var row *sql.Row
+	if tx != nil {
+		row = tx.QueryRowContext(ctx, query, base, quote)
+	}else{
+		row = m.DB.QueryRowContext(ctx, query, base, quote)
+	}
+
+	err := row.Scan(&rate.ID, &rate.BaseCurrency, &rate.QuoteCurrency, &rate.Rate, &rate.CreatedAt, &rate.UpdatedAt)
+	return rate, err
+}
+
+// DeleteExchangeRate stops quoting and booking in the pair's quote currency. Reservations keep the rate they were booked at
+func (m *postgresDBRepo) DeleteExchangeRate(ctx context.Context, tx *sql.Tx, id int) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	stmt := `delete from exchange_rates where id = $1`
+
+	var result sql.Result
+	var err error
+	if tx != nil {
+		result, err = tx.ExecContext(ctx, stmt, id)
+	}else{
+		result, err = m.DB.ExecContext(ctx, stmt, id)
+	}
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package dbrepo
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+)
+
+// exports stream every matching row to the client, which takes longer than the other queries
+const exportTimeout = 5 * time.Minute
+
+// EachRoom calls fn with the rooms matching the filters of the room list one at a time, as they are read,
+// so an export never holds every room in memory. It stops at the first error fn returns
+func (m *postgresDBRepo) EachRoom(ctx context.Context, tx *sql.Tx, id int, room_name string, fn func(models.Room) error) error {
+	ctx, cancel := context.WithTimeout(ctx, exportTimeout)
+	defer cancel()
+
+	query, args := roomsQuery(id, room_name, "", "")
+
+	var rows *sql.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.QueryContext(ctx, query, args...)
+	}else{
+		rows, err = m.DB.QueryContext(ctx, query, args...)
+	}
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next(){
+		var room models.Room
+		err := rows.Scan(&room.ID, &room.RoomName, &room.BaseRate, &room.WeekendRate, &room.Currency, &room.MaxOccupancy, &room.CreatedAt, &room.UpdatedAt)
+		if err != nil {
+			return err
+		}
+
+		err = fn(room)
+		if err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// EachReservation calls fn with the reservations matching the filter one at a time, as they are read.
+// It stops at the first error fn returns
+func (m *postgresDBRepo) EachReservation(ctx context.Context, tx *sql.Tx, filter models.ReservationFilter, fn func(models.Reservation) error) error {
+	ctx, cancel := context.WithTimeout(ctx, exportTimeout)
+	defer cancel()
+
+	query, args := reservationsQuery(filter)
+
+	var rows *sql.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.QueryContext(ctx, query, args...)
+	}else{
+		rows, err = m.DB.QueryContext(ctx, query, args...)
+	}
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next(){
+		var res models.Reservation
+		err := scanReservation(rows, &res)
+		if err != nil {
+			return err
+		}
+
+		err = fn(res)
+		if err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
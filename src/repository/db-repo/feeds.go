@@ -0,0 +1,280 @@
+package dbrepo
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+)
+
+const calendarFeedColumns = `id, room_id, name, url, last_status, last_error, last_synced_at, event_count, created_at, updated_at`
+
+func scanCalendarFeed(row interface{ Scan(dest ...interface{}) error }) (models.CalendarFeed, error) {
+	var f models.CalendarFeed
+	var lastSyncedAt sql.NullTime
+
+	err := row.Scan(
+		&f.ID,
+		&f.RoomID,
+		&f.Name,
+		&f.URL,
+		&f.LastStatus,
+		&f.LastError,
+		&lastSyncedAt,
+		&f.EventCount,
+		&f.CreatedAt,
+		&f.UpdatedAt,
+	)
+	f.LastSyncedAt = lastSyncedAt.Time
+
+	return f, err
+}
+
+func (m *postgresDBRepo) queryCalendarFeeds(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) ([]models.CalendarFeed, error) {
+	var feeds = make([]models.CalendarFeed, 0)
+
+	var rows *sql.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.QueryContext(ctx, query, args...)
+	}else{
+		rows, err = m.DB.QueryContext(ctx, query, args...)
+	}
+	if err != nil {
+		return feeds, err
+	}
+	defer rows.Close()
+
+	for rows.Next(){
+		f, err := scanCalendarFeed(rows)
+		if err != nil {
+			return feeds, err
+		}
+		feeds = append(feeds, f)
+	}
+
+	if err = rows.Err(); err != nil {
+		return feeds, err
+	}
+
+	return feeds, nil
+}
+
+func (m *postgresDBRepo) InsertCalendarFeed(ctx context.Context, tx *sql.Tx, f models.CalendarFeed) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var newId int
+
+	stmt := `insert into calendar_feeds (room_id, name, url, last_status, created_at, updated_at)
+			values ($1, $2, $3, $4, $5, $6) returning id`
+
+	var err error
+	if tx != nil {
+		err = tx.QueryRowContext(ctx, stmt, f.RoomID, f.Name, f.URL, f.LastStatus, time.Now(), time.Now()).Scan(&newId)
+	}else{
+		err = m.DB.QueryRowContext(ctx, stmt, f.RoomID, f.Name, f.URL, f.LastStatus, time.Now(), time.Now()).Scan(&newId)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return newId, nil
+}
+
+func (m *postgresDBRepo) GetCalendarFeedById(ctx context.Context, tx *sql.Tx, id int) (models.CalendarFeed, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := `select ` + calendarFeedColumns + ` from calendar_feeds where id = $1`
+
+	var row *sql.Row
+	if tx != nil {
+		row = tx.QueryRowContext(ctx, query, id)
+	}else{
+		row = m.DB.QueryRowContext(ctx, query, id)
+	}
+
+	return scanCalendarFeed(row)
+}
+
+func (m *postgresDBRepo) GetCalendarFeedsByRoomId(ctx context.Context, tx *sql.Tx, roomId int) ([]models.CalendarFeed, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := `select ` + calendarFeedColumns + ` from calendar_feeds where room_id = $1 order by id`
+
+	return m.queryCalendarFeeds(ctx, tx, query, roomId)
+}
+
+// GetRemoteCalendarFeeds returns every feed with a URL, the ones the periodic sync fetches
+func (m *postgresDBRepo) GetRemoteCalendarFeeds(ctx context.Context, tx *sql.Tx) ([]models.CalendarFeed, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := `select ` + calendarFeedColumns + ` from calendar_feeds where url <> '' order by id`
+
+	return m.queryCalendarFeeds(ctx, tx, query)
+}
+
+// UpdateCalendarFeedStatus records the outcome of the last sync
+func (m *postgresDBRepo) UpdateCalendarFeedStatus(ctx context.Context, tx *sql.Tx, f models.CalendarFeed) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	stmt := `
+		update
+			calendar_feeds
+		set
+			last_status = $1, last_error = $2, last_synced_at = $3, event_count = $4, updated_at = $5
+		where
+			id = $6
+	`
+
+	args := []interface{}{f.LastStatus, f.LastError, nullableTime(f.LastSyncedAt), f.EventCount, time.Now(), f.ID}
+
+	var err error
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, stmt, args...)
+	}else{
+		_, err = m.DB.ExecContext(ctx, stmt, args...)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteCalendarFeed removes the feed and, through the foreign key, every block it created
+func (m *postgresDBRepo) DeleteCalendarFeed(ctx context.Context, tx *sql.Tx, roomId, id int) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	stmt := `delete from calendar_feeds where id = $1 and room_id = $2`
+
+	var result sql.Result
+	var err error
+	if tx != nil {
+		result, err = tx.ExecContext(ctx, stmt, id, roomId)
+	}else{
+		result, err = m.DB.ExecContext(ctx, stmt, id, roomId)
+	}
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (m *postgresDBRepo) GetExternalBlocksByFeedId(ctx context.Context, tx *sql.Tx, feedId int) ([]models.RoomRestriction, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var blocks = make([]models.RoomRestriction, 0)
+
+	query := `
+		select
+			id, start_date, end_date, room_id, restriction_id, feed_id, external_uid, created_at, updated_at
+		from
+			room_restrictions
+		where
+			feed_id = $1
+	`
+
+	var rows *sql.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.QueryContext(ctx, query, feedId)
+	}else{
+		rows, err = m.DB.QueryContext(ctx, query, feedId)
+	}
+	if err != nil {
+		return blocks, err
+	}
+	defer rows.Close()
+
+	for rows.Next(){
+		var b models.RoomRestriction
+		err := rows.Scan(
+			&b.ID,
+			&b.StartDate,
+			&b.EndDate,
+			&b.RoomID,
+			&b.RestrictionID,
+			&b.FeedID,
+			&b.ExternalUID,
+			&b.CreatedAt,
+			&b.UpdatedAt,
+		)
+		if err != nil {
+			return blocks, err
+		}
+		blocks = append(blocks, b)
+	}
+
+	if err = rows.Err(); err != nil {
+		return blocks, err
+	}
+
+	return blocks, nil
+}
+
+// InsertExternalBlock inserts a restriction created from an event of an external calendar
+func (m *postgresDBRepo) InsertExternalBlock(ctx context.Context, tx *sql.Tx, r models.RoomRestriction) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var newId int
+
+	stmt := `insert into room_restrictions (start_date, end_date, room_id, restriction_id,
+			feed_id, external_uid, created_at, updated_at)
+			values ($1, $2, $3, $4, $5, $6, $7, $8) returning id`
+
+	args := []interface{}{r.StartDate, r.EndDate, r.RoomID, models.RestrictionExternalBlock, r.FeedID, r.ExternalUID, time.Now(), time.Now()}
+
+	var err error
+	if tx != nil {
+		err = tx.QueryRowContext(ctx, stmt, args...).Scan(&newId)
+	}else{
+		err = m.DB.QueryRowContext(ctx, stmt, args...).Scan(&newId)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return newId, nil
+}
+
+func (m *postgresDBRepo) UpdateRoomRestrictionDates(ctx context.Context, tx *sql.Tx, id int, start, end time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	stmt := `update room_restrictions set start_date = $1, end_date = $2, updated_at = $3 where id = $4`
+
+	var err error
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, stmt, start, end, time.Now(), id)
+	}else{
+		_, err = m.DB.ExecContext(ctx, stmt, start, end, time.Now(), id)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,199 @@
+package dbrepo
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+)
+
+func (m *postgresDBRepo) InsertReservationGroup(ctx context.Context, tx *sql.Tx, g models.ReservationGroup) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var newId int
+
+	stmt := `insert into reservation_groups (confirmation_code, group_name, first_name, last_name, email,
+			phone, status, created_at, updated_at)
+			values ($1, $2, $3, $4, $5, $6, $7, $8, $9) returning id`
+
+	var err error
+	if tx != nil {
+		err = tx.QueryRowContext(
+			ctx, stmt,
+			g.ConfirmationCode,
+			g.GroupName,
+			g.FirstName,
+			g.LastName,
+			g.Email,
+			g.Phone,
+			g.Status,
+			time.Now(),
+			time.Now(),
+		).Scan(&newId)
+	}else{
+		err = m.DB.QueryRowContext(
+			ctx, stmt,
+			g.ConfirmationCode,
+			g.GroupName,
+			g.FirstName,
+			g.LastName,
+			g.Email,
+			g.Phone,
+			g.Status,
+			time.Now(),
+			time.Now(),
+		).Scan(&newId)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return newId, nil
+}
+
+func (m *postgresDBRepo) GetReservationGroupById(ctx context.Context, tx *sql.Tx, id int) (models.ReservationGroup, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var g models.ReservationGroup
+
+	query := `
+		select
+			id, confirmation_code, group_name, first_name, last_name, email, phone, status, created_at, updated_at
+		from
+			reservation_groups
+		where
+			id = $1
+	`
+
+	var row *sql.Row
+	if tx != nil {
+		row = tx.QueryRowContext(ctx, query, id)
+	}else{
+		row = m.DB.QueryRowContext(ctx, query, id)
+	}
+
+	err := row.Scan(
+		&g.ID,
+		&g.ConfirmationCode,
+		&g.GroupName,
+		&g.FirstName,
+		&g.LastName,
+		&g.Email,
+		&g.Phone,
+		&g.Status,
+		&g.CreatedAt,
+		&g.UpdatedAt,
+	)
+	if err != nil {
+		return g, err
+	}
+
+	return g, nil
+}
+
+func (m *postgresDBRepo) UpdateReservationGroupStatus(ctx context.Context, tx *sql.Tx, id int, status string) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	stmt := `update reservation_groups set status = $1, updated_at = $2 where id = $3`
+
+	var err error
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, stmt, status, time.Now(), id)
+	}else{
+		_, err = m.DB.ExecContext(ctx, stmt, status, time.Now(), id)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetReservationsByGroupId returns the reservations of a group ordered by room so callers lock rooms in a stable order
+func (m *postgresDBRepo) GetReservationsByGroupId(ctx context.Context, tx *sql.Tx, groupId int) ([]models.Reservation, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var reservations = make([]models.Reservation, 0)
+
+	query := `
+		select
+			r.id, r.confirmation_code, r.first_name, r.last_name, r.email, r.phone,
+			r.start_date, r.end_date, r.room_id, r.adults, r.children, r.total_price, r.status, r.group_id,
+			r.cancellation_policy, r.cancellation_fee, r.refund_amount, r.promo_code, r.discount, r.promo_discount_type, r.promo_discount_value,
+			r.currency, r.base_currency, r.exchange_rate, coalesce(r.guest_id, 0), r.special_requests, r.requests_resolved, r.created_at, r.updated_at,
+			rm.id, rm.room_name
+		from
+			reservations r
+			left join rooms rm on (rm.id = r.room_id)
+		where
+			r.group_id = $1
+		order by
+			r.room_id
+	`
+
+	var rows *sql.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.QueryContext(ctx, query, groupId)
+	}else{
+		rows, err = m.DB.QueryContext(ctx, query, groupId)
+	}
+	if err != nil {
+		return reservations, err
+	}
+	defer rows.Close()
+
+	for rows.Next(){
+		var res models.Reservation
+		err := rows.Scan(
+			&res.ID,
+			&res.ConfirmationCode,
+			&res.FirstName,
+			&res.LastName,
+			&res.Email,
+			&res.Phone,
+			&res.StartDate,
+			&res.EndDate,
+			&res.RoomID,
+			&res.Adults,
+			&res.Children,
+			&res.TotalPrice,
+			&res.Status,
+			&res.GroupID,
+			policyColumn{&res.CancellationPolicy},
+			&res.CancellationFee,
+			&res.RefundAmount,
+			&res.PromoCode,
+			&res.Discount,
+			&res.PromoDiscountType,
+			&res.PromoDiscountValue,
+			&res.Currency,
+			&res.BaseCurrency,
+			&res.ExchangeRate,
+			&res.GuestID,
+			&res.SpecialRequests,
+			&res.RequestsResolved,
+			&res.CreatedAt,
+			&res.UpdatedAt,
+			&res.Room.ID,
+			&res.Room.RoomName,
+		)
+		if err != nil {
+			return reservations, err
+		}
+		reservations = append(reservations, res)
+	}
+
+	if err = rows.Err(); err != nil {
+		return reservations, err
+	}
+
+	return reservations, nil
+}
@@ -0,0 +1,273 @@
+package dbrepo
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+)
+
+// LockGuestKey serializes the bookings of one email or phone key until the transaction ends, so two bookings of a
+// new guest made at the same time find or create a single profile
+func (m *postgresDBRepo) LockGuestKey(ctx context.Context, tx *sql.Tx, key string) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := `select pg_advisory_xact_lock(hashtext('guest:' || $1))`
+
+	var err error
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, query, key)
+	}else{
+		_, err = m.DB.ExecContext(ctx, query, key)
+	}
+
+	return err
+}
+
+// FindGuest returns the profile a booking with the keys belongs to. A profile with the same email wins over one with
+// the same phone number, the oldest profile over newer ones. An empty phone key is not matched on
+func (m *postgresDBRepo) FindGuest(ctx context.Context, tx *sql.Tx, emailKey, phoneKey string) (models.Guest, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := `
+		select
+			id, first_name, last_name, email, phone, email_key, phone_key, created_at, updated_at
+		from
+			guests
+		where
+			email_key = $1 or ($2 <> '' and phone_key = $2)
+		order by
+			email_key = $1 desc, id
+		limit 1
+	`
+
+	var row *sql.Row
+	if tx != nil {
+		row = tx.QueryRowContext(ctx, query, emailKey, phoneKey)
+	}else{
+		row = m.DB.QueryRowContext(ctx, query, emailKey, phoneKey)
+	}
+
+	return scanGuest(row)
+}
+
+func (m *postgresDBRepo) InsertGuest(ctx context.Context, tx *sql.Tx, g models.Guest) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var newId int
+
+	stmt := `insert into guests (first_name, last_name, email, phone, email_key, phone_key, created_at, updated_at)
+			values ($1, $2, $3, $4, $5, $6, $7, $8) returning id`
+
+	var err error
+	if tx != nil {
+		err = tx.QueryRowContext(ctx, stmt, g.FirstName, g.LastName, g.Email, g.Phone, g.EmailKey, g.PhoneKey, time.Now(), time.Now()).Scan(&newId)
+	}else{
+		err = m.DB.QueryRowContext(ctx, stmt, g.FirstName, g.LastName, g.Email, g.Phone, g.EmailKey, g.PhoneKey, time.Now(), time.Now()).Scan(&newId)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return newId, nil
+}
+
+func scanGuest(row *sql.Row) (models.Guest, error) {
+	var g models.Guest
+	err := row.Scan(&g.ID, &g.FirstName, &g.LastName, &g.Email, &g.Phone, &g.EmailKey, &g.PhoneKey, &g.CreatedAt, &g.UpdatedAt)
+	return g, err
+}
+
+func (m *postgresDBRepo) GetGuestById(ctx context.Context, tx *sql.Tx, id int) (models.Guest, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := `
+		select
+			id, first_name, last_name, email, phone, email_key, phone_key, created_at, updated_at
+		from
+			guests
+		where
+			id = $1
+	`
+
+	var row *sql.Row
+	if tx != nil {
+		row = tx.QueryRowContext(ctx, query, id)
+	}else{
+		row = m.DB.QueryRowContext(ctx, query, id)
+	}
+
+	return scanGuest(row)
+}
+
+// GetGuests lists the guests whose name or email contains search, or whose phone key does. An empty search lists
+// every guest
+func (m *postgresDBRepo) GetGuests(ctx context.Context, tx *sql.Tx, search string) ([]models.Guest, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var guests = make([]models.Guest, 0)
+
+	query := `
+		select
+			id, first_name, last_name, email, phone, email_key, phone_key, created_at, updated_at
+		from
+			guests
+		where
+			$1 = ''
+			or first_name || ' ' || last_name ilike '%' || $1 || '%'
+			or email ilike '%' || $1 || '%'
+			or phone_key like '%' || $1 || '%'
+		order by
+			last_name, first_name, id
+	`
+
+	var rows *sql.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.QueryContext(ctx, query, search)
+	}else{
+		rows, err = m.DB.QueryContext(ctx, query, search)
+	}
+	if err != nil {
+		return guests, err
+	}
+	defer rows.Close()
+
+	for rows.Next(){
+		var g models.Guest
+		err := rows.Scan(&g.ID, &g.FirstName, &g.LastName, &g.Email, &g.Phone, &g.EmailKey, &g.PhoneKey, &g.CreatedAt, &g.UpdatedAt)
+		if err != nil {
+			return guests, err
+		}
+		guests = append(guests, g)
+	}
+
+	if err = rows.Err(); err != nil {
+		return guests, err
+	}
+
+	return guests, nil
+}
+
+// UpdateReservationGuest moves a reservation from one guest to another. A fromGuestId of 0 links a reservation that
+// has no guest yet. It returns sql.ErrNoRows when the reservation does not belong to fromGuestId
+func (m *postgresDBRepo) UpdateReservationGuest(ctx context.Context, tx *sql.Tx, reservationId, fromGuestId, toGuestId int) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	stmt := `update reservations set guest_id = $1, updated_at = $2 where id = $3 and coalesce(guest_id, 0) = $4`
+
+	var result sql.Result
+	var err error
+	if tx != nil {
+		result, err = tx.ExecContext(ctx, stmt, toGuestId, time.Now(), reservationId, fromGuestId)
+	}else{
+		result, err = m.DB.ExecContext(ctx, stmt, toGuestId, time.Now(), reservationId, fromGuestId)
+	}
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// MergeGuests moves every reservation of the source guest to the target and deletes the source
+func (m *postgresDBRepo) MergeGuests(ctx context.Context, tx *sql.Tx, targetId, sourceId int) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	move := `update reservations set guest_id = $1, updated_at = $2 where guest_id = $3`
+	remove := `delete from guests where id = $1`
+
+	var result sql.Result
+	var err error
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, move, targetId, time.Now(), sourceId)
+		if err == nil {
+			result, err = tx.ExecContext(ctx, remove, sourceId)
+		}
+	}else{
+		_, err = m.DB.ExecContext(ctx, move, targetId, time.Now(), sourceId)
+		if err == nil {
+			result, err = m.DB.ExecContext(ctx, remove, sourceId)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// GetReservationsWithoutGuest returns up to limit reservations booked before guest profiles, oldest first, with their
+// contact details only
+func (m *postgresDBRepo) GetReservationsWithoutGuest(ctx context.Context, tx *sql.Tx, limit int) ([]models.Reservation, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var reservations = make([]models.Reservation, 0)
+
+	query := `
+		select
+			id, first_name, last_name, email, phone
+		from
+			reservations
+		where
+			guest_id is null
+		order by
+			id
+		limit $1
+	`
+
+	var rows *sql.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.QueryContext(ctx, query, limit)
+	}else{
+		rows, err = m.DB.QueryContext(ctx, query, limit)
+	}
+	if err != nil {
+		return reservations, err
+	}
+	defer rows.Close()
+
+	for rows.Next(){
+		var res models.Reservation
+		err := rows.Scan(&res.ID, &res.FirstName, &res.LastName, &res.Email, &res.Phone)
+		if err != nil {
+			return reservations, err
+		}
+		reservations = append(reservations, res)
+	}
+
+	if err = rows.Err(); err != nil {
+		return reservations, err
+	}
+
+	return reservations, nil
+}
@@ -0,0 +1,106 @@
+package dbrepo
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+)
+
+// InsertReservationHistory records a change to a reservation. Pass the transaction of the change so the history
+// is only kept when the change is
+func (m *postgresDBRepo) InsertReservationHistory(ctx context.Context, tx *sql.Tx, h models.ReservationHistory) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var newId int
+
+	stmt := `insert into reservation_history (reservation_id, action, actor, state_before, state_after, created_at, updated_at)
+			values ($1, $2, $3, $4, $5, $6, $7) returning id`
+
+	args := []interface{}{
+		h.ReservationID,
+		h.Action,
+		h.Actor,
+		nullableString(string(h.Before)),
+		nullableString(string(h.After)),
+		time.Now(),
+		time.Now(),
+	}
+
+	var err error
+	if tx != nil {
+		err = tx.QueryRowContext(ctx, stmt, args...).Scan(&newId)
+	}else{
+		err = m.DB.QueryRowContext(ctx, stmt, args...).Scan(&newId)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return newId, nil
+}
+
+// GetReservationHistory returns the changes to a reservation, oldest first
+func (m *postgresDBRepo) GetReservationHistory(ctx context.Context, tx *sql.Tx, reservationId int) ([]models.ReservationHistory, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var history = make([]models.ReservationHistory, 0)
+
+	query := `
+		select
+			id, reservation_id, action, actor, state_before, state_after, created_at
+		from
+			reservation_history
+		where
+			reservation_id = $1
+		order by
+			created_at, id
+	`
+
+	var rows *sql.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.QueryContext(ctx, query, reservationId)
+	}else{
+		rows, err = m.DB.QueryContext(ctx, query, reservationId)
+	}
+	if err != nil {
+		return history, err
+	}
+	defer rows.Close()
+
+	for rows.Next(){
+		var h models.ReservationHistory
+		var before, after sql.NullString
+		err := rows.Scan(
+			&h.ID,
+			&h.ReservationID,
+			&h.Action,
+			&h.Actor,
+			&before,
+			&after,
+			&h.CreatedAt,
+		)
+		if err != nil {
+			return history, err
+		}
+
+		if before.Valid {
+			h.Before = []byte(before.String)
+		}
+		if after.Valid {
+			h.After = []byte(after.String)
+		}
+		history = append(history, h)
+	}
+
+	if err = rows.Err(); err != nil {
+		return history, err
+	}
+
+	return history, nil
+}
@@ -0,0 +1,164 @@
+package dbrepo
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+)
+
+// LockRoom takes a row lock on the room so concurrent bookings for it are serialized until the transaction ends
+func (m *postgresDBRepo) LockRoom(ctx context.Context, tx *sql.Tx, roomId int) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var id int
+
+	query := `select id from rooms where id = $1 for update`
+
+	var err error
+	if tx != nil {
+		err = tx.QueryRowContext(ctx, query, roomId).Scan(&id)
+	}else{
+		err = m.DB.QueryRowContext(ctx, query, roomId).Scan(&id)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// InsertRoomHold inserts a hold restriction without a reservation and returns its id
+func (m *postgresDBRepo) InsertRoomHold(ctx context.Context, tx *sql.Tx, r models.RoomRestriction) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var newId int
+
+	stmt := `insert into room_restrictions (start_date, end_date, room_id, restriction_id,
+			hold_token, expires_at, created_at, updated_at)
+			values ($1, $2, $3, $4, $5, $6, $7, $8) returning id`
+
+	var err error
+	if tx != nil {
+		err = tx.QueryRowContext(
+			ctx, stmt,
+			r.StartDate,
+			r.EndDate,
+			r.RoomID,
+			models.RestrictionHold,
+			r.HoldToken,
+			r.ExpiresAt,
+			time.Now(),
+			time.Now(),
+		).Scan(&newId)
+	}else{
+		err = m.DB.QueryRowContext(
+			ctx, stmt,
+			r.StartDate,
+			r.EndDate,
+			r.RoomID,
+			models.RestrictionHold,
+			r.HoldToken,
+			r.ExpiresAt,
+			time.Now(),
+			time.Now(),
+		).Scan(&newId)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return newId, nil
+}
+
+// GetRoomHoldByToken returns the hold restriction matching the token, whether or not it has expired
+func (m *postgresDBRepo) GetRoomHoldByToken(ctx context.Context, tx *sql.Tx, token string) (models.RoomRestriction, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var hold models.RoomRestriction
+
+	query := `
+		select
+			id, start_date, end_date, room_id, restriction_id, hold_token, expires_at, created_at, updated_at
+		from
+			room_restrictions
+		where
+			hold_token = $1 and restriction_id = $2
+	`
+
+	var row *sql.Row
+	if tx != nil {
+		row = tx.QueryRowContext(ctx, query, token, models.RestrictionHold)
+	}else{
+		row = m.DB.QueryRowContext(ctx, query, token, models.RestrictionHold)
+	}
+
+	err := row.Scan(
+		&hold.ID,
+		&hold.StartDate,
+		&hold.EndDate,
+		&hold.RoomID,
+		&hold.RestrictionID,
+		&hold.HoldToken,
+		&hold.ExpiresAt,
+		&hold.CreatedAt,
+		&hold.UpdatedAt,
+	)
+	if err != nil {
+		return hold, err
+	}
+
+	return hold, nil
+}
+
+func (m *postgresDBRepo) DeleteRoomRestriction(ctx context.Context, tx *sql.Tx, id int) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	stmt := `delete from room_restrictions where id = $1`
+
+	var err error
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, stmt, id)
+	}else{
+		_, err = m.DB.ExecContext(ctx, stmt, id)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteExpiredHolds releases every hold that expired at or before now and returns how many were removed
+func (m *postgresDBRepo) DeleteExpiredHolds(ctx context.Context, tx *sql.Tx, now time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	stmt := `
+		delete from
+			room_restrictions
+		where
+			restriction_id = $1 and expires_at is not null and expires_at <= $2
+	`
+
+	var result sql.Result
+	var err error
+	if tx != nil {
+		result, err = tx.ExecContext(ctx, stmt, models.RestrictionHold, now)
+	}else{
+		result, err = m.DB.ExecContext(ctx, stmt, models.RestrictionHold, now)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,282 @@
+package dbrepo
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+	"github.com/Orololuwa/go-backend-boilerplate/src/repository"
+)
+
+type idempotency struct {
+	DB *sql.DB
+}
+func NewIdempotencyDBRepo(conn *sql.DB) repository.IdempotencyRepo {
+	return &idempotency{
+		DB: conn,
+	}
+}
+
+// memoryIdempotency keeps records in process memory, it is meant for tests and local development
+type memoryIdempotency struct {
+	mu sync.Mutex
+	records map[string]models.IdempotencyRecord
+}
+func NewIdempotencyMemoryRepo() repository.IdempotencyRepo {
+	return &memoryIdempotency{
+		records: make(map[string]models.IdempotencyRecord),
+	}
+}
+
+// ReserveIdempotencyKey claims the key for a new request until rec.LockedUntil. It returns false when an unexpired
+// record already holds the key, unless the record is of the same request and was left in flight past its lease
+func (m *idempotency) ReserveIdempotencyKey(ctx context.Context, tx *sql.Tx, rec models.IdempotencyRecord) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	stmt := `
+		insert into idempotency_keys
+			(idempotency_key, fingerprint, status_code, content_type, response_body, locked_until, lease_token, expires_at, created_at, updated_at)
+		values
+			($1, $2, 0, '', null, $3, $4, $5, $6, $6)
+		on conflict (idempotency_key) do update set
+			fingerprint = excluded.fingerprint,
+			status_code = 0,
+			content_type = '',
+			response_body = null,
+			locked_until = excluded.locked_until,
+			lease_token = excluded.lease_token,
+			expires_at = excluded.expires_at,
+			created_at = excluded.created_at,
+			updated_at = excluded.updated_at
+		where
+			idempotency_keys.expires_at <= excluded.created_at
+			or (
+				idempotency_keys.status_code = 0
+				and idempotency_keys.fingerprint = excluded.fingerprint
+				and coalesce(idempotency_keys.locked_until, idempotency_keys.expires_at) <= excluded.created_at
+			)
+	`
+
+	var result sql.Result
+	var err error
+	if tx != nil {
+		result, err = tx.ExecContext(ctx, stmt, rec.Key, rec.Fingerprint, rec.LockedUntil, rec.LeaseToken, rec.ExpiresAt, time.Now())
+	}else{
+		result, err = m.DB.ExecContext(ctx, stmt, rec.Key, rec.Fingerprint, rec.LockedUntil, rec.LeaseToken, rec.ExpiresAt, time.Now())
+	}
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+func (m *idempotency) GetIdempotencyRecord(ctx context.Context, tx *sql.Tx, key string) (models.IdempotencyRecord, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var rec models.IdempotencyRecord
+
+	query := `
+		select
+			id, idempotency_key, fingerprint, status_code, content_type, coalesce(response_body, ''),
+			coalesce(locked_until, expires_at), lease_token, expires_at, created_at, updated_at
+		from
+			idempotency_keys
+		where
+			idempotency_key = $1
+	`
+
+	var row *sql.Row
+	if tx != nil {
+		row = tx.QueryRowContext(ctx, query, key)
+	}else{
+		row = m.DB.QueryRowContext(ctx, query, key)
+	}
+
+	err := row.Scan(
+		&rec.ID,
+		&rec.Key,
+		&rec.Fingerprint,
+		&rec.StatusCode,
+		&rec.ContentType,
+		&rec.ResponseBody,
+		&rec.LockedUntil,
+		&rec.LeaseToken,
+		&rec.ExpiresAt,
+		&rec.CreatedAt,
+		&rec.UpdatedAt,
+	)
+	if err != nil {
+		return rec, err
+	}
+
+	return rec, nil
+}
+
+// CompleteIdempotencyRecord stores the response that will be replayed for later requests with the same key. It
+// returns sql.ErrNoRows when the request no longer holds the key because a retry took it over
+func (m *idempotency) CompleteIdempotencyRecord(ctx context.Context, tx *sql.Tx, key, leaseToken string, statusCode int, contentType string, body []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	stmt := `
+		update
+			idempotency_keys
+		set
+			status_code = $1, content_type = $2, response_body = $3, updated_at = $4
+		where
+			idempotency_key = $5 and lease_token = $6
+	`
+
+	var result sql.Result
+	var err error
+	if tx != nil {
+		result, err = tx.ExecContext(ctx, stmt, statusCode, contentType, string(body), time.Now(), key, leaseToken)
+	}else{
+		result, err = m.DB.ExecContext(ctx, stmt, statusCode, contentType, string(body), time.Now(), key, leaseToken)
+	}
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// DeleteIdempotencyRecord releases the key held by the request with the lease token. A key a retry took over is
+// left to the retry
+func (m *idempotency) DeleteIdempotencyRecord(ctx context.Context, tx *sql.Tx, key, leaseToken string) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	stmt := `delete from idempotency_keys where idempotency_key = $1 and lease_token = $2`
+
+	var err error
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, stmt, key, leaseToken)
+	}else{
+		_, err = m.DB.ExecContext(ctx, stmt, key, leaseToken)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *idempotency) DeleteExpiredIdempotencyRecords(ctx context.Context, tx *sql.Tx, now time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	stmt := `delete from idempotency_keys where expires_at <= $1`
+
+	var result sql.Result
+	var err error
+	if tx != nil {
+		result, err = tx.ExecContext(ctx, stmt, now)
+	}else{
+		result, err = m.DB.ExecContext(ctx, stmt, now)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// In-memory store
+func (m *memoryIdempotency) ReserveIdempotencyKey(ctx context.Context, tx *sql.Tx, rec models.IdempotencyRecord) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+
+	existing, ok := m.records[rec.Key]
+	abandoned := existing.StatusCode == 0 && existing.Fingerprint == rec.Fingerprint && !existing.LockedUntil.After(now)
+	if ok && existing.ExpiresAt.After(now) && !abandoned {
+		return false, nil
+	}
+
+	rec.StatusCode = 0
+	rec.ResponseBody = nil
+	rec.ContentType = ""
+	rec.CreatedAt = now
+	rec.UpdatedAt = now
+	m.records[rec.Key] = rec
+
+	return true, nil
+}
+
+func (m *memoryIdempotency) GetIdempotencyRecord(ctx context.Context, tx *sql.Tx, key string) (models.IdempotencyRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rec, ok := m.records[key]
+	if !ok {
+		return rec, sql.ErrNoRows
+	}
+
+	return rec, nil
+}
+
+func (m *memoryIdempotency) CompleteIdempotencyRecord(ctx context.Context, tx *sql.Tx, key, leaseToken string, statusCode int, contentType string, body []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rec, ok := m.records[key]
+	if !ok || rec.LeaseToken != leaseToken {
+		return sql.ErrNoRows
+	}
+
+	rec.StatusCode = statusCode
+	rec.ContentType = contentType
+	rec.ResponseBody = append([]byte(nil), body...)
+	rec.UpdatedAt = time.Now()
+	m.records[key] = rec
+
+	return nil
+}
+
+func (m *memoryIdempotency) DeleteIdempotencyRecord(ctx context.Context, tx *sql.Tx, key, leaseToken string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if rec, ok := m.records[key]; ok && rec.LeaseToken == leaseToken {
+		delete(m.records, key)
+	}
+
+	return nil
+}
+
+func (m *memoryIdempotency) DeleteExpiredIdempotencyRecords(ctx context.Context, tx *sql.Tx, now time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var deleted int64
+	for key, rec := range m.records {
+		if !rec.ExpiresAt.After(now) {
+			delete(m.records, key)
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,144 @@
+package dbrepo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+)
+
+// NextInvoiceSequence takes the next number of the series. The series row stays locked until the transaction ends,
+// so numbers are handed out one at a time and a rolled back invoice gives its number back
+func (m *postgresDBRepo) NextInvoiceSequence(ctx context.Context, tx *sql.Tx, series string) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var next int
+
+	stmt := `insert into invoice_sequences (series, last_number, created_at, updated_at)
+			values ($1, 1, $2, $3)
+			on conflict (series) do update set last_number = invoice_sequences.last_number + 1, updated_at = excluded.updated_at
+			returning last_number`
+
+	var err error
+	if tx != nil {
+		err = tx.QueryRowContext(ctx, stmt, series, time.Now(), time.Now()).Scan(&next)
+	}else{
+		err = m.DB.QueryRowContext(ctx, stmt, series, time.Now(), time.Now()).Scan(&next)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return next, nil
+}
+
+func (m *postgresDBRepo) InsertInvoice(ctx context.Context, tx *sql.Tx, inv models.Invoice) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var newId int
+
+	lines, err := json.Marshal(inv.Lines)
+	if err != nil {
+		return 0, err
+	}
+
+	stmt := `insert into invoices (invoice_number, series, sequence, reservation_id, confirmation_code, guest_name,
+			email, room_name, start_date, end_date, currency, lines, subtotal, taxes_and_fees, total, paid, refunded,
+			balance_due, issued_at, created_at, updated_at)
+			values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21) returning id`
+
+	args := []interface{}{
+		inv.InvoiceNumber,
+		inv.Series,
+		inv.Sequence,
+		inv.ReservationID,
+		inv.ConfirmationCode,
+		inv.GuestName,
+		inv.Email,
+		inv.RoomName,
+		inv.StartDate,
+		inv.EndDate,
+		inv.Currency,
+		string(lines),
+		inv.Subtotal,
+		inv.TaxesAndFees,
+		inv.Total,
+		inv.Paid,
+		inv.Refunded,
+		inv.BalanceDue,
+		inv.IssuedAt,
+		time.Now(),
+		time.Now(),
+	}
+
+	if tx != nil {
+		err = tx.QueryRowContext(ctx, stmt, args...).Scan(&newId)
+	}else{
+		err = m.DB.QueryRowContext(ctx, stmt, args...).Scan(&newId)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return newId, nil
+}
+
+func (m *postgresDBRepo) GetInvoiceByReservationId(ctx context.Context, tx *sql.Tx, reservationId int) (models.Invoice, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var inv models.Invoice
+	var lines string
+
+	query := `
+		select
+			id, invoice_number, series, sequence, reservation_id, confirmation_code, guest_name, email, room_name,
+			start_date, end_date, currency, lines, subtotal, taxes_and_fees, total, paid, refunded, balance_due, issued_at
+		from
+			invoices
+		where
+			reservation_id = $1
+	`
+
+	var row *sql.Row
+	if tx != nil {
+		row = tx.QueryRowContext(ctx, query, reservationId)
+	}else{
+		row = m.DB.QueryRowContext(ctx, query, reservationId)
+	}
+
+	err := row.Scan(
+		&inv.ID,
+		&inv.InvoiceNumber,
+		&inv.Series,
+		&inv.Sequence,
+		&inv.ReservationID,
+		&inv.ConfirmationCode,
+		&inv.GuestName,
+		&inv.Email,
+		&inv.RoomName,
+		&inv.StartDate,
+		&inv.EndDate,
+		&inv.Currency,
+		&lines,
+		&inv.Subtotal,
+		&inv.TaxesAndFees,
+		&inv.Total,
+		&inv.Paid,
+		&inv.Refunded,
+		&inv.BalanceDue,
+		&inv.IssuedAt,
+	)
+	if err != nil {
+		return inv, err
+	}
+
+	err = json.Unmarshal([]byte(lines), &inv.Lines)
+	return inv, err
+}
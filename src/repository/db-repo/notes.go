@@ -0,0 +1,78 @@
+package dbrepo
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+)
+
+func (m *postgresDBRepo) InsertReservationNote(ctx context.Context, tx *sql.Tx, n models.ReservationNote) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var newId int
+
+	stmt := `insert into reservation_notes (reservation_id, author, body, created_at, updated_at)
+			values ($1, $2, $3, $4, $5) returning id`
+
+	var err error
+	if tx != nil {
+		err = tx.QueryRowContext(ctx, stmt, n.ReservationID, n.Author, n.Body, n.CreatedAt, n.CreatedAt).Scan(&newId)
+	}else{
+		err = m.DB.QueryRowContext(ctx, stmt, n.ReservationID, n.Author, n.Body, n.CreatedAt, n.CreatedAt).Scan(&newId)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return newId, nil
+}
+
+// GetReservationNotes returns the notes staff keep on a reservation, oldest first
+func (m *postgresDBRepo) GetReservationNotes(ctx context.Context, tx *sql.Tx, reservationId int) ([]models.ReservationNote, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var notes = make([]models.ReservationNote, 0)
+
+	query := `
+		select
+			id, reservation_id, author, body, created_at
+		from
+			reservation_notes
+		where
+			reservation_id = $1
+		order by
+			created_at, id
+	`
+
+	var rows *sql.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.QueryContext(ctx, query, reservationId)
+	}else{
+		rows, err = m.DB.QueryContext(ctx, query, reservationId)
+	}
+	if err != nil {
+		return notes, err
+	}
+	defer rows.Close()
+
+	for rows.Next(){
+		var n models.ReservationNote
+		err := rows.Scan(&n.ID, &n.ReservationID, &n.Author, &n.Body, &n.CreatedAt)
+		if err != nil {
+			return notes, err
+		}
+		notes = append(notes, n)
+	}
+
+	if err = rows.Err(); err != nil {
+		return notes, err
+	}
+
+	return notes, nil
+}
@@ -0,0 +1,211 @@
+package dbrepo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+	"github.com/Orololuwa/go-backend-boilerplate/src/repository"
+)
+
+type payment struct {
+	DB *sql.DB
+}
+func NewPaymentDBRepo(conn *sql.DB) repository.PaymentDBRepo {
+	return &payment{
+		DB: conn,
+	}
+}
+
+type testPaymentDBRepo struct {
+	DB *sql.DB
+}
+func NewPaymentTestingDBRepo() repository.PaymentDBRepo {
+	return &testPaymentDBRepo{
+	}
+}
+
+const paymentColumns = `id, reservation_id, provider, reference, amount, captured_amount, refunded_amount,
+			currency, status, failure_reason, created_at, updated_at`
+
+func scanPayment(row interface{ Scan(dest ...interface{}) error }) (models.Payment, error) {
+	var p models.Payment
+
+	err := row.Scan(
+		&p.ID,
+		&p.ReservationID,
+		&p.Provider,
+		&p.Reference,
+		&p.Amount,
+		&p.CapturedAmount,
+		&p.RefundedAmount,
+		&p.Currency,
+		&p.Status,
+		&p.FailureReason,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+
+	return p, err
+}
+
+func (m *payment) InsertPayment(ctx context.Context, tx *sql.Tx, p models.Payment) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var newId int
+
+	stmt := `insert into payments (reservation_id, provider, reference, amount, captured_amount,
+			refunded_amount, currency, status, failure_reason, created_at, updated_at)
+			values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) returning id`
+
+	var err error
+	if tx != nil {
+		err = tx.QueryRowContext(
+			ctx, stmt,
+			p.ReservationID,
+			p.Provider,
+			p.Reference,
+			p.Amount,
+			p.CapturedAmount,
+			p.RefundedAmount,
+			p.Currency,
+			p.Status,
+			p.FailureReason,
+			time.Now(),
+			time.Now(),
+		).Scan(&newId)
+	}else{
+		err = m.DB.QueryRowContext(
+			ctx, stmt,
+			p.ReservationID,
+			p.Provider,
+			p.Reference,
+			p.Amount,
+			p.CapturedAmount,
+			p.RefundedAmount,
+			p.Currency,
+			p.Status,
+			p.FailureReason,
+			time.Now(),
+			time.Now(),
+		).Scan(&newId)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return newId, nil
+}
+
+func (m *payment) GetPaymentsByReservationId(ctx context.Context, tx *sql.Tx, reservationId int) ([]models.Payment, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var payments = make([]models.Payment, 0)
+
+	query := `select ` + paymentColumns + ` from payments where reservation_id = $1 order by id`
+
+	var rows *sql.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.QueryContext(ctx, query, reservationId)
+	}else{
+		rows, err = m.DB.QueryContext(ctx, query, reservationId)
+	}
+	if err != nil {
+		return payments, err
+	}
+	defer rows.Close()
+
+	for rows.Next(){
+		p, err := scanPayment(rows)
+		if err != nil {
+			return payments, err
+		}
+		payments = append(payments, p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return payments, err
+	}
+
+	return payments, nil
+}
+
+// GetLatestPaymentByReservationId returns the newest payment of the reservation, optionally limited to the given statuses
+func (m *payment) GetLatestPaymentByReservationId(ctx context.Context, tx *sql.Tx, reservationId int, status ...string) (models.Payment, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := `select ` + paymentColumns + ` from payments where reservation_id = $1`
+	args := []interface{}{reservationId}
+
+	if len(status) > 0 {
+		placeholders := make([]string, 0, len(status))
+		for _, s := range status {
+			args = append(args, s)
+			placeholders = append(placeholders, fmt.Sprintf("$%d", len(args)))
+		}
+		query += fmt.Sprintf(" AND status in (%s)", strings.Join(placeholders, ", "))
+	}
+
+	query += " order by id desc limit 1"
+
+	var row *sql.Row
+	if tx != nil {
+		row = tx.QueryRowContext(ctx, query, args...)
+	}else{
+		row = m.DB.QueryRowContext(ctx, query, args...)
+	}
+
+	return scanPayment(row)
+}
+
+func (m *payment) GetPaymentByReference(ctx context.Context, tx *sql.Tx, provider, reference string) (models.Payment, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := `select ` + paymentColumns + ` from payments where provider = $1 and reference = $2`
+
+	var row *sql.Row
+	if tx != nil {
+		row = tx.QueryRowContext(ctx, query, provider, reference)
+	}else{
+		row = m.DB.QueryRowContext(ctx, query, provider, reference)
+	}
+
+	return scanPayment(row)
+}
+
+// UpdatePayment saves the amounts, status and failure reason of a payment
+func (m *payment) UpdatePayment(ctx context.Context, tx *sql.Tx, p models.Payment) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	stmt := `
+		update
+			payments
+		set
+			captured_amount = $1, refunded_amount = $2, status = $3, failure_reason = $4, updated_at = $5
+		where
+			id = $6
+	`
+
+	var err error
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, stmt, p.CapturedAmount, p.RefundedAmount, p.Status, p.FailureReason, time.Now(), p.ID)
+	}else{
+		_, err = m.DB.ExecContext(ctx, stmt, p.CapturedAmount, p.RefundedAmount, p.Status, p.FailureReason, time.Now(), p.ID)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
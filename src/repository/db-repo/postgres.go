@@ -15,15 +15,20 @@ func (m *postgresDBRepo) InsertReservation(ctx context.Context, tx *sql.Tx, res
 
 	var newId int
 
-	stmt := `insert into reservations (first_name, last_name, email, phone, start_date,
-			 end_date, room_id, created_at, updated_at)
-			 values ($1, $2, $3, $4, $5, $6, $7, $8, $9) returning id`
+	stmt := `insert into reservations (confirmation_code, first_name, last_name, email, phone, start_date,
+			 end_date, room_id, adults, children, total_price, status, group_id, cancellation_policy, promo_code, discount, promo_discount_type, promo_discount_value,
+			 currency, base_currency, exchange_rate, guest_id, special_requests, created_at, updated_at)
+			 values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25) returning id`
 
-	var err error
+	policy, err := policySnapshot(res.CancellationPolicy)
+	if err != nil {
+		return 0, err
+	}
 
 	if tx != nil {
 		err = tx.QueryRowContext(
 			ctx, stmt, 
+			res.ConfirmationCode,
 			res.FirstName,
 			res.LastName,
 			res.Email,
@@ -31,12 +36,28 @@ func (m *postgresDBRepo) InsertReservation(ctx context.Context, tx *sql.Tx, res
 			res.StartDate,
 			res.EndDate,
 			res.RoomID,
+			res.Adults,
+			res.Children,
+			res.TotalPrice,
+			res.Status,
+			nullableInt(res.GroupID),
+			policy,
+			res.PromoCode,
+			res.Discount,
+			res.PromoDiscountType,
+			res.PromoDiscountValue,
+			res.Currency,
+			res.BaseCurrency,
+			res.ExchangeRate,
+			nullableInt(res.GuestID),
+			res.SpecialRequests,
 			time.Now(),
 			time.Now(),
 		).Scan(&newId)
 	}else{
 		err = m.DB.QueryRowContext(
 			ctx, stmt, 
+			res.ConfirmationCode,
 			res.FirstName,
 			res.LastName,
 			res.Email,
@@ -44,6 +65,21 @@ func (m *postgresDBRepo) InsertReservation(ctx context.Context, tx *sql.Tx, res
 			res.StartDate,
 			res.EndDate,
 			res.RoomID,
+			res.Adults,
+			res.Children,
+			res.TotalPrice,
+			res.Status,
+			nullableInt(res.GroupID),
+			policy,
+			res.PromoCode,
+			res.Discount,
+			res.PromoDiscountType,
+			res.PromoDiscountValue,
+			res.Currency,
+			res.BaseCurrency,
+			res.ExchangeRate,
+			nullableInt(res.GuestID),
+			res.SpecialRequests,
 			time.Now(),
 			time.Now(),
 		).Scan(&newId)
@@ -72,7 +108,7 @@ func (m *postgresDBRepo) InsertRoomRestriction(ctx context.Context, tx *sql.Tx,
 			r.StartDate,
 			r.EndDate,
 			r.RoomID,
-			r.ReservationID,
+			nullableInt(r.ReservationID),
 			r.RestrictionID,
 			time.Now(),
 			time.Now(),
@@ -83,7 +119,7 @@ func (m *postgresDBRepo) InsertRoomRestriction(ctx context.Context, tx *sql.Tx,
 			r.StartDate,
 			r.EndDate,
 			r.RoomID,
-			r.ReservationID,
+			nullableInt(r.ReservationID),
 			r.RestrictionID,
 			time.Now(),
 			time.Now(),
@@ -112,24 +148,31 @@ func (m *postgresDBRepo) SearchAvailabilityForDatesByRoomId(ctx context.Context,
 		where 
 			room_id = $1
 			and $2 < end_date and $3 > start_date
+			and (expires_at is null or expires_at > $4)
 	`
 
 	var row *sql.Row
 	if tx != nil {
-		row = tx.QueryRowContext(ctx, query, roomId, start, end)
+		row = tx.QueryRowContext(ctx, query, roomId, start, end, time.Now())
 	}else {
-		row = m.DB.QueryRowContext(ctx, query, roomId, start, end)
+		row = m.DB.QueryRowContext(ctx, query, roomId, start, end, time.Now())
 	}
 	err := row.Scan(&numRows)
 	if err != nil {
 		return false, err
 	}
 
-	if numRows == 0 {
-		return true, nil
+	if numRows > 0 {
+		return false, nil
+	}
+
+	// recurring blocks cannot be matched in SQL, their occurrences are expanded for the requested dates only
+	blocks, err := m.recurringBlocksBefore(ctx, tx, roomId, end)
+	if err != nil {
+		return false, err
 	}
 
-	return false, nil
+	return recurringBlocksAllow(blocks, start, end)
 }
 
 // SearchAvailabilityForAllRooms returns a slice of rooms for a given date range
@@ -141,20 +184,21 @@ func (m *postgresDBRepo) SearchAvailabilityForAllRooms(ctx context.Context, tx *
 
 	query := `
 		select 
-			r.id, r.room_name
+			r.id, r.room_name, r.base_rate, r.weekend_rate, r.currency, r.max_occupancy
 		from
 			rooms r
 		where
 			r.id not in 
-		(select rr.room_id from room_restrictions rr where $1 < rr.end_date and $2 > rr.start_date)
+		(select rr.room_id from room_restrictions rr where $1 < rr.end_date and $2 > rr.start_date
+			and (rr.expires_at is null or rr.expires_at > $3))
 	`
 
 	var rows *sql.Rows
 	var err error
 	if tx != nil {
-		rows, err = tx.QueryContext(ctx, query, start, end)
+		rows, err = tx.QueryContext(ctx, query, start, end, time.Now())
 	}else{	
-		rows, err = m.DB.QueryContext(ctx, query, start, end)
+		rows, err = m.DB.QueryContext(ctx, query, start, end, time.Now())
 	}
 	if err != nil {
 		return rooms, err
@@ -162,7 +206,7 @@ func (m *postgresDBRepo) SearchAvailabilityForAllRooms(ctx context.Context, tx *
 
 	for rows.Next(){
 		var room models.Room
-		err := rows.Scan(&room.ID, &room.RoomName)
+		err := rows.Scan(&room.ID, &room.RoomName, &room.BaseRate, &room.WeekendRate, &room.Currency, &room.MaxOccupancy)
 		if err != nil {
 			return rooms, err
 		}
@@ -173,8 +217,28 @@ func (m *postgresDBRepo) SearchAvailabilityForAllRooms(ctx context.Context, tx *
 		return rooms, err
 	}
 
+	blocks, err := m.recurringBlocksBefore(ctx, tx, 0, end)
+	if err != nil {
+		return rooms, err
+	}
+
+	byRoom := make(map[int][]models.RecurringBlock)
+	for _, b := range blocks {
+		byRoom[b.RoomID] = append(byRoom[b.RoomID], b)
+	}
+
+	available := make([]models.Room, 0, len(rooms))
+	for _, room := range rooms {
+		ok, err := recurringBlocksAllow(byRoom[room.ID], start, end)
+		if err != nil {
+			return rooms, err
+		}
+		if ok {
+			available = append(available, room)
+		}
+	}
 
-	return rooms, nil
+	return available, nil
 }
 
 func (m *postgresDBRepo) GetRoomById(ctx context.Context, tx *sql.Tx, id int) (models.Room, error) {
@@ -184,7 +248,7 @@ func (m *postgresDBRepo) GetRoomById(ctx context.Context, tx *sql.Tx, id int) (m
 	var room models.Room
 
 	query := `
-		select id, room_name, created_at, updated_at from rooms where id = $1
+		select id, room_name, base_rate, weekend_rate, currency, max_occupancy, created_at, updated_at from rooms where id = $1
 	`
 
 	var row *sql.Row
@@ -193,7 +257,7 @@ func (m *postgresDBRepo) GetRoomById(ctx context.Context, tx *sql.Tx, id int) (m
 	}else {
 		row = m.DB.QueryRowContext(ctx, query, id)
 	}
-	err := row.Scan(&room.ID, &room.RoomName, &room.CreatedAt, &room.UpdatedAt)
+	err := row.Scan(&room.ID, &room.RoomName, &room.BaseRate, &room.WeekendRate, &room.Currency, &room.MaxOccupancy, &room.CreatedAt, &room.UpdatedAt)
 
 	if err != nil {
 		return room, err
@@ -202,15 +266,11 @@ func (m *postgresDBRepo) GetRoomById(ctx context.Context, tx *sql.Tx, id int) (m
 	return room, nil
 }
 
-func (m *postgresDBRepo) GetAllRooms(ctx context.Context, tx *sql.Tx, id int, room_name string, created_at string, updated_at string) ([]models.Room, error){
-	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
-	defer cancel()
-
-	var rooms = make([]models.Room, 0)
-
+// roomsQuery selects the rooms matching the filters of the room list, zero values are not filtered on
+func roomsQuery(id int, room_name string, created_at string, updated_at string) (string, []interface{}) {
 	query := `
 		select 
-			id, room_name, created_at, updated_at 
+			id, room_name, base_rate, weekend_rate, currency, max_occupancy, created_at, updated_at 
 		from 
 			rooms
 		where
@@ -238,6 +298,17 @@ func (m *postgresDBRepo) GetAllRooms(ctx context.Context, tx *sql.Tx, id int, ro
 		args = append(args, updated_at)
 	}
 
+	return query, args
+}
+
+func (m *postgresDBRepo) GetAllRooms(ctx context.Context, tx *sql.Tx, id int, room_name string, created_at string, updated_at string) ([]models.Room, error){
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var rooms = make([]models.Room, 0)
+
+	query, args := roomsQuery(id, room_name, created_at, updated_at)
+
 	var rows *sql.Rows
 	var err error
 	if tx != nil {
@@ -251,7 +322,7 @@ func (m *postgresDBRepo) GetAllRooms(ctx context.Context, tx *sql.Tx, id int, ro
 
 	for rows.Next(){
 		var room models.Room
-		err := rows.Scan(&room.ID, &room.RoomName, &room.CreatedAt, &room.UpdatedAt)
+		err := rows.Scan(&room.ID, &room.RoomName, &room.BaseRate, &room.WeekendRate, &room.Currency, &room.MaxOccupancy, &room.CreatedAt, &room.UpdatedAt)
 		if err != nil {
 			return rooms, err
 		}
@@ -263,4 +334,57 @@ func (m *postgresDBRepo) GetAllRooms(ctx context.Context, tx *sql.Tx, id int, ro
 	}
 
 	return rooms, nil	
+}
+
+func (m *postgresDBRepo) InsertRoom(ctx context.Context, tx *sql.Tx, room models.Room) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var newId int
+
+	stmt := `insert into rooms (room_name, base_rate, weekend_rate, currency, max_occupancy, created_at, updated_at)
+			values ($1, $2, $3, $4, $5, $6, $7) returning id`
+
+	var err error
+	if tx != nil {
+		err = tx.QueryRowContext(ctx, stmt, room.RoomName, room.BaseRate, room.WeekendRate, room.Currency, room.MaxOccupancy, time.Now(), time.Now()).Scan(&newId)
+	}else{
+		err = m.DB.QueryRowContext(ctx, stmt, room.RoomName, room.BaseRate, room.WeekendRate, room.Currency, room.MaxOccupancy, time.Now(), time.Now()).Scan(&newId)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return newId, nil
+}
+
+// UpdateRoomOccupancy sets the most guests the room sleeps
+func (m *postgresDBRepo) UpdateRoomOccupancy(ctx context.Context, tx *sql.Tx, roomId, maxOccupancy int) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	stmt := `update rooms set max_occupancy = $1, updated_at = $2 where id = $3`
+
+	var result sql.Result
+	var err error
+	if tx != nil {
+		result, err = tx.ExecContext(ctx, stmt, maxOccupancy, time.Now(), roomId)
+	}else{
+		result, err = m.DB.ExecContext(ctx, stmt, maxOccupancy, time.Now(), roomId)
+	}
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
 }
\ No newline at end of file
@@ -0,0 +1,308 @@
+package dbrepo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+)
+
+const promoCodeColumns = `p.id, p.code, p.discount_type, p.discount_value, p.valid_from, p.valid_until, p.min_nights,
+			p.max_uses, p.max_uses_per_email, p.room_ids,
+			(select count(*) from promo_redemptions pr where pr.promo_code_id = p.id),
+			p.created_at, p.updated_at`
+
+func scanPromoCode(row interface{ Scan(dest ...interface{}) error }) (models.PromoCode, error) {
+	var p models.PromoCode
+	var validFrom, validUntil sql.NullTime
+	var roomIds string
+
+	err := row.Scan(
+		&p.ID,
+		&p.Code,
+		&p.DiscountType,
+		&p.DiscountValue,
+		&validFrom,
+		&validUntil,
+		&p.MinNights,
+		&p.MaxUses,
+		&p.MaxUsesPerEmail,
+		&roomIds,
+		&p.Uses,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+	if err != nil {
+		return p, err
+	}
+	p.ValidFrom = validFrom.Time
+	p.ValidUntil = validUntil.Time
+
+	err = json.Unmarshal([]byte(roomIds), &p.RoomIDs)
+	return p, err
+}
+
+func promoRoomIds(p models.PromoCode) (string, error) {
+	ids := p.RoomIDs
+	if ids == nil {
+		ids = []int{}
+	}
+
+	b, err := json.Marshal(ids)
+	return string(b), err
+}
+
+func (m *postgresDBRepo) queryPromoCode(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) (models.PromoCode, error) {
+	var row *sql.Row
+	if tx != nil {
+		row = tx.QueryRowContext(ctx, query, args...)
+	}else{
+		row = m.DB.QueryRowContext(ctx, query, args...)
+	}
+
+	return scanPromoCode(row)
+}
+
+func (m *postgresDBRepo) InsertPromoCode(ctx context.Context, tx *sql.Tx, p models.PromoCode) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var newId int
+
+	roomIds, err := promoRoomIds(p)
+	if err != nil {
+		return 0, err
+	}
+
+	stmt := `insert into promo_codes (code, discount_type, discount_value, valid_from, valid_until, min_nights,
+			max_uses, max_uses_per_email, room_ids, created_at, updated_at)
+			values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) returning id`
+
+	args := []interface{}{
+		p.Code,
+		p.DiscountType,
+		p.DiscountValue,
+		nullableTime(p.ValidFrom),
+		nullableTime(p.ValidUntil),
+		p.MinNights,
+		p.MaxUses,
+		p.MaxUsesPerEmail,
+		roomIds,
+		time.Now(),
+		time.Now(),
+	}
+
+	if tx != nil {
+		err = tx.QueryRowContext(ctx, stmt, args...).Scan(&newId)
+	}else{
+		err = m.DB.QueryRowContext(ctx, stmt, args...).Scan(&newId)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return newId, nil
+}
+
+func (m *postgresDBRepo) GetPromoCodes(ctx context.Context, tx *sql.Tx) ([]models.PromoCode, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var codes = make([]models.PromoCode, 0)
+
+	query := `select ` + promoCodeColumns + ` from promo_codes p order by p.id`
+
+	var rows *sql.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.QueryContext(ctx, query)
+	}else{
+		rows, err = m.DB.QueryContext(ctx, query)
+	}
+	if err != nil {
+		return codes, err
+	}
+	defer rows.Close()
+
+	for rows.Next(){
+		p, err := scanPromoCode(rows)
+		if err != nil {
+			return codes, err
+		}
+		codes = append(codes, p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return codes, err
+	}
+
+	return codes, nil
+}
+
+func (m *postgresDBRepo) GetPromoCodeById(ctx context.Context, tx *sql.Tx, id int) (models.PromoCode, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	return m.queryPromoCode(ctx, tx, `select ` + promoCodeColumns + ` from promo_codes p where p.id = $1`, id)
+}
+
+// GetPromoCodeByCode looks a code up as stored, callers normalize what guests type
+func (m *postgresDBRepo) GetPromoCodeByCode(ctx context.Context, tx *sql.Tx, code string) (models.PromoCode, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	return m.queryPromoCode(ctx, tx, `select ` + promoCodeColumns + ` from promo_codes p where p.code = $1`, code)
+}
+
+// LockPromoCode takes a row lock on the code so concurrent redemptions of it are serialized until the transaction
+// ends, then returns it. The uses are counted after the lock is held so they include every committed redemption
+func (m *postgresDBRepo) LockPromoCode(ctx context.Context, tx *sql.Tx, code string) (models.PromoCode, error) {
+	lockCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var id int
+
+	query := `select id from promo_codes where code = $1 for update`
+
+	var err error
+	if tx != nil {
+		err = tx.QueryRowContext(lockCtx, query, code).Scan(&id)
+	}else{
+		err = m.DB.QueryRowContext(lockCtx, query, code).Scan(&id)
+	}
+
+	if err != nil {
+		return models.PromoCode{}, err
+	}
+
+	return m.GetPromoCodeById(ctx, tx, id)
+}
+
+func (m *postgresDBRepo) UpdatePromoCode(ctx context.Context, tx *sql.Tx, p models.PromoCode) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	roomIds, err := promoRoomIds(p)
+	if err != nil {
+		return err
+	}
+
+	stmt := `update promo_codes set code = $1, discount_type = $2, discount_value = $3, valid_from = $4,
+			valid_until = $5, min_nights = $6, max_uses = $7, max_uses_per_email = $8, room_ids = $9, updated_at = $10
+			where id = $11`
+
+	args := []interface{}{
+		p.Code,
+		p.DiscountType,
+		p.DiscountValue,
+		nullableTime(p.ValidFrom),
+		nullableTime(p.ValidUntil),
+		p.MinNights,
+		p.MaxUses,
+		p.MaxUsesPerEmail,
+		roomIds,
+		time.Now(),
+		p.ID,
+	}
+
+	var result sql.Result
+	if tx != nil {
+		result, err = tx.ExecContext(ctx, stmt, args...)
+	}else{
+		result, err = m.DB.ExecContext(ctx, stmt, args...)
+	}
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// DeletePromoCode removes the code and its redemptions. Reservations keep the code and discount they were booked with
+func (m *postgresDBRepo) DeletePromoCode(ctx context.Context, tx *sql.Tx, id int) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	stmt := `delete from promo_codes where id = $1`
+
+	var result sql.Result
+	var err error
+	if tx != nil {
+		result, err = tx.ExecContext(ctx, stmt, id)
+	}else{
+		result, err = m.DB.ExecContext(ctx, stmt, id)
+	}
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// CountPromoRedemptionsByEmail returns how many times the guest with the email key has used the code
+func (m *postgresDBRepo) CountPromoRedemptionsByEmail(ctx context.Context, tx *sql.Tx, promoCodeId int, emailKey string) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var count int
+
+	query := `select count(*) from promo_redemptions where promo_code_id = $1 and email_key = $2`
+
+	var err error
+	if tx != nil {
+		err = tx.QueryRowContext(ctx, query, promoCodeId, emailKey).Scan(&count)
+	}else{
+		err = m.DB.QueryRowContext(ctx, query, promoCodeId, emailKey).Scan(&count)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// InsertPromoRedemption records a use of a code. Insert it in the transaction that locked the code with LockPromoCode
+func (m *postgresDBRepo) InsertPromoRedemption(ctx context.Context, tx *sql.Tx, r models.PromoRedemption) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var newId int
+
+	stmt := `insert into promo_redemptions (promo_code_id, reservation_id, email, email_key, discount, created_at, updated_at)
+			values ($1, $2, $3, $4, $5, $6, $7) returning id`
+
+	var err error
+	if tx != nil {
+		err = tx.QueryRowContext(ctx, stmt, r.PromoCodeID, r.ReservationID, r.Email, r.EmailKey, r.Discount, time.Now(), time.Now()).Scan(&newId)
+	}else{
+		err = m.DB.QueryRowContext(ctx, stmt, r.PromoCodeID, r.ReservationID, r.Email, r.EmailKey, r.Discount, time.Now(), time.Now()).Scan(&newId)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return newId, nil
+}
@@ -0,0 +1,178 @@
+package dbrepo
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+	"github.com/Orololuwa/go-backend-boilerplate/src/money"
+)
+
+// UpdateRoomRates sets the default weekday and weekend prices of a room. An empty currency keeps the room's currency
+func (m *postgresDBRepo) UpdateRoomRates(ctx context.Context, tx *sql.Tx, roomId int, baseRate, weekendRate money.Amount, currency string) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	stmt := `update rooms set base_rate = $1, weekend_rate = $2, currency = coalesce(nullif($3, ''), currency), updated_at = $4 where id = $5`
+
+	var result sql.Result
+	var err error
+	if tx != nil {
+		result, err = tx.ExecContext(ctx, stmt, baseRate, weekendRate, currency, time.Now(), roomId)
+	}else{
+		result, err = m.DB.ExecContext(ctx, stmt, baseRate, weekendRate, currency, time.Now(), roomId)
+	}
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (m *postgresDBRepo) InsertRoomRate(ctx context.Context, tx *sql.Tx, rate models.RoomRate) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var newId int
+
+	stmt := `insert into room_rates (room_id, rate_name, start_date, end_date, nightly_rate,
+			weekend_rate, cancellation_policy_id, created_at, updated_at)
+			values ($1, $2, $3, $4, $5, $6, $7, $8, $9) returning id`
+
+	var err error
+	if tx != nil {
+		err = tx.QueryRowContext(
+			ctx, stmt,
+			rate.RoomID,
+			rate.RateName,
+			rate.StartDate,
+			rate.EndDate,
+			rate.NightlyRate,
+			rate.WeekendRate,
+			nullableInt(rate.CancellationPolicyID),
+			time.Now(),
+			time.Now(),
+		).Scan(&newId)
+	}else{
+		err = m.DB.QueryRowContext(
+			ctx, stmt,
+			rate.RoomID,
+			rate.RateName,
+			rate.StartDate,
+			rate.EndDate,
+			rate.NightlyRate,
+			rate.WeekendRate,
+			nullableInt(rate.CancellationPolicyID),
+			time.Now(),
+			time.Now(),
+		).Scan(&newId)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return newId, nil
+}
+
+// GetRoomRatesForDates returns the seasonal rates overlapping the date range. A roomId of 0 returns rates for every room
+func (m *postgresDBRepo) GetRoomRatesForDates(ctx context.Context, tx *sql.Tx, roomId int, start, end time.Time) ([]models.RoomRate, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var rates = make([]models.RoomRate, 0)
+
+	query := `
+		select
+			id, room_id, rate_name, start_date, end_date, nightly_rate, weekend_rate,
+			coalesce(cancellation_policy_id, 0), created_at, updated_at
+		from
+			room_rates
+		where
+			$1 < end_date and $2 > start_date
+			and ($3 = 0 or room_id = $3)
+		order by
+			room_id, start_date
+	`
+
+	var rows *sql.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.QueryContext(ctx, query, start, end, roomId)
+	}else{
+		rows, err = m.DB.QueryContext(ctx, query, start, end, roomId)
+	}
+	if err != nil {
+		return rates, err
+	}
+	defer rows.Close()
+
+	for rows.Next(){
+		var rate models.RoomRate
+		err := rows.Scan(
+			&rate.ID,
+			&rate.RoomID,
+			&rate.RateName,
+			&rate.StartDate,
+			&rate.EndDate,
+			&rate.NightlyRate,
+			&rate.WeekendRate,
+			&rate.CancellationPolicyID,
+			&rate.CreatedAt,
+			&rate.UpdatedAt,
+		)
+		if err != nil {
+			return rates, err
+		}
+		rates = append(rates, rate)
+	}
+
+	if err = rows.Err(); err != nil {
+		return rates, err
+	}
+
+	return rates, nil
+}
+
+func (m *postgresDBRepo) GetRoomRatesByRoomId(ctx context.Context, tx *sql.Tx, roomId int) ([]models.RoomRate, error) {
+	return m.GetRoomRatesForDates(ctx, tx, roomId, time.Time{}, time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC))
+}
+
+func (m *postgresDBRepo) DeleteRoomRate(ctx context.Context, tx *sql.Tx, roomId, id int) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	stmt := `delete from room_rates where id = $1 and room_id = $2`
+
+	var result sql.Result
+	var err error
+	if tx != nil {
+		result, err = tx.ExecContext(ctx, stmt, id, roomId)
+	}else{
+		result, err = m.DB.ExecContext(ctx, stmt, id, roomId)
+	}
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
@@ -0,0 +1,218 @@
+package dbrepo
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+)
+
+// the exceptions are aggregated into one column so a block is read in a single row
+const recurringBlockQuery = `
+	select
+		rb.id, rb.room_id, rb.restriction_id, rb.start_date, rb.nights, rb.rrule, rb.note, rb.created_at, rb.updated_at,
+		r.restriction_name,
+		coalesce((
+			select string_agg(to_char(e.occurrence_date, 'YYYY-MM-DD'), ',' order by e.occurrence_date)
+			from recurring_block_exceptions e where e.recurring_block_id = rb.id
+		), '')
+	from
+		recurring_blocks rb
+		join restrictions r on (r.id = rb.restriction_id)
+`
+
+func scanRecurringBlock(row interface{ Scan(dest ...interface{}) error }) (models.RecurringBlock, error) {
+	var b models.RecurringBlock
+	var exceptions string
+
+	err := row.Scan(
+		&b.ID,
+		&b.RoomID,
+		&b.RestrictionID,
+		&b.StartDate,
+		&b.Nights,
+		&b.RRule,
+		&b.Note,
+		&b.CreatedAt,
+		&b.UpdatedAt,
+		&b.Restriction.RestrictionName,
+		&exceptions,
+	)
+	if err != nil {
+		return b, err
+	}
+	b.Restriction.ID = b.RestrictionID
+
+	b.Exceptions = make([]time.Time, 0)
+	for _, v := range strings.Split(exceptions, ",") {
+		if v == "" {
+			continue
+		}
+		day, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return b, err
+		}
+		b.Exceptions = append(b.Exceptions, day)
+	}
+
+	return b, nil
+}
+
+func (m *postgresDBRepo) queryRecurringBlocks(ctx context.Context, tx *sql.Tx, where string, args ...interface{}) ([]models.RecurringBlock, error) {
+	var blocks = make([]models.RecurringBlock, 0)
+
+	query := recurringBlockQuery + where + ` order by rb.id`
+
+	var rows *sql.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.QueryContext(ctx, query, args...)
+	}else{
+		rows, err = m.DB.QueryContext(ctx, query, args...)
+	}
+	if err != nil {
+		return blocks, err
+	}
+	defer rows.Close()
+
+	for rows.Next(){
+		b, err := scanRecurringBlock(rows)
+		if err != nil {
+			return blocks, err
+		}
+		blocks = append(blocks, b)
+	}
+
+	if err = rows.Err(); err != nil {
+		return blocks, err
+	}
+
+	return blocks, nil
+}
+
+// recurringBlocksBefore returns the recurring blocks of the room starting before end, of every room when roomId is 0.
+// The others cannot have an occurrence before end
+func (m *postgresDBRepo) recurringBlocksBefore(ctx context.Context, tx *sql.Tx, roomId int, end time.Time) ([]models.RecurringBlock, error) {
+	if roomId == 0 {
+		return m.queryRecurringBlocks(ctx, tx, `where rb.start_date < $1`, end)
+	}
+
+	return m.queryRecurringBlocks(ctx, tx, `where rb.room_id = $1 and rb.start_date < $2`, roomId, end)
+}
+
+// recurringBlocksAllow reports whether none of the blocks has an occurrence between start and end
+func recurringBlocksAllow(blocks []models.RecurringBlock, start, end time.Time) (bool, error) {
+	for _, b := range blocks {
+		occurrences, err := b.Occurrences(start, end)
+		if err != nil {
+			return false, err
+		}
+		if len(occurrences) > 0 {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func (m *postgresDBRepo) InsertRecurringBlock(ctx context.Context, tx *sql.Tx, b models.RecurringBlock) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var newId int
+
+	stmt := `insert into recurring_blocks (room_id, restriction_id, start_date, nights, rrule, note, created_at, updated_at)
+			values ($1, $2, $3, $4, $5, $6, $7, $8) returning id`
+
+	args := []interface{}{b.RoomID, b.RestrictionID, b.StartDate, b.Nights, b.RRule, b.Note, time.Now(), time.Now()}
+
+	var err error
+	if tx != nil {
+		err = tx.QueryRowContext(ctx, stmt, args...).Scan(&newId)
+	}else{
+		err = m.DB.QueryRowContext(ctx, stmt, args...).Scan(&newId)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return newId, nil
+}
+
+func (m *postgresDBRepo) GetRecurringBlockById(ctx context.Context, tx *sql.Tx, id int) (models.RecurringBlock, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := recurringBlockQuery + `where rb.id = $1`
+
+	var row *sql.Row
+	if tx != nil {
+		row = tx.QueryRowContext(ctx, query, id)
+	}else{
+		row = m.DB.QueryRowContext(ctx, query, id)
+	}
+
+	return scanRecurringBlock(row)
+}
+
+func (m *postgresDBRepo) GetRecurringBlocksByRoomId(ctx context.Context, tx *sql.Tx, roomId int) ([]models.RecurringBlock, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	return m.queryRecurringBlocks(ctx, tx, `where rb.room_id = $1`, roomId)
+}
+
+// DeleteRecurringBlock removes the block of the room together with its exceptions
+func (m *postgresDBRepo) DeleteRecurringBlock(ctx context.Context, tx *sql.Tx, roomId, id int) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	stmt := `delete from recurring_blocks where id = $1 and room_id = $2`
+
+	var result sql.Result
+	var err error
+	if tx != nil {
+		result, err = tx.ExecContext(ctx, stmt, id, roomId)
+	}else{
+		result, err = m.DB.ExecContext(ctx, stmt, id, roomId)
+	}
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// InsertRecurringBlockException removes one occurrence of the block. Removing it twice is not an error
+func (m *postgresDBRepo) InsertRecurringBlockException(ctx context.Context, tx *sql.Tx, blockId int, occurrence time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	stmt := `insert into recurring_block_exceptions (recurring_block_id, occurrence_date, created_at, updated_at)
+			values ($1, $2, $3, $4) on conflict (recurring_block_id, occurrence_date) do nothing`
+
+	var err error
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, stmt, blockId, occurrence, time.Now(), time.Now())
+	}else{
+		_, err = m.DB.ExecContext(ctx, stmt, blockId, occurrence, time.Now(), time.Now())
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+package dbrepo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+	"github.com/Orololuwa/go-backend-boilerplate/src/money"
+)
+
+// exponentValues lists the currencies whose minor unit is not a hundredth as a values list, so a query can convert
+// amounts between currencies the way money.Convert does
+func exponentValues() string {
+	exponents := money.Exponents()
+
+	currencies := make([]string, 0, len(exponents))
+	for c := range exponents {
+		currencies = append(currencies, c)
+	}
+	sort.Strings(currencies)
+
+	values := make([]string, len(currencies))
+	for i, c := range currencies {
+		values[i] = fmt.Sprintf("('%s', %d)", c, exponents[c])
+	}
+	return "values " + strings.Join(values, ", ")
+}
+
+// GetRoomReports returns the counts of every room between start and end, end exclusive. Booked nights are counted
+// from the reservations that are not cancelled or no-shows, blocked nights from the staff and imported blocks, one row per night
+// with generate_series. A booking's price is spread evenly over its nights, the taxes and fees stored with it are
+// taken out of the revenue and counted apart. Bookings charged in another currency are converted back into the
+// room's currency, their base currency, at the rate they were booked at
+func (m *postgresDBRepo) GetRoomReports(ctx context.Context, tx *sql.Tx, start, end time.Time) ([]models.RoomReport, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var reports = make([]models.RoomReport, 0)
+
+	query := `
+		with exponents (currency, exponent) as (` + exponentValues() + `)
+		select
+			r.id, r.room_name, r.currency,
+			coalesce(sold.nights, 0), coalesce(blocked.nights, 0), coalesce(sold.revenue, 0), coalesce(sold.charges, 0),
+			coalesce(stays.reservations, 0), coalesce(stays.nights, 0), coalesce(stays.lead_days, 0)
+		from
+			rooms r
+			left join (
+				select
+					res.room_id, count(*) as nights,
+					round(sum((res.total_price - coalesce(ch.amount, 0))::numeric / (res.end_date - res.start_date) / fx.factor))::bigint as revenue,
+					round(sum(coalesce(ch.amount, 0)::numeric / (res.end_date - res.start_date) / fx.factor))::bigint as charges
+				from
+					reservations res
+					left join (
+						select reservation_id, sum(amount) as amount from reservation_charges group by reservation_id
+					) ch on (ch.reservation_id = res.id)
+					cross join lateral (
+						select coalesce(nullif(res.exchange_rate, 0), 1000000)::numeric / 1000000 * power(10::numeric,
+							coalesce((select exponent from exponents where currency = res.currency), 2)
+							- coalesce((select exponent from exponents where currency = res.base_currency), 2)) as factor
+					) fx
+					cross join lateral generate_series(
+						greatest(res.start_date, $1::date), least(res.end_date, $2::date) - 1, interval '1 day'
+					) night
+				where
+					res.status not in ($3, $4) and res.start_date < $2 and res.end_date > $1
+				group by
+					res.room_id
+			) sold on (sold.room_id = r.id)
+			left join (
+				select
+					rr.room_id, count(distinct night) as nights
+				from
+					room_restrictions rr
+					cross join lateral generate_series(
+						greatest(rr.start_date, $1::date), least(rr.end_date, $2::date) - 1, interval '1 day'
+					) night
+				where
+					rr.reservation_id is null and rr.hold_token is null
+					and rr.start_date < $2 and rr.end_date > $1
+				group by
+					rr.room_id
+			) blocked on (blocked.room_id = r.id)
+			left join (
+				select
+					room_id, count(*) as reservations, sum(end_date - start_date) as nights,
+					sum(greatest(start_date - created_at::date, 0)) as lead_days
+				from
+					reservations
+				where
+					status not in ($3, $4) and start_date >= $1 and start_date < $2
+				group by
+					room_id
+			) stays on (stays.room_id = r.id)
+		order by
+			r.id
+	`
+
+	var rows *sql.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.QueryContext(ctx, query, start, end, models.ReservationStatusCancelled, models.ReservationStatusNoShow)
+	}else{
+		rows, err = m.DB.QueryContext(ctx, query, start, end, models.ReservationStatusCancelled, models.ReservationStatusNoShow)
+	}
+	if err != nil {
+		return reports, err
+	}
+	defer rows.Close()
+
+	for rows.Next(){
+		var report models.RoomReport
+		err := rows.Scan(
+			&report.RoomID,
+			&report.RoomName,
+			&report.Currency,
+			&report.SoldNights,
+			&report.BlockedNights,
+			&report.Revenue,
+			&report.Charges,
+			&report.Reservations,
+			&report.StayNights,
+			&report.LeadDays,
+		)
+		if err != nil {
+			return reports, err
+		}
+		reports = append(reports, report)
+	}
+
+	if err = rows.Err(); err != nil {
+		return reports, err
+	}
+
+	return reports, nil
+}
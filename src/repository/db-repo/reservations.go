@@ -0,0 +1,402 @@
+package dbrepo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+)
+
+func (m *postgresDBRepo) ConfirmationCodeExists(ctx context.Context, tx *sql.Tx, code string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var exists bool
+
+	// reservations and groups share one code space so a guest can never confuse the two
+	query := `
+		select
+			exists(select 1 from reservations where confirmation_code = $1)
+			or exists(select 1 from reservation_groups where confirmation_code = $1)
+	`
+
+	var err error
+	if tx != nil {
+		err = tx.QueryRowContext(ctx, query, code).Scan(&exists)
+	}else{
+		err = m.DB.QueryRowContext(ctx, query, code).Scan(&exists)
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+// GetReservationByConfirmationCode returns the reservation only when both the code and the guest's last name match
+func (m *postgresDBRepo) GetReservationByConfirmationCode(ctx context.Context, tx *sql.Tx, code, lastName string) (models.Reservation, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var res models.Reservation
+
+	query := `
+		select
+			r.id, r.confirmation_code, r.first_name, r.last_name, r.email, r.phone,
+			r.start_date, r.end_date, r.room_id, r.adults, r.children, r.total_price, r.status,
+			r.cancellation_policy, r.cancellation_fee, r.refund_amount, r.promo_code, r.discount, r.promo_discount_type, r.promo_discount_value,
+			r.currency, r.base_currency, r.exchange_rate, coalesce(r.guest_id, 0), r.special_requests, r.requests_resolved, r.created_at, r.updated_at,
+			rm.id, rm.room_name
+		from
+			reservations r
+			left join rooms rm on (rm.id = r.room_id)
+		where
+			r.confirmation_code = $1 and lower(r.last_name) = lower($2)
+	`
+
+	var row *sql.Row
+	if tx != nil {
+		row = tx.QueryRowContext(ctx, query, code, lastName)
+	}else{
+		row = m.DB.QueryRowContext(ctx, query, code, lastName)
+	}
+
+	err := row.Scan(
+		&res.ID,
+		&res.ConfirmationCode,
+		&res.FirstName,
+		&res.LastName,
+		&res.Email,
+		&res.Phone,
+		&res.StartDate,
+		&res.EndDate,
+		&res.RoomID,
+		&res.Adults,
+		&res.Children,
+		&res.TotalPrice,
+		&res.Status,
+		policyColumn{&res.CancellationPolicy},
+		&res.CancellationFee,
+		&res.RefundAmount,
+		&res.PromoCode,
+		&res.Discount,
+		&res.PromoDiscountType,
+		&res.PromoDiscountValue,
+		&res.Currency,
+		&res.BaseCurrency,
+		&res.ExchangeRate,
+		&res.GuestID,
+		&res.SpecialRequests,
+		&res.RequestsResolved,
+		&res.CreatedAt,
+		&res.UpdatedAt,
+		&res.Room.ID,
+		&res.Room.RoomName,
+	)
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+// LockReservation locks the reservation row until the transaction ends, so payments for it are taken and refunded
+// one at a time
+func (m *postgresDBRepo) LockReservation(ctx context.Context, tx *sql.Tx, id int) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var lockedId int
+
+	query := `select id from reservations where id = $1 for update`
+
+	var err error
+	if tx != nil {
+		err = tx.QueryRowContext(ctx, query, id).Scan(&lockedId)
+	}else{
+		err = m.DB.QueryRowContext(ctx, query, id).Scan(&lockedId)
+	}
+
+	return err
+}
+
+func (m *postgresDBRepo) GetReservationById(ctx context.Context, tx *sql.Tx, id int) (models.Reservation, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var res models.Reservation
+
+	query := `
+		select
+			r.id, r.confirmation_code, r.first_name, r.last_name, r.email, r.phone,
+			r.start_date, r.end_date, r.room_id, r.adults, r.children, r.total_price, r.status,
+			r.cancellation_policy, r.cancellation_fee, r.refund_amount, r.promo_code, r.discount, r.promo_discount_type, r.promo_discount_value,
+			r.currency, r.base_currency, r.exchange_rate, coalesce(r.guest_id, 0), r.special_requests, r.requests_resolved, r.created_at, r.updated_at,
+			rm.id, rm.room_name
+		from
+			reservations r
+			left join rooms rm on (rm.id = r.room_id)
+		where
+			r.id = $1
+	`
+
+	var row *sql.Row
+	if tx != nil {
+		row = tx.QueryRowContext(ctx, query, id)
+	}else{
+		row = m.DB.QueryRowContext(ctx, query, id)
+	}
+
+	err := row.Scan(
+		&res.ID,
+		&res.ConfirmationCode,
+		&res.FirstName,
+		&res.LastName,
+		&res.Email,
+		&res.Phone,
+		&res.StartDate,
+		&res.EndDate,
+		&res.RoomID,
+		&res.Adults,
+		&res.Children,
+		&res.TotalPrice,
+		&res.Status,
+		policyColumn{&res.CancellationPolicy},
+		&res.CancellationFee,
+		&res.RefundAmount,
+		&res.PromoCode,
+		&res.Discount,
+		&res.PromoDiscountType,
+		&res.PromoDiscountValue,
+		&res.Currency,
+		&res.BaseCurrency,
+		&res.ExchangeRate,
+		&res.GuestID,
+		&res.SpecialRequests,
+		&res.RequestsResolved,
+		&res.CreatedAt,
+		&res.UpdatedAt,
+		&res.Room.ID,
+		&res.Room.RoomName,
+	)
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+func (m *postgresDBRepo) UpdateReservationStatus(ctx context.Context, tx *sql.Tx, id int, status string) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	stmt := `update reservations set status = $1, updated_at = $2 where id = $3`
+
+	var err error
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, stmt, status, time.Now(), id)
+	}else{
+		_, err = m.DB.ExecContext(ctx, stmt, status, time.Now(), id)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateReservationRequestsResolved marks whether staff have seen to the special requests of the reservation
+func (m *postgresDBRepo) UpdateReservationRequestsResolved(ctx context.Context, tx *sql.Tx, id int, resolved bool) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	stmt := `update reservations set requests_resolved = $1, updated_at = $2 where id = $3`
+
+	var err error
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, stmt, resolved, time.Now(), id)
+	}else{
+		_, err = m.DB.ExecContext(ctx, stmt, resolved, time.Now(), id)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateReservationStay saves new dates and the price that goes with them
+func (m *postgresDBRepo) UpdateReservationStay(ctx context.Context, tx *sql.Tx, res models.Reservation) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	stmt := `update reservations set start_date = $1, end_date = $2, total_price = $3, discount = $4, updated_at = $5 where id = $6`
+
+	var err error
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, stmt, res.StartDate, res.EndDate, res.TotalPrice, res.Discount, time.Now(), res.ID)
+	}else{
+		_, err = m.DB.ExecContext(ctx, stmt, res.StartDate, res.EndDate, res.TotalPrice, res.Discount, time.Now(), res.ID)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteRoomRestrictionsByReservationId releases the room dates held by a reservation
+func (m *postgresDBRepo) DeleteRoomRestrictionsByReservationId(ctx context.Context, tx *sql.Tx, reservationId int) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	stmt := `delete from room_restrictions where reservation_id = $1`
+
+	var err error
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, stmt, reservationId)
+	}else{
+		_, err = m.DB.ExecContext(ctx, stmt, reservationId)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// reservationsQuery selects the reservations matching the filter, newest stays first
+func reservationsQuery(filter models.ReservationFilter) (string, []interface{}) {
+	query := `
+		select
+			r.id, r.confirmation_code, r.first_name, r.last_name, r.email, r.phone,
+			r.start_date, r.end_date, r.room_id, r.adults, r.children, r.total_price, r.status,
+			coalesce(r.group_id, 0), r.cancellation_policy, r.cancellation_fee, r.refund_amount, r.promo_code, r.discount, r.promo_discount_type, r.promo_discount_value,
+			r.currency, r.base_currency, r.exchange_rate, coalesce(r.guest_id, 0), r.special_requests, r.requests_resolved, r.created_at, r.updated_at,
+			rm.id, rm.room_name
+		from
+			reservations r
+			left join rooms rm on (rm.id = r.room_id)
+		where
+			1=1
+	`
+	args := []interface{}{}
+
+	if filter.RoomID != 0 {
+		query += fmt.Sprintf(" AND r.room_id = $%d", len(args)+1)
+		args = append(args, filter.RoomID)
+	}
+
+	if filter.GuestID != 0 {
+		query += fmt.Sprintf(" AND r.guest_id = $%d", len(args)+1)
+		args = append(args, filter.GuestID)
+	}
+
+	if filter.Status != "" {
+		query += fmt.Sprintf(" AND r.status = $%d", len(args)+1)
+		args = append(args, filter.Status)
+	}
+
+	if !filter.Start.IsZero() {
+		query += fmt.Sprintf(" AND r.end_date > $%d", len(args)+1)
+		args = append(args, filter.Start)
+	}
+
+	if !filter.End.IsZero() {
+		query += fmt.Sprintf(" AND r.start_date < $%d", len(args)+1)
+		args = append(args, filter.End)
+	}
+
+	if !filter.ArrivingFrom.IsZero() {
+		query += fmt.Sprintf(" AND r.start_date >= $%d", len(args)+1)
+		args = append(args, filter.ArrivingFrom)
+	}
+
+	// a request of a stay that will not happen needs nothing done
+	if filter.UnresolvedRequests {
+		query += fmt.Sprintf(" AND r.special_requests <> '' AND NOT r.requests_resolved AND r.status in ('%s', '%s')", models.ReservationStatusPending, models.ReservationStatusConfirmed)
+	}
+
+	query += " order by r.start_date desc, r.id desc"
+
+	return query, args
+}
+
+func scanReservation(rows *sql.Rows, res *models.Reservation) error {
+	return rows.Scan(
+		&res.ID,
+		&res.ConfirmationCode,
+		&res.FirstName,
+		&res.LastName,
+		&res.Email,
+		&res.Phone,
+		&res.StartDate,
+		&res.EndDate,
+		&res.RoomID,
+		&res.Adults,
+		&res.Children,
+		&res.TotalPrice,
+		&res.Status,
+		&res.GroupID,
+		policyColumn{&res.CancellationPolicy},
+		&res.CancellationFee,
+		&res.RefundAmount,
+		&res.PromoCode,
+		&res.Discount,
+		&res.PromoDiscountType,
+		&res.PromoDiscountValue,
+		&res.Currency,
+		&res.BaseCurrency,
+		&res.ExchangeRate,
+		&res.GuestID,
+		&res.SpecialRequests,
+		&res.RequestsResolved,
+		&res.CreatedAt,
+		&res.UpdatedAt,
+		&res.Room.ID,
+		&res.Room.RoomName,
+	)
+}
+
+func (m *postgresDBRepo) GetReservations(ctx context.Context, tx *sql.Tx, filter models.ReservationFilter) ([]models.Reservation, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var reservations = make([]models.Reservation, 0)
+
+	query, args := reservationsQuery(filter)
+
+	var rows *sql.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.QueryContext(ctx, query, args...)
+	}else{
+		rows, err = m.DB.QueryContext(ctx, query, args...)
+	}
+	if err != nil {
+		return reservations, err
+	}
+	defer rows.Close()
+
+	for rows.Next(){
+		var res models.Reservation
+		err := scanReservation(rows, &res)
+		if err != nil {
+			return reservations, err
+		}
+		reservations = append(reservations, res)
+	}
+
+	if err = rows.Err(); err != nil {
+		return reservations, err
+	}
+
+	return reservations, nil
+}
@@ -0,0 +1,293 @@
+package dbrepo
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+)
+
+func (m *postgresDBRepo) GetRestrictions(ctx context.Context, tx *sql.Tx) ([]models.Restriction, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var restrictions = make([]models.Restriction, 0)
+
+	query := `select id, restriction_name, created_at, updated_at from restrictions order by id`
+
+	var rows *sql.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.QueryContext(ctx, query)
+	}else{
+		rows, err = m.DB.QueryContext(ctx, query)
+	}
+	if err != nil {
+		return restrictions, err
+	}
+	defer rows.Close()
+
+	for rows.Next(){
+		var r models.Restriction
+		err := rows.Scan(&r.ID, &r.RestrictionName, &r.CreatedAt, &r.UpdatedAt)
+		if err != nil {
+			return restrictions, err
+		}
+		restrictions = append(restrictions, r)
+	}
+
+	if err = rows.Err(); err != nil {
+		return restrictions, err
+	}
+
+	return restrictions, nil
+}
+
+func (m *postgresDBRepo) GetRestrictionById(ctx context.Context, tx *sql.Tx, id int) (models.Restriction, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var r models.Restriction
+
+	query := `select id, restriction_name, created_at, updated_at from restrictions where id = $1`
+
+	var row *sql.Row
+	if tx != nil {
+		row = tx.QueryRowContext(ctx, query, id)
+	}else{
+		row = m.DB.QueryRowContext(ctx, query, id)
+	}
+
+	err := row.Scan(&r.ID, &r.RestrictionName, &r.CreatedAt, &r.UpdatedAt)
+	if err != nil {
+		return r, err
+	}
+
+	return r, nil
+}
+
+func (m *postgresDBRepo) InsertRestriction(ctx context.Context, tx *sql.Tx, r models.Restriction) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var newId int
+
+	stmt := `insert into restrictions (restriction_name, created_at, updated_at) values ($1, $2, $3) returning id`
+
+	var err error
+	if tx != nil {
+		err = tx.QueryRowContext(ctx, stmt, r.RestrictionName, time.Now(), time.Now()).Scan(&newId)
+	}else{
+		err = m.DB.QueryRowContext(ctx, stmt, r.RestrictionName, time.Now(), time.Now()).Scan(&newId)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return newId, nil
+}
+
+func (m *postgresDBRepo) UpdateRestriction(ctx context.Context, tx *sql.Tx, r models.Restriction) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	stmt := `update restrictions set restriction_name = $1, updated_at = $2 where id = $3`
+
+	var result sql.Result
+	var err error
+	if tx != nil {
+		result, err = tx.ExecContext(ctx, stmt, r.RestrictionName, time.Now(), r.ID)
+	}else{
+		result, err = m.DB.ExecContext(ctx, stmt, r.RestrictionName, time.Now(), r.ID)
+	}
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (m *postgresDBRepo) DeleteRestriction(ctx context.Context, tx *sql.Tx, id int) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	stmt := `delete from restrictions where id = $1`
+
+	var result sql.Result
+	var err error
+	if tx != nil {
+		result, err = tx.ExecContext(ctx, stmt, id)
+	}else{
+		result, err = m.DB.ExecContext(ctx, stmt, id)
+	}
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// RestrictionInUse reports whether any room restriction or recurring block still references the restriction type
+func (m *postgresDBRepo) RestrictionInUse(ctx context.Context, tx *sql.Tx, id int) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var exists bool
+
+	query := `
+		select
+			exists(select 1 from room_restrictions where restriction_id = $1)
+			or exists(select 1 from recurring_blocks where restriction_id = $1)
+	`
+
+	var err error
+	if tx != nil {
+		err = tx.QueryRowContext(ctx, query, id).Scan(&exists)
+	}else{
+		err = m.DB.QueryRowContext(ctx, query, id).Scan(&exists)
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+// InsertRoomBlock inserts a staff block, a restriction without a reservation, and returns its id
+func (m *postgresDBRepo) InsertRoomBlock(ctx context.Context, tx *sql.Tx, r models.RoomRestriction) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var newId int
+
+	stmt := `insert into room_restrictions (start_date, end_date, room_id, restriction_id, note, created_at, updated_at)
+			values ($1, $2, $3, $4, $5, $6, $7) returning id`
+
+	args := []interface{}{r.StartDate, r.EndDate, r.RoomID, r.RestrictionID, r.Note, time.Now(), time.Now()}
+
+	var err error
+	if tx != nil {
+		err = tx.QueryRowContext(ctx, stmt, args...).Scan(&newId)
+	}else{
+		err = m.DB.QueryRowContext(ctx, stmt, args...).Scan(&newId)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return newId, nil
+}
+
+// staffBlockCondition matches the restrictions staff created by hand, as opposed to reservations, holds and imported blocks
+const staffBlockCondition = `rr.reservation_id is null and rr.hold_token is null and rr.feed_id is null`
+
+// GetRoomBlocks returns the staff blocks of the room overlapping the window
+func (m *postgresDBRepo) GetRoomBlocks(ctx context.Context, tx *sql.Tx, roomId int, start, end time.Time) ([]models.RoomRestriction, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var blocks = make([]models.RoomRestriction, 0)
+
+	query := `
+		select
+			rr.id, rr.start_date, rr.end_date, rr.room_id, rr.restriction_id, rr.note, rr.created_at, rr.updated_at,
+			r.id, r.restriction_name
+		from
+			room_restrictions rr
+			join restrictions r on (r.id = rr.restriction_id)
+		where
+			rr.room_id = $1 and $2 < rr.end_date and $3 > rr.start_date and ` + staffBlockCondition + `
+		order by
+			rr.start_date, rr.id
+	`
+
+	var rows *sql.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.QueryContext(ctx, query, roomId, start, end)
+	}else{
+		rows, err = m.DB.QueryContext(ctx, query, roomId, start, end)
+	}
+	if err != nil {
+		return blocks, err
+	}
+	defer rows.Close()
+
+	for rows.Next(){
+		var b models.RoomRestriction
+		err := rows.Scan(
+			&b.ID,
+			&b.StartDate,
+			&b.EndDate,
+			&b.RoomID,
+			&b.RestrictionID,
+			&b.Note,
+			&b.CreatedAt,
+			&b.UpdatedAt,
+			&b.Restriction.ID,
+			&b.Restriction.RestrictionName,
+		)
+		if err != nil {
+			return blocks, err
+		}
+		blocks = append(blocks, b)
+	}
+
+	if err = rows.Err(); err != nil {
+		return blocks, err
+	}
+
+	return blocks, nil
+}
+
+// DeleteRoomBlock removes a staff block of the room. Reservations, holds and imported blocks are never matched
+func (m *postgresDBRepo) DeleteRoomBlock(ctx context.Context, tx *sql.Tx, roomId, id int) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	stmt := `delete from room_restrictions rr where rr.id = $1 and rr.room_id = $2 and ` + staffBlockCondition
+
+	var result sql.Result
+	var err error
+	if tx != nil {
+		result, err = tx.ExecContext(ctx, stmt, id, roomId)
+	}else{
+		result, err = m.DB.ExecContext(ctx, stmt, id, roomId)
+	}
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
@@ -0,0 +1,144 @@
+package dbrepo
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+)
+
+func (m *postgresDBRepo) InsertStayRule(ctx context.Context, tx *sql.Tx, rule models.StayRule) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var newId int
+
+	stmt := `insert into stay_rules (room_id, rule_name, start_date, end_date, min_nights, max_nights,
+			closed_to_arrival, closed_to_departure, min_lead_days, max_lead_days, created_at, updated_at)
+			values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) returning id`
+
+	args := []interface{}{
+		rule.RoomID,
+		rule.RuleName,
+		rule.StartDate,
+		rule.EndDate,
+		rule.MinNights,
+		rule.MaxNights,
+		int(rule.ClosedToArrival),
+		int(rule.ClosedToDeparture),
+		rule.MinLeadDays,
+		rule.MaxLeadDays,
+		time.Now(),
+		time.Now(),
+	}
+
+	var err error
+	if tx != nil {
+		err = tx.QueryRowContext(ctx, stmt, args...).Scan(&newId)
+	}else{
+		err = m.DB.QueryRowContext(ctx, stmt, args...).Scan(&newId)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return newId, nil
+}
+
+// GetStayRulesForDates returns the stay rules that can apply to a stay arriving on start and leaving on end.
+// A roomId of 0 returns rules for every room
+func (m *postgresDBRepo) GetStayRulesForDates(ctx context.Context, tx *sql.Tx, roomId int, start, end time.Time) ([]models.StayRule, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var rules = make([]models.StayRule, 0)
+
+	query := `
+		select
+			id, room_id, rule_name, start_date, end_date, min_nights, max_nights,
+			closed_to_arrival, closed_to_departure, min_lead_days, max_lead_days, created_at, updated_at
+		from
+			stay_rules
+		where
+			start_date <= $2 and end_date > $1
+			and ($3 = 0 or room_id = $3)
+		order by
+			room_id, start_date
+	`
+
+	var rows *sql.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.QueryContext(ctx, query, start, end, roomId)
+	}else{
+		rows, err = m.DB.QueryContext(ctx, query, start, end, roomId)
+	}
+	if err != nil {
+		return rules, err
+	}
+	defer rows.Close()
+
+	for rows.Next(){
+		var rule models.StayRule
+		err := rows.Scan(
+			&rule.ID,
+			&rule.RoomID,
+			&rule.RuleName,
+			&rule.StartDate,
+			&rule.EndDate,
+			&rule.MinNights,
+			&rule.MaxNights,
+			&rule.ClosedToArrival,
+			&rule.ClosedToDeparture,
+			&rule.MinLeadDays,
+			&rule.MaxLeadDays,
+			&rule.CreatedAt,
+			&rule.UpdatedAt,
+		)
+		if err != nil {
+			return rules, err
+		}
+		rules = append(rules, rule)
+	}
+
+	if err = rows.Err(); err != nil {
+		return rules, err
+	}
+
+	return rules, nil
+}
+
+func (m *postgresDBRepo) GetStayRulesByRoomId(ctx context.Context, tx *sql.Tx, roomId int) ([]models.StayRule, error) {
+	return m.GetStayRulesForDates(ctx, tx, roomId, time.Time{}, time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC))
+}
+
+func (m *postgresDBRepo) DeleteStayRule(ctx context.Context, tx *sql.Tx, roomId, id int) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	stmt := `delete from stay_rules where id = $1 and room_id = $2`
+
+	var result sql.Result
+	var err error
+	if tx != nil {
+		result, err = tx.ExecContext(ctx, stmt, id, roomId)
+	}else{
+		result, err = m.DB.ExecContext(ctx, stmt, id, roomId)
+	}
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
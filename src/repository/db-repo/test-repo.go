@@ -4,9 +4,11 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+	"github.com/Orololuwa/go-backend-boilerplate/src/money"
 )
 
 // Transactions
@@ -48,6 +50,14 @@ func (m *testDBRepo) SearchAvailabilityForAllRooms(ctx context.Context, tx *sql.
 		return rooms, errors.New("error searching rooms")
 	}
 
+	// rooms 15 and 20 are free in 2050, room 20 has stay rules
+	if start.Year() == 2050 {
+		rooms = append(rooms,
+			models.Room{ID: 15, RoomName: "Test Room", BaseRate: 10000, WeekendRate: 12000, MaxOccupancy: 2, Currency: "USD"},
+			models.Room{ID: 20, RoomName: "Rule Room", BaseRate: 10000, WeekendRate: 12000, MaxOccupancy: 4, Currency: "USD"},
+		)
+	}
+
 	return rooms, nil
 }
 
@@ -58,6 +68,11 @@ func (m *testDBRepo) SearchAvailabilityForDatesByRoomId(ctx context.Context, tx
 		return false, errors.New("reservation for room not found")
 	}
 
+	// simulate a room that is already booked for roomId 3
+	if roomId == 3 {
+		return false, nil
+	}
+
 	return true, nil
 }
 
@@ -72,6 +87,41 @@ func (m *testDBRepo) GetAllRooms(ctx context.Context, tx *sql.Tx, id int, room_n
 	return rooms, nil	
 }
 
+// testRooms are the rooms listed and exported
+func testRooms() []models.Room {
+	return []models.Room{
+		{ID: 15, RoomName: "Test Room", BaseRate: 10000, WeekendRate: 12000, MaxOccupancy: 4, Currency: "USD"},
+		{ID: 16, RoomName: "Other Room", BaseRate: 12000, MaxOccupancy: 2, Currency: "USD"},
+	}
+}
+
+func (m *testDBRepo) EachRoom(ctx context.Context, tx *sql.Tx, id int, room_name string, fn func(models.Room) error) error {
+	// simulate failure for roomId 2
+	if id == 2 {
+		return errors.New("error getting rooms")
+	}
+
+	for _, room := range testRooms() {
+		if (id != 0 && room.ID != id) || (room_name != "" && room.RoomName != room_name) {
+			continue
+		}
+		if err := fn(room); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *testDBRepo) InsertRoom(ctx context.Context, tx *sql.Tx, room models.Room) (int, error) {
+	// simulate failure for a room named Broken Room
+	if room.RoomName == "Broken Room" {
+		return 0, errors.New("failed to insert room")
+	}
+
+	return 1, nil
+}
+
 func (m *testDBRepo) GetRoomById(ctx context.Context, tx *sql.Tx, id int) (models.Room, error) {
 	var room models.Room
 
@@ -79,13 +129,1496 @@ func (m *testDBRepo) GetRoomById(ctx context.Context, tx *sql.Tx, id int) (model
 		return room, errors.New("error getting room")
 	}
 
+	if id == 404 {
+		return room, sql.ErrNoRows
+	}
+
+	room = models.Room{
+		ID: id,
+		RoomName: "Test Room",
+		BaseRate: 10000,
+		WeekendRate: 12000,
+		MaxOccupancy: 4,
+		Currency: "USD",
+	}
+
 	return room, nil
 }
 
+// Holds
+func (m *testDBRepo) LockRoom(ctx context.Context, tx *sql.Tx, roomId int) error {
+	// simulate a room that does not exist for roomId 404
+	if roomId == 404 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (m *testDBRepo) LockReservation(ctx context.Context, tx *sql.Tx, id int) error {
+	// simulate a reservation that does not exist for id 404
+	if id == 404 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (m *testDBRepo) InsertRoomHold(ctx context.Context, tx *sql.Tx, r models.RoomRestriction) (int, error) {
+	// fail if i try to hold room id 1000
+	if r.RoomID == 1000 {
+		return 0, errors.New("failed to insert room hold")
+	}
+
+	return 1, nil
+}
+
+func (m *testDBRepo) GetRoomHoldByToken(ctx context.Context, tx *sql.Tx, token string) (models.RoomRestriction, error) {
+	var hold models.RoomRestriction
+
+	if token == "missing" {
+		return hold, sql.ErrNoRows
+	}
+
+	hold = models.RoomRestriction{
+		ID: 1,
+		StartDate: time.Date(2050, time.January, 1, 0, 0, 0, 0, time.UTC),
+		EndDate: time.Date(2050, time.January, 5, 0, 0, 0, 0, time.UTC),
+		RoomID: 15,
+		RestrictionID: models.RestrictionHold,
+		HoldToken: token,
+		ExpiresAt: time.Now().Add(10 * time.Minute),
+	}
+
+	// simulate a hold that has lapsed but has not been swept yet
+	if token == "expired" {
+		hold.ExpiresAt = time.Now().Add(-1 * time.Minute)
+	}
+
+	return hold, nil
+}
+
+func (m *testDBRepo) DeleteRoomRestriction(ctx context.Context, tx *sql.Tx, id int) error {
+	if id == 1000 {
+		return errors.New("failed to delete room restriction")
+	}
+
+	return nil
+}
+
+func (m *testDBRepo) DeleteExpiredHolds(ctx context.Context, tx *sql.Tx, now time.Time) (int64, error) {
+	return 0, nil
+}
+
+// Confirmation codes
+func (m *testDBRepo) ConfirmationCodeExists(ctx context.Context, tx *sql.Tx, code string) (bool, error) {
+	return false, nil
+}
+
+func (m *testDBRepo) GetReservationByConfirmationCode(ctx context.Context, tx *sql.Tx, code, lastName string) (models.Reservation, error) {
+	var res models.Reservation
+
+	if code == "NOTFOUND" {
+		return res, sql.ErrNoRows
+	}
+
+	if code == "FAILING" {
+		return res, errors.New("error getting reservation")
+	}
+
+	res = models.Reservation{
+		ID: 1,
+		ConfirmationCode: code,
+		LastName: lastName,
+		RoomID: 15,
+	}
+
+	return res, nil
+}
+
+// testReservations are the reservations listed and exported
+func testReservations() []models.Reservation {
+	return []models.Reservation{
+		{
+			ID: 8,
+			ConfirmationCode: "EFGH2345",
+			FirstName: "Jane",
+			LastName: "Doe",
+			Email: "janedoe@gmail.com",
+			StartDate: time.Date(2050, time.January, 5, 0, 0, 0, 0, time.UTC),
+			EndDate: time.Date(2050, time.January, 7, 0, 0, 0, 0, time.UTC),
+			RoomID: 16,
+			Adults: 2,
+			TotalPrice: 24000,
+			Currency: "USD",
+			BaseCurrency: "USD",
+			ExchangeRate: money.RateScale,
+			GuestID: 1,
+			SpecialRequests: "A cot for our baby",
+			Status: models.ReservationStatusPending,
+			Room: models.Room{ID: 16, RoomName: "Other Room"},
+		},
+		{
+			ID: 7,
+			ConfirmationCode: "ABCD2345",
+			FirstName: "John",
+			LastName: "Doe",
+			Email: "johndoe@gmail.com",
+			StartDate: time.Date(2050, time.January, 2, 0, 0, 0, 0, time.UTC),
+			EndDate: time.Date(2050, time.January, 4, 0, 0, 0, 0, time.UTC),
+			RoomID: 15,
+			Adults: 1,
+			TotalPrice: 20000,
+			Currency: "USD",
+			BaseCurrency: "USD",
+			ExchangeRate: money.RateScale,
+			GuestID: 1,
+			Status: models.ReservationStatusConfirmed,
+			Room: models.Room{ID: 15, RoomName: "Test Room"},
+		},
+	}
+}
+
+func (m *testDBRepo) GetReservations(ctx context.Context, tx *sql.Tx, filter models.ReservationFilter) ([]models.Reservation, error) {
+	reservations := make([]models.Reservation, 0)
+
+	err := m.EachReservation(ctx, tx, filter, func(res models.Reservation) error {
+		reservations = append(reservations, res)
+		return nil
+	})
+
+	return reservations, err
+}
+
+func (m *testDBRepo) EachReservation(ctx context.Context, tx *sql.Tx, filter models.ReservationFilter, fn func(models.Reservation) error) error {
+	// simulate failure for roomId 1000
+	if filter.RoomID == 1000 {
+		return errors.New("error getting reservations")
+	}
+
+	for _, res := range testReservations() {
+		if (filter.RoomID != 0 && res.RoomID != filter.RoomID) || (filter.Status != "" && res.Status != filter.Status) || (filter.GuestID != 0 && res.GuestID != filter.GuestID) {
+			continue
+		}
+		if !filter.ArrivingFrom.IsZero() && res.StartDate.Before(filter.ArrivingFrom) {
+			continue
+		}
+		if filter.UnresolvedRequests && (res.SpecialRequests == "" || res.RequestsResolved) {
+			continue
+		}
+		if err := fn(res); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *testDBRepo) GetReservationById(ctx context.Context, tx *sql.Tx, id int) (models.Reservation, error) {
+	var res models.Reservation
+
+	if id == 404 {
+		return res, sql.ErrNoRows
+	}
+
+	if id == 1000 {
+		return res, errors.New("error getting reservation")
+	}
+
+	res = models.Reservation{
+		ID: id,
+		ConfirmationCode: "ABCD2345",
+		FirstName: "John",
+		LastName: "Doe",
+		Email: "johndoe@gmail.com",
+		StartDate: time.Date(2050, time.January, 6, 0, 0, 0, 0, time.UTC),
+		EndDate: time.Date(2050, time.January, 9, 0, 0, 0, 0, time.UTC),
+		RoomID: 15,
+		TotalPrice: 34000,
+		Status: models.ReservationStatusPending,
+	}
+
+	// simulate an already confirmed reservation for id 2 and a free one for id 3
+	if id == 2 {
+		res.Status = models.ReservationStatusConfirmed
+	}
+
+	if id == 3 {
+		res.TotalPrice = 0
+	}
+
+	// simulate a reservation that was already cancelled for id 4
+	if id == 4 {
+		res.Status = models.ReservationStatusCancelled
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	// simulate a stay under the test policy arriving the day after tomorrow for id 9,
+	// one that arrived yesterday for id 10 and a guest who never turned up for id 11
+	switch id {
+	case 9:
+		res.StartDate = today.AddDate(0, 0, 2)
+		res.EndDate = today.AddDate(0, 0, 4)
+		res.CancellationPolicy = testCancellationPolicy()
+	case 10:
+		res.StartDate = today.AddDate(0, 0, -1)
+		res.EndDate = today.AddDate(0, 0, 2)
+		res.Status = models.ReservationStatusConfirmed
+		res.CancellationPolicy = testCancellationPolicy()
+	case 11:
+		res.Status = models.ReservationStatusNoShow
+	}
+
+	// simulate stays that checked out two days ago in room 20, charged like a quote for two adults, for ids 12 to 14
+	if id >= 12 && id <= 14 {
+		res.StartDate = today.AddDate(0, 0, -5)
+		res.EndDate = today.AddDate(0, 0, -2)
+		res.RoomID = 20
+		res.Room = models.Room{ID: 20, RoomName: "Garden Suite"}
+		res.Adults = 2
+		res.TotalPrice = 40138
+		res.Status = models.ReservationStatusConfirmed
+	}
+
+	// simulate a guest who asked for a late check-in for id 15, and one whose request was seen to for id 16
+	if id == 15 || id == 16 {
+		res.SpecialRequests = "Late check-in, around 11pm"
+		res.RequestsResolved = id == 16
+	}
+
+	return res, nil
+}
+
+func (m *testDBRepo) UpdateReservationStatus(ctx context.Context, tx *sql.Tx, id int, status string) error {
+	// reservation 4 is cancelled, nothing should move it again
+	if id == 4 {
+		return errors.New("cancelled reservation changed status")
+	}
+
+	return nil
+}
+
+func (m *testDBRepo) UpdateReservationStay(ctx context.Context, tx *sql.Tx, res models.Reservation) error {
+	return nil
+}
+
+func (m *testDBRepo) UpdateReservationRequestsResolved(ctx context.Context, tx *sql.Tx, id int, resolved bool) error {
+	return nil
+}
+
+func (m *testDBRepo) DeleteRoomRestrictionsByReservationId(ctx context.Context, tx *sql.Tx, reservationId int) error {
+	if reservationId == 1000 {
+		return errors.New("failed to delete room restrictions")
+	}
+
+	return nil
+}
+
+// Groups
+func (m *testDBRepo) InsertReservationGroup(ctx context.Context, tx *sql.Tx, g models.ReservationGroup) (int, error) {
+	return 1, nil
+}
+
+func (m *testDBRepo) GetReservationGroupById(ctx context.Context, tx *sql.Tx, id int) (models.ReservationGroup, error) {
+	var g models.ReservationGroup
+
+	if id == 404 {
+		return g, sql.ErrNoRows
+	}
+
+	g = models.ReservationGroup{
+		ID: id,
+		ConfirmationCode: "GRP23456",
+		GroupName: "Conference",
+		FirstName: "John",
+		LastName: "Doe",
+		Email: "johndoe@gmail.com",
+		Status: models.ReservationStatusPending,
+	}
+
+	// simulate a group that was already cancelled for id 2
+	if id == 2 {
+		g.Status = models.ReservationStatusCancelled
+	}
+
+	return g, nil
+}
+
+func (m *testDBRepo) UpdateReservationGroupStatus(ctx context.Context, tx *sql.Tx, id int, status string) error {
+	return nil
+}
+
+func (m *testDBRepo) GetReservationsByGroupId(ctx context.Context, tx *sql.Tx, groupId int) ([]models.Reservation, error) {
+	var reservations = make([]models.Reservation, 0)
+
+	for _, roomId := range []int{15, 27} {
+		reservations = append(reservations, models.Reservation{
+			ID: roomId,
+			ConfirmationCode: "ABCD2345",
+			LastName: "Doe",
+			StartDate: time.Date(2050, time.January, 6, 0, 0, 0, 0, time.UTC),
+			EndDate: time.Date(2050, time.January, 9, 0, 0, 0, 0, time.UTC),
+			RoomID: roomId,
+			Status: models.ReservationStatusPending,
+			GroupID: groupId,
+		})
+	}
+
+	// simulate a group whose second room can no longer be moved
+	if groupId == 3 {
+		reservations[1].RoomID = 3
+	}
+
+	// simulate a group whose second room was cancelled on its own, with a fee, for group 4
+	if groupId == 4 {
+		reservations[1].Status = models.ReservationStatusCancelled
+		reservations[1].TotalPrice = 10000
+		reservations[1].CancellationFee = 5000
+		reservations[1].RefundAmount = 0
+	}
+
+	// simulate a group whose first room was a no-show for group 5, and one whose second room was paid for, group 6
+	if groupId == 5 {
+		reservations[0].Status = models.ReservationStatusNoShow
+	}
+	if groupId == 6 {
+		reservations[1].Status = models.ReservationStatusConfirmed
+		reservations[1].TotalPrice = 10000
+	}
+
+	// simulate a group whose first room was booked for 3 nights with 10% off, for group 7
+	if groupId == 7 {
+		reservations[0].PromoCode = "SAVE10"
+		reservations[0].PromoDiscountType = models.DiscountPercent
+		reservations[0].PromoDiscountValue = 10
+		reservations[0].Discount = 3000
+		reservations[0].TotalPrice = 27000
+	}
+
+	return reservations, nil
+}
+
+// Rates
+func (m *testDBRepo) UpdateRoomRates(ctx context.Context, tx *sql.Tx, roomId int, baseRate, weekendRate money.Amount, currency string) error {
+	if roomId == 404 {
+		return sql.ErrNoRows
+	}
+
+	if roomId == 1000 {
+		return errors.New("failed to update room rates")
+	}
+
+	return nil
+}
+
+func (m *testDBRepo) UpdateRoomOccupancy(ctx context.Context, tx *sql.Tx, roomId, maxOccupancy int) error {
+	if roomId == 404 {
+		return sql.ErrNoRows
+	}
+
+	if roomId == 1000 {
+		return errors.New("failed to update room occupancy")
+	}
+
+	return nil
+}
+
+func (m *testDBRepo) InsertRoomRate(ctx context.Context, tx *sql.Tx, rate models.RoomRate) (int, error) {
+	if rate.RoomID == 1000 {
+		return 0, errors.New("failed to insert room rate")
+	}
+
+	return 1, nil
+}
+
+func (m *testDBRepo) GetRoomRatesForDates(ctx context.Context, tx *sql.Tx, roomId int, start, end time.Time) ([]models.RoomRate, error) {
+	var rates = make([]models.RoomRate, 0)
+
+	// simulate failure for roomId 500
+	if roomId == 500 {
+		return rates, errors.New("error getting room rates")
+	}
+
+	return rates, nil
+}
+
+func (m *testDBRepo) GetRoomRatesByRoomId(ctx context.Context, tx *sql.Tx, roomId int) ([]models.RoomRate, error) {
+	return m.GetRoomRatesForDates(ctx, tx, roomId, time.Time{}, time.Time{})
+}
+
+func (m *testDBRepo) DeleteRoomRate(ctx context.Context, tx *sql.Tx, roomId, id int) error {
+	if id == 404 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
 // User
 func (m *testUserDBRepo) CreateAUser(ctx context.Context, tx *sql.Tx, user models.User) (int, error){
 	var newId int
 
 
 	return newId, nil
-}
\ No newline at end of file
+}
+
+// Payments
+func (m *testPaymentDBRepo) InsertPayment(ctx context.Context, tx *sql.Tx, p models.Payment) (int, error) {
+	return 1, nil
+}
+
+func (m *testPaymentDBRepo) GetPaymentsByReservationId(ctx context.Context, tx *sql.Tx, reservationId int) ([]models.Payment, error) {
+	var payments = make([]models.Payment, 0)
+
+	if reservationId == 1000 {
+		return payments, errors.New("error getting payments")
+	}
+
+	// the checked out stays were paid in full
+	if reservationId >= 12 && reservationId <= 14 {
+		payments = append(payments, models.Payment{
+			ID: 1,
+			ReservationID: reservationId,
+			Provider: "fake",
+			Reference: "fake_success_1",
+			Amount: 40138,
+			CapturedAmount: 40138,
+			Status: models.PaymentStatusCaptured,
+		})
+	}
+
+	return payments, nil
+}
+
+func (m *testPaymentDBRepo) GetLatestPaymentByReservationId(ctx context.Context, tx *sql.Tx, reservationId int, status ...string) (models.Payment, error) {
+	var p models.Payment
+
+	if reservationId == 404 {
+		return p, sql.ErrNoRows
+	}
+
+	p = models.Payment{
+		ID: 1,
+		ReservationID: reservationId,
+		Provider: "fake",
+		Reference: "fake_success_1",
+		Amount: 34000,
+		Status: models.PaymentStatusAuthorized,
+	}
+
+	if len(status) > 0 {
+		p.Status = status[0]
+	}
+
+	if p.Status == models.PaymentStatusCaptured {
+		p.CapturedAmount = p.Amount
+	}
+
+	// simulate gateway failures for reservation 5 on capture and reservation 6 on refund
+	if reservationId == 5 {
+		p.Reference = "fake_capture_fail_1"
+	}
+
+	if reservationId == 6 {
+		p.Reference = "fake_refund_fail_1"
+	}
+
+	return p, nil
+}
+
+func (m *testPaymentDBRepo) GetPaymentByReference(ctx context.Context, tx *sql.Tx, provider, reference string) (models.Payment, error) {
+	var p models.Payment
+
+	if reference == "unknown" {
+		return p, sql.ErrNoRows
+	}
+
+	p = models.Payment{
+		ID: 1,
+		ReservationID: 1,
+		Provider: provider,
+		Reference: reference,
+		Amount: 34000,
+		Status: models.PaymentStatusAuthorized,
+	}
+
+	// simulate a payment of the cancelled reservation 4
+	if reference == "fake_cancelled_1" {
+		p.ReservationID = 4
+	}
+
+	return p, nil
+}
+
+func (m *testPaymentDBRepo) UpdatePayment(ctx context.Context, tx *sql.Tx, p models.Payment) error {
+	return nil
+}
+
+// Waitlist
+func (m *testDBRepo) InsertWaitlistEntry(ctx context.Context, tx *sql.Tx, e models.WaitlistEntry) (int, error) {
+	if e.RoomID == 1000 {
+		return 0, errors.New("failed to insert waitlist entry")
+	}
+
+	return 1, nil
+}
+
+func (m *testDBRepo) GetWaitlistEntries(ctx context.Context, tx *sql.Tx, status string) ([]models.WaitlistEntry, error) {
+	var entries = make([]models.WaitlistEntry, 0)
+
+	entries = append(entries, models.WaitlistEntry{
+		ID: 1,
+		RoomID: 15,
+		StartDate: time.Date(2050, time.January, 6, 0, 0, 0, 0, time.UTC),
+		EndDate: time.Date(2050, time.January, 9, 0, 0, 0, 0, time.UTC),
+		FirstName: "Jane",
+		LastName: "Roe",
+		Email: "janeroe@gmail.com",
+		Status: models.WaitlistStatusWaiting,
+	})
+
+	return entries, nil
+}
+
+func (m *testDBRepo) GetWaitingEntriesForRoom(ctx context.Context, tx *sql.Tx, roomId int, start, end time.Time) ([]models.WaitlistEntry, error) {
+	var entries = make([]models.WaitlistEntry, 0)
+
+	if roomId == 500 {
+		return entries, errors.New("failed to get waitlist entries")
+	}
+
+	// the first two guests want overlapping dates, the third wants the following week
+	dates := [][2]int{{6, 9}, {7, 10}, {13, 15}}
+	for i, d := range dates {
+		entries = append(entries, models.WaitlistEntry{
+			ID: i + 1,
+			StartDate: time.Date(2050, time.January, d[0], 0, 0, 0, 0, time.UTC),
+			EndDate: time.Date(2050, time.January, d[1], 0, 0, 0, 0, time.UTC),
+			FirstName: "Jane",
+			LastName: "Roe",
+			Email: fmt.Sprintf("guest%d@gmail.com", i + 1),
+			Status: models.WaitlistStatusWaiting,
+		})
+	}
+
+	return entries, nil
+}
+
+func (m *testDBRepo) GetExpiredWaitlistOffers(ctx context.Context, tx *sql.Tx, now time.Time) ([]models.WaitlistEntry, error) {
+	var entries = make([]models.WaitlistEntry, 0)
+
+	entries = append(entries, models.WaitlistEntry{
+		ID: 9,
+		StartDate: time.Date(2050, time.January, 6, 0, 0, 0, 0, time.UTC),
+		EndDate: time.Date(2050, time.January, 9, 0, 0, 0, 0, time.UTC),
+		Email: "janeroe@gmail.com",
+		Status: models.WaitlistStatusOffered,
+		OfferRoomID: 15,
+		OfferToken: "expired",
+		OfferExpiresAt: now.Add(-1 * time.Minute),
+	})
+
+	return entries, nil
+}
+
+func (m *testDBRepo) UpdateWaitlistOffer(ctx context.Context, tx *sql.Tx, e models.WaitlistEntry) error {
+	return nil
+}
+
+func (m *testDBRepo) AcceptWaitlistOffer(ctx context.Context, tx *sql.Tx, token string) error {
+	return nil
+}
+
+// Calendar
+func (m *testDBRepo) GetRoomCalendarToken(ctx context.Context, tx *sql.Tx, roomId int) (string, error) {
+	if roomId == 404 {
+		return "", sql.ErrNoRows
+	}
+
+	if roomId == 1000 {
+		return "", errors.New("error getting calendar token")
+	}
+
+	// simulate a room whose feed was never enabled for roomId 16
+	if roomId == 16 {
+		return "", nil
+	}
+
+	return "room-secret", nil
+}
+
+func (m *testDBRepo) UpdateRoomCalendarToken(ctx context.Context, tx *sql.Tx, roomId int, token string) error {
+	if roomId == 404 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (m *testDBRepo) GetRoomReports(ctx context.Context, tx *sql.Tx, start, end time.Time) ([]models.RoomReport, error) {
+	var reports = make([]models.RoomReport, 0)
+
+	if start.Year() < 1960 {
+		return reports, errors.New("error getting room reports")
+	}
+
+	// over the 10 nights of the reports tests: room 15 sold 6 and room 16 sold 2 with 2 blocked
+	reports = append(reports,
+		models.RoomReport{RoomID: 15, RoomName: "Test Room", Currency: "USD", SoldNights: 6, Revenue: 60000, Charges: 4500, Reservations: 2, StayNights: 7, LeadDays: 30},
+		models.RoomReport{RoomID: 16, RoomName: "Other Room", Currency: "USD", SoldNights: 2, BlockedNights: 2, Revenue: 24000, Reservations: 1, StayNights: 2, LeadDays: 3},
+	)
+
+	return reports, nil
+}
+
+func (m *testDBRepo) GetCalendarGrid(ctx context.Context, tx *sql.Tx, roomId int, start, end time.Time) ([]models.RoomCalendar, error) {
+	var calendars = make([]models.RoomCalendar, 0)
+
+	if start.Year() < 1960 {
+		return calendars, errors.New("error getting calendar grid")
+	}
+
+	day := func(d int) time.Time {
+		return time.Date(2050, time.January, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	// room 3 is booked from 2050-01-01 to 2050-01-10, it is only returned on its own
+	if roomId == 3 {
+		calendars = append(calendars, models.RoomCalendar{
+			Room: models.Room{ID: 3, RoomName: "Booked Room", MaxOccupancy: 4},
+			Restrictions: []models.RoomRestriction{
+				{ID: 3, StartDate: day(1), EndDate: day(10), RoomID: 3, ReservationID: 8, RestrictionID: models.RestrictionReservation},
+			},
+			RecurringBlocks: []models.RecurringBlock{},
+		})
+		return calendars, nil
+	}
+
+	// room 15 is booked, room 20 has an owner block and a weekly monday block, room 21 is free
+	calendars = append(calendars,
+		models.RoomCalendar{
+			Room: models.Room{ID: 15, RoomName: "Test Room", MaxOccupancy: 2},
+			Restrictions: []models.RoomRestriction{
+				{ID: 1, StartDate: day(2), EndDate: day(4), RoomID: 15, ReservationID: 7, RestrictionID: models.RestrictionReservation, Restriction: models.Restriction{ID: models.RestrictionReservation, RestrictionName: "Reservation"}},
+			},
+			RecurringBlocks: []models.RecurringBlock{},
+		},
+		models.RoomCalendar{
+			Room: models.Room{ID: 20, RoomName: "Rule Room", MaxOccupancy: 4},
+			Restrictions: []models.RoomRestriction{
+				{ID: 2, StartDate: day(5), EndDate: day(7), RoomID: 20, RestrictionID: models.RestrictionOwnerBlock, Restriction: models.Restriction{ID: models.RestrictionOwnerBlock, RestrictionName: "Owner Block"}},
+			},
+			RecurringBlocks: []models.RecurringBlock{
+				{ID: 1, RoomID: 20, RestrictionID: 5, StartDate: day(3), Nights: 1, RRule: "FREQ=WEEKLY;BYDAY=MO", Restriction: models.Restriction{ID: 5, RestrictionName: "Maintenance"}},
+			},
+		},
+		models.RoomCalendar{
+			Room: models.Room{ID: 21, RoomName: "Free Room", MaxOccupancy: 2},
+			Restrictions: []models.RoomRestriction{},
+			RecurringBlocks: []models.RecurringBlock{},
+		},
+	)
+
+	if roomId == 0 {
+		return calendars, nil
+	}
+
+	for _, cal := range calendars {
+		if cal.Room.ID == roomId {
+			return []models.RoomCalendar{cal}, nil
+		}
+	}
+
+	return make([]models.RoomCalendar, 0), nil
+}
+
+func (m *testDBRepo) GetRoomRestrictionsForCalendar(ctx context.Context, tx *sql.Tx, roomId int, from time.Time) ([]models.RoomRestriction, error) {
+	var restrictions = make([]models.RoomRestriction, 0)
+
+	restrictions = append(restrictions, models.RoomRestriction{
+		ID: 1,
+		StartDate: time.Date(2050, time.January, 6, 0, 0, 0, 0, time.UTC),
+		EndDate: time.Date(2050, time.January, 9, 0, 0, 0, 0, time.UTC),
+		RoomID: roomId,
+		ReservationID: 7,
+		RestrictionID: models.RestrictionReservation,
+		Reservation: models.Reservation{
+			ID: 7,
+			ConfirmationCode: "ABCD2345",
+			FirstName: "John",
+			LastName: "Doe",
+			Status: models.ReservationStatusConfirmed,
+		},
+	})
+
+	restrictions = append(restrictions, models.RoomRestriction{
+		ID: 2,
+		StartDate: time.Date(2050, time.February, 1, 0, 0, 0, 0, time.UTC),
+		EndDate: time.Date(2050, time.February, 3, 0, 0, 0, 0, time.UTC),
+		RoomID: roomId,
+		RestrictionID: models.RestrictionOwnerBlock,
+	})
+
+	return restrictions, nil
+}
+
+// Calendar feeds
+func (m *testDBRepo) InsertCalendarFeed(ctx context.Context, tx *sql.Tx, f models.CalendarFeed) (int, error) {
+	if f.RoomID == 1000 {
+		return 0, errors.New("failed to insert calendar feed")
+	}
+
+	return 1, nil
+}
+
+func (m *testDBRepo) GetCalendarFeedById(ctx context.Context, tx *sql.Tx, id int) (models.CalendarFeed, error) {
+	var f models.CalendarFeed
+
+	if id == 404 {
+		return f, sql.ErrNoRows
+	}
+
+	f = models.CalendarFeed{
+		ID: id,
+		RoomID: 15,
+		Name: "Other platform",
+		LastStatus: models.CalendarFeedStatusPending,
+	}
+
+	// simulate a feed whose URL cannot be reached for id 2
+	if id == 2 {
+		f.URL = "http://127.0.0.1:1/calendar.ics"
+	}
+
+	return f, nil
+}
+
+func (m *testDBRepo) GetCalendarFeedsByRoomId(ctx context.Context, tx *sql.Tx, roomId int) ([]models.CalendarFeed, error) {
+	var feeds = make([]models.CalendarFeed, 0)
+
+	feeds = append(feeds, models.CalendarFeed{
+		ID: 1,
+		RoomID: roomId,
+		Name: "Other platform",
+		LastStatus: models.CalendarFeedStatusOK,
+	})
+
+	return feeds, nil
+}
+
+func (m *testDBRepo) GetRemoteCalendarFeeds(ctx context.Context, tx *sql.Tx) ([]models.CalendarFeed, error) {
+	return make([]models.CalendarFeed, 0), nil
+}
+
+func (m *testDBRepo) UpdateCalendarFeedStatus(ctx context.Context, tx *sql.Tx, f models.CalendarFeed) error {
+	return nil
+}
+
+func (m *testDBRepo) DeleteCalendarFeed(ctx context.Context, tx *sql.Tx, roomId, id int) error {
+	if id == 404 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (m *testDBRepo) GetExternalBlocksByFeedId(ctx context.Context, tx *sql.Tx, feedId int) ([]models.RoomRestriction, error) {
+	var blocks = make([]models.RoomRestriction, 0)
+
+	// one block that is still in the source calendar and one that was removed from it
+	blocks = append(blocks, models.RoomRestriction{
+		ID: 1,
+		StartDate: time.Date(2050, time.March, 1, 0, 0, 0, 0, time.UTC),
+		EndDate: time.Date(2050, time.March, 3, 0, 0, 0, 0, time.UTC),
+		RoomID: 15,
+		RestrictionID: models.RestrictionExternalBlock,
+		FeedID: feedId,
+		ExternalUID: "keep@external",
+	})
+
+	blocks = append(blocks, models.RoomRestriction{
+		ID: 2,
+		StartDate: time.Date(2050, time.April, 1, 0, 0, 0, 0, time.UTC),
+		EndDate: time.Date(2050, time.April, 2, 0, 0, 0, 0, time.UTC),
+		RoomID: 15,
+		RestrictionID: models.RestrictionExternalBlock,
+		FeedID: feedId,
+		ExternalUID: "gone@external",
+	})
+
+	return blocks, nil
+}
+
+func (m *testDBRepo) InsertExternalBlock(ctx context.Context, tx *sql.Tx, r models.RoomRestriction) (int, error) {
+	return 3, nil
+}
+
+func (m *testDBRepo) UpdateRoomRestrictionDates(ctx context.Context, tx *sql.Tx, id int, start, end time.Time) error {
+	return nil
+}
+
+// Restriction types
+func (m *testDBRepo) GetRestrictions(ctx context.Context, tx *sql.Tx) ([]models.Restriction, error) {
+	var restrictions = make([]models.Restriction, 0)
+
+	restrictions = append(restrictions,
+		models.Restriction{ID: models.RestrictionReservation, RestrictionName: "Reservation"},
+		models.Restriction{ID: models.RestrictionOwnerBlock, RestrictionName: "Owner Block"},
+		models.Restriction{ID: models.RestrictionHold, RestrictionName: "Hold"},
+		models.Restriction{ID: models.RestrictionExternalBlock, RestrictionName: "External Block"},
+		models.Restriction{ID: 5, RestrictionName: "Maintenance"},
+	)
+
+	return restrictions, nil
+}
+
+func (m *testDBRepo) GetRestrictionById(ctx context.Context, tx *sql.Tx, id int) (models.Restriction, error) {
+	var r models.Restriction
+
+	if id == 404 {
+		return r, sql.ErrNoRows
+	}
+
+	if id == 1000 {
+		return r, errors.New("error getting restriction")
+	}
+
+	r = models.Restriction{
+		ID: id,
+		RestrictionName: "Maintenance",
+	}
+
+	return r, nil
+}
+
+func (m *testDBRepo) InsertRestriction(ctx context.Context, tx *sql.Tx, r models.Restriction) (int, error) {
+	if r.RestrictionName == "fail" {
+		return 0, errors.New("failed to insert restriction")
+	}
+
+	return 6, nil
+}
+
+func (m *testDBRepo) UpdateRestriction(ctx context.Context, tx *sql.Tx, r models.Restriction) error {
+	if r.ID == 404 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (m *testDBRepo) DeleteRestriction(ctx context.Context, tx *sql.Tx, id int) error {
+	if id == 404 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (m *testDBRepo) RestrictionInUse(ctx context.Context, tx *sql.Tx, id int) (bool, error) {
+	// simulate a restriction type that still has blocks for id 5
+	return id == 5, nil
+}
+
+// Room blocks
+func (m *testDBRepo) InsertRoomBlock(ctx context.Context, tx *sql.Tx, r models.RoomRestriction) (int, error) {
+	if r.RoomID == 1000 {
+		return 0, errors.New("failed to insert room block")
+	}
+
+	return 1, nil
+}
+
+func (m *testDBRepo) GetRoomBlocks(ctx context.Context, tx *sql.Tx, roomId int, start, end time.Time) ([]models.RoomRestriction, error) {
+	var blocks = make([]models.RoomRestriction, 0)
+
+	if roomId == 1000 {
+		return blocks, errors.New("error getting room blocks")
+	}
+
+	blocks = append(blocks, models.RoomRestriction{
+		ID: 2,
+		StartDate: time.Date(2050, time.February, 1, 0, 0, 0, 0, time.UTC),
+		EndDate: time.Date(2050, time.February, 3, 0, 0, 0, 0, time.UTC),
+		RoomID: roomId,
+		RestrictionID: 5,
+		Note: "Repainting",
+		Restriction: models.Restriction{ID: 5, RestrictionName: "Maintenance"},
+	})
+
+	return blocks, nil
+}
+
+func (m *testDBRepo) DeleteRoomBlock(ctx context.Context, tx *sql.Tx, roomId, id int) error {
+	if id == 404 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// Recurring blocks
+func (m *testDBRepo) InsertRecurringBlock(ctx context.Context, tx *sql.Tx, b models.RecurringBlock) (int, error) {
+	if b.RoomID == 1000 {
+		return 0, errors.New("failed to insert recurring block")
+	}
+
+	return 1, nil
+}
+
+func (m *testDBRepo) GetRecurringBlockById(ctx context.Context, tx *sql.Tx, id int) (models.RecurringBlock, error) {
+	var b models.RecurringBlock
+
+	if id == 404 {
+		return b, sql.ErrNoRows
+	}
+
+	// closed every monday from 3 January 2050, the monday after was removed
+	b = models.RecurringBlock{
+		ID: id,
+		RoomID: 15,
+		RestrictionID: 5,
+		StartDate: time.Date(2050, time.January, 3, 0, 0, 0, 0, time.UTC),
+		Nights: 1,
+		RRule: "FREQ=WEEKLY;BYDAY=MO",
+		Note: "Deep cleaning",
+		Exceptions: []time.Time{time.Date(2050, time.January, 10, 0, 0, 0, 0, time.UTC)},
+		Restriction: models.Restriction{ID: 5, RestrictionName: "Maintenance"},
+	}
+
+	return b, nil
+}
+
+func (m *testDBRepo) GetRecurringBlocksByRoomId(ctx context.Context, tx *sql.Tx, roomId int) ([]models.RecurringBlock, error) {
+	var blocks = make([]models.RecurringBlock, 0)
+
+	if roomId == 1000 {
+		return blocks, errors.New("error getting recurring blocks")
+	}
+
+	b, _ := m.GetRecurringBlockById(ctx, tx, 1)
+	b.RoomID = roomId
+	blocks = append(blocks, b)
+
+	return blocks, nil
+}
+
+func (m *testDBRepo) DeleteRecurringBlock(ctx context.Context, tx *sql.Tx, roomId, id int) error {
+	if id == 404 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (m *testDBRepo) InsertRecurringBlockException(ctx context.Context, tx *sql.Tx, blockId int, occurrence time.Time) error {
+	return nil
+}
+
+// Stay rules
+func (m *testDBRepo) InsertStayRule(ctx context.Context, tx *sql.Tx, rule models.StayRule) (int, error) {
+	if rule.RoomID == 1000 {
+		return 0, errors.New("failed to insert stay rule")
+	}
+
+	return 1, nil
+}
+
+func (m *testDBRepo) GetStayRulesForDates(ctx context.Context, tx *sql.Tx, roomId int, start, end time.Time) ([]models.StayRule, error) {
+	var rules = make([]models.StayRule, 0)
+
+	if roomId == 500 {
+		return rules, errors.New("error getting stay rules")
+	}
+
+	// room 20 takes stays of at least 3 nights in 2050 and no arrivals on a sunday
+	if roomId == 0 || roomId == 20 {
+		rules = append(rules, models.StayRule{
+			ID: 1,
+			RoomID: 20,
+			RuleName: "Minimum stay",
+			StartDate: time.Date(2050, time.January, 1, 0, 0, 0, 0, time.UTC),
+			EndDate: time.Date(2051, time.January, 1, 0, 0, 0, 0, time.UTC),
+			MinNights: 3,
+			ClosedToArrival: 1 << uint(time.Sunday),
+		})
+	}
+
+	return rules, nil
+}
+
+func (m *testDBRepo) GetStayRulesByRoomId(ctx context.Context, tx *sql.Tx, roomId int) ([]models.StayRule, error) {
+	return m.GetStayRulesForDates(ctx, tx, roomId, time.Time{}, time.Time{})
+}
+
+func (m *testDBRepo) DeleteStayRule(ctx context.Context, tx *sql.Tx, roomId, id int) error {
+	if id == 404 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// Cancellation policies
+
+// testCancellationPolicy is free until 48 hours before arrival, half the total after that and all of it in the last day
+func testCancellationPolicy() *models.CancellationPolicy {
+	return &models.CancellationPolicy{
+		ID: 1,
+		PolicyName: "Moderate",
+		Windows: []models.CancellationWindow{
+			{HoursBefore: 48, FeePercent: 50},
+			{HoursBefore: 24, FeePercent: 100},
+		},
+		NoShowPercent: 100,
+	}
+}
+
+func (m *testDBRepo) InsertCancellationPolicy(ctx context.Context, tx *sql.Tx, p models.CancellationPolicy) (int, error) {
+	if p.PolicyName == "Broken Policy" {
+		return 0, errors.New("failed to insert cancellation policy")
+	}
+
+	return 1, nil
+}
+
+func (m *testDBRepo) GetCancellationPolicies(ctx context.Context, tx *sql.Tx) ([]models.CancellationPolicy, error) {
+	return []models.CancellationPolicy{*testCancellationPolicy()}, nil
+}
+
+func (m *testDBRepo) GetCancellationPolicyById(ctx context.Context, tx *sql.Tx, id int) (models.CancellationPolicy, error) {
+	if id == 404 {
+		return models.CancellationPolicy{}, sql.ErrNoRows
+	}
+
+	if id == 1000 {
+		return models.CancellationPolicy{}, errors.New("error getting cancellation policy")
+	}
+
+	p := *testCancellationPolicy()
+	p.ID = id
+	return p, nil
+}
+
+// GetCancellationPolicyForStay books stays in room 15 under the test policy, other rooms are free to cancel
+func (m *testDBRepo) GetCancellationPolicyForStay(ctx context.Context, tx *sql.Tx, roomId, rateId int) (models.CancellationPolicy, error) {
+	if roomId != 15 {
+		return models.CancellationPolicy{}, sql.ErrNoRows
+	}
+
+	return *testCancellationPolicy(), nil
+}
+
+func (m *testDBRepo) DeleteCancellationPolicy(ctx context.Context, tx *sql.Tx, id int) error {
+	if id == 404 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (m *testDBRepo) UpdateRoomCancellationPolicy(ctx context.Context, tx *sql.Tx, roomId, policyId int) error {
+	if roomId == 404 {
+		return sql.ErrNoRows
+	}
+
+	if roomId == 1000 {
+		return errors.New("failed to update room cancellation policy")
+	}
+
+	return nil
+}
+
+func (m *testDBRepo) UpdateReservationCancellation(ctx context.Context, tx *sql.Tx, id int, status string, fee, refund money.Amount) error {
+	return nil
+}
+
+// History
+func (m *testDBRepo) InsertReservationHistory(ctx context.Context, tx *sql.Tx, h models.ReservationHistory) (int, error) {
+	// simulate failure for changes made by broken@example.com
+	if h.Actor == "broken@example.com" {
+		return 0, errors.New("failed to insert reservation history")
+	}
+
+	return 1, nil
+}
+
+func (m *testDBRepo) GetReservationHistory(ctx context.Context, tx *sql.Tx, reservationId int) ([]models.ReservationHistory, error) {
+	var history = make([]models.ReservationHistory, 0)
+
+	if reservationId == 500 {
+		return history, errors.New("error getting reservation history")
+	}
+
+	history = append(history,
+		models.ReservationHistory{
+			ID: 1,
+			ReservationID: reservationId,
+			Action: models.HistoryCreated,
+			Actor: models.HistoryActorGuest,
+			After: []byte(`{"id":1,"startDate":"2050-01-06","endDate":"2050-01-09","status":"pending"}`),
+		},
+		models.ReservationHistory{
+			ID: 2,
+			ReservationID: reservationId,
+			Action: models.HistoryUpdated,
+			Actor: "staff@example.com",
+			Before: []byte(`{"id":1,"startDate":"2050-01-06","endDate":"2050-01-09","status":"pending"}`),
+			After: []byte(`{"id":1,"startDate":"2050-01-07","endDate":"2050-01-10","status":"pending"}`),
+		},
+	)
+
+	return history, nil
+}
+
+// Promo codes
+// testPromoCode returns the promo code fixtures the handler tests book with, looked up by code
+func testPromoCode(code string) (models.PromoCode, error) {
+	p := models.PromoCode{ID: 1, Code: code, DiscountType: models.DiscountPercent, DiscountValue: 10}
+
+	switch code {
+	case "SAVE10":
+	case "FLAT5000":
+		p.ID = 2
+		p.DiscountType = models.DiscountFixed
+		p.DiscountValue = 5000
+	case "EXPIRED":
+		p.ID = 3
+		p.ValidUntil = time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	case "LONGSTAY":
+		p.ID = 4
+		p.MinNights = 7
+	case "SUITE":
+		p.ID = 5
+		p.RoomIDs = []int{16}
+	case "SOLDOUT":
+		p.ID = 6
+		p.MaxUses = 5
+		p.Uses = 5
+	case "ONCE":
+		p.ID = 7
+		p.MaxUsesPerEmail = 1
+	case "BROKEN":
+		return models.PromoCode{}, errors.New("error getting promo code")
+	default:
+		return models.PromoCode{}, sql.ErrNoRows
+	}
+
+	return p, nil
+}
+
+func (m *testDBRepo) InsertPromoCode(ctx context.Context, tx *sql.Tx, p models.PromoCode) (int, error) {
+	if p.Code == "FAILED" {
+		return 0, errors.New("failed to insert promo code")
+	}
+
+	return 1, nil
+}
+
+func (m *testDBRepo) GetPromoCodes(ctx context.Context, tx *sql.Tx) ([]models.PromoCode, error) {
+	p, _ := testPromoCode("SAVE10")
+	return []models.PromoCode{p}, nil
+}
+
+func (m *testDBRepo) GetPromoCodeById(ctx context.Context, tx *sql.Tx, id int) (models.PromoCode, error) {
+	if id == 404 {
+		return models.PromoCode{}, sql.ErrNoRows
+	}
+
+	if id == 1000 {
+		return models.PromoCode{}, errors.New("error getting promo code")
+	}
+
+	p, _ := testPromoCode("SAVE10")
+	p.ID = id
+	return p, nil
+}
+
+func (m *testDBRepo) GetPromoCodeByCode(ctx context.Context, tx *sql.Tx, code string) (models.PromoCode, error) {
+	return testPromoCode(code)
+}
+
+func (m *testDBRepo) LockPromoCode(ctx context.Context, tx *sql.Tx, code string) (models.PromoCode, error) {
+	return testPromoCode(code)
+}
+
+func (m *testDBRepo) UpdatePromoCode(ctx context.Context, tx *sql.Tx, p models.PromoCode) error {
+	if p.ID == 404 {
+		return sql.ErrNoRows
+	}
+
+	if p.ID == 1000 {
+		return errors.New("failed to update promo code")
+	}
+
+	return nil
+}
+
+func (m *testDBRepo) DeletePromoCode(ctx context.Context, tx *sql.Tx, id int) error {
+	if id == 404 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// CountPromoRedemptionsByEmail reports that repeat@example.com used every code once already
+func (m *testDBRepo) CountPromoRedemptionsByEmail(ctx context.Context, tx *sql.Tx, promoCodeId int, emailKey string) (int, error) {
+	if emailKey == "repeat@example.com" {
+		return 1, nil
+	}
+
+	return 0, nil
+}
+
+func (m *testDBRepo) InsertPromoRedemption(ctx context.Context, tx *sql.Tx, r models.PromoRedemption) (int, error) {
+	// simulate failure for redemptions by unredeemable@example.com
+	if r.Email == "unredeemable@example.com" {
+		return 0, errors.New("failed to insert promo redemption")
+	}
+
+	return 1, nil
+}
+
+// Charges
+func (m *testDBRepo) InsertChargeRule(ctx context.Context, tx *sql.Tx, rule models.ChargeRule) (int, error) {
+	if rule.RuleName == "Broken Rule" {
+		return 0, errors.New("failed to insert charge rule")
+	}
+
+	return 1, nil
+}
+
+// GetChargeRules charges city tax, a cleaning fee and VAT on stays in room 20, stays in other rooms are not charged
+func (m *testDBRepo) GetChargeRules(ctx context.Context, tx *sql.Tx) ([]models.ChargeRule, error) {
+	return []models.ChargeRule{
+		{ID: 1, RuleName: "City tax", Kind: models.ChargePerPersonNight, Amount: 150, RoomID: 20},
+		{ID: 2, RuleName: "Cleaning fee", Kind: models.ChargeFlat, Amount: 2500, RoomID: 20},
+		{ID: 3, RuleName: "VAT", Kind: models.ChargePercent, Amount: 750, RoomID: 20},
+	}, nil
+}
+
+func (m *testDBRepo) DeleteChargeRule(ctx context.Context, tx *sql.Tx, id int) error {
+	if id == 404 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (m *testDBRepo) InsertReservationCharges(ctx context.Context, tx *sql.Tx, reservationId int, lines []models.ReservationCharge) error {
+	return nil
+}
+
+func (m *testDBRepo) DeleteReservationCharges(ctx context.Context, tx *sql.Tx, reservationId int) error {
+	return nil
+}
+
+func (m *testDBRepo) GetReservationCharges(ctx context.Context, tx *sql.Tx, reservationId int) ([]models.ReservationCharge, error) {
+	var lines = make([]models.ReservationCharge, 0)
+
+	if reservationId == 500 {
+		return lines, errors.New("error getting reservation charges")
+	}
+
+	if reservationId == 12 {
+		lines = append(lines,
+			models.ReservationCharge{ID: 1, ReservationID: 12, ChargeRuleID: 1, ChargeName: "City tax", Kind: models.ChargePerPersonNight, Rate: 150, Quantity: 6, Amount: 900},
+			models.ReservationCharge{ID: 2, ReservationID: 12, ChargeRuleID: 2, ChargeName: "Cleaning fee", Kind: models.ChargeFlat, Rate: 2500, Quantity: 1, Amount: 2500},
+			models.ReservationCharge{ID: 3, ReservationID: 12, ChargeRuleID: 3, ChargeName: "VAT", Kind: models.ChargePercent, Rate: 750, Quantity: 1, Amount: 2738},
+		)
+	}
+
+	return lines, nil
+}
+
+// Invoices
+func (m *testDBRepo) NextInvoiceSequence(ctx context.Context, tx *sql.Tx, series string) (int, error) {
+	return 42, nil
+}
+
+func (m *testDBRepo) InsertInvoice(ctx context.Context, tx *sql.Tx, inv models.Invoice) (int, error) {
+	if inv.ReservationID == 14 {
+		return 0, errors.New("failed to insert invoice")
+	}
+
+	return 1, nil
+}
+
+// GetInvoiceByReservationId returns the invoice already issued for reservation 13
+func (m *testDBRepo) GetInvoiceByReservationId(ctx context.Context, tx *sql.Tx, reservationId int) (models.Invoice, error) {
+	var inv models.Invoice
+
+	if reservationId == 500 {
+		return inv, errors.New("error getting invoice")
+	}
+
+	if reservationId != 13 {
+		return inv, sql.ErrNoRows
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	return models.Invoice{
+		ID: 7,
+		InvoiceNumber: "INV-000007",
+		Series: "INV",
+		Sequence: 7,
+		ReservationID: 13,
+		ConfirmationCode: "ABCD2345",
+		GuestName: "John Doe",
+		Email: "johndoe@gmail.com",
+		RoomName: "Garden Suite",
+		StartDate: today.AddDate(0, 0, -5),
+		EndDate: today.AddDate(0, 0, -2),
+		Lines: []models.InvoiceLine{{Description: "Garden Suite", Quantity: 3, Amount: 40138}},
+		Subtotal: 40138,
+		Total: 40138,
+		Paid: 40138,
+		IssuedAt: today.AddDate(0, 0, -1),
+	}, nil
+}
+
+// Exchange rates
+func (m *testDBRepo) UpsertExchangeRate(ctx context.Context, tx *sql.Tx, rate models.ExchangeRate) (int, error) {
+	// simulate failure for rates into XXX
+	if rate.QuoteCurrency == "XXX" {
+		return 0, errors.New("failed to upsert exchange rate")
+	}
+
+	return 1, nil
+}
+
+func (m *testDBRepo) GetExchangeRates(ctx context.Context, tx *sql.Tx) ([]models.ExchangeRate, error) {
+	return []models.ExchangeRate{
+		{ID: 1, BaseCurrency: "USD", QuoteCurrency: "EUR", Rate: 920000},
+		{ID: 2, BaseCurrency: "USD", QuoteCurrency: "JPY", Rate: 151250000},
+	}, nil
+}
+
+// GetExchangeRate converts USD into EUR at 0.92 and into JPY at 151.25, other pairs have no rate
+func (m *testDBRepo) GetExchangeRate(ctx context.Context, tx *sql.Tx, base, quote string) (models.ExchangeRate, error) {
+	if quote == "XXX" {
+		return models.ExchangeRate{}, errors.New("error getting exchange rate")
+	}
+
+	rates, _ := m.GetExchangeRates(ctx, tx)
+	for _, rate := range rates {
+		if rate.BaseCurrency == base && rate.QuoteCurrency == quote {
+			return rate, nil
+		}
+	}
+
+	return models.ExchangeRate{}, sql.ErrNoRows
+}
+
+func (m *testDBRepo) DeleteExchangeRate(ctx context.Context, tx *sql.Tx, id int) error {
+	if id == 404 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// Guests
+func (m *testDBRepo) LockGuestKey(ctx context.Context, tx *sql.Tx, key string) error {
+	return nil
+}
+
+// testGuests returns John Doe, guest 1, who the test reservations are attached to, Jane's by mistake, and a second
+// profile of John booked from his work address, guest 2
+func testGuests() []models.Guest {
+	return []models.Guest{
+		{ID: 1, FirstName: "John", LastName: "Doe", Email: "johndoe@gmail.com", Phone: "08012345678", EmailKey: "johndoe@gmail.com", PhoneKey: "+2348012345678"},
+		{ID: 2, FirstName: "John", LastName: "Doe", Email: "john.doe@example.com", EmailKey: "john.doe@example.com"},
+	}
+}
+
+func (m *testDBRepo) FindGuest(ctx context.Context, tx *sql.Tx, emailKey, phoneKey string) (models.Guest, error) {
+	// simulate failure for the email key fail@example.com
+	if emailKey == "fail@example.com" {
+		return models.Guest{}, errors.New("error finding guest")
+	}
+
+	for _, g := range testGuests() {
+		if g.EmailKey == emailKey || (phoneKey != "" && g.PhoneKey == phoneKey) {
+			return g, nil
+		}
+	}
+
+	return models.Guest{}, sql.ErrNoRows
+}
+
+func (m *testDBRepo) InsertGuest(ctx context.Context, tx *sql.Tx, g models.Guest) (int, error) {
+	return 3, nil
+}
+
+func (m *testDBRepo) GetGuestById(ctx context.Context, tx *sql.Tx, id int) (models.Guest, error) {
+	if id == 1000 {
+		return models.Guest{}, errors.New("error getting guest")
+	}
+
+	for _, g := range testGuests() {
+		if g.ID == id {
+			return g, nil
+		}
+	}
+
+	return models.Guest{}, sql.ErrNoRows
+}
+
+func (m *testDBRepo) GetGuests(ctx context.Context, tx *sql.Tx, search string) ([]models.Guest, error) {
+	// simulate failure for the search fail
+	if search == "fail" {
+		return nil, errors.New("error getting guests")
+	}
+
+	return testGuests(), nil
+}
+
+func (m *testDBRepo) UpdateReservationGuest(ctx context.Context, tx *sql.Tx, reservationId, fromGuestId, toGuestId int) error {
+	// reservation 1 was booked before guest profiles
+	if reservationId == 1 && fromGuestId == 0 {
+		return nil
+	}
+
+	for _, res := range testReservations() {
+		if res.ID == reservationId && res.GuestID == fromGuestId {
+			return nil
+		}
+	}
+
+	return sql.ErrNoRows
+}
+
+func (m *testDBRepo) MergeGuests(ctx context.Context, tx *sql.Tx, targetId, sourceId int) error {
+	if _, err := m.GetGuestById(ctx, tx, sourceId); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *testDBRepo) GetReservationsWithoutGuest(ctx context.Context, tx *sql.Tx, limit int) ([]models.Reservation, error) {
+	return []models.Reservation{
+		{ID: 1, FirstName: "John", LastName: "Doe", Email: "johndoe@gmail.com"},
+	}, nil
+}
+
+// Notes
+func (m *testDBRepo) InsertReservationNote(ctx context.Context, tx *sql.Tx, n models.ReservationNote) (int, error) {
+	// simulate failure for the note fail
+	if n.Body == "fail" {
+		return 0, errors.New("failed to insert note")
+	}
+
+	return 1, nil
+}
+
+// GetReservationNotes returns a note on every reservation
+func (m *testDBRepo) GetReservationNotes(ctx context.Context, tx *sql.Tx, reservationId int) ([]models.ReservationNote, error) {
+	return []models.ReservationNote{
+		{ID: 1, ReservationID: reservationId, Author: "admin@example.com", Body: "Regular guest, offer an upgrade if one is free", CreatedAt: time.Date(2049, time.December, 1, 9, 0, 0, 0, time.UTC)},
+	}, nil
+}
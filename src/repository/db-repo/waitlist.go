@@ -0,0 +1,211 @@
+package dbrepo
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+)
+
+const waitlistColumns = `id, room_id, start_date, end_date, first_name, last_name, email, phone, status,
+			offer_room_id, offer_token, offer_expires_at, created_at, updated_at`
+
+func scanWaitlistEntry(row interface{ Scan(dest ...interface{}) error }) (models.WaitlistEntry, error) {
+	var e models.WaitlistEntry
+	var roomId, offerRoomId sql.NullInt64
+	var offerToken sql.NullString
+	var offerExpiresAt sql.NullTime
+
+	err := row.Scan(
+		&e.ID,
+		&roomId,
+		&e.StartDate,
+		&e.EndDate,
+		&e.FirstName,
+		&e.LastName,
+		&e.Email,
+		&e.Phone,
+		&e.Status,
+		&offerRoomId,
+		&offerToken,
+		&offerExpiresAt,
+		&e.CreatedAt,
+		&e.UpdatedAt,
+	)
+
+	e.RoomID = int(roomId.Int64)
+	e.OfferRoomID = int(offerRoomId.Int64)
+	e.OfferToken = offerToken.String
+	e.OfferExpiresAt = offerExpiresAt.Time
+
+	return e, err
+}
+
+func (m *postgresDBRepo) queryWaitlistEntries(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) ([]models.WaitlistEntry, error) {
+	var entries = make([]models.WaitlistEntry, 0)
+
+	var rows *sql.Rows
+	var err error
+	if tx != nil {
+		rows, err = tx.QueryContext(ctx, query, args...)
+	}else{
+		rows, err = m.DB.QueryContext(ctx, query, args...)
+	}
+	if err != nil {
+		return entries, err
+	}
+	defer rows.Close()
+
+	for rows.Next(){
+		e, err := scanWaitlistEntry(rows)
+		if err != nil {
+			return entries, err
+		}
+		entries = append(entries, e)
+	}
+
+	if err = rows.Err(); err != nil {
+		return entries, err
+	}
+
+	return entries, nil
+}
+
+func (m *postgresDBRepo) InsertWaitlistEntry(ctx context.Context, tx *sql.Tx, e models.WaitlistEntry) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var newId int
+
+	stmt := `insert into waitlist_entries (room_id, start_date, end_date, first_name, last_name, email, phone,
+			status, created_at, updated_at)
+			values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) returning id`
+
+	var err error
+	if tx != nil {
+		err = tx.QueryRowContext(
+			ctx, stmt,
+			nullableInt(e.RoomID),
+			e.StartDate,
+			e.EndDate,
+			e.FirstName,
+			e.LastName,
+			e.Email,
+			e.Phone,
+			e.Status,
+			time.Now(),
+			time.Now(),
+		).Scan(&newId)
+	}else{
+		err = m.DB.QueryRowContext(
+			ctx, stmt,
+			nullableInt(e.RoomID),
+			e.StartDate,
+			e.EndDate,
+			e.FirstName,
+			e.LastName,
+			e.Email,
+			e.Phone,
+			e.Status,
+			time.Now(),
+			time.Now(),
+		).Scan(&newId)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return newId, nil
+}
+
+// GetWaitlistEntries returns the entries with the given status, or every entry when status is empty, oldest first
+func (m *postgresDBRepo) GetWaitlistEntries(ctx context.Context, tx *sql.Tx, status string) ([]models.WaitlistEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := `select ` + waitlistColumns + ` from waitlist_entries where ($1 = '' or status = $1) order by created_at, id`
+
+	return m.queryWaitlistEntries(ctx, tx, query, status)
+}
+
+// GetWaitingEntriesForRoom returns the guests still waiting for the room, or for any room, whose dates overlap the window.
+// Entries are in the order they joined the waitlist
+func (m *postgresDBRepo) GetWaitingEntriesForRoom(ctx context.Context, tx *sql.Tx, roomId int, start, end time.Time) ([]models.WaitlistEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := `
+		select ` + waitlistColumns + `
+		from
+			waitlist_entries
+		where
+			status = $1 and (room_id = $2 or room_id is null) and $3 < end_date and $4 > start_date
+		order by
+			created_at, id
+	`
+
+	return m.queryWaitlistEntries(ctx, tx, query, models.WaitlistStatusWaiting, roomId, start, end)
+}
+
+// GetExpiredWaitlistOffers returns the open offers that lapsed at or before now
+func (m *postgresDBRepo) GetExpiredWaitlistOffers(ctx context.Context, tx *sql.Tx, now time.Time) ([]models.WaitlistEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := `select ` + waitlistColumns + ` from waitlist_entries where status = $1 and offer_expires_at <= $2 order by created_at, id`
+
+	return m.queryWaitlistEntries(ctx, tx, query, models.WaitlistStatusOffered, now)
+}
+
+// UpdateWaitlistOffer saves the status and offer details of an entry
+func (m *postgresDBRepo) UpdateWaitlistOffer(ctx context.Context, tx *sql.Tx, e models.WaitlistEntry) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	stmt := `
+		update
+			waitlist_entries
+		set
+			status = $1, offer_room_id = $2, offer_token = $3, offer_expires_at = $4, updated_at = $5
+		where
+			id = $6
+	`
+
+	args := []interface{}{e.Status, nullableInt(e.OfferRoomID), nullableString(e.OfferToken), nullableTime(e.OfferExpiresAt), time.Now(), e.ID}
+
+	var err error
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, stmt, args...)
+	}else{
+		_, err = m.DB.ExecContext(ctx, stmt, args...)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// AcceptWaitlistOffer marks the open offer made under the hold token as accepted. Tokens that belong to no offer are ignored
+func (m *postgresDBRepo) AcceptWaitlistOffer(ctx context.Context, tx *sql.Tx, token string) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	stmt := `update waitlist_entries set status = $1, updated_at = $2 where offer_token = $3 and status = $4`
+
+	var err error
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, stmt, models.WaitlistStatusAccepted, time.Now(), token, models.WaitlistStatusOffered)
+	}else{
+		_, err = m.DB.ExecContext(ctx, stmt, models.WaitlistStatusAccepted, time.Now(), token, models.WaitlistStatusOffered)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
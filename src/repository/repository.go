@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+	"github.com/Orololuwa/go-backend-boilerplate/src/money"
 )
 
 type DatabaseRepo interface {
@@ -16,8 +17,132 @@ type DatabaseRepo interface {
 	SearchAvailabilityForAllRooms(ctx context.Context, tx *sql.Tx, start, end time.Time) ([]models.Room, error)
 	GetRoomById(ctx context.Context, tx *sql.Tx, id int) (models.Room, error)
 	GetAllRooms(ctx context.Context, tx *sql.Tx, id int, room_name string, created_at string, updated_at string)([]models.Room, error)
+	EachRoom(ctx context.Context, tx *sql.Tx, id int, room_name string, fn func(models.Room) error) error
+	InsertRoom(ctx context.Context, tx *sql.Tx, room models.Room) (int, error)
+	LockRoom(ctx context.Context, tx *sql.Tx, roomId int) error
+	LockReservation(ctx context.Context, tx *sql.Tx, id int) error
+	InsertRoomHold(ctx context.Context, tx *sql.Tx, r models.RoomRestriction) (int, error)
+	GetRoomHoldByToken(ctx context.Context, tx *sql.Tx, token string) (models.RoomRestriction, error)
+	DeleteRoomRestriction(ctx context.Context, tx *sql.Tx, id int) error
+	DeleteExpiredHolds(ctx context.Context, tx *sql.Tx, now time.Time) (int64, error)
+	ConfirmationCodeExists(ctx context.Context, tx *sql.Tx, code string) (bool, error)
+	GetReservationByConfirmationCode(ctx context.Context, tx *sql.Tx, code, lastName string) (models.Reservation, error)
+	GetReservationById(ctx context.Context, tx *sql.Tx, id int) (models.Reservation, error)
+	GetReservations(ctx context.Context, tx *sql.Tx, filter models.ReservationFilter) ([]models.Reservation, error)
+	EachReservation(ctx context.Context, tx *sql.Tx, filter models.ReservationFilter, fn func(models.Reservation) error) error
+	UpdateReservationStatus(ctx context.Context, tx *sql.Tx, id int, status string) error
+	UpdateReservationStay(ctx context.Context, tx *sql.Tx, res models.Reservation) error
+	UpdateReservationRequestsResolved(ctx context.Context, tx *sql.Tx, id int, resolved bool) error
+	DeleteRoomRestrictionsByReservationId(ctx context.Context, tx *sql.Tx, reservationId int) error
+	InsertReservationGroup(ctx context.Context, tx *sql.Tx, g models.ReservationGroup) (int, error)
+	GetReservationGroupById(ctx context.Context, tx *sql.Tx, id int) (models.ReservationGroup, error)
+	UpdateReservationGroupStatus(ctx context.Context, tx *sql.Tx, id int, status string) error
+	GetReservationsByGroupId(ctx context.Context, tx *sql.Tx, groupId int) ([]models.Reservation, error)
+	UpdateRoomRates(ctx context.Context, tx *sql.Tx, roomId int, baseRate, weekendRate money.Amount, currency string) error
+	UpdateRoomOccupancy(ctx context.Context, tx *sql.Tx, roomId, maxOccupancy int) error
+	InsertRoomRate(ctx context.Context, tx *sql.Tx, rate models.RoomRate) (int, error)
+	GetRoomRatesForDates(ctx context.Context, tx *sql.Tx, roomId int, start, end time.Time) ([]models.RoomRate, error)
+	GetRoomRatesByRoomId(ctx context.Context, tx *sql.Tx, roomId int) ([]models.RoomRate, error)
+	DeleteRoomRate(ctx context.Context, tx *sql.Tx, roomId, id int) error
+	InsertWaitlistEntry(ctx context.Context, tx *sql.Tx, e models.WaitlistEntry) (int, error)
+	GetWaitlistEntries(ctx context.Context, tx *sql.Tx, status string) ([]models.WaitlistEntry, error)
+	GetWaitingEntriesForRoom(ctx context.Context, tx *sql.Tx, roomId int, start, end time.Time) ([]models.WaitlistEntry, error)
+	GetExpiredWaitlistOffers(ctx context.Context, tx *sql.Tx, now time.Time) ([]models.WaitlistEntry, error)
+	UpdateWaitlistOffer(ctx context.Context, tx *sql.Tx, e models.WaitlistEntry) error
+	AcceptWaitlistOffer(ctx context.Context, tx *sql.Tx, token string) error
+	GetRoomCalendarToken(ctx context.Context, tx *sql.Tx, roomId int) (string, error)
+	UpdateRoomCalendarToken(ctx context.Context, tx *sql.Tx, roomId int, token string) error
+	GetRoomReports(ctx context.Context, tx *sql.Tx, start, end time.Time) ([]models.RoomReport, error)
+	GetCalendarGrid(ctx context.Context, tx *sql.Tx, roomId int, start, end time.Time) ([]models.RoomCalendar, error)
+	GetRoomRestrictionsForCalendar(ctx context.Context, tx *sql.Tx, roomId int, from time.Time) ([]models.RoomRestriction, error)
+	InsertCalendarFeed(ctx context.Context, tx *sql.Tx, f models.CalendarFeed) (int, error)
+	GetCalendarFeedById(ctx context.Context, tx *sql.Tx, id int) (models.CalendarFeed, error)
+	GetCalendarFeedsByRoomId(ctx context.Context, tx *sql.Tx, roomId int) ([]models.CalendarFeed, error)
+	GetRemoteCalendarFeeds(ctx context.Context, tx *sql.Tx) ([]models.CalendarFeed, error)
+	UpdateCalendarFeedStatus(ctx context.Context, tx *sql.Tx, f models.CalendarFeed) error
+	DeleteCalendarFeed(ctx context.Context, tx *sql.Tx, roomId, id int) error
+	GetExternalBlocksByFeedId(ctx context.Context, tx *sql.Tx, feedId int) ([]models.RoomRestriction, error)
+	InsertExternalBlock(ctx context.Context, tx *sql.Tx, r models.RoomRestriction) (int, error)
+	UpdateRoomRestrictionDates(ctx context.Context, tx *sql.Tx, id int, start, end time.Time) error
+	GetRestrictions(ctx context.Context, tx *sql.Tx) ([]models.Restriction, error)
+	GetRestrictionById(ctx context.Context, tx *sql.Tx, id int) (models.Restriction, error)
+	InsertRestriction(ctx context.Context, tx *sql.Tx, r models.Restriction) (int, error)
+	UpdateRestriction(ctx context.Context, tx *sql.Tx, r models.Restriction) error
+	DeleteRestriction(ctx context.Context, tx *sql.Tx, id int) error
+	RestrictionInUse(ctx context.Context, tx *sql.Tx, id int) (bool, error)
+	InsertRoomBlock(ctx context.Context, tx *sql.Tx, r models.RoomRestriction) (int, error)
+	GetRoomBlocks(ctx context.Context, tx *sql.Tx, roomId int, start, end time.Time) ([]models.RoomRestriction, error)
+	DeleteRoomBlock(ctx context.Context, tx *sql.Tx, roomId, id int) error
+	InsertRecurringBlock(ctx context.Context, tx *sql.Tx, b models.RecurringBlock) (int, error)
+	GetRecurringBlockById(ctx context.Context, tx *sql.Tx, id int) (models.RecurringBlock, error)
+	GetRecurringBlocksByRoomId(ctx context.Context, tx *sql.Tx, roomId int) ([]models.RecurringBlock, error)
+	DeleteRecurringBlock(ctx context.Context, tx *sql.Tx, roomId, id int) error
+	InsertRecurringBlockException(ctx context.Context, tx *sql.Tx, blockId int, occurrence time.Time) error
+	InsertStayRule(ctx context.Context, tx *sql.Tx, rule models.StayRule) (int, error)
+	GetStayRulesForDates(ctx context.Context, tx *sql.Tx, roomId int, start, end time.Time) ([]models.StayRule, error)
+	GetStayRulesByRoomId(ctx context.Context, tx *sql.Tx, roomId int) ([]models.StayRule, error)
+	DeleteStayRule(ctx context.Context, tx *sql.Tx, roomId, id int) error
+	InsertCancellationPolicy(ctx context.Context, tx *sql.Tx, p models.CancellationPolicy) (int, error)
+	GetCancellationPolicies(ctx context.Context, tx *sql.Tx) ([]models.CancellationPolicy, error)
+	GetCancellationPolicyById(ctx context.Context, tx *sql.Tx, id int) (models.CancellationPolicy, error)
+	GetCancellationPolicyForStay(ctx context.Context, tx *sql.Tx, roomId, rateId int) (models.CancellationPolicy, error)
+	DeleteCancellationPolicy(ctx context.Context, tx *sql.Tx, id int) error
+	UpdateRoomCancellationPolicy(ctx context.Context, tx *sql.Tx, roomId, policyId int) error
+	UpdateReservationCancellation(ctx context.Context, tx *sql.Tx, id int, status string, fee, refund money.Amount) error
+	InsertReservationHistory(ctx context.Context, tx *sql.Tx, h models.ReservationHistory) (int, error)
+	GetReservationHistory(ctx context.Context, tx *sql.Tx, reservationId int) ([]models.ReservationHistory, error)
+	InsertPromoCode(ctx context.Context, tx *sql.Tx, p models.PromoCode) (int, error)
+	GetPromoCodes(ctx context.Context, tx *sql.Tx) ([]models.PromoCode, error)
+	GetPromoCodeById(ctx context.Context, tx *sql.Tx, id int) (models.PromoCode, error)
+	GetPromoCodeByCode(ctx context.Context, tx *sql.Tx, code string) (models.PromoCode, error)
+	LockPromoCode(ctx context.Context, tx *sql.Tx, code string) (models.PromoCode, error)
+	UpdatePromoCode(ctx context.Context, tx *sql.Tx, p models.PromoCode) error
+	DeletePromoCode(ctx context.Context, tx *sql.Tx, id int) error
+	CountPromoRedemptionsByEmail(ctx context.Context, tx *sql.Tx, promoCodeId int, emailKey string) (int, error)
+	InsertPromoRedemption(ctx context.Context, tx *sql.Tx, r models.PromoRedemption) (int, error)
+	InsertChargeRule(ctx context.Context, tx *sql.Tx, rule models.ChargeRule) (int, error)
+	GetChargeRules(ctx context.Context, tx *sql.Tx) ([]models.ChargeRule, error)
+	DeleteChargeRule(ctx context.Context, tx *sql.Tx, id int) error
+	InsertReservationCharges(ctx context.Context, tx *sql.Tx, reservationId int, lines []models.ReservationCharge) error
+	DeleteReservationCharges(ctx context.Context, tx *sql.Tx, reservationId int) error
+	GetReservationCharges(ctx context.Context, tx *sql.Tx, reservationId int) ([]models.ReservationCharge, error)
+	NextInvoiceSequence(ctx context.Context, tx *sql.Tx, series string) (int, error)
+	InsertInvoice(ctx context.Context, tx *sql.Tx, inv models.Invoice) (int, error)
+	GetInvoiceByReservationId(ctx context.Context, tx *sql.Tx, reservationId int) (models.Invoice, error)
+	UpsertExchangeRate(ctx context.Context, tx *sql.Tx, rate models.ExchangeRate) (int, error)
+	GetExchangeRates(ctx context.Context, tx *sql.Tx) ([]models.ExchangeRate, error)
+	GetExchangeRate(ctx context.Context, tx *sql.Tx, base, quote string) (models.ExchangeRate, error)
+	DeleteExchangeRate(ctx context.Context, tx *sql.Tx, id int) error
+
+	LockGuestKey(ctx context.Context, tx *sql.Tx, key string) error
+	FindGuest(ctx context.Context, tx *sql.Tx, emailKey, phoneKey string) (models.Guest, error)
+	InsertGuest(ctx context.Context, tx *sql.Tx, g models.Guest) (int, error)
+	GetGuestById(ctx context.Context, tx *sql.Tx, id int) (models.Guest, error)
+	GetGuests(ctx context.Context, tx *sql.Tx, search string) ([]models.Guest, error)
+	UpdateReservationGuest(ctx context.Context, tx *sql.Tx, reservationId, fromGuestId, toGuestId int) error
+	MergeGuests(ctx context.Context, tx *sql.Tx, targetId, sourceId int) error
+	GetReservationsWithoutGuest(ctx context.Context, tx *sql.Tx, limit int) ([]models.Reservation, error)
+
+	InsertReservationNote(ctx context.Context, tx *sql.Tx, n models.ReservationNote) (int, error)
+	GetReservationNotes(ctx context.Context, tx *sql.Tx, reservationId int) ([]models.ReservationNote, error)
 }
 
 type UserDBRepo interface {
 	CreateAUser(ctx context.Context, tx *sql.Tx, user models.User) (int, error)
+}
+
+type IdempotencyRepo interface {
+	ReserveIdempotencyKey(ctx context.Context, tx *sql.Tx, rec models.IdempotencyRecord) (bool, error)
+	GetIdempotencyRecord(ctx context.Context, tx *sql.Tx, key string) (models.IdempotencyRecord, error)
+	CompleteIdempotencyRecord(ctx context.Context, tx *sql.Tx, key, leaseToken string, statusCode int, contentType string, body []byte) error
+	DeleteIdempotencyRecord(ctx context.Context, tx *sql.Tx, key, leaseToken string) error
+	DeleteExpiredIdempotencyRecords(ctx context.Context, tx *sql.Tx, now time.Time) (int64, error)
+}
+
+type PaymentDBRepo interface {
+	InsertPayment(ctx context.Context, tx *sql.Tx, p models.Payment) (int, error)
+	GetPaymentsByReservationId(ctx context.Context, tx *sql.Tx, reservationId int) ([]models.Payment, error)
+	GetLatestPaymentByReservationId(ctx context.Context, tx *sql.Tx, reservationId int, status ...string) (models.Payment, error)
+	GetPaymentByReference(ctx context.Context, tx *sql.Tx, provider, reference string) (models.Payment, error)
+	UpdatePayment(ctx context.Context, tx *sql.Tx, p models.Payment) error
 }
\ No newline at end of file
@@ -0,0 +1,143 @@
+package stayrules
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+)
+
+// Violation codes
+const (
+	CodeMinNights = "min_nights"
+	CodeMaxNights = "max_nights"
+	CodeClosedToArrival = "closed_to_arrival"
+	CodeClosedToDeparture = "closed_to_departure"
+	CodeMinLeadTime = "min_lead_time"
+	CodeMaxLeadTime = "max_lead_time"
+)
+
+// Violation is a rule the stay breaks
+type Violation struct {
+	Code string `json:"code"`
+	Message string `json:"message"`
+	RuleID int `json:"ruleId,omitempty"`
+}
+
+// ViolationError refuses a booking that breaks the room's stay rules. It is marshalled into error responses as is,
+// so the client learns every rule it has to satisfy at once
+type ViolationError struct {
+	Violations []Violation `json:"violations"`
+}
+
+func (e *ViolationError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		messages[i] = v.Message
+	}
+	return "stay is not allowed: " + strings.Join(messages, "; ")
+}
+
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func daysBetween(from, to time.Time) int {
+	return int(day(to).Sub(day(from)).Hours() / 24)
+}
+
+// ruleFor picks the rule covering the date. When rules overlap the most recently created one wins
+func ruleFor(rules []models.StayRule, roomId int, date time.Time) (models.StayRule, bool) {
+	var found models.StayRule
+	ok := false
+
+	for _, rule := range rules {
+		if rule.RoomID != roomId {
+			continue
+		}
+
+		if date.Before(rule.StartDate) || !date.Before(rule.EndDate) {
+			continue
+		}
+
+		if !ok || rule.ID > found.ID {
+			found = rule
+			ok = true
+		}
+	}
+
+	return found, ok
+}
+
+// Check returns the rules a stay in the room from start to end breaks when it is booked on today.
+// The length of stay, arrival day and lead time are checked against the rule covering the arrival,
+// the departure day against the rule covering the departure
+func Check(rules []models.StayRule, roomId int, start, end, today time.Time) []Violation {
+	violations := make([]Violation, 0)
+
+	if rule, ok := ruleFor(rules, roomId, start); ok {
+		nights := daysBetween(start, end)
+		lead := daysBetween(today, start)
+
+		if rule.MinNights > 0 && nights < rule.MinNights {
+			violations = append(violations, Violation{
+				Code: CodeMinNights,
+				Message: fmt.Sprintf("stays arriving on %s must be at least %d nights", start.Format("2006-01-02"), rule.MinNights),
+				RuleID: rule.ID,
+			})
+		}
+
+		if rule.MaxNights > 0 && nights > rule.MaxNights {
+			violations = append(violations, Violation{
+				Code: CodeMaxNights,
+				Message: fmt.Sprintf("stays arriving on %s can be at most %d nights", start.Format("2006-01-02"), rule.MaxNights),
+				RuleID: rule.ID,
+			})
+		}
+
+		if rule.ClosedToArrival.Has(start.Weekday()) {
+			violations = append(violations, Violation{
+				Code: CodeClosedToArrival,
+				Message: fmt.Sprintf("arrivals are not allowed on %s", start.Weekday()),
+				RuleID: rule.ID,
+			})
+		}
+
+		if rule.MinLeadDays > 0 && lead < rule.MinLeadDays {
+			violations = append(violations, Violation{
+				Code: CodeMinLeadTime,
+				Message: fmt.Sprintf("stays must be booked at least %d days before arrival", rule.MinLeadDays),
+				RuleID: rule.ID,
+			})
+		}
+
+		if rule.MaxLeadDays > 0 && lead > rule.MaxLeadDays {
+			violations = append(violations, Violation{
+				Code: CodeMaxLeadTime,
+				Message: fmt.Sprintf("stays can be booked at most %d days before arrival", rule.MaxLeadDays),
+				RuleID: rule.ID,
+			})
+		}
+	}
+
+	if rule, ok := ruleFor(rules, roomId, end); ok && rule.ClosedToDeparture.Has(end.Weekday()) {
+		violations = append(violations, Violation{
+			Code: CodeClosedToDeparture,
+			Message: fmt.Sprintf("departures are not allowed on %s", end.Weekday()),
+			RuleID: rule.ID,
+		})
+	}
+
+	return violations
+}
+
+// Validate returns a *ViolationError when the stay breaks any rule
+func Validate(rules []models.StayRule, roomId int, start, end, today time.Time) error {
+	violations := Check(rules, roomId, start, end, today)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	return &ViolationError{Violations: violations}
+}
@@ -0,0 +1,86 @@
+package stayrules
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+)
+
+func date(s string) time.Time {
+	d, _ := time.Parse("2006-01-02", s)
+	return d
+}
+
+func codes(violations []Violation) []string {
+	c := make([]string, len(violations))
+	for i, v := range violations {
+		c[i] = v.Code
+	}
+	return c
+}
+
+func TestCheck(t *testing.T){
+	sunday, _ := models.ParseWeekdays([]string{"sunday"})
+	monday, _ := models.ParseWeekdays([]string{"mon"})
+
+	rules := []models.StayRule{
+		{ID: 1, RoomID: 1, StartDate: date("2050-01-01"), EndDate: date("2051-01-01"), MinNights: 2, MaxNights: 14, ClosedToArrival: sunday, MinLeadDays: 1, MaxLeadDays: 365},
+		// a newer summer rule replaces the year round one for arrivals in July
+		{ID: 2, RoomID: 1, StartDate: date("2050-07-01"), EndDate: date("2050-08-01"), MinNights: 7, ClosedToDeparture: monday},
+		{ID: 3, RoomID: 2, StartDate: date("2050-01-01"), EndDate: date("2051-01-01"), MinNights: 30},
+	}
+
+	today := date("2050-01-01")
+
+	// 2050-01-02 is a Sunday, 2050-07-04 a Monday
+	var checkTests = []struct {
+		name string
+		start string
+		end string
+		today time.Time
+		expected []string
+	}{
+		{"allowed", "2050-01-04", "2050-01-07", today, []string{}},
+		{"too short", "2050-01-04", "2050-01-05", today, []string{CodeMinNights}},
+		{"too long", "2050-01-04", "2050-02-04", today, []string{CodeMaxNights}},
+		{"sunday arrival booked the same day", "2050-01-02", "2050-01-05", date("2050-01-02"), []string{CodeClosedToArrival, CodeMinLeadTime}},
+		{"too far ahead", "2050-12-20", "2050-12-23", date("2049-12-01"), []string{CodeMaxLeadTime}},
+		{"summer rule wins", "2050-06-27", "2050-07-04", today, []string{CodeClosedToDeparture}},
+		{"summer minimum", "2050-07-02", "2050-07-05", today, []string{CodeMinNights}},
+		{"no rule covers the stay", "2052-01-04", "2052-01-05", today, []string{}},
+	}
+
+	for _, e := range checkTests {
+		got := codes(Check(rules, 1, date(e.start), date(e.end), e.today))
+		if len(got) != len(e.expected) {
+			t.Errorf("%s: got %v, wanted %v", e.name, got, e.expected)
+			continue
+		}
+		for i := range got {
+			if got[i] != e.expected[i] {
+				t.Errorf("%s: got %v, wanted %v", e.name, got, e.expected)
+				break
+			}
+		}
+	}
+}
+
+func TestValidate(t *testing.T){
+	rules := []models.StayRule{
+		{ID: 1, RoomID: 1, StartDate: date("2050-01-01"), EndDate: date("2051-01-01"), MinNights: 2},
+	}
+
+	err := Validate(rules, 1, date("2050-01-04"), date("2050-01-05"), date("2050-01-01"))
+
+	var violationErr *ViolationError
+	if !errors.As(err, &violationErr) || len(violationErr.Violations) != 1 {
+		t.Fatalf("Validate expected a ViolationError with one violation, got %v", err)
+	}
+
+	err = Validate(rules, 1, date("2050-01-04"), date("2050-01-06"), date("2050-01-01"))
+	if err != nil {
+		t.Errorf("Validate expected no error, got %v", err)
+	}
+}
@@ -0,0 +1,196 @@
+package types
+
+import (
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+	"github.com/Orololuwa/go-backend-boilerplate/src/money"
+	"github.com/Orololuwa/go-backend-boilerplate/src/pricing"
+	"github.com/Orololuwa/go-backend-boilerplate/src/promo"
+	"github.com/Orololuwa/go-backend-boilerplate/src/stayrules"
+)
+
+type RoomHoldResponse struct {
+	HoldToken string `json:"holdToken"`
+	RoomId int `json:"roomId"`
+	StartDate string `json:"startDate"`
+	EndDate string `json:"endDate"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+type ReservationResponse struct {
+	ID int `json:"id"`
+	ConfirmationCode string `json:"confirmationCode"`
+	FirstName string `json:"firstName"`
+	LastName string `json:"lastName"`
+	Email string `json:"email"`
+	Phone string `json:"phone"`
+	StartDate string `json:"startDate"`
+	EndDate string `json:"endDate"`
+	RoomId int `json:"roomId"`
+	RoomName string `json:"roomName,omitempty"`
+	Adults int `json:"adults"`
+	Children int `json:"children"`
+	TotalPrice money.Amount `json:"totalPrice"`
+	Currency string `json:"currency"`
+	BaseCurrency string `json:"baseCurrency,omitempty"`
+	ExchangeRate money.Rate `json:"exchangeRate,omitempty"`
+	GuestId int `json:"guestId,omitempty"`
+	SpecialRequests string `json:"specialRequests,omitempty"`
+	RequestsResolved bool `json:"requestsResolved,omitempty"`
+	Status string `json:"status"`
+	CancellationFee money.Amount `json:"cancellationFee"`
+	RefundAmount money.Amount `json:"refundAmount"`
+	CancellationPolicy *models.CancellationPolicy `json:"cancellationPolicy,omitempty"`
+	PromoCode string `json:"promoCode,omitempty"`
+	Discount money.Amount `json:"discount"`
+	Charges []models.ReservationCharge `json:"charges,omitempty"`
+}
+
+type RoomQuoteResponse struct {
+	RoomId int `json:"roomId"`
+	RoomName string `json:"roomName"`
+	Available bool `json:"available"`
+	StartDate string `json:"startDate"`
+	EndDate string `json:"endDate"`
+	Nights []pricing.Night `json:"nights"`
+	Total money.Amount `json:"total"`
+	Currency string `json:"currency"`
+	BaseCurrency string `json:"baseCurrency,omitempty"`
+	ExchangeRate money.Rate `json:"exchangeRate,omitempty"`
+	CancellationPolicy *models.CancellationPolicy `json:"cancellationPolicy,omitempty"`
+	CancellationTerms string `json:"cancellationTerms"`
+	PromoCode string `json:"promoCode,omitempty"`
+	Discount money.Amount `json:"discount"`
+	PromoError *promo.Error `json:"promoError,omitempty"`
+	Subtotal money.Amount `json:"subtotal"`
+	Charges []models.ReservationCharge `json:"charges"`
+}
+
+type ReservationGroupResponse struct {
+	ID int `json:"id"`
+	ConfirmationCode string `json:"confirmationCode"`
+	GroupName string `json:"groupName"`
+	FirstName string `json:"firstName"`
+	LastName string `json:"lastName"`
+	Email string `json:"email"`
+	Phone string `json:"phone"`
+	Status string `json:"status"`
+	TotalPrice money.Amount `json:"totalPrice"`
+	Currency string `json:"currency"`
+	Reservations []ReservationResponse `json:"reservations"`
+}
+
+type RoomBlockResponse struct {
+	ID int `json:"id"`
+	RoomId int `json:"roomId"`
+	RestrictionId int `json:"restrictionId"`
+	RestrictionName string `json:"restrictionName"`
+	StartDate string `json:"startDate"`
+	EndDate string `json:"endDate"`
+	Note string `json:"note"`
+}
+
+type RecurringBlockResponse struct {
+	ID int `json:"id"`
+	RoomId int `json:"roomId"`
+	RestrictionId int `json:"restrictionId"`
+	RestrictionName string `json:"restrictionName"`
+	StartDate string `json:"startDate"`
+	Nights int `json:"nights"`
+	RRule string `json:"rrule"`
+	Note string `json:"note"`
+	Exceptions []string `json:"exceptions"`
+	Occurrences []string `json:"occurrences"`
+}
+
+type ExcludedRoom struct {
+	RoomId int `json:"roomId"`
+	RoomName string `json:"roomName"`
+	Reasons []stayrules.Violation `json:"reasons"`
+}
+
+// AlternativeDates is a stay the rooms are free for
+type AlternativeDates struct {
+	StartDate string `json:"startDate"`
+	EndDate string `json:"endDate"`
+	Rooms []models.Room `json:"rooms"`
+}
+
+// AvailabilityResponse lists the rooms that take the party. When none does on its own, Combinations suggests
+// sets of available rooms that sleep it together. Alternatives are the nearest other dates with a room for the party
+type AvailabilityResponse struct {
+	Rooms []models.Room `json:"rooms"`
+	Excluded []ExcludedRoom `json:"excluded"`
+	Combinations [][]models.Room `json:"combinations"`
+	Alternatives []AlternativeDates `json:"alternatives"`
+}
+
+type DateRange struct {
+	StartDate string `json:"startDate"`
+	EndDate string `json:"endDate"`
+}
+
+// RoomAvailabilityResponse tells why the room cannot be booked and, when it cannot, the nearest dates it can
+type RoomAvailabilityResponse struct {
+	Available bool `json:"available"`
+	Reasons []stayrules.Violation `json:"reasons"`
+	NearestWindows []DateRange `json:"nearestWindows"`
+}
+
+// CalendarGridSpan covers Nights consecutive days of a row from Day, the index of its first day in the grid
+type CalendarGridSpan struct {
+	Day int `json:"day"`
+	Nights int `json:"nights"`
+	Status string `json:"status"`
+	ReservationId int `json:"reservationId,omitempty"`
+	Restriction string `json:"restriction,omitempty"`
+}
+
+// CalendarGridRow lists the booked and blocked spans of a room, the days in no span are free
+type CalendarGridRow struct {
+	RoomId int `json:"roomId"`
+	RoomName string `json:"roomName"`
+	Spans []CalendarGridSpan `json:"spans"`
+}
+
+type CalendarGridResponse struct {
+	StartDate string `json:"startDate"`
+	EndDate string `json:"endDate"`
+	Days int `json:"days"`
+	Rooms []CalendarGridRow `json:"rooms"`
+}
+
+// ReportResponse holds one row per room and a total over every room for the report's date range
+type ReportResponse struct {
+	StartDate string `json:"startDate"`
+	EndDate string `json:"endDate"`
+	Days int `json:"days"`
+	Rooms interface{} `json:"rooms"`
+	Total interface{} `json:"total"`
+}
+
+// ImportRowError lists what is wrong with a row of an imported file, the header is line 1
+type ImportRowError struct {
+	Line int `json:"line"`
+	Errors []string `json:"errors"`
+}
+
+// ImportResult reports on an imported file. Nothing is saved when the file has errors or on a dry run
+type ImportResult struct {
+	DryRun bool `json:"dryRun"`
+	Rows int `json:"rows"`
+	Imported int `json:"imported"`
+	Errors []ImportRowError `json:"errors"`
+}
+// GuestResponse is a guest profile with the reservations attached to it and what they add up to
+type GuestResponse struct {
+	models.Guest
+	Stats models.GuestStats `json:"stats"`
+	Reservations []ReservationResponse `json:"reservations"`
+}
+
+// GuestBackfillResult reports how many reservations booked before guest profiles were attached to one
+type GuestBackfillResult struct {
+	Linked int `json:"linked"`
+}
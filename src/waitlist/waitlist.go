@@ -0,0 +1,186 @@
+package waitlist
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/config"
+	"github.com/Orololuwa/go-backend-boilerplate/src/helpers"
+	"github.com/Orololuwa/go-backend-boilerplate/src/models"
+	"github.com/Orololuwa/go-backend-boilerplate/src/notifications"
+	"github.com/Orololuwa/go-backend-boilerplate/src/repository"
+)
+
+const defaultOfferDuration = 24 * time.Hour
+
+// Service turns released room dates into offers for the guests on the waitlist.
+// An offer is a room hold, so the guest accepts it by booking with the hold token
+type Service struct {
+	App *config.AppConfig
+	DB repository.DatabaseRepo
+	Notifier notifications.Notifier
+}
+
+func New(a *config.AppConfig, db repository.DatabaseRepo, notifier notifications.Notifier) *Service {
+	return &Service{
+		App: a,
+		DB: db,
+		Notifier: notifier,
+	}
+}
+
+func (s *Service) offerDuration() time.Duration {
+	if s.App.WaitlistOfferDuration > 0 {
+		return s.App.WaitlistOfferDuration
+	}
+
+	return defaultOfferDuration
+}
+
+func overlaps(a, b models.WaitlistEntry) bool {
+	return a.StartDate.Before(b.EndDate) && a.EndDate.After(b.StartDate)
+}
+
+// OfferReleased offers the room to the waiting guests whose dates overlap the released window, first come first served.
+// It returns the entries that received an offer
+func (s *Service) OfferReleased(ctx context.Context, roomId int, start, end time.Time) ([]models.WaitlistEntry, error) {
+	offered := make([]models.WaitlistEntry, 0)
+
+	entries, err := s.DB.GetWaitingEntriesForRoom(ctx, nil, roomId, start, end)
+	if err != nil {
+		return offered, err
+	}
+
+	for _, entry := range entries {
+		// a guest further down the list never jumps ahead of an earlier guest who wants the same nights
+		skip := false
+		for _, o := range offered {
+			if overlaps(o, entry) {
+				skip = true
+				break
+			}
+		}
+		if skip {
+			continue
+		}
+
+		ok, err := s.offer(ctx, roomId, &entry)
+		if err != nil {
+			return offered, err
+		}
+		if !ok {
+			continue
+		}
+
+		offered = append(offered, entry)
+		s.notify(ctx, entry)
+	}
+
+	return offered, nil
+}
+
+// offer holds the room for the entry's dates and records the offer. It reports false when the room is not free for them
+func (s *Service) offer(ctx context.Context, roomId int, entry *models.WaitlistEntry) (bool, error) {
+	token, err := helpers.RandomToken(16)
+	if err != nil {
+		return false, err
+	}
+
+	available := false
+
+	err = s.DB.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		err := s.DB.LockRoom(ctx, tx, roomId)
+		if err != nil {
+			return err
+		}
+
+		available, err = s.DB.SearchAvailabilityForDatesByRoomId(ctx, tx, entry.StartDate, entry.EndDate, roomId)
+		if err != nil || !available {
+			return err
+		}
+
+		entry.Status = models.WaitlistStatusOffered
+		entry.OfferRoomID = roomId
+		entry.OfferToken = token
+		entry.OfferExpiresAt = time.Now().Add(s.offerDuration())
+
+		_, err = s.DB.InsertRoomHold(ctx, tx, models.RoomRestriction{
+			StartDate: entry.StartDate,
+			EndDate: entry.EndDate,
+			RoomID: roomId,
+			RestrictionID: models.RestrictionHold,
+			HoldToken: token,
+			ExpiresAt: entry.OfferExpiresAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		return s.DB.UpdateWaitlistOffer(ctx, tx, *entry)
+	})
+
+	return available, err
+}
+
+// notify failures are logged and do not withdraw the offer, the guest can still accept it with the token
+func (s *Service) notify(ctx context.Context, entry models.WaitlistEntry) {
+	layout := "2006-01-02"
+
+	err := s.Notifier.Send(ctx, notifications.Message{
+		To: entry.Email,
+		Subject: "A room is available for your dates",
+		Body: fmt.Sprintf(
+			"Room %d is held for you from %s to %s until %s. Book it with the hold token %s.",
+			entry.OfferRoomID,
+			entry.StartDate.Format(layout),
+			entry.EndDate.Format(layout),
+			entry.OfferExpiresAt.Format(time.RFC1123),
+			entry.OfferToken,
+		),
+	})
+	if err != nil {
+		s.App.ErrorLog.Println(err)
+	}
+}
+
+// ExpireOffers closes the offers that were not accepted in time, releases their holds
+// and passes the room on to the next guest. It returns how many offers expired
+func (s *Service) ExpireOffers(ctx context.Context, now time.Time) (int, error) {
+	entries, err := s.DB.GetExpiredWaitlistOffers(ctx, nil, now)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, entry := range entries {
+		err = s.DB.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+			hold, err := s.DB.GetRoomHoldByToken(ctx, tx, entry.OfferToken)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return err
+			}
+
+			// the hold sweeper may already have removed it
+			if err == nil {
+				err = s.DB.DeleteRoomRestriction(ctx, tx, hold.ID)
+				if err != nil {
+					return err
+				}
+			}
+
+			entry.Status = models.WaitlistStatusExpired
+			return s.DB.UpdateWaitlistOffer(ctx, tx, entry)
+		})
+		if err != nil {
+			return 0, err
+		}
+
+		_, err = s.OfferReleased(ctx, entry.OfferRoomID, entry.StartDate, entry.EndDate)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(entries), nil
+}
@@ -0,0 +1,82 @@
+package waitlist
+
+import (
+	"context"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Orololuwa/go-backend-boilerplate/src/config"
+	"github.com/Orololuwa/go-backend-boilerplate/src/notifications"
+	dbrepo "github.com/Orololuwa/go-backend-boilerplate/src/repository/db-repo"
+)
+
+func newTestService() (*Service, *notifications.MemoryNotifier) {
+	app := &config.AppConfig{
+		ErrorLog: log.New(os.Stdout, "ERROR\t", log.Ldate|log.Ltime|log.Lshortfile),
+		WaitlistOfferDuration: time.Hour,
+	}
+	notifier := notifications.NewMemoryNotifier()
+
+	return New(app, dbrepo.NewTestingDBRepo(), notifier), notifier
+}
+
+func TestOfferReleased(t *testing.T){
+	s, notifier := newTestService()
+	start := time.Date(2050, time.January, 6, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2050, time.January, 16, 0, 0, 0, 0, time.UTC)
+
+	// the second guest overlaps the first, so only the first and third get an offer
+	offered, err := s.OfferReleased(context.Background(), 15, start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(offered) != 2 || offered[0].ID != 1 || offered[1].ID != 3 {
+		t.Fatalf("OfferReleased expected offers for entries 1 and 3, got %+v", offered)
+	}
+
+	for _, e := range offered {
+		if e.OfferToken == "" || e.OfferRoomID != 15 || !e.OfferExpiresAt.After(time.Now()) {
+			t.Errorf("OfferReleased returned an incomplete offer: %+v", e)
+		}
+	}
+
+	messages := notifier.Messages()
+	if len(messages) != 2 || messages[0].To != "guest1@gmail.com" || messages[1].To != "guest3@gmail.com" {
+		t.Errorf("OfferReleased expected notifications for guest1 and guest3, got %+v", messages)
+	}
+
+	// test that nothing is offered when the room is still taken
+	offered, err = s.OfferReleased(context.Background(), 3, start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(offered) != 0 {
+		t.Errorf("OfferReleased expected no offers for an unavailable room, got %d", len(offered))
+	}
+
+	_, err = s.OfferReleased(context.Background(), 500, start, end)
+	if err == nil {
+		t.Error("OfferReleased expected an error when the waitlist cannot be read")
+	}
+}
+
+func TestExpireOffers(t *testing.T){
+	s, notifier := newTestService()
+
+	expired, err := s.ExpireOffers(context.Background(), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expired != 1 {
+		t.Errorf("ExpireOffers expected 1 expired offer, got %d", expired)
+	}
+
+	// the released room is passed on to the next guests in line
+	if len(notifier.Messages()) == 0 {
+		t.Error("ExpireOffers did not pass the room on to the next guest")
+	}
+}